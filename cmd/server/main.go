@@ -16,9 +16,11 @@ import (
 	"github.com/unalkalkan/TwelveReader/internal/book"
 	"github.com/unalkalkan/TwelveReader/internal/config"
 	"github.com/unalkalkan/TwelveReader/internal/health"
+	"github.com/unalkalkan/TwelveReader/internal/packaging"
 	"github.com/unalkalkan/TwelveReader/internal/parser"
 	"github.com/unalkalkan/TwelveReader/internal/provider"
 	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/internal/webhook"
 	"github.com/unalkalkan/TwelveReader/pkg/types"
 )
 
@@ -58,12 +60,19 @@ func main() {
 	log.Printf("  TTS: %v", providerRegistry.ListTTS())
 	log.Printf("  OCR: %v", providerRegistry.ListOCR())
 
-	// Initialize book repository
-	bookRepo := book.NewRepository(storageAdapter)
-	log.Printf("Book repository initialized")
+	// Initialize book repository, notifying webhooks of book status changes
+	webhooks := webhook.NewDispatcher(storageAdapter, cfg.Webhooks)
+	webhooks.SetRetryPolicy(cfg.Pipeline.MaxRetries, cfg.Pipeline.RetryBackoffMs)
+	bookRepo := webhook.WatchRepository(book.NewRepository(storageAdapter), webhooks)
+	log.Printf("Book repository initialized (%d configured webhooks)", len(cfg.Webhooks))
 
-	// Initialize parser factory
-	parserFactory := parser.NewFactory()
+	// Initialize parser factory, using the first OCR provider for scanned PDFs
+	var ocrProvider provider.OCRProvider
+	if ocrProviders := providerRegistry.ListOCR(); len(ocrProviders) > 0 {
+		ocrProvider, _ = providerRegistry.GetOCR(ocrProviders[0])
+		log.Printf("PDF OCR enabled with provider: %s", ocrProviders[0])
+	}
+	parserFactory := parser.NewFactoryWithOCR(ocrProvider)
 	log.Printf("Parser factory initialized")
 
 	// Initialize health checks
@@ -114,8 +123,19 @@ func main() {
 	mux.HandleFunc("/api/v1/voices/preview", voicesHandler.PreviewVoice)
 
 	// Book API endpoints (Milestone 3)
-	bookHandler := api.NewBookHandler(bookRepo, parserFactory, providerRegistry, storageAdapter)
+	bookHandler := api.NewBookHandlerWithUploadLimit(bookRepo, parserFactory, providerRegistry, storageAdapter, int64(cfg.Server.MaxUploadMB)<<20)
+	bookHandler.SetChapterAudioSilence(time.Duration(cfg.Pipeline.ChapterAudioSilenceMs) * time.Millisecond)
+	if encoder := packaging.DetectEncoder(); encoder != "" {
+		log.Printf("M4B downloads are encoded to AAC with %s", encoder)
+		bookHandler.SetM4BEncoder(encoder)
+	} else {
+		log.Printf("ffmpeg not found, M4B downloads hold uncompressed PCM audio")
+	}
 	debugHandler := api.NewDebugHandler(bookRepo, storageAdapter)
+
+	// Resume books left mid-pipeline by a previous run
+	go bookHandler.ResumeUnfinishedBooks(context.Background())
+
 	mux.HandleFunc("/api/v1/books", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
 			bookHandler.UploadBook(w, r)
@@ -127,34 +147,10 @@ func main() {
 		}
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	})
-	mux.HandleFunc("/api/v1/books/", func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Path
-		if r.Method == http.MethodDelete {
-			bookHandler.DeleteBook(w, r)
-		} else if strings.HasSuffix(path, "/status") {
-			bookHandler.GetBookStatus(w, r)
-		} else if strings.HasSuffix(path, "/segments") {
-			bookHandler.ListSegments(w, r)
-		} else if strings.HasSuffix(path, "/voice-map") {
-			if r.Method == http.MethodPost {
-				bookHandler.SetVoiceMap(w, r)
-			} else {
-				bookHandler.GetVoiceMap(w, r)
-			}
-		} else if strings.HasSuffix(path, "/stream") {
-			bookHandler.StreamSegments(w, r)
-		} else if strings.HasSuffix(path, "/download") {
-			bookHandler.DownloadBook(w, r)
-		} else if strings.Contains(path, "/pipeline/status") {
-			bookHandler.GetPipelineStatus(w, r)
-		} else if strings.HasSuffix(path, "/personas") {
-			bookHandler.GetPersonas(w, r)
-		} else if strings.Contains(path, "/audio/") {
-			bookHandler.GetAudio(w, r)
-		} else {
-			bookHandler.GetBook(w, r)
-		}
-	})
+	mux.HandleFunc("/api/v1/books/", bookHandler.Route)
+	webhookHandler := api.NewWebhookHandler(webhooks)
+	mux.HandleFunc("/api/v1/webhooks", webhookHandler.Subscriptions)
+	mux.HandleFunc("/api/v1/webhooks/", webhookHandler.Subscription)
 	mux.HandleFunc("/api/v1/debug/events", debugHandler.Events)
 	mux.HandleFunc("/api/v1/debug/stream", debugHandler.EventStream)
 	mux.HandleFunc("/api/v1/debug/books/", func(w http.ResponseWriter, r *http.Request) {
@@ -165,6 +161,8 @@ func main() {
 			debugHandler.AudioValidation(w, r)
 		} else if strings.HasSuffix(path, "/playback-events") {
 			debugHandler.PlaybackEvents(w, r)
+		} else if strings.HasSuffix(path, "/webhook-deliveries") {
+			debugHandler.WebhookDeliveries(w, r)
 		} else if strings.HasSuffix(path, "/user-progress") {
 			debugHandler.UserProgress(w, r)
 		} else if strings.HasSuffix(path, "/events") {
@@ -209,6 +207,13 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Let pending webhook deliveries and their retries finish
+	webhookCtx, webhookCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer webhookCancel()
+	if err := webhooks.Shutdown(webhookCtx); err != nil {
+		log.Printf("Gave up on pending webhook deliveries: %v", err)
+	}
+
 	log.Println("Server stopped")
 }
 
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+)
+
+// serveStoredContent writes the data at path with http.ServeContent, which
+// answers range requests with 206 Partial Content and conditional requests
+// (If-None-Match, If-Modified-Since) with 304 Not Modified. Only the requested
+// byte ranges are read from storage.
+func serveStoredContent(w http.ResponseWriter, r *http.Request, adapter storage.Adapter, metadata *storage.Metadata, contentType string) {
+	w.Header().Set("Content-Type", contentType)
+	if metadata.ETag != "" {
+		w.Header().Set("ETag", metadata.ETag)
+	}
+	var modTime time.Time
+	if metadata.LastModified > 0 {
+		modTime = time.Unix(metadata.LastModified, 0)
+	}
+
+	content := &storageContent{ctx: r.Context(), adapter: adapter, path: metadata.Path, size: metadata.Size}
+	defer content.Close()
+	http.ServeContent(w, r, "", modTime, content)
+}
+
+// storageContent is an io.ReadSeeker over stored data. Reads after a seek open
+// a ranged read at the new offset.
+type storageContent struct {
+	ctx     context.Context
+	adapter storage.Adapter
+	path    string
+	size    int64
+	offset  int64
+	reader  io.ReadCloser // Open ranged read at offset, if any
+}
+
+func (c *storageContent) Read(p []byte) (int, error) {
+	if c.offset >= c.size {
+		return 0, io.EOF
+	}
+	if c.reader == nil {
+		reader, err := c.adapter.GetRange(c.ctx, c.path, c.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		c.reader = reader
+	}
+	n, err := c.reader.Read(p)
+	c.offset += int64(n)
+	return n, err
+}
+
+func (c *storageContent) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += c.offset
+	case io.SeekEnd:
+		offset += c.size
+	}
+	if offset < 0 {
+		return 0, errors.New("seek before start of content")
+	}
+	if offset != c.offset {
+		c.Close()
+		c.offset = offset
+	}
+	return offset, nil
+}
+
+// Close closes the open ranged read, if any
+func (c *storageContent) Close() error {
+	if c.reader == nil {
+		return nil
+	}
+	err := c.reader.Close()
+	c.reader = nil
+	return err
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/packaging"
+	"github.com/unalkalkan/TwelveReader/internal/util"
+)
+
+const (
+	// defaultChapterAudioSilence is the silence between segments in chapter
+	// audio unless configured otherwise
+	defaultChapterAudioSilence = 300 * time.Millisecond
+
+	// maxChapterAudioSilenceMs caps the silence a request can ask for
+	maxChapterAudioSilenceMs = 10000
+)
+
+// SetChapterAudioSilence sets the default silence between segments in chapter audio
+func (h *BookHandler) SetChapterAudioSilence(silence time.Duration) {
+	h.chapterAudioSilence = silence
+}
+
+// ChapterAudio handles GET /api/v1/books/:id/chapters/:chapterId/audio, which
+// streams the audio of all segments of a chapter as one WAV file, and
+// GET /api/v1/books/:id/chapters/:chapterId/audio/index, which returns where
+// each segment plays in that file. The optional silence_ms query parameter
+// overrides the silence between segments.
+func (h *BookHandler) ChapterAudio(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bookID := extractIDFromPath(r.URL.Path, "/api/v1/books/")
+	if bookID == "" {
+		respondError(w, "Book ID required", http.StatusBadRequest)
+		return
+	}
+
+	// Path after /chapters/ is :chapterId/audio or :chapterId/audio/index
+	parts := strings.Split(strings.SplitN(r.URL.Path, "/chapters/", 2)[1], "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] != "audio" || (len(parts) == 3 && parts[2] != "index") || len(parts) > 3 {
+		respondError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	chapterID := parts[0]
+	wantIndex := len(parts) == 3
+
+	silence := h.chapterAudioSilence
+	if value := r.URL.Query().Get("silence_ms"); value != "" {
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms < 0 || ms > maxChapterAudioSilenceMs {
+			respondError(w, "silence_ms must be between 0 and 10000", http.StatusBadRequest)
+			return
+		}
+		silence = time.Duration(ms) * time.Millisecond
+	}
+
+	if _, err := h.repo.GetBook(r.Context(), bookID); err != nil {
+		respondError(w, "Book not found", http.StatusNotFound)
+		return
+	}
+
+	// Building a long chapter on a cache miss can outlast the server's
+	// write timeout before the first byte is sent
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	index, audioPath, err := h.packagingService.ChapterAudio(r.Context(), bookID, chapterID, silence)
+	if err != nil {
+		switch {
+		case errors.Is(err, packaging.ErrChapterNotFound):
+			respondError(w, "Chapter not found", http.StatusNotFound)
+		case errors.Is(err, packaging.ErrChapterAudioIncomplete), errors.Is(err, packaging.ErrChapterAudioFormat):
+			respondError(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("[ChapterAudio] Failed to build audio of chapter %s of book %s: %v", chapterID, bookID, err)
+			respondError(w, "Failed to build chapter audio", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if wantIndex {
+		respondJSON(w, index, http.StatusOK)
+		return
+	}
+
+	metadata, err := h.storage.Stat(r.Context(), audioPath)
+	if err != nil {
+		respondError(w, "Chapter audio not found", http.StatusNotFound)
+		return
+	}
+	serveStoredContent(w, r, h.storage, metadata, util.AudioMimeType(index.Format))
+}
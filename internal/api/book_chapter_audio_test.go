@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/internal/audio"
+	"github.com/unalkalkan/TwelveReader/internal/packaging"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestBookHandler_ChapterAudio(t *testing.T) {
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+
+	// Mono 16-bit audio at 1000 Hz, 2000 bytes per second
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:2], 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:4], 1)
+	binary.LittleEndian.PutUint32(fmtChunk[4:8], 1000)
+	binary.LittleEndian.PutUint32(fmtChunk[8:12], 2000)
+	binary.LittleEndian.PutUint16(fmtChunk[12:14], 2)
+	binary.LittleEndian.PutUint16(fmtChunk[14:16], 16)
+
+	handler.repo.SaveBook(ctx, &types.Book{ID: "book_ch", Status: "synthesized"})
+	handler.repo.SaveChapter(ctx, &types.Chapter{ID: "ch_001", BookID: "book_ch", Number: 1})
+	handler.repo.SaveChapter(ctx, &types.Chapter{ID: "ch_002", BookID: "book_ch", Number: 2})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: "book_ch", Chapter: "ch_001", Text: "One."})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00002", BookID: "book_ch", Chapter: "ch_001", Text: "Two."})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00003", BookID: "book_ch", Chapter: "ch_002", Text: "Three."})
+	handler.storage.Put(ctx, "books/book_ch/audio/seg_00001.wav", bytes.NewReader(audio.EncodeWAV(fmtChunk, make([]byte, 1000))))
+	handler.storage.Put(ctx, "books/book_ch/audio/seg_00002.wav", bytes.NewReader(audio.EncodeWAV(fmtChunk, make([]byte, 600))))
+
+	t.Run("Index", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ChapterAudio(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_ch/chapters/ch_001/audio/index?silence_ms=200", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var index packaging.ChapterAudioIndex
+		json.NewDecoder(w.Body).Decode(&index)
+		if len(index.Segments) != 2 || index.Segments[1].SegmentID != "seg_00002" || index.Segments[1].Start != 0.7 || index.Duration != 1.0 {
+			t.Errorf("Unexpected index: %+v", index)
+		}
+	})
+
+	t.Run("Audio", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ChapterAudio(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_ch/chapters/ch_001/audio?silence_ms=200", nil))
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "audio/wav" {
+			t.Fatalf("Expected WAV audio, got %d: %s", w.Code, w.Body.String())
+		}
+		parsed, err := audio.ParseWAV(w.Body.Bytes())
+		if err != nil || len(parsed.Data) != 2000 {
+			t.Fatalf("Expected 2000 bytes of joined audio, got %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/books/book_ch/chapters/ch_001/audio?silence_ms=200", nil)
+		req.Header.Set("Range", "bytes=0-3")
+		w = httptest.NewRecorder()
+		handler.ChapterAudio(w, req)
+		if w.Code != http.StatusPartialContent || w.Body.String() != "RIFF" {
+			t.Errorf("Expected partial chapter audio, got %d %q", w.Code, w.Body.String())
+		}
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"Unknown book", "/api/v1/books/book_missing/chapters/ch_001/audio", http.StatusNotFound},
+		{"Unknown chapter", "/api/v1/books/book_ch/chapters/ch_404/audio", http.StatusNotFound},
+		{"Unknown sub-resource", "/api/v1/books/book_ch/chapters/ch_001/audio/other", http.StatusNotFound},
+		{"Missing audio", "/api/v1/books/book_ch/chapters/ch_002/audio", http.StatusConflict},
+		{"Invalid silence", "/api/v1/books/book_ch/chapters/ch_001/audio?silence_ms=-1", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ChapterAudio(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/unalkalkan/TwelveReader/internal/packaging"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// downloadEPUB writes the book as an EPUB3 whose media overlays highlight
+// each segment's text while its audio plays
+func (h *BookHandler) downloadEPUB(w http.ResponseWriter, r *http.Request, book *types.Book) {
+	epub, err := h.packagingService.PackageEPUB(r.Context(), book.ID)
+	if err != nil {
+		if errors.Is(err, packaging.ErrBookNotSynthesized) {
+			respondError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("[DownloadBook] Failed to build EPUB of book %s: %v", book.ID, err)
+		respondError(w, "Failed to build EPUB", http.StatusInternalServerError)
+		return
+	}
+	defer epub.Close()
+
+	w.Header().Set("Content-Type", "application/epub+zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", downloadFilename(book, "epub")))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, epub); err != nil {
+		log.Printf("[DownloadBook] Failed to stream EPUB of book %s: %v", book.ID, err)
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestBookHandler_DownloadBookEPUB(t *testing.T) {
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+
+	handler.repo.SaveBook(ctx, &types.Book{ID: "book_epub", Title: "Read Along", Status: "synthesized"})
+	handler.repo.SaveChapter(ctx, &types.Chapter{ID: "ch_001", BookID: "book_epub", Number: 1, Title: "Opening"})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: "book_epub", Chapter: "ch_001", Text: "One."})
+	handler.storage.Put(ctx, "books/book_epub/audio/seg_00001.mp3", bytes.NewReader([]byte("ID3")))
+
+	w := httptest.NewRecorder()
+	handler.DownloadBook(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_epub/download?format=epub", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/epub+zip" {
+		t.Fatalf("Expected an EPUB, got %d: %s", w.Code, w.Body.String())
+	}
+	if disposition := w.Header().Get("Content-Disposition"); disposition != "attachment; filename=Read_Along.epub" {
+		t.Errorf("Unexpected Content-Disposition: %s", disposition)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil || zipReader.File[0].Name != "mimetype" {
+		t.Fatalf("Expected an EPUB container, got %v", err)
+	}
+
+	t.Run("Requires synthesis", func(t *testing.T) {
+		handler.repo.SaveBook(ctx, &types.Book{ID: "book_epub_pending", Status: "synthesizing"})
+		w := httptest.NewRecorder()
+		handler.DownloadBook(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_epub_pending/download?format=epub", nil))
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/events"
+)
+
+// eventStreamKeepAlive is how often an idle event stream sends a comment so
+// proxies keep the connection open
+const eventStreamKeepAlive = 15 * time.Second
+
+// BookEvents handles GET /api/v1/books/:id/events, a Server-Sent Events
+// stream of a book's pipeline events. The stream starts with a status event
+// holding the book, then sends events as they are published. Clients
+// reconnecting with Last-Event-ID first receive the events they missed.
+func (h *BookHandler) BookEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bookID := extractIDFromPath(r.URL.Path, "/api/v1/books/")
+	if bookID == "" {
+		respondError(w, "Book ID required", http.StatusBadRequest)
+		return
+	}
+	book, err := h.repo.GetBook(r.Context(), bookID)
+	if err != nil {
+		respondError(w, "Book not found", http.StatusNotFound)
+		return
+	}
+
+	var lastEventID uint64
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		lastEventID, err = strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			respondError(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	replay, published, cancel := h.events.Subscribe(bookID, lastEventID)
+	defer cancel()
+
+	// The stream outlives the server's write timeout
+	controller := http.NewResponseController(w)
+	controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	data, _ := json.Marshal(book)
+	fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+	for _, event := range replay {
+		if err := writeSSEEvent(w, event); err != nil {
+			return
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-published:
+			if !ok {
+				// Dropped for falling behind; the client resumes with Last-Event-ID
+				log.Printf("[BookEvents] Closing slow event stream of book %s", bookID)
+				return
+			}
+			if err := writeSSEEvent(w, event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeSSEEvent writes a bus event as a Server-Sent Event
+func writeSSEEvent(w http.ResponseWriter, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/pipeline"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestBookHandler_BookEvents(t *testing.T) {
+	handler := newTestBookHandler(t)
+	handler.repo.SaveBook(context.Background(), &types.Book{ID: "book_events", Status: "segmenting"})
+
+	handler.events.Publish("book_events", pipeline.EventStage, pipeline.StageProgress{Stage: "segmenting", Status: "in_progress"})
+	handler.events.Publish("book_other", pipeline.EventStage, pipeline.StageProgress{Stage: "segmenting"})
+	handler.events.Publish("book_events", pipeline.EventSegmentReady, pipeline.SegmentEvent{SegmentID: "seg_00001"})
+
+	stream := func(lastEventID string) string {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/books/book_events/events", nil).WithContext(ctx)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		w := httptest.NewRecorder()
+		handler.BookEvents(w, req)
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("Expected an event stream, got %d: %s", w.Code, w.Body.String())
+		}
+		return w.Body.String()
+	}
+
+	t.Run("Replays the book's events", func(t *testing.T) {
+		body := stream("")
+		if !strings.HasPrefix(body, "event: status\ndata: {") || !strings.Contains(body, `"status":"segmenting"`) {
+			t.Errorf("Expected an initial status event, got %q", body)
+		}
+		if !strings.Contains(body, "id: 1\nevent: stage\n") || !strings.Contains(body, "id: 3\nevent: segment_ready\n") {
+			t.Errorf("Expected both book events, got %q", body)
+		}
+		if strings.Contains(body, "id: 2\n") {
+			t.Errorf("Expected no events of other books, got %q", body)
+		}
+	})
+
+	t.Run("Resumes after Last-Event-ID", func(t *testing.T) {
+		body := stream("1")
+		if strings.Contains(body, "id: 1\n") || !strings.Contains(body, `"segment_id":"seg_00001"`) {
+			t.Errorf("Expected only events after 1, got %q", body)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.BookEvents(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_missing/events", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/books/book_events/events", nil)
+		req.Header.Set("Last-Event-ID", "abc")
+		w = httptest.NewRecorder()
+		handler.BookEvents(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
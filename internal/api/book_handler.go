@@ -1,18 +1,24 @@
 package api
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"mime/multipart"
+	"mime"
 	"net/http"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/internal/events"
 	"github.com/unalkalkan/TwelveReader/internal/packaging"
 	"github.com/unalkalkan/TwelveReader/internal/parser"
 	"github.com/unalkalkan/TwelveReader/internal/pipeline"
@@ -33,11 +39,23 @@ type BookHandler struct {
 	hybridOrchestrator *pipeline.HybridOrchestrator
 	packagingService   *packaging.Service
 	streamingService   *streaming.Service
+	events             *events.Bus
 	storage            storage.Adapter
+	importClient       *http.Client
+	maxUploadSize      int64 // bytes
+
+	chapterAudioSilence time.Duration // between segments in chapter audio
+	contentLocks        sync.Map      // Serializes duplicate checks of each content hash
 }
 
 // NewBookHandler creates a new book handler
 func NewBookHandler(repo book.Repository, parserFactory parser.Factory, providerReg *provider.Registry, storage storage.Adapter) *BookHandler {
+	return NewBookHandlerWithUploadLimit(repo, parserFactory, providerReg, storage, defaultMaxUploadSize)
+}
+
+// NewBookHandlerWithUploadLimit creates a new book handler that accepts book
+// files of up to maxUploadSize bytes
+func NewBookHandlerWithUploadLimit(repo book.Repository, parserFactory parser.Factory, providerReg *provider.Registry, storage storage.Adapter, maxUploadSize int64) *BookHandler {
 	// Get first available LLM provider for hybrid orchestrator
 	var llmProvider provider.LLMProvider
 	llmProviders := providerReg.ListLLM()
@@ -45,7 +63,7 @@ func NewBookHandler(repo book.Repository, parserFactory parser.Factory, provider
 		llmProvider, _ = providerReg.GetLLM(llmProviders[0])
 	}
 
-	return &BookHandler{
+	handler := &BookHandler{
 		repo:            repo,
 		parserFactory:   parserFactory,
 		providerReg:     providerReg,
@@ -58,9 +76,16 @@ func NewBookHandler(repo book.Repository, parserFactory parser.Factory, provider
 			providerReg,
 		),
 		packagingService: packaging.NewService(repo, storage),
-		streamingService: streaming.NewService(repo),
+		streamingService: streaming.NewService(repo, storage),
+		events:           events.NewBus(events.DefaultHistorySize),
 		storage:          storage,
+		importClient:     newImportClient(false),
+		maxUploadSize:    maxUploadSize,
+
+		chapterAudioSilence: defaultChapterAudioSilence,
 	}
+	handler.hybridOrchestrator.SetEventBus(handler.events)
+	return handler
 }
 
 // ListBooks handles GET /api/v1/books
@@ -80,102 +105,293 @@ func (h *BookHandler) ListBooks(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, books, http.StatusOK)
 }
 
-// UploadBook handles POST /api/v1/books
+// UploadBook handles POST /api/v1/books. The multipart body is read as a
+// stream and the file is written straight to storage, so uploads are never
+// held in memory.
 func (h *BookHandler) UploadBook(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Parse multipart form (max 100MB)
-	if err := r.ParseMultipartForm(100 << 20); err != nil {
+	ctx := r.Context()
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadSize+uploadFormOverhead)
+	reader, err := r.MultipartReader()
+	if err != nil {
 		log.Printf("Upload form parse failed: content_length=%d content_type=%q err=%v", r.ContentLength, r.Header.Get("Content-Type"), err)
 		respondError(w, "Failed to parse form", http.StatusBadRequest)
 		return
 	}
 
-	// Get file from form
-	file, header, err := r.FormFile("file")
-	if err != nil {
-		log.Printf("Upload missing file field: content_length=%d content_type=%q form_keys=%v err=%v", r.ContentLength, r.Header.Get("Content-Type"), formKeys(r.MultipartForm), err)
+	// Generate book ID
+	newBook := &types.Book{
+		ID:         fmt.Sprintf("book_%d", time.Now().UnixNano()),
+		UploadedAt: time.Now(),
+		Status:     "uploaded",
+	}
+
+	// Remove a stored raw file when the upload is rejected after it
+	fail := func(message string, status int) {
+		if newBook.OrigFormat != "" {
+			h.repo.DeleteBook(ctx, newBook.ID)
+		}
+		respondError(w, message, status)
+	}
+
+	// Form fields may come before or after the file
+	var fieldNames []string
+	var cloneDuplicate bool
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("Upload form parse failed: content_length=%d content_type=%q err=%v", r.ContentLength, r.Header.Get("Content-Type"), err)
+			if isFileTooLarge(err) {
+				fail(h.fileTooLargeMessage(), http.StatusRequestEntityTooLarge)
+			} else {
+				fail("Failed to parse form", http.StatusBadRequest)
+			}
+			return
+		}
+		fieldNames = append(fieldNames, part.FormName())
+
+		switch part.FormName() {
+		case "file":
+			if part.FileName() == "" || newBook.OrigFormat != "" {
+				break
+			}
+			// Detect format from content, validated against the filename extension
+			head, file, err := peekHead(part)
+			if err != nil {
+				fail("Failed to read file", http.StatusBadRequest)
+				return
+			}
+			format, err := h.detectUploadFormat(part.FileName(), head)
+			if err != nil {
+				log.Printf("Upload format rejected: filename=%q err=%v", part.FileName(), err)
+				fail(fmt.Sprintf("Invalid upload: %v", err), http.StatusBadRequest)
+				return
+			}
+			newBook.OrigFormat = format
+			if err := h.saveRawFile(ctx, newBook, file); err != nil {
+				log.Printf("Upload raw file save failed: book=%s err=%v", newBook.ID, err)
+				if isFileTooLarge(err) {
+					fail(h.fileTooLargeMessage(), http.StatusRequestEntityTooLarge)
+				} else {
+					fail("Failed to save raw file", http.StatusInternalServerError)
+				}
+				return
+			}
+		case "title", "author", "language", "skip_front_matter", "clone_duplicate":
+			value, err := io.ReadAll(io.LimitReader(part, uploadFieldMaxSize))
+			if err != nil {
+				fail("Failed to parse form", http.StatusBadRequest)
+				return
+			}
+			switch part.FormName() {
+			case "title":
+				newBook.Title = string(value)
+			case "author":
+				newBook.Author = string(value)
+			case "language":
+				newBook.Language = string(value) // Empty fields are filled from document metadata
+			case "skip_front_matter":
+				newBook.SkipFrontMatter, _ = strconv.ParseBool(string(value))
+			case "clone_duplicate":
+				cloneDuplicate, _ = strconv.ParseBool(string(value))
+			}
+		}
+		part.Close()
+	}
+
+	if newBook.OrigFormat == "" {
+		log.Printf("Upload missing file field: content_length=%d content_type=%q form_keys=%v", r.ContentLength, r.Header.Get("Content-Type"), fieldNames)
 		respondError(w, "No file provided", http.StatusBadRequest)
 		return
 	}
-	defer file.Close()
 
-	// Get metadata
-	title := r.FormValue("title")
-	author := r.FormValue("author")
-	language := r.FormValue("language")
-	if language == "" {
-		language = "en"
+	h.createAndProcessBook(ctx, w, newBook, cloneDuplicate)
+}
+
+// detectUploadFormat picks the parser format for an upload. Content sniffed
+// as PDF, EPUB, DOCX or FB2 must agree with a supported filename extension
+// and is used as is when the extension is missing or unknown. Plain text and
+// HTML content cannot tell formats like Markdown apart, so any text format
+// extension is trusted for it.
+func (h *BookHandler) detectUploadFormat(filename string, data []byte) (string, error) {
+	detected := parser.DetectMIMEType(data)
+	sniffedParser, sniffedFormat, sniffErr := h.parserFactory.GetParserByMIME(detected)
+
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	extParser, extErr := h.parserFactory.GetParser(ext)
+	if ext == "" || extErr != nil {
+		if sniffErr != nil {
+			if ext != "" {
+				return "", fmt.Errorf("unsupported format: %s", ext)
+			}
+			return "", errors.New("could not detect file format")
+		}
+		return sniffedFormat, nil
 	}
 
-	// Detect format from filename
-	ext := strings.ToLower(filepath.Ext(header.Filename))
-	format := strings.TrimPrefix(ext, ".")
-	if format == "" {
-		respondError(w, "Could not detect file format", http.StatusBadRequest)
-		return
+	if sniffErr == nil && sniffedParser == extParser {
+		return ext, nil
+	}
+	weakSniff := detected == "text/plain" || detected == "text/html"
+	if sniffErr == nil && weakSniff && parser.IsTextMIMEType(extParser.SupportedMIMETypes()[0]) {
+		return ext, nil
 	}
 
-	// Validate format
-	if _, err := h.parserFactory.GetParser(format); err != nil {
-		respondError(w, fmt.Sprintf("Unsupported format: %s", format), http.StatusBadRequest)
-		return
+	if sniffErr != nil {
+		return "", fmt.Errorf("file content does not match its .%s extension (detected %s)", ext, detected)
+	}
+	return "", fmt.Errorf("file content does not match its .%s extension (detected %s)", ext, sniffedFormat)
+}
+
+// peekHead returns the first bytes of a file for format detection together
+// with a reader that still yields the whole file
+func peekHead(file io.Reader) ([]byte, io.Reader, error) {
+	buffered := bufio.NewReaderSize(file, parser.SniffLen)
+	head, err := buffered.Peek(parser.SniffLen)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
 	}
+	return head, buffered, nil
+}
 
-	// Read file data
-	data, err := io.ReadAll(file)
+// saveRawFile streams a book's raw file into storage, recording its size and
+// content hash on the book. Files over the upload limit fail with
+// errFileTooLarge; the caller removes what was stored.
+func (h *BookHandler) saveRawFile(ctx context.Context, newBook *types.Book, file io.Reader) error {
+	limited := &sizeLimitReader{r: file, limit: h.maxUploadSize}
+	info, err := h.repo.SaveRawFile(ctx, newBook.ID, limited, newBook.OrigFormat)
 	if err != nil {
-		respondError(w, "Failed to read file", http.StatusInternalServerError)
-		return
+		return err
 	}
+	newBook.FileSize = info.Size
+	newBook.ContentHash = info.ContentHash
+	return nil
+}
 
-	// Generate book ID
-	bookID := fmt.Sprintf("book_%d", time.Now().UnixNano())
+// fileTooLargeMessage describes the upload limit to clients
+func (h *BookHandler) fileTooLargeMessage() string {
+	return fmt.Sprintf("File exceeds the %d MB size limit", h.maxUploadSize>>20)
+}
 
-	// Create book metadata
-	newBook := &types.Book{
-		ID:         bookID,
-		Title:      title,
-		Author:     author,
-		Language:   language,
-		UploadedAt: time.Now(),
-		Status:     "uploaded",
-		OrigFormat: format,
+// createAndProcessBook saves a new book whose raw file is already stored,
+// starts processing in the background and responds with the created book.
+// Content that was already uploaded is not processed again: the existing book
+// is returned, or cloned when cloneDuplicate is set.
+func (h *BookHandler) createAndProcessBook(ctx context.Context, w http.ResponseWriter, newBook *types.Book, cloneDuplicate bool) {
+	// Concurrent uploads of the same content must not both miss each other
+	// and run the pipeline twice, so the lookup and saving the book that
+	// later lookups find are done under a lock per content hash
+	lockIface, _ := h.contentLocks.LoadOrStore(newBook.ContentHash, &sync.Mutex{})
+	lock := lockIface.(*sync.Mutex)
+	lock.Lock()
+
+	if existing := h.findDuplicate(ctx, newBook); existing != nil {
+		lock.Unlock()
+		h.respondDuplicate(ctx, w, newBook, existing, cloneDuplicate)
+		return
 	}
 
 	// Save book metadata
-	ctx := r.Context()
 	if err := h.repo.SaveBook(ctx, newBook); err != nil {
+		lock.Unlock()
+		h.repo.DeleteBook(ctx, newBook.ID)
 		respondError(w, "Failed to save book metadata", http.StatusInternalServerError)
 		return
 	}
-
-	// Save raw file
-	if err := h.repo.SaveRawFile(ctx, bookID, data, format); err != nil {
-		respondError(w, "Failed to save raw file", http.StatusInternalServerError)
-		return
-	}
+	lock.Unlock()
 
 	// Start async processing with proper error handling
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				log.Printf("[PANIC] Book processing for %s: %v", bookID, r)
-				h.updateBookError(context.Background(), bookID, fmt.Sprintf("Processing panic: %v", r))
-			}
-		}()
-		h.processBook(bookID, data, format)
-	}()
+	go h.processBookAsync(newBook.ID)
 
 	// Return success
 	respondJSON(w, newBook, http.StatusCreated)
 }
 
-// processBook handles async book processing using the hybrid pipeline
-func (h *BookHandler) processBook(bookID string, data []byte, format string) {
+// findDuplicate returns an earlier book with the same content, format and
+// front matter setting, so processing it again would give the same result.
+// Books that failed are ignored so a re-upload retries them.
+func (h *BookHandler) findDuplicate(ctx context.Context, newBook *types.Book) *types.Book {
+	books, err := h.repo.FindBooksByContentHash(ctx, newBook.ContentHash)
+	if err != nil {
+		log.Printf("[findDuplicate] Failed to look up content hash for book %s: %v", newBook.ID, err)
+		return nil
+	}
+	for _, existing := range books {
+		if existing.ID != newBook.ID && existing.Status != "error" &&
+			existing.OrigFormat == newBook.OrigFormat && existing.SkipFrontMatter == newBook.SkipFrontMatter {
+			return existing
+		}
+	}
+	return nil
+}
+
+// respondDuplicate answers an upload of content that already has a book. By
+// default the new raw file is dropped and the existing book returned with
+// 200 OK. A clone copies the finished book's chapters, segments and audio
+// under the new ID without any provider calls.
+func (h *BookHandler) respondDuplicate(ctx context.Context, w http.ResponseWriter, newBook, existing *types.Book, clone bool) {
+	if !clone {
+		log.Printf("[respondDuplicate] Upload %s duplicates book %s", newBook.ID, existing.ID)
+		h.repo.DeleteBook(ctx, newBook.ID)
+		respondJSON(w, existing, http.StatusOK)
+		return
+	}
+
+	if existing.Status != "synthesized" {
+		h.repo.DeleteBook(ctx, newBook.ID)
+		respondError(w, fmt.Sprintf("Duplicate of book %s, which can only be cloned once synthesized", existing.ID), http.StatusConflict)
+		return
+	}
+
+	cloned := *existing
+	cloned.ID = newBook.ID
+	cloned.UploadedAt = newBook.UploadedAt
+	cloned.SourceURL = newBook.SourceURL
+	cloned.ClonedFrom = existing.ID
+	if newBook.Title != "" {
+		cloned.Title = newBook.Title
+	}
+	if newBook.Author != "" {
+		cloned.Author = newBook.Author
+	}
+
+	if err := h.repo.CloneBook(ctx, existing.ID, cloned.ID); err != nil {
+		log.Printf("[respondDuplicate] Failed to clone book %s: %v", existing.ID, err)
+		h.repo.DeleteBook(ctx, cloned.ID)
+		respondError(w, "Failed to clone book", http.StatusInternalServerError)
+		return
+	}
+	if err := h.repo.SaveBook(ctx, &cloned); err != nil {
+		h.repo.DeleteBook(ctx, cloned.ID)
+		respondError(w, "Failed to save book metadata", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("[respondDuplicate] Cloned book %s into %s", existing.ID, cloned.ID)
+	respondJSON(w, &cloned, http.StatusCreated)
+}
+
+// processBookAsync runs processBook, recording any panic as a book error
+func (h *BookHandler) processBookAsync(bookID string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[PANIC] Book processing for %s: %v", bookID, r)
+			h.updateBookError(context.Background(), bookID, fmt.Sprintf("Processing panic: %v", r))
+		}
+	}()
+	h.processBook(bookID)
+}
+
+// processBook handles async book processing using the hybrid pipeline. The
+// raw file is read from storage rather than held in memory.
+func (h *BookHandler) processBook(bookID string) {
 	ctx := context.Background()
 
 	// Update status to parsing
@@ -185,18 +401,36 @@ func (h *BookHandler) processBook(bookID string, data []byte, format string) {
 		h.repo.UpdateBook(ctx, book)
 	}
 
+	raw, err := h.repo.OpenRawFile(ctx, bookID)
+	if err != nil {
+		h.updateBookError(ctx, bookID, fmt.Sprintf("Raw file missing: %v", err))
+		return
+	}
+	defer raw.Close()
+
 	// Parse the book
-	parser, err := h.parserFactory.GetParser(format)
+	p, err := h.parserFactory.GetParser(raw.Format)
 	if err != nil {
 		h.updateBookError(ctx, bookID, fmt.Sprintf("Parser error: %v", err))
 		return
 	}
 
-	chapters, err := parser.Parse(ctx, data)
+	doc := parser.NewDocument(p, raw)
+	chapters, err := doc.Parse(ctx)
 	if err != nil {
 		h.updateBookError(ctx, bookID, fmt.Sprintf("Parse failed: %v", err))
 		return
 	}
+	if book != nil {
+		h.applyDocumentMetadata(ctx, book, doc)
+	}
+	if book != nil && book.SkipFrontMatter {
+		chapters = withoutFrontMatter(chapters)
+		if len(chapters) == 0 {
+			h.updateBookError(ctx, bookID, "Parse failed: book has no chapters besides front matter")
+			return
+		}
+	}
 
 	// Save chapters and count total paragraphs
 	totalParagraphs := 0
@@ -217,8 +451,125 @@ func (h *BookHandler) processBook(bookID string, data []byte, format string) {
 		h.repo.UpdateBook(ctx, book)
 	}
 
-	// Start hybrid pipeline with progress tracking
-	progressCallback := func(status *pipeline.PipelineStatus) {
+	// Start the hybrid pipeline
+	if err := h.hybridOrchestrator.StartPipeline(ctx, bookID, chapters, h.pipelineProgressCallback(ctx, bookID)); err != nil {
+		log.Printf("Failed to start hybrid pipeline for book %s: %v", bookID, err)
+		h.updateBookError(ctx, bookID, fmt.Sprintf("Pipeline error: %v", err))
+	}
+}
+
+// applyDocumentMetadata fills book fields the uploader left empty from the
+// document's own metadata and stores its cover image, if any
+func (h *BookHandler) applyDocumentMetadata(ctx context.Context, book *types.Book, doc *parser.Document) {
+	metadata, err := doc.Metadata(ctx)
+	if err != nil {
+		log.Printf("[processBook] Failed to read metadata for book %s: %v", book.ID, err)
+		metadata = &parser.Metadata{}
+	}
+
+	if book.Title == "" {
+		book.Title = metadata.Title
+	}
+	if book.Author == "" {
+		book.Author = metadata.Author
+	}
+	if book.Language == "" {
+		book.Language = metadata.Language
+	}
+	if book.Language == "" {
+		book.Language = "en"
+	}
+
+	if len(metadata.Cover) > 0 {
+		ext := coverExtension(metadata.CoverType)
+		if ext == "" {
+			log.Printf("[processBook] Unsupported cover type %q for book %s", metadata.CoverType, book.ID)
+		} else if err := h.repo.SaveCover(ctx, book.ID, metadata.Cover, ext); err != nil {
+			log.Printf("[processBook] Failed to save cover for book %s: %v", book.ID, err)
+		} else {
+			book.HasCover = true
+		}
+	}
+}
+
+// coverExtension maps an image MIME type to the extension covers are stored
+// with. SVG is not accepted: covers are served from the API origin and an
+// SVG can carry scripts.
+func coverExtension(contentType string) string {
+	switch strings.ToLower(contentType) {
+	case "image/jpeg", "image/jpg":
+		return "jpg"
+	case "image/png":
+		return "png"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	}
+	return ""
+}
+
+// withoutFrontMatter drops chapters the parser detected as cover, copyright,
+// table of contents or index pages
+func withoutFrontMatter(chapters []*types.Chapter) []*types.Chapter {
+	kept := make([]*types.Chapter, 0, len(chapters))
+	for _, chapter := range chapters {
+		if chapter.FrontMatter == "" {
+			kept = append(kept, chapter)
+		}
+	}
+	return kept
+}
+
+// ResumeUnfinishedBooks restarts processing for books that a previous server
+// run left mid-pipeline. Books still being parsed are parsed again from their
+// raw file; books past parsing resume from their last pipeline checkpoint.
+func (h *BookHandler) ResumeUnfinishedBooks(ctx context.Context) {
+	books, err := h.repo.ListBooks(ctx)
+	if err != nil {
+		log.Printf("[ResumeUnfinishedBooks] Failed to list books: %v", err)
+		return
+	}
+
+	for _, book := range books {
+		switch book.Status {
+		case "uploaded", "parsing":
+			log.Printf("[ResumeUnfinishedBooks] Re-parsing book %s", book.ID)
+			go h.processBookAsync(book.ID)
+		case "segmenting", "voice_mapping", "ready", "synthesizing":
+			h.resumePipeline(ctx, book.ID)
+		}
+	}
+}
+
+// resumePipeline restarts the hybrid pipeline for a book from its saved chapters
+func (h *BookHandler) resumePipeline(ctx context.Context, bookID string) {
+	chapters, err := h.repo.ListChapters(ctx, bookID)
+	if err != nil || len(chapters) == 0 {
+		log.Printf("[ResumeUnfinishedBooks] No chapters for book %s: %v", bookID, err)
+		h.updateBookError(ctx, bookID, "Resume failed: chapters not found")
+		return
+	}
+	sort.Slice(chapters, func(i, j int) bool {
+		return chapters[i].Number < chapters[j].Number
+	})
+
+	err = h.hybridOrchestrator.ResumePipeline(ctx, bookID, chapters, h.pipelineProgressCallback(ctx, bookID))
+	if errors.Is(err, pipeline.ErrNoCheckpoint) {
+		log.Printf("[ResumeUnfinishedBooks] No checkpoint for book %s, restarting pipeline", bookID)
+		err = h.hybridOrchestrator.StartPipeline(ctx, bookID, chapters, h.pipelineProgressCallback(ctx, bookID))
+	}
+	if err != nil {
+		log.Printf("[ResumeUnfinishedBooks] Failed to resume book %s: %v", bookID, err)
+		h.updateBookError(ctx, bookID, fmt.Sprintf("Pipeline error: %v", err))
+		return
+	}
+	log.Printf("[ResumeUnfinishedBooks] Resumed pipeline for book %s", bookID)
+}
+
+// pipelineProgressCallback mirrors hybrid pipeline progress into the book metadata
+func (h *BookHandler) pipelineProgressCallback(ctx context.Context, bookID string) pipeline.ProgressCallback {
+	return func(status *pipeline.PipelineStatus) {
 		book, err := h.repo.GetBook(ctx, bookID)
 		if err != nil {
 			log.Printf("Failed to get book for progress update: %v", err)
@@ -273,12 +624,6 @@ func (h *BookHandler) processBook(bookID string, data []byte, format string) {
 			log.Printf("Failed to update book progress: %v", err)
 		}
 	}
-
-	// Start the hybrid pipeline
-	if err := h.hybridOrchestrator.StartPipeline(ctx, bookID, chapters, progressCallback); err != nil {
-		log.Printf("Failed to start hybrid pipeline for book %s: %v", bookID, err)
-		h.updateBookError(ctx, bookID, fmt.Sprintf("Pipeline error: %v", err))
-	}
 }
 
 // updateBookError updates book with error status
@@ -466,7 +811,7 @@ func (h *BookHandler) SetVoiceMap(w http.ResponseWriter, r *http.Request) {
 	// Apply voice mapping to hybrid orchestrator
 	// The orchestrator will update book.UnmappedPersonas and book.WaitingForMapping
 	log.Printf("[SetVoiceMap] Applying voice mapping to orchestrator")
-	if err := h.hybridOrchestrator.ApplyVoiceMapping(r.Context(), bookID, &voiceMap, isInitial); err != nil {
+	if _, err := h.hybridOrchestrator.ApplyVoiceMapping(r.Context(), bookID, &voiceMap, isInitial); err != nil {
 		// Log error but don't fail the request - orchestrator might not be running
 		log.Printf("[SetVoiceMap] Failed to apply voice mapping to orchestrator: %v", err)
 
@@ -513,7 +858,9 @@ func (h *BookHandler) GetVoiceMap(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, voiceMap, http.StatusOK)
 }
 
-// StreamSegments handles GET /api/v1/books/:id/stream
+// StreamSegments handles GET /api/v1/books/:id/stream. Segments are written
+// and flushed as the pipeline produces them, and the stream stays open until
+// the book is synthesized or fails.
 func (h *BookHandler) StreamSegments(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -527,30 +874,38 @@ func (h *BookHandler) StreamSegments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get optional "after" parameter for resumption
-	afterSegmentID := r.URL.Query().Get("after")
-
-	// Get stream items
-	items, err := h.streamingService.StreamSegments(r.Context(), bookID, afterSegmentID)
-	if err != nil {
-		respondError(w, "Failed to stream segments", http.StatusInternalServerError)
+	if _, err := h.repo.GetBook(r.Context(), bookID); err != nil {
+		respondError(w, "Book not found", http.StatusNotFound)
 		return
 	}
 
-	// Encode as NDJSON
-	ndjson, err := streaming.EncodeNDJSON(items)
-	if err != nil {
-		respondError(w, "Failed to encode stream", http.StatusInternalServerError)
-		return
-	}
+	// Get optional "after" parameter for resumption
+	afterSegmentID := r.URL.Query().Get("after")
+
+	// The stream outlives the server's write timeout while the pipeline runs
+	controller := http.NewResponseController(w)
+	controller.SetWriteDeadline(time.Time{})
 
-	// Return NDJSON response
 	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(ndjson))
+	controller.Flush()
+
+	encoder := json.NewEncoder(w)
+	err := h.streamingService.FollowSegments(r.Context(), bookID, afterSegmentID, func(item streaming.StreamItem) error {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+		return controller.Flush()
+	})
+	if err != nil && r.Context().Err() == nil {
+		log.Printf("[StreamSegments] Stream of book %s ended early: %v", bookID, err)
+	}
 }
 
-// DownloadBook handles GET /api/v1/books/:id/download
+// DownloadBook handles GET /api/v1/books/:id/download. The format query
+// parameter selects a ZIP archive (zip, the default), an M4B audiobook (m4b)
+// or a read-along EPUB3 with media overlays (epub).
 func (h *BookHandler) DownloadBook(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -571,35 +926,59 @@ func (h *BookHandler) DownloadBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Packages are built while they are streamed, which can outlast the
+	// server's write timeout and would cut the file short
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "zip":
+	case "m4b":
+		h.downloadM4B(w, r, book)
+		return
+	case "epub":
+		h.downloadEPUB(w, r, book)
+		return
+	default:
+		respondError(w, fmt.Sprintf("Unsupported download format: %s", format), http.StatusBadRequest)
+		return
+	}
+
 	// Package the book
 	zipReader, err := h.packagingService.PackageBook(r.Context(), bookID)
 	if err != nil {
 		respondError(w, fmt.Sprintf("Failed to package book: %v", err), http.StatusInternalServerError)
 		return
 	}
+	defer zipReader.Close()
 
 	// Set headers for ZIP download
-	filename := fmt.Sprintf("book-%s.zip", bookID)
-	if book.Title != "" {
-		// Sanitize title for filename
-		safeTitle := strings.ReplaceAll(book.Title, " ", "_")
-		safeTitle = strings.Map(func(r rune) rune {
-			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
-				return r
-			}
-			return -1
-		}, safeTitle)
-		if safeTitle != "" {
-			filename = fmt.Sprintf("%s.zip", safeTitle)
-		}
-	}
-
+	filename := downloadFilename(book, "zip")
 	w.Header().Set("Content-Type", "application/zip")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 	w.WriteHeader(http.StatusOK)
 
-	// Copy ZIP data to response
-	io.Copy(w, zipReader)
+	// Stream the ZIP to the response as it is built. Headers are already sent,
+	// so a failure midway can only cut the download short.
+	if _, err := io.Copy(w, zipReader); err != nil {
+		log.Printf("[DownloadBook] Failed to stream package of book %s: %v", bookID, err)
+	}
+}
+
+// downloadFilename returns the attachment filename of a downloaded book, made
+// from its title when it has one
+func downloadFilename(book *types.Book, ext string) string {
+	// Sanitize title for filename
+	safeTitle := strings.ReplaceAll(book.Title, " ", "_")
+	safeTitle = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			return r
+		}
+		return -1
+	}, safeTitle)
+	if safeTitle != "" {
+		return fmt.Sprintf("%s.%s", safeTitle, ext)
+	}
+	return fmt.Sprintf("book-%s.%s", book.ID, ext)
 }
 
 // DeleteBook handles DELETE /api/v1/books/:id
@@ -629,13 +1008,14 @@ func (h *BookHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 		respondError(w, "Failed to delete book", http.StatusInternalServerError)
 		return
 	}
+	h.events.Forget(bookID)
 
 	respondJSON(w, map[string]string{"status": "deleted"}, http.StatusOK)
 }
 
-// GetAudio handles GET /api/v1/books/:id/audio/:segmentId
+// GetAudio handles GET and HEAD /api/v1/books/:id/audio/:segmentId
 func (h *BookHandler) GetAudio(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -655,42 +1035,63 @@ func (h *BookHandler) GetAudio(w http.ResponseWriter, r *http.Request) {
 	}
 	segmentID := parts[1]
 
-	// Try different audio formats
-	var audioReader io.ReadCloser
-	var err error
-	var format string
+	metadata, format, err := h.statSegmentAudio(r.Context(), bookID, segmentID)
+	if err != nil && h.synthesizeMissingAudio(r.Context(), bookID, segmentID) {
+		metadata, format, err = h.statSegmentAudio(r.Context(), bookID, segmentID)
+	}
+	if err != nil {
+		if h.prioritizeMissingAudio(bookID, segmentID) {
+			respondError(w, "Audio not synthesized yet; segment prioritized", http.StatusNotFound)
+			return
+		}
+		respondError(w, "Audio file not found", http.StatusNotFound)
+		return
+	}
+
+	// Seeking players request byte ranges; caches revalidate with the entity tag
+	serveStoredContent(w, r, h.storage, metadata, util.AudioMimeType(format))
+}
 
-	for _, audioFormat := range util.AudioFormats() {
-		audioPath := util.GetAudioPath(bookID, segmentID, audioFormat)
-		audioReader, err = h.storage.Get(r.Context(), audioPath)
+// statSegmentAudio finds the stored audio of a segment in whichever format it was synthesized
+func (h *BookHandler) statSegmentAudio(ctx context.Context, bookID, segmentID string) (*storage.Metadata, string, error) {
+	var err error
+	for _, format := range util.AudioFormats() {
+		var metadata *storage.Metadata
+		metadata, err = h.storage.Stat(ctx, util.GetAudioPath(bookID, segmentID, format))
 		if err == nil {
-			format = audioFormat
-			break
+			return metadata, format, nil
 		}
 	}
+	return nil, "", err
+}
+
+// GetCover handles GET /api/v1/books/:id/cover
+func (h *BookHandler) GetCover(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	if err != nil {
-		respondError(w, "Audio file not found", http.StatusNotFound)
+	bookID := extractIDFromPath(r.URL.Path, "/api/v1/books/")
+	if bookID == "" {
+		respondError(w, "Book ID required", http.StatusBadRequest)
 		return
 	}
-	defer audioReader.Close()
 
-	// Set content type based on format
-	contentType := "audio/wav"
-	switch format {
-	case "mp3":
-		contentType = "audio/mpeg"
-	case "ogg":
-		contentType = "audio/ogg"
-	case "flac":
-		contentType = "audio/flac"
+	data, ext, err := h.repo.GetCover(r.Context(), bookID)
+	if err != nil {
+		respondError(w, "Cover not found", http.StatusNotFound)
+		return
 	}
 
+	contentType := mime.TypeByExtension("." + ext)
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
 	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.WriteHeader(http.StatusOK)
-
-	// Stream audio data
-	io.Copy(w, audioReader)
+	w.Write(data)
 }
 
 // GetPipelineStatus handles GET /api/v1/books/:id/pipeline/status
@@ -755,17 +1156,29 @@ func (h *BookHandler) GetPersonas(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[GetPersonas] Book status: %s, DiscoveredPersonas: %v, UnmappedPersonas: %v",
 		book.Status, book.DiscoveredPersonas, book.UnmappedPersonas)
 
+	personaDiscovery := h.personaDiscovery(r.Context(), book)
+
+	log.Printf("[GetPersonas] Returning: Discovered=%v, Mapped=%v, Unmapped=%v, Pending=%d",
+		personaDiscovery.Discovered, len(personaDiscovery.Mapped),
+		personaDiscovery.Unmapped, personaDiscovery.PendingSegments)
+
+	respondJSON(w, personaDiscovery, http.StatusOK)
+}
+
+// personaDiscovery reports a book's personas and their voices from its
+// metadata and saved voice map
+func (h *BookHandler) personaDiscovery(ctx context.Context, book *types.Book) *types.PersonaDiscovery {
 	// Get voice map
-	voiceMap, err := h.repo.GetVoiceMap(r.Context(), bookID)
+	voiceMap, err := h.repo.GetVoiceMap(ctx, book.ID)
 	mapped := make(map[string]string)
 	if err == nil && voiceMap != nil {
-		log.Printf("[GetPersonas] Found voice map with %d personas", len(voiceMap.Persons))
+		log.Printf("[personaDiscovery] Found voice map with %d personas", len(voiceMap.Persons))
 		for _, pv := range voiceMap.Persons {
 			mapped[pv.ID] = pv.ProviderVoice
-			log.Printf("[GetPersonas]   - %s -> %s", pv.ID, pv.ProviderVoice)
+			log.Printf("[personaDiscovery]   - %s -> %s", pv.ID, pv.ProviderVoice)
 		}
 	} else {
-		log.Printf("[GetPersonas] No voice map found or error: %v", err)
+		log.Printf("[personaDiscovery] No voice map found or error: %v", err)
 	}
 
 	// Build persona discovery response. Older books can have stale
@@ -781,18 +1194,12 @@ func (h *BookHandler) GetPersonas(w http.ResponseWriter, r *http.Request) {
 	if len(unmapped) == 0 {
 		pendingSegments = 0
 	}
-	personaDiscovery := &types.PersonaDiscovery{
+	return &types.PersonaDiscovery{
 		Discovered:      book.DiscoveredPersonas,
 		Mapped:          mapped,
 		Unmapped:        unmapped,
 		PendingSegments: pendingSegments,
 	}
-
-	log.Printf("[GetPersonas] Returning: Discovered=%v, Mapped=%v, Unmapped=%v, Pending=%d",
-		personaDiscovery.Discovered, len(personaDiscovery.Mapped),
-		personaDiscovery.Unmapped, personaDiscovery.PendingSegments)
-
-	respondJSON(w, personaDiscovery, http.StatusOK)
 }
 
 // Helper functions
@@ -911,20 +1318,6 @@ func respondJSON(w http.ResponseWriter, data interface{}, status int) {
 	json.NewEncoder(w).Encode(data)
 }
 
-func formKeys(form *multipart.Form) []string {
-	if form == nil {
-		return nil
-	}
-	keys := make([]string, 0, len(form.Value)+len(form.File))
-	for key := range form.Value {
-		keys = append(keys, key)
-	}
-	for key := range form.File {
-		keys = append(keys, key)
-	}
-	return keys
-}
-
 func respondError(w http.ResponseWriter, message string, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
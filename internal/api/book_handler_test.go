@@ -0,0 +1,428 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/internal/parser"
+	"github.com/unalkalkan/TwelveReader/internal/provider"
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func newTestBookHandler(t *testing.T) *BookHandler {
+	t.Helper()
+	return newLimitedTestBookHandler(t, defaultMaxUploadSize)
+}
+
+func newLimitedTestBookHandler(t *testing.T, maxUploadSize int64) *BookHandler {
+	t.Helper()
+	storageAdapter, err := storage.NewLocalAdapter(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage adapter: %v", err)
+	}
+	t.Cleanup(func() { storageAdapter.Close() })
+	return NewBookHandlerWithUploadLimit(book.NewRepository(storageAdapter), parser.NewFactory(), provider.NewRegistry(), storageAdapter, maxUploadSize)
+}
+
+func TestBookHandler_DetectUploadFormat(t *testing.T) {
+	handler := newTestBookHandler(t)
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<< >>\nendobj\n")
+	epub := testEPUBBytes(t)
+	text := []byte("Once upon a time.")
+
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		format   string
+		errPart  string
+	}{
+		{"Matching extension", "book.pdf", pdf, "pdf", ""},
+		{"No extension", "book", epub, "epub", ""},
+		{"Unknown extension", "download.bin", pdf, "pdf", ""},
+		{"Text with markdown extension", "notes.md", text, "md", ""},
+		{"Text with UTF-16 BOM", "notes.txt", []byte{0xFF, 0xFE, 'H', 0, 'i', 0}, "txt", ""},
+		{"PDF named as text", "book.txt", pdf, "", "does not match its .txt extension (detected pdf)"},
+		{"Text named as EPUB", "book.epub", text, "", "does not match its .epub extension (detected txt)"},
+		{"Binary named as PDF", "book.pdf", []byte{0x00, 0x01, 0x02, 0xFF}, "", "does not match its .pdf extension"},
+		{"Undetectable without extension", "book", []byte{0x00, 0x01, 0x02, 0xFF}, "", "could not detect file format"},
+		{"Unsupported extension", "image.png", []byte{0x89, 'P', 'N', 'G', 0x00}, "", "unsupported format: png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := handler.detectUploadFormat(tt.filename, tt.data)
+			if tt.errPart != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errPart) {
+					t.Fatalf("Expected error containing %q, got %v (format %q)", tt.errPart, err, format)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("detectUploadFormat failed: %v", err)
+			}
+			if format != tt.format {
+				t.Errorf("Expected format %q, got %q", tt.format, format)
+			}
+		})
+	}
+}
+
+func TestBookHandler_UploadBookRejectsMislabelledFile(t *testing.T) {
+	handler := newTestBookHandler(t)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "book.epub")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write([]byte("%PDF-1.4\n"))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/books", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	handler.UploadBook(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "does not match its .epub extension (detected pdf)") {
+		t.Errorf("Expected mismatch error, got %s", w.Body.String())
+	}
+
+	assertNoStoredBooks(t, handler)
+}
+
+// newUploadRequest builds a multipart upload with the given form fields
+func newUploadRequest(t *testing.T, filename string, content []byte, fields map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/books", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestBookHandler_UploadBookRejectsOversizedFile(t *testing.T) {
+	handler := newLimitedTestBookHandler(t, 1024)
+
+	req := newUploadRequest(t, "book.txt", bytes.Repeat([]byte("All work and no play. "), 200), map[string]string{"title": "Too Long"})
+	w := httptest.NewRecorder()
+	handler.UploadBook(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected status 413, got %d: %s", w.Code, w.Body.String())
+	}
+	assertNoStoredBooks(t, handler)
+}
+
+func TestBookHandler_UploadBookDuplicate(t *testing.T) {
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+	content := []byte("Chapter 1\n\nIt was a dark and stormy night.")
+
+	// An earlier upload of the same content that finished synthesis
+	existing := &types.Book{ID: "book_existing", Title: "Stormy", Status: "synthesized", OrigFormat: "txt", TotalSegments: 1}
+	if err := handler.saveRawFile(ctx, existing, bytes.NewReader(content)); err != nil {
+		t.Fatalf("Failed to save raw file: %v", err)
+	}
+	handler.repo.SaveBook(ctx, existing)
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: existing.ID, Text: "It was a dark and stormy night."})
+	handler.storage.Put(ctx, "books/book_existing/audio/seg_00001.wav", bytes.NewReader([]byte("RIFF")))
+
+	t.Run("Returns existing book", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.UploadBook(w, newUploadRequest(t, "stormy.txt", content, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var returned types.Book
+		json.NewDecoder(w.Body).Decode(&returned)
+		if returned.ID != existing.ID {
+			t.Errorf("Expected existing book %s, got %s", existing.ID, returned.ID)
+		}
+		if books, _ := handler.repo.ListBooks(ctx); len(books) != 1 {
+			t.Errorf("Duplicate upload should not create a book, got %d books", len(books))
+		}
+	})
+
+	t.Run("Clones on request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.UploadBook(w, newUploadRequest(t, "stormy.txt", content, map[string]string{"clone_duplicate": "true", "title": "Stormy Copy"}))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var cloned types.Book
+		json.NewDecoder(w.Body).Decode(&cloned)
+		if cloned.ID == existing.ID || cloned.ClonedFrom != existing.ID || cloned.Status != "synthesized" || cloned.Title != "Stormy Copy" {
+			t.Errorf("Unexpected clone: %+v", cloned)
+		}
+		if _, err := handler.repo.GetSegment(ctx, cloned.ID, "seg_00001"); err != nil {
+			t.Errorf("Expected cloned segment: %v", err)
+		}
+		if exists, _ := handler.storage.Exists(ctx, "books/"+cloned.ID+"/audio/seg_00001.wav"); !exists {
+			t.Error("Expected cloned audio")
+		}
+	})
+
+	t.Run("Different format is not a duplicate", func(t *testing.T) {
+		if found := handler.findDuplicate(ctx, &types.Book{ID: "book_md", OrigFormat: "md", ContentHash: existing.ContentHash}); found != nil {
+			t.Errorf("Expected no duplicate for another format, got %s", found.ID)
+		}
+	})
+}
+
+func TestBookHandler_ConcurrentDuplicateUploads(t *testing.T) {
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+	content := []byte("Chapter 1\n\nCall me Ishmael.")
+
+	// Slow saves widen the window between the duplicate check and the book
+	// becoming visible. Processing never gets past parsing, so no book ends
+	// in an error status that would let a later upload through.
+	handler.repo = slowSaveRepository{handler.repo}
+	handler.parserFactory = blockingParserFactory{handler.parserFactory}
+
+	const uploads = 8
+	books := make([]*types.Book, uploads)
+	for i := range books {
+		books[i] = &types.Book{ID: fmt.Sprintf("book_%d", i), Status: "uploaded", OrigFormat: "txt"}
+		if err := handler.saveRawFile(ctx, books[i], bytes.NewReader(content)); err != nil {
+			t.Fatalf("Failed to save raw file: %v", err)
+		}
+	}
+
+	codes := make([]int, uploads)
+	var wg sync.WaitGroup
+	for i, newBook := range books {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			handler.createAndProcessBook(ctx, w, newBook, false)
+			codes[i] = w.Code
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, code := range codes {
+		if code == http.StatusCreated {
+			created++
+		}
+	}
+	if created != 1 {
+		t.Errorf("Expected one upload to create a book and the rest to be duplicates, got statuses %v", codes)
+	}
+}
+
+// slowSaveRepository delays saving book metadata
+type slowSaveRepository struct {
+	book.Repository
+}
+
+func (r slowSaveRepository) SaveBook(ctx context.Context, b *types.Book) error {
+	time.Sleep(20 * time.Millisecond)
+	return r.Repository.SaveBook(ctx, b)
+}
+
+// blockingParserFactory never returns from GetParser, which holds background
+// processing of books before it parses them
+type blockingParserFactory struct {
+	parser.Factory
+}
+
+func (f blockingParserFactory) GetParser(format string) (parser.Parser, error) {
+	select {}
+}
+
+func TestBookHandler_SaveRawFile(t *testing.T) {
+	handler := newLimitedTestBookHandler(t, 16)
+	ctx := context.Background()
+
+	content := []byte("Hello, reader.")
+	newBook := &types.Book{ID: "book_raw", OrigFormat: "txt"}
+	if err := handler.saveRawFile(ctx, newBook, bytes.NewReader(content)); err != nil {
+		t.Fatalf("saveRawFile failed: %v", err)
+	}
+	sum := sha256.Sum256(content)
+	if newBook.FileSize != int64(len(content)) || newBook.ContentHash != hex.EncodeToString(sum[:]) {
+		t.Errorf("Unexpected size %d and hash %s", newBook.FileSize, newBook.ContentHash)
+	}
+
+	raw, err := handler.repo.OpenRawFile(ctx, "book_raw")
+	if err != nil {
+		t.Fatalf("Failed to open raw file: %v", err)
+	}
+	defer raw.Close()
+	stored, _ := io.ReadAll(io.NewSectionReader(raw, 0, raw.Size()))
+	if !bytes.Equal(stored, content) {
+		t.Errorf("Stored raw file mismatch: %q", stored)
+	}
+
+	tooLarge := &types.Book{ID: "book_large", OrigFormat: "txt"}
+	if err := handler.saveRawFile(ctx, tooLarge, strings.NewReader(strings.Repeat("x", 17))); !errors.Is(err, errFileTooLarge) {
+		t.Errorf("Expected errFileTooLarge, got %v", err)
+	}
+}
+
+// assertNoStoredBooks checks that rejected requests left no book or raw file behind
+func assertNoStoredBooks(t *testing.T, handler *BookHandler) {
+	t.Helper()
+	books, err := handler.repo.ListBooks(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list books: %v", err)
+	}
+	if len(books) != 0 {
+		t.Errorf("Rejected requests should not create books, got %d", len(books))
+	}
+	files, err := handler.storage.List(context.Background(), "books")
+	if err != nil {
+		t.Fatalf("Failed to list storage: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Rejected requests should not leave files, got %v", files)
+	}
+}
+
+func TestBookHandler_Covers(t *testing.T) {
+	for contentType, ext := range map[string]string{"image/jpeg": "jpg", "image/png": "png", "image/svg+xml": "", "text/html": ""} {
+		if got := coverExtension(contentType); got != ext {
+			t.Errorf("coverExtension(%s): expected %q, got %q", contentType, ext, got)
+		}
+	}
+
+	handler := newTestBookHandler(t)
+	handler.repo.SaveCover(context.Background(), "book_1", []byte{0x89, 'P', 'N', 'G'}, "png")
+	w := httptest.NewRecorder()
+	handler.GetCover(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_1/cover", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" || w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("Unexpected cover response %d %v", w.Code, w.Header())
+	}
+}
+
+func TestBookHandler_GetAudioRangeAndConditionalRequests(t *testing.T) {
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+	audio := []byte("RIFF0123456789")
+	handler.storage.Put(ctx, "books/book_audio/audio/seg_00001.wav", bytes.NewReader(audio))
+	audioURL := "/api/v1/books/book_audio/audio/seg_00001"
+
+	w := httptest.NewRecorder()
+	handler.GetAudio(w, httptest.NewRequest(http.MethodGet, audioURL, nil))
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), audio) {
+		t.Fatalf("Expected full audio, got %d %q", w.Code, w.Body.String())
+	}
+	etag := w.Header().Get("ETag")
+	lastModified := w.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" || w.Header().Get("Accept-Ranges") != "bytes" || w.Header().Get("Content-Length") != "14" {
+		t.Fatalf("Expected caching and range headers, got %v", w.Header())
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		header  string
+		value   string
+		status  int
+		body    string
+		content string
+	}{
+		{"Range", http.MethodGet, "Range", "bytes=4-7", http.StatusPartialContent, "0123", "bytes 4-7/14"},
+		{"Suffix range", http.MethodGet, "Range", "bytes=-4", http.StatusPartialContent, "6789", "bytes 10-13/14"},
+		{"Unsatisfiable range", http.MethodGet, "Range", "bytes=20-", http.StatusRequestedRangeNotSatisfiable, "", "bytes */14"},
+		{"Matching entity tag", http.MethodGet, "If-None-Match", etag, http.StatusNotModified, "", ""},
+		{"Other entity tag", http.MethodGet, "If-None-Match", `"other"`, http.StatusOK, string(audio), ""},
+		{"Not modified since", http.MethodGet, "If-Modified-Since", lastModified, http.StatusNotModified, "", ""},
+		{"Head", http.MethodHead, "", "", http.StatusOK, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, audioURL, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			handler.GetAudio(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status != http.StatusRequestedRangeNotSatisfiable && w.Body.String() != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, w.Body.String())
+			}
+			if w.Header().Get("Content-Range") != tt.content {
+				t.Errorf("Expected Content-Range %q, got %q", tt.content, w.Header().Get("Content-Range"))
+			}
+		})
+	}
+}
+
+func TestBookHandler_StreamSegments(t *testing.T) {
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+
+	handler.repo.SaveBook(ctx, &types.Book{ID: "book_stream", Status: "synthesized"})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: "book_stream", Text: "One.", VoiceID: "voice-a"})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00002", BookID: "book_stream", Text: "Two."})
+	handler.storage.Put(ctx, "books/book_stream/audio/seg_00001.wav", strings.NewReader("audio"))
+
+	t.Run("Ends once synthesized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.StreamSegments(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_stream/stream", nil))
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-ndjson" {
+			t.Fatalf("Expected NDJSON stream, got %d: %s", w.Code, w.Body.String())
+		}
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Expected 2 lines, got %d: %s", len(lines), w.Body.String())
+		}
+		for i, ready := range []bool{true, false} {
+			var item struct {
+				ID         string `json:"id"`
+				AudioURL   string `json:"audio_url"`
+				AudioReady bool   `json:"audio_ready"`
+			}
+			if err := json.Unmarshal([]byte(lines[i]), &item); err != nil {
+				t.Fatalf("Line %d is not valid JSON: %v", i, err)
+			}
+			if item.AudioReady != ready || item.AudioURL == "" {
+				t.Errorf("Expected %s to have audio_ready %v, got %+v", item.ID, ready, item)
+			}
+		}
+	})
+
+	t.Run("Unknown book", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.StreamSegments(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_missing/stream", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
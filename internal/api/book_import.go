@@ -0,0 +1,289 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/netguard"
+	"github.com/unalkalkan/TwelveReader/internal/parser"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+const (
+	// defaultMaxUploadSize limits uploaded and imported book files unless
+	// configured otherwise
+	defaultMaxUploadSize = 100 << 20
+
+	// uploadFormOverhead is the room left for multipart headers and text
+	// fields on top of the file size limit
+	uploadFormOverhead = 1 << 20
+
+	// uploadFieldMaxSize limits a single text field of an upload form
+	uploadFieldMaxSize = 64 << 10
+
+	// importTimeout bounds fetching a book from a remote URL
+	importTimeout = 2 * time.Minute
+
+	// importMaxRedirects is the number of redirects followed when importing
+	importMaxRedirects = 5
+
+	importUserAgent = "TwelveReader-Importer/1.0"
+)
+
+var (
+	errFileTooLarge      = errors.New("file exceeds size limit")
+	errImportUnsupported = errors.New("unsupported remote content type")
+	errImportRead        = errors.New("failed to read remote file")
+)
+
+// importBookRequest is the body of POST /api/v1/books/import
+type importBookRequest struct {
+	URL             string `json:"url"`
+	Title           string `json:"title"`
+	Author          string `json:"author"`
+	Language        string `json:"language"`
+	SkipFrontMatter bool   `json:"skip_front_matter"`
+	CloneDuplicate  bool   `json:"clone_duplicate"`
+}
+
+// importedDocument is a remote document being fetched and the format chosen for it
+type importedDocument struct {
+	body      io.ReadCloser // Whole response body, including the sniffed head
+	format    string
+	mediaType string
+}
+
+// importBody marks read errors of a remote response so they can be told
+// apart from storage errors
+type importBody struct {
+	io.ReadCloser
+}
+
+func (b importBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%w: %v", errImportRead, err)
+	}
+	return n, err
+}
+
+// sizeLimitReader fails with errFileTooLarge once more than limit bytes are read
+type sizeLimitReader struct {
+	r     io.Reader
+	limit int64
+	n     int64 // bytes read so far
+}
+
+func (l *sizeLimitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.limit {
+		return n, errFileTooLarge
+	}
+	return n, err
+}
+
+// isFileTooLarge reports whether err comes from exceeding an upload limit
+func isFileTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.Is(err, errFileTooLarge) || errors.As(err, &maxBytesErr)
+}
+
+// newImportClient creates the HTTP client used to fetch remote books. Unless
+// allowPrivate is set it only connects to publicly routable addresses, which
+// is checked on every dial and so also applies after redirects.
+func newImportClient(allowPrivate bool) *http.Client {
+	client := &http.Client{
+		Timeout: importTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= importMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", importMaxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+	if !allowPrivate {
+		client.Transport = netguard.NewTransport()
+	}
+	return client
+}
+
+// ImportBook handles POST /api/v1/books/import
+func (h *BookHandler) ImportBook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req importBookRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sourceURL, err := parseImportURL(req.URL)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), importTimeout)
+	defer cancel()
+
+	doc, err := fetchImportDocument(ctx, h.importClient, h.parserFactory, sourceURL, h.maxUploadSize)
+	if err != nil {
+		log.Printf("[ImportBook] Failed to import %s: %v", sourceURL, err)
+		switch {
+		case errors.Is(err, errFileTooLarge):
+			respondError(w, h.fileTooLargeMessage(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, errImportUnsupported):
+			respondError(w, err.Error(), http.StatusUnsupportedMediaType)
+		case errors.Is(err, netguard.ErrNotPublic):
+			respondError(w, "url must point to a public address", http.StatusBadRequest)
+		default:
+			respondError(w, fmt.Sprintf("Failed to fetch URL: %v", err), http.StatusBadGateway)
+		}
+		return
+	}
+
+	defer doc.body.Close()
+
+	newBook := &types.Book{
+		ID:         fmt.Sprintf("book_%d", time.Now().UnixNano()),
+		Title:      req.Title,
+		Author:     req.Author,
+		Language:   req.Language, // Empty fields are filled from document metadata
+		UploadedAt: time.Now(),
+		Status:     "uploaded",
+		OrigFormat: doc.format,
+		SourceURL:  sourceURL.String(),
+
+		SkipFrontMatter: req.SkipFrontMatter,
+	}
+	if err := h.saveRawFile(ctx, newBook, doc.body); err != nil {
+		log.Printf("[ImportBook] Failed to store %s: %v", sourceURL, err)
+		h.repo.DeleteBook(r.Context(), newBook.ID)
+		switch {
+		case isFileTooLarge(err):
+			respondError(w, h.fileTooLargeMessage(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, errImportRead):
+			respondError(w, fmt.Sprintf("Failed to fetch URL: %v", err), http.StatusBadGateway)
+		default:
+			respondError(w, "Failed to save raw file", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	log.Printf("[ImportBook] Fetched %s: %d bytes, media type %q, format %s", sourceURL, newBook.FileSize, doc.mediaType, doc.format)
+	h.createAndProcessBook(r.Context(), w, newBook, req.CloneDuplicate)
+}
+
+// parseImportURL validates that a URL is an absolute http(s) URL
+func parseImportURL(rawURL string) (*url.URL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return nil, errors.New("url is required")
+	}
+	sourceURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %v", err)
+	}
+	if sourceURL.Scheme != "http" && sourceURL.Scheme != "https" {
+		return nil, errors.New("url must use http or https")
+	}
+	if sourceURL.Host == "" {
+		return nil, errors.New("url must include a host")
+	}
+	return sourceURL, nil
+}
+
+// fetchImportDocument starts downloading a remote document and chooses a
+// parser format from its media type. Generic or missing content types are
+// sniffed from the first bytes, and plain text responses defer to a known URL
+// file extension. The caller reads and closes the returned body.
+func fetchImportDocument(ctx context.Context, client *http.Client, factory parser.Factory, sourceURL *url.URL, maxSize int64) (*importedDocument, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", importUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := chooseImportFormat(resp, factory, maxSize)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return doc, nil
+}
+
+// chooseImportFormat checks a remote response and picks the document format
+func chooseImportFormat(resp *http.Response, factory parser.Factory, maxSize int64) (*importedDocument, error) {
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote server returned %s", resp.Status)
+	}
+	if resp.ContentLength > maxSize {
+		return nil, fmt.Errorf("%w: %d bytes", errFileTooLarge, resp.ContentLength)
+	}
+
+	head, body, err := peekHead(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(head) == 0 {
+		return nil, errors.New("remote file is empty")
+	}
+	doc := &importedDocument{body: importBody{struct {
+		io.Reader
+		io.Closer
+	}{body, resp.Body}}}
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || isGenericMediaType(mediaType) {
+		mediaType = parser.DetectMIMEType(head)
+	}
+	doc.mediaType = mediaType
+
+	// Servers often label Markdown and other text files as text/plain
+	extFormat := strings.TrimPrefix(strings.ToLower(path.Ext(resp.Request.URL.Path)), ".")
+	if mediaType == "text/plain" && extFormat != "" && extFormat != "txt" {
+		if _, err := factory.GetParser(extFormat); err == nil {
+			doc.format = extFormat
+			return doc, nil
+		}
+	}
+
+	if _, format, err := factory.GetParserByMIME(mediaType); err == nil {
+		doc.format = format
+		return doc, nil
+	}
+	return nil, fmt.Errorf("%w: %s", errImportUnsupported, mediaType)
+}
+
+// isGenericMediaType reports whether a content type says nothing about the document format
+func isGenericMediaType(mediaType string) bool {
+	switch mediaType {
+	case "", "application/octet-stream", "binary/octet-stream", "application/download",
+		"application/force-download", "application/zip", "application/x-zip-compressed",
+		"application/xml", "text/xml":
+		return true
+	}
+	return false
+}
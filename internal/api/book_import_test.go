@@ -0,0 +1,160 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/internal/parser"
+)
+
+func testEPUBBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("mimetype")
+	if err != nil {
+		t.Fatalf("Failed to create zip entry: %v", err)
+	}
+	f.Write([]byte("application/epub+zip"))
+	w.Close()
+	return buf.Bytes()
+}
+
+func newImportTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	epub := testEPUBBytes(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pg11.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("CHAPTER I.\n\nAlice was beginning to get very tired."))
+	})
+	mux.HandleFunc("/notes.md", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("# Notes\n\nSome text."))
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><body><h1>Article</h1><p>Text.</p></body></html>"))
+	})
+	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(epub)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/download", http.StatusFound)
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.(http.Flusher).Flush() // Stream without Content-Length
+		w.Write(bytes.Repeat([]byte("a"), 2048))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchImportDocument(t *testing.T) {
+	server := newImportTestServer(t)
+	factory := parser.NewFactory()
+	client := newImportClient(true)
+
+	tests := []struct {
+		path      string
+		format    string
+		mediaType string
+	}{
+		{"/pg11.txt", "txt", "text/plain"},
+		{"/notes.md", "md", "text/plain"},
+		{"/article", "html", "text/html"},
+		{"/download", "epub", "application/epub+zip"},
+		{"/redirect", "epub", "application/epub+zip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			sourceURL, _ := url.Parse(server.URL + tt.path)
+			doc, err := fetchImportDocument(context.Background(), client, factory, sourceURL, 1024)
+			if err != nil {
+				t.Fatalf("fetchImportDocument failed: %v", err)
+			}
+			if doc.format != tt.format || doc.mediaType != tt.mediaType {
+				t.Errorf("Expected format %q (%s), got %q (%s)", tt.format, tt.mediaType, doc.format, doc.mediaType)
+			}
+			data, err := io.ReadAll(doc.body)
+			doc.body.Close()
+			if err != nil || len(data) == 0 {
+				t.Errorf("Expected document data, got %d bytes (%v)", len(data), err)
+			}
+		})
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		sourceURL, _ := url.Parse(server.URL + "/image.png")
+		if _, err := fetchImportDocument(context.Background(), client, factory, sourceURL, 1024); !errors.Is(err, errImportUnsupported) {
+			t.Errorf("Expected %v, got %v", errImportUnsupported, err)
+		}
+
+		sourceURL, _ = url.Parse(server.URL + "/missing")
+		if _, err := fetchImportDocument(context.Background(), client, factory, sourceURL, 1024); err == nil {
+			t.Error("Expected error for 404 response")
+		}
+	})
+}
+
+func TestBookHandler_ImportBookRejectsBadRequests(t *testing.T) {
+	server := newImportTestServer(t)
+	handler := newLimitedTestBookHandler(t, 1024)
+	handler.importClient = newImportClient(true) // The test server listens on loopback
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"Invalid JSON", `{`, http.StatusBadRequest},
+		{"Missing URL", `{}`, http.StatusBadRequest},
+		{"Unsupported scheme", `{"url":"file:///etc/passwd"}`, http.StatusBadRequest},
+		{"Unsupported content", `{"url":"` + server.URL + `/image.png"}`, http.StatusUnsupportedMediaType},
+		{"Remote error", `{"url":"` + server.URL + `/missing"}`, http.StatusBadGateway},
+		{"Too large", `{"url":"` + server.URL + `/large"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/books/import", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.ImportBook(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	assertNoStoredBooks(t, handler)
+}
+
+func TestBookHandler_ImportBookRejectsPrivateAddresses(t *testing.T) {
+	server := newImportTestServer(t)
+	handler := newTestBookHandler(t)
+
+	for _, path := range []string{"/pg11.txt", "/redirect"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/books/import", strings.NewReader(`{"url":"`+server.URL+path+`"}`))
+		w := httptest.NewRecorder()
+		handler.ImportBook(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400 for a loopback URL, got %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	assertNoStoredBooks(t, handler)
+}
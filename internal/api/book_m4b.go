@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/unalkalkan/TwelveReader/internal/packaging"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// SetM4BEncoder sets the ffmpeg binary used to compress M4B downloads to AAC.
+// Without one, M4B downloads hold uncompressed PCM audio.
+func (h *BookHandler) SetM4BEncoder(path string) {
+	h.packagingService.SetEncoder(path)
+}
+
+// downloadM4B writes the book as one M4B audiobook with chapter markers
+func (h *BookHandler) downloadM4B(w http.ResponseWriter, r *http.Request, book *types.Book) {
+	m4b, err := h.packagingService.PackageM4B(r.Context(), book.ID, h.chapterAudioSilence)
+	if err != nil {
+		switch {
+		case errors.Is(err, packaging.ErrBookNotSynthesized), errors.Is(err, packaging.ErrChapterAudioIncomplete), errors.Is(err, packaging.ErrChapterAudioFormat):
+			respondError(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("[DownloadBook] Failed to build M4B of book %s: %v", book.ID, err)
+			respondError(w, "Failed to build M4B audiobook", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer m4b.Close()
+
+	w.Header().Set("Content-Type", "audio/mp4")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", downloadFilename(book, "m4b")))
+	w.Header().Set("Content-Length", strconv.FormatInt(m4b.Size(), 10))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, m4b); err != nil {
+		log.Printf("[DownloadBook] Failed to stream M4B of book %s: %v", book.ID, err)
+	}
+}
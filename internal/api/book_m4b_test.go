@@ -0,0 +1,68 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/internal/audio"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestBookHandler_DownloadBookM4B(t *testing.T) {
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+
+	// Mono 16-bit audio at 1000 Hz
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:2], 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:4], 1)
+	binary.LittleEndian.PutUint32(fmtChunk[4:8], 1000)
+	binary.LittleEndian.PutUint32(fmtChunk[8:12], 2000)
+	binary.LittleEndian.PutUint16(fmtChunk[12:14], 2)
+	binary.LittleEndian.PutUint16(fmtChunk[14:16], 16)
+
+	handler.repo.SaveBook(ctx, &types.Book{ID: "book_m4b", Title: "My Audio Book", Author: "Someone", Status: "synthesized"})
+	handler.repo.SaveChapter(ctx, &types.Chapter{ID: "ch_001", BookID: "book_m4b", Number: 1, Title: "Opening"})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: "book_m4b", Chapter: "ch_001", Text: "One."})
+	handler.storage.Put(ctx, "books/book_m4b/audio/seg_00001.wav", bytes.NewReader(audio.EncodeWAV(fmtChunk, make([]byte, 1000))))
+
+	t.Run("M4B", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.DownloadBook(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_m4b/download?format=m4b", nil))
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "audio/mp4" {
+			t.Fatalf("Expected M4B audio, got %d: %s", w.Code, w.Body.String())
+		}
+		if disposition := w.Header().Get("Content-Disposition"); disposition != "attachment; filename=My_Audio_Book.m4b" {
+			t.Errorf("Unexpected Content-Disposition: %s", disposition)
+		}
+		if w.Header().Get("Content-Length") != strconv.Itoa(w.Body.Len()) {
+			t.Errorf("Expected Content-Length %s to match body of %d bytes", w.Header().Get("Content-Length"), w.Body.Len())
+		}
+		if body := w.Body.Bytes(); string(body[4:12]) != "ftypM4B " || !bytes.Contains(body, []byte("Opening")) {
+			t.Error("Expected an M4B file with the chapter title")
+		}
+	})
+
+	t.Run("Incomplete audio", func(t *testing.T) {
+		handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00002", BookID: "book_m4b", Chapter: "ch_001", Text: "Two."})
+		defer handler.repo.DeleteSegment(ctx, "book_m4b", "seg_00002")
+		w := httptest.NewRecorder()
+		handler.DownloadBook(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_m4b/download?format=m4b", nil))
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.DownloadBook(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_m4b/download?format=mp3", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/pipeline"
+)
+
+const (
+	// defaultPlayNowFollowing is the number of segments after a requested one
+	// that are prioritized with it, so playback can continue uninterrupted
+	defaultPlayNowFollowing = 3
+
+	// maxPlayNowFollowing caps how many segments one play request can prioritize
+	maxPlayNowFollowing = 20
+
+	// onDemandSynthesisTimeout bounds synthesizing a missing segment while an
+	// audio request waits
+	onDemandSynthesisTimeout = 30 * time.Second
+)
+
+// playNowRequest is the body of POST /api/v1/books/:id/play-now
+type playNowRequest struct {
+	SegmentID string `json:"segment_id"`
+	Following *int   `json:"following"`
+}
+
+// playNowResponse reports prioritized segments and play-now latency
+type playNowResponse struct {
+	BookID      string                    `json:"book_id"`
+	Prioritized []string                  `json:"prioritized,omitempty"`
+	Latency     *pipeline.PriorityLatency `json:"latency"`
+}
+
+// PlayNow handles GET and POST /api/v1/books/:id/play-now. POST moves a
+// segment and the segments following it to the front of the TTS queue; GET
+// reports how long prioritized segments waited for their audio.
+func (h *BookHandler) PlayNow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bookID := extractIDFromPath(r.URL.Path, "/api/v1/books/")
+	if bookID == "" {
+		respondError(w, "Book ID required", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.repo.GetBook(r.Context(), bookID); err != nil {
+		respondError(w, "Book not found", http.StatusNotFound)
+		return
+	}
+
+	response := playNowResponse{BookID: bookID}
+	if r.Method == http.MethodPost {
+		var req playNowRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.SegmentID == "" {
+			respondError(w, "segment_id is required", http.StatusBadRequest)
+			return
+		}
+		following := defaultPlayNowFollowing
+		if req.Following != nil {
+			following = min(max(*req.Following, 0), maxPlayNowFollowing)
+		}
+		if _, err := h.repo.GetSegment(r.Context(), bookID, req.SegmentID); err != nil {
+			respondError(w, "Segment not found", http.StatusNotFound)
+			return
+		}
+
+		prioritized, err := h.hybridOrchestrator.PrioritizeSegment(bookID, req.SegmentID, following)
+		if err != nil {
+			respondError(w, "Book is not being synthesized", http.StatusConflict)
+			return
+		}
+		response.Prioritized = prioritized
+	}
+
+	latency, err := h.hybridOrchestrator.GetPriorityLatency(bookID)
+	if err != nil {
+		respondError(w, "Book is not being synthesized", http.StatusConflict)
+		return
+	}
+	response.Latency = latency
+	respondJSON(w, response, http.StatusOK)
+}
+
+// prioritizeMissingAudio moves a segment whose audio was requested before it
+// was synthesized to the front of the TTS queue. It reports whether the
+// segment is now prioritized.
+func (h *BookHandler) prioritizeMissingAudio(bookID, segmentID string) bool {
+	prioritized, err := h.hybridOrchestrator.PrioritizeSegment(bookID, segmentID, defaultPlayNowFollowing)
+	if err != nil || len(prioritized) == 0 {
+		return false
+	}
+	log.Printf("[GetAudio] Audio for segment %s of book %s not ready, prioritized %d segments", segmentID, bookID, len(prioritized))
+	return true
+}
+
+// synthesizeMissingAudio synthesizes a segment without audio while the
+// request waits, bounded by onDemandSynthesisTimeout. It reports whether the
+// audio is now stored.
+func (h *BookHandler) synthesizeMissingAudio(ctx context.Context, bookID, segmentID string) bool {
+	if strings.ContainsAny(segmentID, `/\`) {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, onDemandSynthesisTimeout)
+	defer cancel()
+
+	if err := h.hybridOrchestrator.SynthesizeSegmentNow(ctx, bookID, segmentID); err != nil {
+		log.Printf("[GetAudio] On-demand synthesis of segment %s of book %s failed: %v", segmentID, bookID, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/internal/parser"
+	"github.com/unalkalkan/TwelveReader/internal/provider"
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestBookHandler_PlayNowRejectsBadRequests(t *testing.T) {
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+	handler.repo.SaveBook(ctx, &types.Book{ID: "book_done", Status: "synthesized"})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: "book_done", Text: "Hello."})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"Unknown book", http.MethodPost, "/api/v1/books/book_missing/play-now", `{"segment_id":"seg_00001"}`, http.StatusNotFound},
+		{"Invalid body", http.MethodPost, "/api/v1/books/book_done/play-now", `{`, http.StatusBadRequest},
+		{"Missing segment ID", http.MethodPost, "/api/v1/books/book_done/play-now", `{}`, http.StatusBadRequest},
+		{"Unknown segment", http.MethodPost, "/api/v1/books/book_done/play-now", `{"segment_id":"seg_09999"}`, http.StatusNotFound},
+		{"No pipeline", http.MethodPost, "/api/v1/books/book_done/play-now", `{"segment_id":"seg_00001"}`, http.StatusConflict},
+		{"No pipeline latency", http.MethodGet, "/api/v1/books/book_done/play-now", "", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.PlayNow(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBookHandler_GetAudioSynthesizesMissingAudio(t *testing.T) {
+	storageAdapter, err := storage.NewLocalAdapter(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage adapter: %v", err)
+	}
+	t.Cleanup(func() { storageAdapter.Close() })
+	registry := provider.NewRegistry()
+	registry.RegisterTTS(provider.NewStubTTSProvider(types.TTSProviderConfig{Name: "stub"}))
+	handler := NewBookHandler(book.NewRepository(storageAdapter), parser.NewFactory(), registry, storageAdapter)
+	ctx := context.Background()
+
+	handler.repo.SaveBook(ctx, &types.Book{ID: "book_done", Status: "synthesized"})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: "book_done", Text: "Hello.", Person: "narrator"})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00002", BookID: "book_done", Text: "Who?", Person: "stranger"})
+	handler.repo.SaveVoiceMap(ctx, &types.VoiceMap{BookID: "book_done", Persons: []types.PersonVoice{{ID: "narrator", ProviderVoice: "stub-voice-1"}}})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books/book_done/audio/seg_00001", nil)
+	w := httptest.NewRecorder()
+	handler.GetAudio(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "audio/wav" || w.Body.String() != "STUB_AUDIO_Hello." {
+		t.Errorf("Unexpected audio response %q (%s)", w.Body.String(), w.Header().Get("Content-Type"))
+	}
+	if exists, _ := handler.storage.Exists(ctx, "books/book_done/audio/seg_00001.wav"); !exists {
+		t.Error("Expected synthesized audio to be stored")
+	}
+
+	// Without a voice for the persona the audio stays missing
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/books/book_done/audio/seg_00002", nil)
+	w = httptest.NewRecorder()
+	handler.GetAudio(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
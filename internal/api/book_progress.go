@@ -0,0 +1,349 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// ListeningProgress handles GET and PUT /api/v1/books/:id/progress, the
+// segment and offset the listener reached. Responses carry the progress
+// version as ETag. A PUT with If-Match is only saved while that version is
+// current. Without it, a PUT carrying an updated_at older than the stored
+// progress is rejected, so a device that was offline cannot rewind another
+// device's position; timestamps ahead of the server clock count as now.
+func (h *BookHandler) ListeningProgress(w http.ResponseWriter, r *http.Request) {
+	bookID, ok := h.requireBook(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		progress, err := h.repo.GetListeningProgress(ctx, bookID)
+		if err != nil {
+			log.Printf("[ListeningProgress] Failed to get progress of book %s: %v", bookID, err)
+			respondError(w, "Failed to get listening progress", http.StatusInternalServerError)
+			return
+		}
+		if progress == nil {
+			respondError(w, "No listening progress saved", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", progressETag(progress))
+		respondJSON(w, progress, http.StatusOK)
+	case http.MethodPut:
+		var progress types.ListeningProgress
+		if err := json.NewDecoder(r.Body).Decode(&progress); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if msg := h.validatePosition(ctx, bookID, progress.SegmentID, progress.OffsetSeconds); msg != "" {
+			respondError(w, msg, http.StatusBadRequest)
+			return
+		}
+
+		stored, err := h.repo.GetListeningProgress(ctx, bookID)
+		if err != nil {
+			log.Printf("[ListeningProgress] Failed to get progress of book %s: %v", bookID, err)
+			respondError(w, "Failed to get listening progress", http.StatusInternalServerError)
+			return
+		}
+
+		now := time.Now().UTC()
+		if progress.UpdatedAt.IsZero() || progress.UpdatedAt.After(now) {
+			progress.UpdatedAt = now
+		}
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+			if stored == nil || (ifMatch != "*" && ifMatch != progressETag(stored)) {
+				respondProgressConflict(w, stored, http.StatusPreconditionFailed)
+				return
+			}
+		} else if stored != nil && clampedTime(stored.UpdatedAt, now).After(progress.UpdatedAt) {
+			respondProgressConflict(w, stored, http.StatusConflict)
+			return
+		}
+
+		progress.BookID = bookID
+		progress.Version = 1
+		if stored != nil {
+			progress.Version = stored.Version + 1
+		}
+		if err := h.repo.SaveListeningProgress(ctx, &progress); err != nil {
+			log.Printf("[ListeningProgress] Failed to save progress of book %s: %v", bookID, err)
+			respondError(w, "Failed to save listening progress", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", progressETag(&progress))
+		respondJSON(w, progress, http.StatusOK)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// respondProgressConflict rejects a progress update with the stored progress
+func respondProgressConflict(w http.ResponseWriter, stored *types.ListeningProgress, status int) {
+	if stored == nil {
+		respondError(w, "No listening progress saved", status)
+		return
+	}
+	w.Header().Set("ETag", progressETag(stored))
+	respondJSON(w, stored, status)
+}
+
+// progressETag returns the entity tag of a progress version
+func progressETag(progress *types.ListeningProgress) string {
+	return fmt.Sprintf(`"%d"`, progress.Version)
+}
+
+// clampedTime returns t, or now when t lies in the future
+func clampedTime(t, now time.Time) time.Time {
+	if t.After(now) {
+		return now
+	}
+	return t
+}
+
+// Bookmarks handles GET and POST /api/v1/books/:id/bookmarks and PUT and
+// DELETE /api/v1/books/:id/bookmarks/:bookmarkId
+func (h *BookHandler) Bookmarks(w http.ResponseWriter, r *http.Request) {
+	bookID, ok := h.requireBook(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+
+	bookmarks, err := h.repo.ListBookmarks(ctx, bookID)
+	if err != nil {
+		log.Printf("[Bookmarks] Failed to list bookmarks of book %s: %v", bookID, err)
+		respondError(w, "Failed to list bookmarks", http.StatusInternalServerError)
+		return
+	}
+
+	bookmarkID := annotationID(r.URL.Path, "/bookmarks/")
+	if bookmarkID == "" {
+		switch r.Method {
+		case http.MethodGet:
+			respondJSON(w, bookmarks, http.StatusOK)
+		case http.MethodPost:
+			var bookmark types.Bookmark
+			if err := json.NewDecoder(r.Body).Decode(&bookmark); err != nil {
+				respondError(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			now := time.Now().UTC()
+			bookmark.ID = fmt.Sprintf("bookmark_%d", now.UnixNano())
+			bookmark.BookID = bookID
+			bookmark.CreatedAt = now
+			h.saveBookmark(ctx, w, &bookmark, http.StatusCreated)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	var bookmark *types.Bookmark
+	for _, candidate := range bookmarks {
+		if candidate.ID == bookmarkID {
+			bookmark = candidate
+		}
+	}
+	if bookmark == nil {
+		respondError(w, "Bookmark not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		// Fields missing from the body keep their values
+		update := *bookmark
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		update.ID, update.BookID, update.CreatedAt = bookmark.ID, bookmark.BookID, bookmark.CreatedAt
+		h.saveBookmark(ctx, w, &update, http.StatusOK)
+	case http.MethodDelete:
+		if err := h.repo.DeleteBookmark(ctx, bookID, bookmarkID); err != nil {
+			log.Printf("[Bookmarks] Failed to delete bookmark %s: %v", bookmarkID, err)
+			respondError(w, "Failed to delete bookmark", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, map[string]string{"status": "deleted"}, http.StatusOK)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// saveBookmark validates and stores a bookmark
+func (h *BookHandler) saveBookmark(ctx context.Context, w http.ResponseWriter, bookmark *types.Bookmark, status int) {
+	bookmark.Name = strings.TrimSpace(bookmark.Name)
+	if bookmark.Name == "" {
+		respondError(w, "Bookmark name required", http.StatusBadRequest)
+		return
+	}
+	if msg := h.validatePosition(ctx, bookmark.BookID, bookmark.SegmentID, bookmark.OffsetSeconds); msg != "" {
+		respondError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	bookmark.UpdatedAt = time.Now().UTC()
+	if err := h.repo.SaveBookmark(ctx, bookmark); err != nil {
+		log.Printf("[Bookmarks] Failed to save bookmark %s: %v", bookmark.ID, err)
+		respondError(w, "Failed to save bookmark", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, bookmark, status)
+}
+
+// Highlights handles GET and POST /api/v1/books/:id/highlights and PUT and
+// DELETE /api/v1/books/:id/highlights/:highlightId
+func (h *BookHandler) Highlights(w http.ResponseWriter, r *http.Request) {
+	bookID, ok := h.requireBook(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+
+	highlights, err := h.repo.ListHighlights(ctx, bookID)
+	if err != nil {
+		log.Printf("[Highlights] Failed to list highlights of book %s: %v", bookID, err)
+		respondError(w, "Failed to list highlights", http.StatusInternalServerError)
+		return
+	}
+
+	highlightID := annotationID(r.URL.Path, "/highlights/")
+	if highlightID == "" {
+		switch r.Method {
+		case http.MethodGet:
+			respondJSON(w, highlights, http.StatusOK)
+		case http.MethodPost:
+			var highlight types.Highlight
+			if err := json.NewDecoder(r.Body).Decode(&highlight); err != nil {
+				respondError(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			now := time.Now().UTC()
+			highlight.ID = fmt.Sprintf("highlight_%d", now.UnixNano())
+			highlight.BookID = bookID
+			highlight.CreatedAt = now
+			h.saveHighlight(ctx, w, &highlight, http.StatusCreated)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	var highlight *types.Highlight
+	for _, candidate := range highlights {
+		if candidate.ID == highlightID {
+			highlight = candidate
+		}
+	}
+	if highlight == nil {
+		respondError(w, "Highlight not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		// Fields missing from the body keep their values
+		update := *highlight
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		update.ID, update.BookID, update.CreatedAt = highlight.ID, highlight.BookID, highlight.CreatedAt
+		h.saveHighlight(ctx, w, &update, http.StatusOK)
+	case http.MethodDelete:
+		if err := h.repo.DeleteHighlight(ctx, bookID, highlightID); err != nil {
+			log.Printf("[Highlights] Failed to delete highlight %s: %v", highlightID, err)
+			respondError(w, "Failed to delete highlight", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, map[string]string{"status": "deleted"}, http.StatusOK)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// saveHighlight validates a highlight, fills in its text from the segment
+// and stores it
+func (h *BookHandler) saveHighlight(ctx context.Context, w http.ResponseWriter, highlight *types.Highlight, status int) {
+	if highlight.SegmentID == "" {
+		respondError(w, "segment_id required", http.StatusBadRequest)
+		return
+	}
+	segment, err := h.repo.GetSegment(ctx, highlight.BookID, highlight.SegmentID)
+	if err != nil {
+		respondError(w, "Segment not found", http.StatusBadRequest)
+		return
+	}
+
+	// Character offsets count runes, as clients see them
+	text := []rune(segment.Text)
+	switch {
+	case highlight.StartChar == 0 && highlight.EndChar == 0:
+		highlight.Text = segment.Text
+	case highlight.StartChar < 0 || highlight.StartChar >= highlight.EndChar || highlight.EndChar > len(text):
+		respondError(w, "Invalid character range", http.StatusBadRequest)
+		return
+	default:
+		highlight.Text = string(text[highlight.StartChar:highlight.EndChar])
+	}
+
+	highlight.UpdatedAt = time.Now().UTC()
+	if err := h.repo.SaveHighlight(ctx, highlight); err != nil {
+		log.Printf("[Highlights] Failed to save highlight %s: %v", highlight.ID, err)
+		respondError(w, "Failed to save highlight", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, highlight, status)
+}
+
+// requireBook extracts the book ID of a request and responds with an error
+// unless the book exists
+func (h *BookHandler) requireBook(w http.ResponseWriter, r *http.Request) (string, bool) {
+	bookID := extractIDFromPath(r.URL.Path, "/api/v1/books/")
+	if bookID == "" {
+		respondError(w, "Book ID required", http.StatusBadRequest)
+		return "", false
+	}
+	if _, err := h.repo.GetBook(r.Context(), bookID); err != nil {
+		respondError(w, "Book not found", http.StatusNotFound)
+		return "", false
+	}
+	return bookID, true
+}
+
+// validatePosition checks a position in a book and returns the error
+// message for an invalid one
+func (h *BookHandler) validatePosition(ctx context.Context, bookID, segmentID string, offsetSeconds float64) string {
+	if segmentID == "" {
+		return "segment_id required"
+	}
+	if offsetSeconds < 0 {
+		return "offset_seconds must not be negative"
+	}
+	if _, err := h.repo.GetSegment(ctx, bookID, segmentID); err != nil {
+		return "Segment not found"
+	}
+	return ""
+}
+
+// annotationID returns the ID following a collection in a path, e.g. the
+// bookmark ID of /api/v1/books/:id/bookmarks/:bookmarkId
+func annotationID(path, collection string) string {
+	parts := strings.SplitN(path, collection, 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return strings.Trim(parts[1], "/")
+}
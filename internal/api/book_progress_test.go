@@ -0,0 +1,215 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func newProgressTestHandler(t *testing.T) *BookHandler {
+	t.Helper()
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+	handler.repo.SaveBook(ctx, &types.Book{ID: "book_1", Status: "synthesized"})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: "book_1", Text: "Call me Ishmael. Some years ago."})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00002", BookID: "book_1", Text: "Never mind how long precisely."})
+	return handler
+}
+
+func TestBookHandler_ListeningProgress(t *testing.T) {
+	handler := newProgressTestHandler(t)
+	request := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ListeningProgress(w, httptest.NewRequest(method, "/api/v1/books/book_1/progress", strings.NewReader(body)))
+		return w
+	}
+
+	if w := request(http.MethodGet, ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 before any progress is saved, got %d", w.Code)
+	}
+
+	saved := time.Date(2026, 1, 25, 10, 0, 0, 0, time.UTC)
+	if w := request(http.MethodPut, `{"segment_id":"seg_00002","offset_seconds":4.5,"device":"phone","updated_at":"2026-01-25T10:00:00Z"}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := request(http.MethodGet, "")
+	var progress types.ListeningProgress
+	json.NewDecoder(w.Body).Decode(&progress)
+	if progress.SegmentID != "seg_00002" || progress.OffsetSeconds != 4.5 || progress.Device != "phone" || !progress.UpdatedAt.Equal(saved) {
+		t.Errorf("Unexpected progress %+v", progress)
+	}
+
+	t.Run("Rejects stale progress from another device", func(t *testing.T) {
+		w := request(http.MethodPut, `{"segment_id":"seg_00001","offset_seconds":1,"device":"laptop","updated_at":"2026-01-25T09:00:00Z"}`)
+		if w.Code != http.StatusConflict {
+			t.Fatalf("Expected status 409, got %d", w.Code)
+		}
+		var current types.ListeningProgress
+		json.NewDecoder(w.Body).Decode(&current)
+		if current.Device != "phone" {
+			t.Errorf("Expected the stored progress in the conflict, got %+v", current)
+		}
+	})
+
+	t.Run("Clamps timestamps ahead of the server clock", func(t *testing.T) {
+		w := request(http.MethodPut, `{"segment_id":"seg_00002","offset_seconds":8,"device":"tablet","updated_at":"2100-01-01T00:00:00Z"}`)
+		var saved types.ListeningProgress
+		json.NewDecoder(w.Body).Decode(&saved)
+		if w.Code != http.StatusOK || saved.UpdatedAt.After(time.Now()) {
+			t.Fatalf("Expected the future timestamp to be clamped, got %d %+v", w.Code, saved)
+		}
+		if w := request(http.MethodPut, `{"segment_id":"seg_00001","offset_seconds":1,"device":"phone"}`); w.Code != http.StatusOK {
+			t.Errorf("Expected other devices to keep saving progress, got %d", w.Code)
+		}
+	})
+
+	t.Run("Saves only the matching version with If-Match", func(t *testing.T) {
+		etag := request(http.MethodGet, "").Header().Get("ETag")
+		put := func(ifMatch string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/books/book_1/progress", strings.NewReader(`{"segment_id":"seg_00002","offset_seconds":3}`))
+			req.Header.Set("If-Match", ifMatch)
+			w := httptest.NewRecorder()
+			handler.ListeningProgress(w, req)
+			return w
+		}
+
+		w := put(etag)
+		if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+			t.Fatalf("Expected status 200 with a new ETag, got %d %q", w.Code, w.Header().Get("ETag"))
+		}
+		w = put(etag)
+		if w.Code != http.StatusPreconditionFailed {
+			t.Fatalf("Expected status 412 for an outdated ETag, got %d", w.Code)
+		}
+		var current types.ListeningProgress
+		json.NewDecoder(w.Body).Decode(&current)
+		if current.OffsetSeconds != 3 || progressETag(&current) != w.Header().Get("ETag") {
+			t.Errorf("Expected the stored progress and its ETag in the conflict, got %+v", current)
+		}
+	})
+
+	t.Run("DELETE keeps the book and its bookmarks", func(t *testing.T) {
+		ctx := context.Background()
+		handler.repo.SaveBookmark(ctx, &types.Bookmark{ID: "bm_1", BookID: "book_1", SegmentID: "seg_00001", Name: "Opening"})
+		w := httptest.NewRecorder()
+		handler.Route(w, httptest.NewRequest(http.MethodDelete, "/api/v1/books/book_1/progress", nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("Expected status 405, got %d", w.Code)
+		}
+		if _, err := handler.repo.GetBook(ctx, "book_1"); err != nil {
+			t.Fatalf("Expected the book to survive: %v", err)
+		}
+		if bookmarks, _ := handler.repo.ListBookmarks(ctx, "book_1"); len(bookmarks) != 1 {
+			t.Errorf("Expected the bookmark to survive, got %+v", bookmarks)
+		}
+		if request(http.MethodGet, "").Code != http.StatusOK {
+			t.Error("Expected the progress to survive")
+		}
+	})
+
+	t.Run("Validates positions", func(t *testing.T) {
+		for _, body := range []string{`{"segment_id":"seg_99999"}`, `{"segment_id":"seg_00001","offset_seconds":-1}`, `{}`} {
+			if w := request(http.MethodPut, body); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %s, got %d", body, w.Code)
+			}
+		}
+	})
+}
+
+func TestBookHandler_Bookmarks(t *testing.T) {
+	handler := newProgressTestHandler(t)
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.Bookmarks(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := request(http.MethodPost, "/api/v1/books/book_1/bookmarks", `{"segment_id":"seg_00001","offset_seconds":2,"name":"Opening"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created types.Bookmark
+	json.NewDecoder(w.Body).Decode(&created)
+
+	if w := request(http.MethodPost, "/api/v1/books/book_1/bookmarks", `{"segment_id":"seg_00001","name":"  "}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a name, got %d", w.Code)
+	}
+
+	w = request(http.MethodPut, "/api/v1/books/book_1/bookmarks/"+created.ID, `{"name":"Ishmael"}`)
+	var updated types.Bookmark
+	json.NewDecoder(w.Body).Decode(&updated)
+	if w.Code != http.StatusOK || updated.Name != "Ishmael" || updated.SegmentID != "seg_00001" || updated.OffsetSeconds != 2 {
+		t.Errorf("Expected only the name to change, got %d %+v", w.Code, updated)
+	}
+
+	w = request(http.MethodGet, "/api/v1/books/book_1/bookmarks", "")
+	var bookmarks []types.Bookmark
+	json.NewDecoder(w.Body).Decode(&bookmarks)
+	if len(bookmarks) != 1 || bookmarks[0].Name != "Ishmael" {
+		t.Errorf("Unexpected bookmarks %+v", bookmarks)
+	}
+
+	if w := request(http.MethodDelete, "/api/v1/books/book_1/bookmarks/"+created.ID, ""); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if w := request(http.MethodDelete, "/api/v1/books/book_1/bookmarks/"+created.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a deleted bookmark, got %d", w.Code)
+	}
+	if _, err := handler.repo.GetBook(context.Background(), "book_1"); err != nil {
+		t.Errorf("Expected the book to survive deleting a bookmark: %v", err)
+	}
+}
+
+func TestBookHandler_Highlights(t *testing.T) {
+	handler := newProgressTestHandler(t)
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.Highlights(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := request(http.MethodPost, "/api/v1/books/book_1/highlights", `{"segment_id":"seg_00001","start_char":0,"end_char":16,"note":"Famous opening"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created types.Highlight
+	json.NewDecoder(w.Body).Decode(&created)
+	if created.Text != "Call me Ishmael." || created.Note != "Famous opening" {
+		t.Errorf("Unexpected highlight %+v", created)
+	}
+
+	w = request(http.MethodPost, "/api/v1/books/book_1/highlights", `{"segment_id":"seg_00002"}`)
+	var whole types.Highlight
+	json.NewDecoder(w.Body).Decode(&whole)
+	if whole.Text != "Never mind how long precisely." {
+		t.Errorf("Expected the whole segment to be highlighted, got %q", whole.Text)
+	}
+
+	if w := request(http.MethodPost, "/api/v1/books/book_1/highlights", `{"segment_id":"seg_00001","start_char":5,"end_char":500}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a range outside the segment, got %d", w.Code)
+	}
+
+	w = request(http.MethodPut, "/api/v1/books/book_1/highlights/"+created.ID, `{"note":"Narrator introduces himself"}`)
+	var updated types.Highlight
+	json.NewDecoder(w.Body).Decode(&updated)
+	if updated.Note != "Narrator introduces himself" || updated.Text != "Call me Ishmael." {
+		t.Errorf("Expected only the note to change, got %+v", updated)
+	}
+
+	if w := request(http.MethodDelete, "/api/v1/books/book_1/highlights/"+created.ID, ""); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	w = request(http.MethodGet, "/api/v1/books/book_1/highlights", "")
+	var highlights []types.Highlight
+	json.NewDecoder(w.Body).Decode(&highlights)
+	if len(highlights) != 1 || highlights[0].ID != whole.ID {
+		t.Errorf("Expected one highlight left, got %+v", highlights)
+	}
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// Pipeline stages a book can be reprocessed from
+const (
+	reprocessFromParse      = "parse"
+	reprocessFromSegment    = "segment"
+	reprocessFromSynthesize = "synthesize"
+)
+
+// reprocessBookRequest is the optional body of POST /api/v1/books/:id/reprocess
+type reprocessBookRequest struct {
+	From string `json:"from"`
+}
+
+// ReprocessBook handles POST /api/v1/books/:id/reprocess. It restarts the
+// pipeline of a book from the stage given by "from": parse re-reads the raw
+// file, segment re-segments the saved chapters and synthesize regenerates the
+// audio of the saved segments. Everything produced by later stages is discarded.
+func (h *BookHandler) ReprocessBook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bookID := extractIDFromPath(r.URL.Path, "/api/v1/books/")
+	if bookID == "" {
+		respondError(w, "Book ID required", http.StatusBadRequest)
+		return
+	}
+
+	from := r.URL.Query().Get("from")
+	if from == "" && r.Body != nil {
+		var req reprocessBookRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil && err != io.EOF {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		from = req.From
+	}
+	switch from {
+	case reprocessFromParse, reprocessFromSegment, reprocessFromSynthesize:
+	default:
+		respondError(w, "from must be one of parse, segment or synthesize", http.StatusBadRequest)
+		return
+	}
+
+	book, err := h.repo.GetBook(r.Context(), bookID)
+	if err != nil {
+		respondError(w, "Book not found", http.StatusNotFound)
+		return
+	}
+	if book.Status == "uploaded" || book.Status == "parsing" {
+		respondError(w, "Book is still being parsed", http.StatusConflict)
+		return
+	}
+	if _, err := h.hybridOrchestrator.GetPipelineStatus(bookID); err == nil {
+		respondError(w, "Book pipeline is still running", http.StatusConflict)
+		return
+	}
+
+	ctx := context.Background()
+	switch from {
+	case reprocessFromParse:
+		err = h.reprocessFromParse(ctx, book)
+	case reprocessFromSegment:
+		err = h.reprocessFromSegment(ctx, book)
+	case reprocessFromSynthesize:
+		err = h.reprocessFromSynthesize(ctx, book)
+	}
+	if err != nil {
+		log.Printf("[ReprocessBook] Failed to reprocess book %s from %s: %v", bookID, from, err)
+		respondError(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	log.Printf("[ReprocessBook] Reprocessing book %s from %s", bookID, from)
+	respondJSON(w, book, http.StatusAccepted)
+}
+
+// reprocessFromParse discards chapters, segments and audio and parses the
+// raw file again
+func (h *BookHandler) reprocessFromParse(ctx context.Context, book *types.Book) error {
+	raw, err := h.repo.OpenRawFile(ctx, book.ID)
+	if err != nil {
+		return fmt.Errorf("raw file not found: %v", err)
+	}
+	raw.Close()
+
+	if err := h.clearPipelineOutput(ctx, book.ID, true); err != nil {
+		return err
+	}
+
+	resetBookProgress(book)
+	book.Status = "uploaded"
+	book.TotalChapters = 0
+	book.TotalParagraphs = 0
+	if err := h.repo.UpdateBook(ctx, book); err != nil {
+		return fmt.Errorf("failed to update book: %v", err)
+	}
+
+	go h.processBookAsync(book.ID)
+	return nil
+}
+
+// reprocessFromSegment discards segments and audio and segments the saved
+// chapters again
+func (h *BookHandler) reprocessFromSegment(ctx context.Context, book *types.Book) error {
+	chapters, err := h.sortedChapters(ctx, book.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.clearPipelineOutput(ctx, book.ID, false); err != nil {
+		return err
+	}
+
+	resetBookProgress(book)
+	book.Status = "segmenting"
+	if err := h.repo.UpdateBook(ctx, book); err != nil {
+		return fmt.Errorf("failed to update book: %v", err)
+	}
+
+	if err := h.hybridOrchestrator.StartPipeline(ctx, book.ID, chapters, h.pipelineProgressCallback(ctx, book.ID)); err != nil {
+		h.updateBookError(ctx, book.ID, fmt.Sprintf("Pipeline error: %v", err))
+		return fmt.Errorf("failed to start pipeline: %v", err)
+	}
+	return nil
+}
+
+// reprocessFromSynthesize discards audio and synthesizes the saved segments
+// again with the book's voice mapping
+func (h *BookHandler) reprocessFromSynthesize(ctx context.Context, book *types.Book) error {
+	chapters, err := h.sortedChapters(ctx, book.ID)
+	if err != nil {
+		return err
+	}
+
+	book.Status = "synthesizing"
+	book.Error = ""
+	book.SynthesizedSegments = 0
+	if err := h.repo.UpdateBook(ctx, book); err != nil {
+		return fmt.Errorf("failed to update book: %v", err)
+	}
+
+	if err := h.hybridOrchestrator.ResynthesizePipeline(ctx, book.ID, chapters, h.pipelineProgressCallback(ctx, book.ID)); err != nil {
+		h.updateBookError(ctx, book.ID, fmt.Sprintf("Pipeline error: %v", err))
+		return fmt.Errorf("failed to start synthesis: %v", err)
+	}
+	return nil
+}
+
+// sortedChapters returns the saved chapters of a book in reading order
+func (h *BookHandler) sortedChapters(ctx context.Context, bookID string) ([]*types.Chapter, error) {
+	chapters, err := h.repo.ListChapters(ctx, bookID)
+	if err != nil || len(chapters) == 0 {
+		return nil, fmt.Errorf("book has no parsed chapters")
+	}
+	sort.Slice(chapters, func(i, j int) bool {
+		return chapters[i].Number < chapters[j].Number
+	})
+	return chapters, nil
+}
+
+// clearPipelineOutput deletes the audio and segments of a book, and its
+// chapters as well when withChapters is set
+func (h *BookHandler) clearPipelineOutput(ctx context.Context, bookID string, withChapters bool) error {
+	if err := h.repo.DeleteAudio(ctx, bookID); err != nil {
+		return fmt.Errorf("failed to delete audio: %v", err)
+	}
+	if err := h.repo.DeleteSegments(ctx, bookID); err != nil {
+		return fmt.Errorf("failed to delete segments: %v", err)
+	}
+	if withChapters {
+		if err := h.repo.DeleteChapters(ctx, bookID); err != nil {
+			return fmt.Errorf("failed to delete chapters: %v", err)
+		}
+	}
+	return nil
+}
+
+// resetBookProgress clears the segmentation and synthesis progress of a book
+func resetBookProgress(book *types.Book) {
+	book.Error = ""
+	book.TotalSegments = 0
+	book.SegmentedParagraphs = 0
+	book.SynthesizedSegments = 0
+	book.PendingSegmentCount = 0
+	book.DiscoveredPersonas = nil
+	book.UnmappedPersonas = nil
+	book.WaitingForMapping = false
+}
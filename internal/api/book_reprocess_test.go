@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/internal/parser"
+	"github.com/unalkalkan/TwelveReader/internal/provider"
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestBookHandler_ReprocessBookRejectsBadRequests(t *testing.T) {
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+
+	handler.repo.SaveBook(ctx, &types.Book{ID: "book_parsing", Status: "parsing", OrigFormat: "txt"})
+	handler.repo.SaveBook(ctx, &types.Book{ID: "book_bare", Status: "error", OrigFormat: "txt"})
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"Missing stage", "/api/v1/books/book_bare/reprocess", "", http.StatusBadRequest},
+		{"Unknown stage", "/api/v1/books/book_bare/reprocess?from=upload", "", http.StatusBadRequest},
+		{"Invalid body", "/api/v1/books/book_bare/reprocess", `{`, http.StatusBadRequest},
+		{"Unknown book", "/api/v1/books/book_missing/reprocess?from=parse", "", http.StatusNotFound},
+		{"Still parsing", "/api/v1/books/book_parsing/reprocess?from=parse", "", http.StatusConflict},
+		{"No raw file", "/api/v1/books/book_bare/reprocess?from=parse", "", http.StatusConflict},
+		{"No chapters", "/api/v1/books/book_bare/reprocess", `{"from":"segment"}`, http.StatusConflict},
+		{"No segments", "/api/v1/books/book_bare/reprocess?from=synthesize", "", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.ReprocessBook(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBookHandler_ReprocessBookFromParse(t *testing.T) {
+	storageAdapter, err := storage.NewLocalAdapter(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage adapter: %v", err)
+	}
+	t.Cleanup(func() { storageAdapter.Close() })
+	registry := provider.NewRegistry()
+	registry.RegisterLLM(provider.NewStubLLMProvider(types.LLMProviderConfig{Name: "stub"}))
+	handler := NewBookHandler(book.NewRepository(storageAdapter), parser.NewFactory(), registry, storageAdapter)
+	ctx := context.Background()
+
+	existing := &types.Book{ID: "book_reparse", Status: "synthesized", OrigFormat: "txt", TotalSegments: 1, SynthesizedSegments: 1}
+	if err := handler.saveRawFile(ctx, existing, strings.NewReader("Chapter 1\n\nA short story.")); err != nil {
+		t.Fatalf("Failed to save raw file: %v", err)
+	}
+	handler.repo.SaveBook(ctx, existing)
+	handler.repo.SaveChapter(ctx, &types.Chapter{ID: "ch_001", BookID: existing.ID, Number: 1, Title: "Old"})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: existing.ID, Text: "Old text."})
+	handler.storage.Put(ctx, "books/book_reparse/audio/seg_00001.wav", bytes.NewReader([]byte("RIFF")))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/books/book_reparse/reprocess?from=parse", nil)
+	w := httptest.NewRecorder()
+	handler.ReprocessBook(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if _, err := handler.repo.GetSegment(ctx, existing.ID, "seg_00001"); err == nil {
+		t.Error("Expected old segments to be deleted")
+	}
+	if exists, _ := handler.storage.Exists(ctx, "books/book_reparse/audio/seg_00001.wav"); exists {
+		t.Error("Expected old audio to be deleted")
+	}
+	raw, err := handler.repo.OpenRawFile(ctx, existing.ID)
+	if err != nil {
+		t.Fatalf("Expected raw file to be kept: %v", err)
+	}
+	raw.Close()
+
+	// Segmentation runs again and waits for a new voice mapping
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		updated, _ := handler.repo.GetBook(ctx, existing.ID)
+		if updated != nil && updated.Status == "voice_mapping" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected book to be parsed and segmented again, got %+v", updated)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	chapters, _ := handler.repo.ListChapters(ctx, existing.ID)
+	if len(chapters) != 1 || chapters[0].Title == "Old" {
+		t.Errorf("Expected chapters to be parsed again, got %+v", chapters)
+	}
+}
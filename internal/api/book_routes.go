@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Route dispatches requests under /api/v1/books/ to the handler of their
+// sub-resource. Only requests that match no sub-resource reach DeleteBook,
+// so a DELETE sent to a sub-resource gets 405 from its handler instead of
+// deleting the whole book.
+func (h *BookHandler) Route(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if strings.Contains(path, "/bookmarks") {
+		h.Bookmarks(w, r)
+	} else if strings.Contains(path, "/highlights") {
+		h.Highlights(w, r)
+	} else if path == "/api/v1/books/import" {
+		h.ImportBook(w, r)
+	} else if strings.HasSuffix(path, "/reprocess") {
+		h.ReprocessBook(w, r)
+	} else if strings.HasSuffix(path, "/synthesis-scope") {
+		h.SynthesisScope(w, r)
+	} else if strings.HasSuffix(path, "/play-now") {
+		h.PlayNow(w, r)
+	} else if strings.HasSuffix(path, "/events") {
+		h.BookEvents(w, r)
+	} else if strings.HasSuffix(path, "/progress") {
+		h.ListeningProgress(w, r)
+	} else if strings.HasSuffix(path, "/voice-map/ws") {
+		h.VoiceMapSocket(w, r)
+	} else if strings.Contains(path, "/chapters/") {
+		h.ChapterAudio(w, r)
+	} else if strings.HasSuffix(path, "/status") {
+		h.GetBookStatus(w, r)
+	} else if strings.HasSuffix(path, "/segments") {
+		h.ListSegments(w, r)
+	} else if strings.HasSuffix(path, "/voice-map") {
+		if r.Method == http.MethodPost {
+			h.SetVoiceMap(w, r)
+		} else {
+			h.GetVoiceMap(w, r)
+		}
+	} else if strings.HasSuffix(path, "/stream") {
+		h.StreamSegments(w, r)
+	} else if strings.HasSuffix(path, "/download") {
+		h.DownloadBook(w, r)
+	} else if strings.Contains(path, "/pipeline/status") {
+		h.GetPipelineStatus(w, r)
+	} else if strings.HasSuffix(path, "/personas") {
+		h.GetPersonas(w, r)
+	} else if strings.HasSuffix(path, "/cover") {
+		h.GetCover(w, r)
+	} else if strings.Contains(path, "/audio/") {
+		h.GetAudio(w, r)
+	} else if r.Method == http.MethodDelete {
+		h.DeleteBook(w, r)
+	} else {
+		h.GetBook(w, r)
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestBookHandler_RouteDeleteOnSubResources(t *testing.T) {
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+	handler.repo.SaveBook(ctx, &types.Book{ID: "book_1", Status: "synthesized"})
+
+	subResources := []string{
+		"/synthesis-scope",
+		"/play-now",
+		"/progress",
+		"/chapters/ch_001/audio",
+		"/reprocess",
+		"/events",
+		"/voice-map/ws",
+		"/voice-map",
+		"/cover",
+		"/status",
+		"/segments",
+		"/stream",
+		"/download",
+		"/pipeline/status",
+		"/personas",
+		"/audio/seg_00001",
+	}
+	for _, subResource := range subResources {
+		w := httptest.NewRecorder()
+		handler.Route(w, httptest.NewRequest(http.MethodDelete, "/api/v1/books/book_1"+subResource, nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("DELETE %s: expected status 405, got %d", subResource, w.Code)
+		}
+		if _, err := handler.repo.GetBook(ctx, "book_1"); err != nil {
+			t.Fatalf("DELETE %s removed the book: %v", subResource, err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.Route(w, httptest.NewRequest(http.MethodDelete, "/api/v1/books/book_1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 deleting the book, got %d", w.Code)
+	}
+	if _, err := handler.repo.GetBook(ctx, "book_1"); err == nil {
+		t.Error("Expected the book to be deleted")
+	}
+}
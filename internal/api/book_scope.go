@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/unalkalkan/TwelveReader/internal/pipeline"
+)
+
+// synthesisScopeResponse is returned by the synthesis scope endpoints
+type synthesisScopeResponse struct {
+	BookID           string                   `json:"book_id"`
+	Scope            *pipeline.SynthesisScope `json:"scope"`
+	DeferredSegments int                      `json:"deferred_segments"`
+}
+
+// SynthesisScope handles GET and POST /api/v1/books/:id/synthesis-scope.
+// POST prioritizes or restricts synthesis of a book that is still being
+// synthesized to chosen chapters; an empty body clears the scope.
+func (h *BookHandler) SynthesisScope(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bookID := extractIDFromPath(r.URL.Path, "/api/v1/books/")
+	if bookID == "" {
+		respondError(w, "Book ID required", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.repo.GetBook(r.Context(), bookID); err != nil {
+		respondError(w, "Book not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		var scope pipeline.SynthesisScope
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&scope); err != nil && err != io.EOF {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if _, _, err := h.hybridOrchestrator.GetSynthesisScope(bookID); err != nil {
+			respondError(w, "Book is not being synthesized", http.StatusConflict)
+			return
+		}
+		if _, err := h.hybridOrchestrator.SetSynthesisScope(r.Context(), bookID, &scope); err != nil {
+			log.Printf("[SynthesisScope] Rejected scope for book %s: %v", bookID, err)
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	scope, deferred, err := h.hybridOrchestrator.GetSynthesisScope(bookID)
+	if err != nil {
+		respondError(w, "Book is not being synthesized", http.StatusConflict)
+		return
+	}
+	respondJSON(w, synthesisScopeResponse{BookID: bookID, Scope: scope, DeferredSegments: deferred}, http.StatusOK)
+}
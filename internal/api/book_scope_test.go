@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestBookHandler_SynthesisScopeRequiresRunningPipeline(t *testing.T) {
+	handler := newTestBookHandler(t)
+	handler.repo.SaveBook(context.Background(), &types.Book{ID: "book_done", Status: "synthesized"})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"Unknown book", http.MethodGet, "/api/v1/books/book_missing/synthesis-scope", "", http.StatusNotFound},
+		{"Invalid body", http.MethodPost, "/api/v1/books/book_done/synthesis-scope", `{`, http.StatusBadRequest},
+		{"No pipeline", http.MethodPost, "/api/v1/books/book_done/synthesis-scope", `{"chapters":["ch_001"]}`, http.StatusConflict},
+		{"No pipeline status", http.MethodGet, "/api/v1/books/book_done/synthesis-scope", "", http.StatusConflict},
+		{"Wrong method", http.MethodDelete, "/api/v1/books/book_done/synthesis-scope", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.SynthesisScope(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
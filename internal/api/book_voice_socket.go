@@ -0,0 +1,272 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/pipeline"
+	"github.com/unalkalkan/TwelveReader/internal/websocket"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+const (
+	// voiceSocketPingInterval is how often an open voice mapping socket is
+	// pinged so proxies keep it open
+	voiceSocketPingInterval = 30 * time.Second
+
+	// voiceSocketSampleCount is how many segments of each persona are sent
+	// as samples to choose a voice by
+	voiceSocketSampleCount = 3
+)
+
+// voiceSocketConn is the connection of a voice mapping session
+type voiceSocketConn interface {
+	ReadMessage() ([]byte, error)
+	WriteJSON(v any) error
+	WriteClose(code int, reason string) error
+	Ping() error
+}
+
+// voiceSocketRequest is a message from the client. Type "voice_map" maps
+// personas to voices; Initial defaults to whether the book still waits for
+// its initial mapping.
+type voiceSocketRequest struct {
+	Type    string              `json:"type"`
+	ID      string              `json:"id,omitempty"` // Echoed in the reply
+	Persons []types.PersonVoice `json:"persons"`
+	Initial *bool               `json:"initial,omitempty"`
+}
+
+// voiceSocketPersonas is the persona state sent when a session opens
+type voiceSocketPersonas struct {
+	Type string `json:"type"` // "personas"
+	*types.PersonaDiscovery
+	Samples map[string][]*types.Segment `json:"samples"` // Unmapped persona to sample segments
+}
+
+// voiceSocketDiscovery reports personas discovered during the session
+type voiceSocketDiscovery struct {
+	Type string `json:"type"` // "persona_discovery"
+	pipeline.PersonaEvent
+	Samples map[string][]*types.Segment `json:"samples"`
+}
+
+// voiceSocketAck acknowledges an applied voice mapping
+type voiceSocketAck struct {
+	Type            string   `json:"type"` // "ack"
+	ID              string   `json:"id,omitempty"`
+	Promoted        int      `json:"promoted"` // Pending segments moved to the TTS queue
+	Unmapped        []string `json:"unmapped"`
+	PendingSegments int      `json:"pending_segments"`
+}
+
+// voiceSocketError reports a message that could not be handled
+type voiceSocketError struct {
+	Type  string `json:"type"` // "error"
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+// VoiceMapSocket handles GET /api/v1/books/:id/voice-map/ws, a WebSocket for
+// interactive voice mapping. The server pushes the book's personas when the
+// socket opens and personas the pipeline discovers afterwards, each with
+// sample segments; the client answers with voice_map messages that are
+// applied to the running pipeline straight away.
+func (h *BookHandler) VoiceMapSocket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bookID := extractIDFromPath(r.URL.Path, "/api/v1/books/")
+	if bookID == "" {
+		respondError(w, "Book ID required", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.repo.GetBook(r.Context(), bookID); err != nil {
+		respondError(w, "Book not found", http.StatusNotFound)
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		log.Printf("[VoiceMapSocket] Upgrade failed for book %s: %v", bookID, err)
+		return
+	}
+	defer conn.Close()
+
+	log.Printf("[VoiceMapSocket] Opened voice mapping socket for book %s", bookID)
+	h.serveVoiceSocket(r.Context(), bookID, conn)
+	log.Printf("[VoiceMapSocket] Closed voice mapping socket for book %s", bookID)
+}
+
+// serveVoiceSocket runs a voice mapping session until the client leaves
+func (h *BookHandler) serveVoiceSocket(ctx context.Context, bookID string, conn voiceSocketConn) {
+	// Subscribe before the snapshot so no discovery falls in between
+	_, published, cancel := h.events.Subscribe(bookID, 0)
+	defer cancel()
+
+	book, err := h.repo.GetBook(ctx, bookID)
+	if err != nil {
+		conn.WriteClose(websocket.CloseInternalError, "book not found")
+		return
+	}
+	discovery := h.personaDiscovery(ctx, book)
+	if err := conn.WriteJSON(voiceSocketPersonas{
+		Type:             "personas",
+		PersonaDiscovery: discovery,
+		Samples:          h.personaSamples(ctx, bookID, discovery.Unmapped),
+	}); err != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ping := time.NewTicker(voiceSocketPingInterval)
+		defer ping.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case event, ok := <-published:
+				if !ok {
+					conn.WriteClose(websocket.CloseTryAgainLater, "too far behind")
+					return
+				}
+				personas, ok := event.Data.(pipeline.PersonaEvent)
+				if event.Type != pipeline.EventPersonas || !ok {
+					continue
+				}
+				conn.WriteJSON(voiceSocketDiscovery{
+					Type:         "persona_discovery",
+					PersonaEvent: personas,
+					Samples:      h.personaSamples(ctx, bookID, personas.Personas),
+				})
+			case <-ping.C:
+				conn.Ping()
+			}
+		}
+	}()
+
+	for {
+		message, err := conn.ReadMessage()
+		if err != nil {
+			if !errors.Is(err, websocket.ErrClosed) && !errors.Is(err, io.EOF) {
+				log.Printf("[serveVoiceSocket] Read from book %s socket failed: %v", bookID, err)
+			}
+			return
+		}
+
+		var request voiceSocketRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			conn.WriteJSON(voiceSocketError{Type: "error", Error: "Invalid message"})
+			continue
+		}
+		switch request.Type {
+		case "voice_map":
+			conn.WriteJSON(h.applySocketVoiceMap(ctx, bookID, request))
+		default:
+			conn.WriteJSON(voiceSocketError{Type: "error", ID: request.ID, Error: "Unknown message type"})
+		}
+	}
+}
+
+// applySocketVoiceMap applies a voice_map message and returns the reply
+func (h *BookHandler) applySocketVoiceMap(ctx context.Context, bookID string, request voiceSocketRequest) any {
+	if len(request.Persons) == 0 {
+		return voiceSocketError{Type: "error", ID: request.ID, Error: "At least one person is required"}
+	}
+	for _, pv := range request.Persons {
+		if pv.ID == "" || pv.ProviderVoice == "" {
+			return voiceSocketError{Type: "error", ID: request.ID, Error: "Each person needs an id and a provider_voice"}
+		}
+	}
+	book, err := h.repo.GetBook(ctx, bookID)
+	if err != nil {
+		return voiceSocketError{Type: "error", ID: request.ID, Error: "Book not found"}
+	}
+
+	isInitial := book.Status == "voice_mapping"
+	if request.Initial != nil {
+		isInitial = *request.Initial
+	}
+	voiceMap := &types.VoiceMap{BookID: bookID, Persons: request.Persons}
+	promoted, err := h.hybridOrchestrator.ApplyVoiceMapping(ctx, bookID, voiceMap, isInitial)
+	if err != nil {
+		// Without a running pipeline the mapping is kept for the next synthesis
+		log.Printf("[applySocketVoiceMap] Failed to apply voice mapping to orchestrator: %v", err)
+		if err := h.mergeVoiceMap(ctx, voiceMap); err != nil {
+			log.Printf("[applySocketVoiceMap] Failed to save voice map: %v", err)
+			return voiceSocketError{Type: "error", ID: request.ID, Error: "Failed to save voice map"}
+		}
+		if book.Status == "voice_mapping" {
+			book.Status = "ready"
+		}
+		book.WaitingForMapping = false
+		h.repo.UpdateBook(ctx, book)
+	}
+
+	// The orchestrator updated the book's unmapped personas
+	if updated, err := h.repo.GetBook(ctx, bookID); err == nil {
+		book = updated
+	}
+	discovery := h.personaDiscovery(ctx, book)
+	return voiceSocketAck{
+		Type:            "ack",
+		ID:              request.ID,
+		Promoted:        promoted,
+		Unmapped:        discovery.Unmapped,
+		PendingSegments: discovery.PendingSegments,
+	}
+}
+
+// mergeVoiceMap adds a partial voice map to the book's saved voice map
+func (h *BookHandler) mergeVoiceMap(ctx context.Context, update *types.VoiceMap) error {
+	merged := &types.VoiceMap{BookID: update.BookID}
+	if saved, err := h.repo.GetVoiceMap(ctx, update.BookID); err == nil && saved != nil {
+		merged.Persons = saved.Persons
+	}
+	for _, pv := range update.Persons {
+		replaced := false
+		for i := range merged.Persons {
+			if merged.Persons[i].ID == pv.ID {
+				merged.Persons[i].ProviderVoice = pv.ProviderVoice
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged.Persons = append(merged.Persons, pv)
+		}
+	}
+	return h.repo.SaveVoiceMap(ctx, merged)
+}
+
+// personaSamples returns up to voiceSocketSampleCount stored segments of
+// each persona
+func (h *BookHandler) personaSamples(ctx context.Context, bookID string, personas []string) map[string][]*types.Segment {
+	samples := make(map[string][]*types.Segment, len(personas))
+	if len(personas) == 0 {
+		return samples
+	}
+	wanted := make(map[string]bool, len(personas))
+	for _, persona := range personas {
+		wanted[persona] = true
+	}
+	segments, err := h.repo.ListSegments(ctx, bookID)
+	if err != nil {
+		log.Printf("[personaSamples] Failed to list segments of book %s: %v", bookID, err)
+		return samples
+	}
+	for _, segment := range segments {
+		if wanted[segment.Person] && len(samples[segment.Person]) < voiceSocketSampleCount {
+			samples[segment.Person] = append(samples[segment.Person], segment)
+		}
+	}
+	return samples
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/pipeline"
+	"github.com/unalkalkan/TwelveReader/internal/websocket"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// fakeVoiceSocket passes messages of a voice mapping session through channels
+type fakeVoiceSocket struct {
+	incoming chan []byte
+	outgoing chan []byte
+}
+
+func (s *fakeVoiceSocket) ReadMessage() ([]byte, error) {
+	message, ok := <-s.incoming
+	if !ok {
+		return nil, websocket.ErrClosed
+	}
+	return message, nil
+}
+
+func (s *fakeVoiceSocket) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.outgoing <- data
+	return nil
+}
+
+func (s *fakeVoiceSocket) WriteClose(code int, reason string) error { return nil }
+
+func (s *fakeVoiceSocket) Ping() error { return nil }
+
+func TestBookHandler_VoiceMapSocket(t *testing.T) {
+	handler := newTestBookHandler(t)
+	ctx := context.Background()
+
+	handler.repo.SaveBook(ctx, &types.Book{
+		ID:                 "book_ws",
+		Status:             "voice_mapping",
+		WaitingForMapping:  true,
+		DiscoveredPersonas: []string{"narrator", "villain"},
+		UnmappedPersonas:   []string{"narrator", "villain"},
+	})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: "book_ws", Text: "Once upon a time.", Person: "narrator"})
+	handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00002", BookID: "book_ws", Text: "Mwahaha!", Person: "villain"})
+
+	socket := &fakeVoiceSocket{incoming: make(chan []byte), outgoing: make(chan []byte, 1)}
+	done := make(chan struct{})
+	go func() {
+		handler.serveVoiceSocket(ctx, "book_ws", socket)
+		close(done)
+	}()
+
+	receive := func(v any) {
+		t.Helper()
+		select {
+		case message := <-socket.outgoing:
+			if err := json.Unmarshal(message, v); err != nil {
+				t.Fatalf("Invalid message %s: %v", message, err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for a message")
+		}
+	}
+	send := func(message string) {
+		t.Helper()
+		socket.incoming <- []byte(message)
+	}
+
+	var personas struct {
+		Type     string                       `json:"type"`
+		Unmapped []string                     `json:"unmapped"`
+		Samples  map[string][]json.RawMessage `json:"samples"`
+	}
+	receive(&personas)
+	if personas.Type != "personas" || len(personas.Unmapped) != 2 || len(personas.Samples["villain"]) != 1 {
+		t.Fatalf("Unexpected opening message: %+v", personas)
+	}
+
+	t.Run("Pushes discovered personas with samples", func(t *testing.T) {
+		handler.repo.SaveSegment(ctx, &types.Segment{ID: "seg_00003", BookID: "book_ws", Text: "Boo.", Person: "ghost"})
+		handler.events.Publish("book_ws", pipeline.EventStage, pipeline.StageProgress{Stage: "segmenting"})
+		handler.events.Publish("book_ws", pipeline.EventPersonas, pipeline.PersonaEvent{Personas: []string{"ghost"}, BlockingSegmentID: "seg_00003"})
+
+		var discovery struct {
+			Type              string                     `json:"type"`
+			Personas          []string                   `json:"personas"`
+			BlockingSegmentID string                     `json:"blocking_segment_id"`
+			Samples           map[string][]types.Segment `json:"samples"`
+		}
+		receive(&discovery)
+		if discovery.Type != "persona_discovery" || discovery.BlockingSegmentID != "seg_00003" || len(discovery.Samples["ghost"]) != 1 || discovery.Samples["ghost"][0].Text != "Boo." {
+			t.Errorf("Unexpected discovery message: %+v", discovery)
+		}
+	})
+
+	t.Run("Acknowledges mappings", func(t *testing.T) {
+		var ack voiceSocketAck
+		send(`{"type":"voice_map","id":"1","persons":[{"id":"narrator","provider_voice":"voice-a"}]}`)
+		receive(&ack)
+		if ack.Type != "ack" || ack.ID != "1" || ack.Promoted != 0 || len(ack.Unmapped) != 1 || ack.Unmapped[0] != "villain" {
+			t.Errorf("Unexpected ack: %+v", ack)
+		}
+
+		send(`{"type":"voice_map","id":"2","persons":[{"id":"villain","provider_voice":"voice-b"}]}`)
+		receive(&ack)
+		if ack.ID != "2" || len(ack.Unmapped) != 0 {
+			t.Errorf("Unexpected ack: %+v", ack)
+		}
+
+		// Without a running pipeline, partial mappings add up in the saved voice map
+		voiceMap, err := handler.repo.GetVoiceMap(ctx, "book_ws")
+		if err != nil || len(voiceMap.Persons) != 2 {
+			t.Fatalf("Expected both personas in the saved voice map, got %+v (%v)", voiceMap, err)
+		}
+		if book, _ := handler.repo.GetBook(ctx, "book_ws"); book.Status != "ready" || book.WaitingForMapping {
+			t.Errorf("Expected the book to stop waiting for mapping, got %s", book.Status)
+		}
+	})
+
+	t.Run("Reports invalid messages", func(t *testing.T) {
+		var reply voiceSocketError
+		send(`{"type":"voice_map","id":"3","persons":[{"id":"ghost"}]}`)
+		receive(&reply)
+		if reply.Type != "error" || reply.ID != "3" {
+			t.Errorf("Expected an error for a person without a voice, got %+v", reply)
+		}
+		send(`not json`)
+		receive(&reply)
+		if reply.Type != "error" {
+			t.Errorf("Expected an error for invalid JSON, got %+v", reply)
+		}
+	})
+
+	close(socket.incoming)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the session to end when the client leaves")
+	}
+
+	t.Run("Requires a WebSocket upgrade", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.VoiceMapSocket(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_ws/voice-map/ws", nil))
+		if w.Code != http.StatusUpgradeRequired {
+			t.Errorf("Expected status 426, got %d", w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		handler.VoiceMapSocket(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/book_missing/voice-map/ws", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
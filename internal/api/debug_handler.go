@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/unalkalkan/TwelveReader/internal/audio"
 	"github.com/unalkalkan/TwelveReader/internal/book"
 	"github.com/unalkalkan/TwelveReader/internal/debugstate"
 	"github.com/unalkalkan/TwelveReader/internal/storage"
@@ -104,6 +105,24 @@ func (h *DebugHandler) PlaybackEvents(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *DebugHandler) WebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	bookID := extractDebugBookID(r.URL.Path)
+	if bookID == "" {
+		respondError(w, "Book ID required", http.StatusBadRequest)
+		return
+	}
+	deliveries, err := h.store.ListWebhookDeliveries(r.Context(), bookID, parseLimit(r, 100))
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, map[string]interface{}{"book_id": bookID, "deliveries": deliveries}, http.StatusOK)
+}
+
 func (h *DebugHandler) UserProgress(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -188,6 +207,7 @@ func (h *DebugHandler) buildSynthJobs(ctx context.Context, bookID string) ([]*ty
 			continue
 		}
 		if job := bySegment[segment.ID]; job != nil {
+			job.AudioDurationSec = segment.AudioDuration
 			jobs = append(jobs, job)
 			continue
 		}
@@ -212,6 +232,7 @@ func (h *DebugHandler) buildSynthJobs(ctx context.Context, bookID string) ([]*ty
 			job.OutputPath = validation.Path
 			job.OutputFormat = validation.Format
 			job.OutputBytes = validation.Bytes
+			job.AudioDurationSec = validation.AudioDurationSec
 			if validation.Status == "missing" && segment.VoiceID != "" {
 				job.Status = "failed"
 				job.Error = validation.Error
@@ -247,6 +268,12 @@ func (h *DebugHandler) validateAudioArtifacts(ctx context.Context, bookID string
 			validation.Path = path
 			validation.Bytes = int64(len(data))
 			validation.ContentType = audioContentType(format)
+			validation.AudioDurationSec = segment.AudioDuration
+			if validation.AudioDurationSec == 0 {
+				if duration, err := audio.Duration(data, format); err == nil {
+					validation.AudioDurationSec = duration.Seconds()
+				}
+			}
 			if readErr != nil {
 				validation.Status = "invalid"
 				validation.Error = readErr.Error()
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -18,6 +20,7 @@ import (
 	"github.com/unalkalkan/TwelveReader/internal/book"
 	"github.com/unalkalkan/TwelveReader/internal/provider"
 	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/internal/util"
 	"github.com/unalkalkan/TwelveReader/pkg/types"
 )
 
@@ -316,16 +319,27 @@ func (h *VoicesHandler) ListVoices(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// PreviewVoice handles POST /api/v1/voices/preview
+// PreviewVoice handles POST /api/v1/voices/preview, which returns the sample
+// as base64 encoded JSON, and GET /api/v1/voices/preview, which streams the
+// sample audio with support for range and conditional requests
 func (h *VoicesHandler) PreviewVoice(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req VoicePreviewRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, "Invalid request body", http.StatusBadRequest)
+	switch r.Method {
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	case http.MethodGet, http.MethodHead:
+		query := r.URL.Query()
+		req = VoicePreviewRequest{
+			Provider:         query.Get("provider"),
+			VoiceID:          query.Get("voice_id"),
+			Language:         query.Get("language"),
+			VoiceDescription: query.Get("voice_description"),
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -337,7 +351,7 @@ func (h *VoicesHandler) PreviewVoice(w http.ResponseWriter, r *http.Request) {
 		respondError(w, "voice_id is required", http.StatusBadRequest)
 		return
 	}
-	if req.Text == "" {
+	if req.Text == "" && r.Method == http.MethodPost {
 		respondError(w, "text is required", http.StatusBadRequest)
 		return
 	}
@@ -356,10 +370,14 @@ func (h *VoicesHandler) PreviewVoice(w http.ResponseWriter, r *http.Request) {
 		respondError(w, fmt.Sprintf("Failed to synthesize preview: %v", err), http.StatusInternalServerError)
 		return
 	}
+	if r.Method != http.MethodPost {
+		h.serveVoiceSample(ctx, w, r, sample, req)
+		return
+	}
 
 	resp := VoicePreviewResponse{
 		AudioBase64: base64.StdEncoding.EncodeToString(sample.AudioData),
-		MimeType:    audioMimeType(sample.Format),
+		MimeType:    util.AudioMimeType(sample.Format),
 		Format:      sample.Format,
 		Cached:      sample.Cached,
 	}
@@ -373,6 +391,23 @@ type voiceSample struct {
 	Cached    bool
 }
 
+// serveVoiceSample streams a preview sample. Samples are tagged by their
+// content, and by their storage time when they are persisted.
+func (h *VoicesHandler) serveVoiceSample(ctx context.Context, w http.ResponseWriter, r *http.Request, sample *voiceSample, req VoicePreviewRequest) {
+	var modTime time.Time
+	if h.sampleStore != nil {
+		key := h.voiceSampleKey(req.Provider, req.VoiceID, req.Language, req.VoiceDescription)
+		if metadata, err := h.sampleStore.Stat(ctx, voiceSamplePathForKey(key, sample.Format)); err == nil && metadata.LastModified > 0 {
+			modTime = time.Unix(metadata.LastModified, 0)
+		}
+	}
+
+	sum := sha256.Sum256(sample.AudioData)
+	w.Header().Set("Content-Type", util.AudioMimeType(sample.Format))
+	w.Header().Set("ETag", fmt.Sprintf(`"%x"`, sum[:16]))
+	http.ServeContent(w, r, "", modTime, bytes.NewReader(sample.AudioData))
+}
+
 // PreGenerateVoiceSamples creates missing persistent preview samples for every available TTS voice.
 func (h *VoicesHandler) PreGenerateVoiceSamples(ctx context.Context) error {
 	if h.sampleStore == nil {
@@ -493,18 +528,3 @@ func safeVoiceSamplePart(value string) string {
 func voiceSamplePathForKey(key, format string) string {
 	return filepath.Join("voice-samples", fmt.Sprintf("%s.%s", key, format))
 }
-
-func audioMimeType(format string) string {
-	switch format {
-	case "mp3":
-		return "audio/mpeg"
-	case "wav":
-		return "audio/wav"
-	case "ogg":
-		return "audio/ogg"
-	case "flac":
-		return "audio/flac"
-	default:
-		return "application/octet-stream"
-	}
-}
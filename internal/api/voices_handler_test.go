@@ -537,3 +537,47 @@ func TestVoicesHandler_PreGenerateVoiceSamplesStoresEachVoice(t *testing.T) {
 		t.Fatalf("Expected persisted startup samples to prevent resynthesis, got %d calls", counting.synthesizeCalls)
 	}
 }
+
+func TestVoicesHandler_PreviewVoiceStreamsSampleAudio(t *testing.T) {
+	registry := provider.NewRegistry()
+	counting := &countingTTSProvider{name: "counting-tts"}
+	if err := registry.RegisterTTS(counting); err != nil {
+		t.Fatalf("Failed to register counting TTS provider: %v", err)
+	}
+	handler := NewVoicesHandlerWithSampleStorage(registry, storage.NewMemorySampleStore())
+	previewURL := "/api/v1/voices/preview?provider=counting-tts&voice_id=voice-1&language=en"
+
+	req := httptest.NewRequest(http.MethodGet, previewURL, nil)
+	w := httptest.NewRecorder()
+	handler.PreviewVoice(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != "CACHED_AUDIO_voice-1" || w.Header().Get("Content-Type") != "audio/wav" {
+		t.Fatalf("Unexpected sample response %q (%s)", w.Body.String(), w.Header().Get("Content-Type"))
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Last-Modified") == "" || w.Header().Get("Accept-Ranges") != "bytes" {
+		t.Fatalf("Expected caching and range headers, got %v", w.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, previewURL, nil)
+	req.Header.Set("Range", "bytes=13-")
+	w = httptest.NewRecorder()
+	handler.PreviewVoice(w, req)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "voice-1" {
+		t.Errorf("Expected partial sample, got %d %q", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, previewURL, nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler.PreviewVoice(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected status 304, got %d", w.Code)
+	}
+
+	if counting.synthesizeCalls != 1 {
+		t.Errorf("Expected sample to be synthesized once, got %d calls", counting.synthesizeCalls)
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/unalkalkan/TwelveReader/internal/netguard"
+	"github.com/unalkalkan/TwelveReader/internal/webhook"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// WebhookHandler handles webhook subscription endpoints
+type WebhookHandler struct {
+	dispatcher *webhook.Dispatcher
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(dispatcher *webhook.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{dispatcher: dispatcher}
+}
+
+// webhookRequest is the body of POST /api/v1/webhooks
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"` // Generated when empty
+	Events []string `json:"events"` // Empty for all events
+}
+
+// Subscriptions handles GET and POST /api/v1/webhooks. Secrets are only
+// returned when a subscription is created.
+func (h *WebhookHandler) Subscriptions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		subscriptions, err := h.dispatcher.ListSubscriptions(r.Context())
+		if err != nil {
+			log.Printf("[WebhookHandler] Failed to list subscriptions: %v", err)
+			respondError(w, "Failed to list webhooks", http.StatusInternalServerError)
+			return
+		}
+		listed := make([]types.WebhookSubscription, 0, len(subscriptions))
+		for _, subscription := range subscriptions {
+			redacted := *subscription
+			redacted.Secret = ""
+			listed = append(listed, redacted)
+		}
+		respondJSON(w, listed, http.StatusOK)
+	case http.MethodPost:
+		var req webhookRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := webhook.ValidateSubscription(req.URL, req.Events); err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		subscription := &types.WebhookSubscription{URL: req.URL, Secret: req.Secret, Events: req.Events}
+		err := h.dispatcher.CreateSubscription(r.Context(), subscription)
+		if errors.Is(err, netguard.ErrNotPublic) {
+			respondError(w, "Webhook url must point to a public address", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("[WebhookHandler] Failed to create subscription: %v", err)
+			respondError(w, "Failed to create webhook", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("[WebhookHandler] Created webhook %s for %s", subscription.ID, subscription.URL)
+		respondJSON(w, subscription, http.StatusCreated)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Subscription handles DELETE /api/v1/webhooks/:id
+func (h *WebhookHandler) Subscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/webhooks/"), "/")
+	if id == "" {
+		respondError(w, "Webhook ID required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.dispatcher.DeleteSubscription(r.Context(), id)
+	switch {
+	case errors.Is(err, webhook.ErrNotFound):
+		respondError(w, "Webhook not found", http.StatusNotFound)
+	case errors.Is(err, webhook.ErrConfigured):
+		respondError(w, "Webhooks from the configuration file cannot be deleted", http.StatusForbidden)
+	case err != nil:
+		log.Printf("[WebhookHandler] Failed to delete webhook %s: %v", id, err)
+		respondError(w, "Failed to delete webhook", http.StatusInternalServerError)
+	default:
+		log.Printf("[WebhookHandler] Deleted webhook %s", id)
+		respondJSON(w, map[string]string{"status": "deleted"}, http.StatusOK)
+	}
+}
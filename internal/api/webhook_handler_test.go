@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/internal/webhook"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	storageAdapter, err := storage.NewLocalAdapter(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage adapter: %v", err)
+	}
+	defer storageAdapter.Close()
+	handler := NewWebhookHandler(webhook.NewDispatcher(storageAdapter, []types.WebhookConfig{{URL: "https://cms.example.com/hooks", Secret: "s3cret"}}))
+
+	var created types.WebhookSubscription
+	t.Run("Creates subscriptions", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.Subscriptions(w, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"url":"https://ops.example.com/hooks","events":["error"]}`)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+		json.NewDecoder(w.Body).Decode(&created)
+		if created.ID == "" || created.Secret == "" {
+			t.Errorf("Expected the created subscription with its secret, got %+v", created)
+		}
+
+		w = httptest.NewRecorder()
+		handler.Subscriptions(w, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"url":"https://ops.example.com/hooks","events":["uploaded"]}`)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for an unknown event, got %d", w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		handler.Subscriptions(w, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"url":"http://169.254.169.254/latest/meta-data"}`)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for a link-local address, got %d", w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		oversized := `{"url":"https://ops.example.com/hooks","secret":"` + strings.Repeat("x", 1<<20) + `"}`
+		handler.Subscriptions(w, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(oversized)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for an oversized body, got %d", w.Code)
+		}
+	})
+
+	t.Run("Lists subscriptions without secrets", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.Subscriptions(w, httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil))
+		var listed []types.WebhookSubscription
+		json.NewDecoder(w.Body).Decode(&listed)
+		if len(listed) != 2 || listed[0].Source != "config" || listed[1].ID != created.ID {
+			t.Fatalf("Unexpected subscriptions %+v", listed)
+		}
+		for _, subscription := range listed {
+			if subscription.Secret != "" {
+				t.Errorf("Expected secrets to be hidden, got %+v", subscription)
+			}
+		}
+	})
+
+	t.Run("Deletes subscriptions", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.Subscription(w, httptest.NewRequest(http.MethodDelete, "/api/v1/webhooks/config_1", nil))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403 for a configured webhook, got %d", w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		handler.Subscription(w, httptest.NewRequest(http.MethodDelete, "/api/v1/webhooks/"+created.ID, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		handler.Subscription(w, httptest.NewRequest(http.MethodDelete, "/api/v1/webhooks/"+created.ID, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Duration measures the playing time of an audio file in the given format:
+// "wav", "mp3", "ogg" (Vorbis or Opus) or "flac"
+func Duration(data []byte, format string) (time.Duration, error) {
+	switch format {
+	case "wav":
+		return wavDuration(data)
+	case "mp3":
+		return mp3Duration(data)
+	case "ogg":
+		return oggDuration(data)
+	case "flac":
+		return flacDuration(data)
+	default:
+		return 0, fmt.Errorf("unsupported audio format: %s", format)
+	}
+}
+
+// wavDuration measures WAV audio from its header. Streamed WAV files can
+// carry a placeholder data size, so the size is capped at the bytes present.
+func wavDuration(data []byte) (time.Duration, error) {
+	header, err := ParseWAVHeader(data)
+	if err != nil {
+		return 0, err
+	}
+	if available := int64(len(data)) - header.DataOffset; header.DataSize > available {
+		header.DataSize = available
+	}
+	return header.Duration(), nil
+}
+
+var (
+	// mp3Bitrates lists bitrates in kbps by [MPEG-1][layer] with layer
+	// I, II, III at index 0, 1, 2
+	mp3Bitrates = [2][3][15]int{
+		{ // MPEG-2 and MPEG-2.5
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		},
+		{ // MPEG-1
+			{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+			{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		},
+	}
+
+	// mp3SampleRates lists sample rates by version bits (MPEG-2.5, reserved,
+	// MPEG-2, MPEG-1)
+	mp3SampleRates = [4][3]int{
+		{11025, 12000, 8000},
+		{},
+		{22050, 24000, 16000},
+		{44100, 48000, 32000},
+	}
+)
+
+// mp3Duration adds up the samples of every MPEG audio frame
+func mp3Duration(data []byte) (time.Duration, error) {
+	pos := id3v2Size(data)
+	frames := 0
+	var seconds float64
+	for pos+4 <= len(data) {
+		header := binary.BigEndian.Uint32(data[pos:])
+		version := (header >> 19) & 0x3
+		layerBits := (header >> 17) & 0x3
+		bitrateIndex := (header >> 12) & 0xF
+		rateIndex := (header >> 10) & 0x3
+		valid := header&0xFFE00000 == 0xFFE00000 && version != 1 && layerBits != 0 &&
+			bitrateIndex != 0 && bitrateIndex != 15 && rateIndex != 3
+		if !valid {
+			// Skip junk before the first frame; anything after the last frame,
+			// such as an ID3v1 tag, ends the audio
+			if frames == 0 {
+				pos++
+				continue
+			}
+			break
+		}
+
+		mpeg1 := 0
+		if version == 3 {
+			mpeg1 = 1
+		}
+		layer := 3 - int(layerBits) // 0 for layer I, 2 for layer III
+		bitrate := mp3Bitrates[mpeg1][layer][bitrateIndex] * 1000
+		sampleRate := mp3SampleRates[version][rateIndex]
+		padding := int((header >> 9) & 0x1)
+
+		var samples, length int
+		switch {
+		case layer == 0:
+			samples = 384
+			length = (12*bitrate/sampleRate + padding) * 4
+		case layer == 2 && mpeg1 == 0:
+			samples = 576
+			length = 72*bitrate/sampleRate + padding
+		default:
+			samples = 1152
+			length = 144*bitrate/sampleRate + padding
+		}
+
+		frames++
+		seconds += float64(samples) / float64(sampleRate)
+		pos += length
+	}
+	if frames == 0 {
+		return 0, fmt.Errorf("no MPEG audio frames found")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// id3v2Size returns the size of a leading ID3v2 tag, or 0 when there is none
+func id3v2Size(data []byte) int {
+	if len(data) < 10 || string(data[0:3]) != "ID3" {
+		return 0
+	}
+	size := 10 + (int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F))
+	if data[5]&0x10 != 0 {
+		size += 10 // Footer
+	}
+	return min(size, len(data))
+}
+
+// oggDuration reads the sample rate from the first packet and the number of
+// samples from the granule position of the last page
+func oggDuration(data []byte) (time.Duration, error) {
+	if len(data) < 27 || string(data[0:4]) != "OggS" {
+		return 0, fmt.Errorf("not an Ogg file")
+	}
+	packet := data[27+int(data[26]):]
+
+	var sampleRate, preSkip int64
+	switch {
+	case len(packet) >= 16 && string(packet[0:7]) == "\x01vorbis":
+		sampleRate = int64(binary.LittleEndian.Uint32(packet[12:16]))
+	case len(packet) >= 12 && string(packet[0:8]) == "OpusHead":
+		// Opus granule positions always count 48 kHz samples
+		sampleRate = 48000
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+	default:
+		return 0, fmt.Errorf("unsupported Ogg codec")
+	}
+	if sampleRate == 0 {
+		return 0, fmt.Errorf("invalid Ogg sample rate")
+	}
+
+	for end := len(data); ; {
+		pos := bytes.LastIndex(data[:end], []byte("OggS"))
+		if pos < 0 {
+			return 0, fmt.Errorf("no Ogg page with a granule position")
+		}
+		if pos+14 <= len(data) && data[pos+4] == 0 {
+			granule := int64(binary.LittleEndian.Uint64(data[pos+6 : pos+14]))
+			if granule >= 0 {
+				return samplesDuration(max(granule-preSkip, 0), sampleRate), nil
+			}
+		}
+		end = pos
+	}
+}
+
+// flacDuration reads the sample rate and total samples from STREAMINFO
+func flacDuration(data []byte) (time.Duration, error) {
+	data = data[id3v2Size(data):]
+	// "fLaC", a metadata block header and the 34 byte STREAMINFO block
+	if len(data) < 42 || string(data[0:4]) != "fLaC" || data[4]&0x7F != 0 {
+		return 0, fmt.Errorf("not a FLAC file")
+	}
+	info := data[8:42]
+	sampleRate := int64(info[10])<<12 | int64(info[11])<<4 | int64(info[12])>>4
+	totalSamples := int64(info[13]&0x0F)<<32 | int64(binary.BigEndian.Uint32(info[14:18]))
+	if sampleRate == 0 {
+		return 0, fmt.Errorf("invalid FLAC sample rate")
+	}
+	if totalSamples == 0 {
+		return 0, fmt.Errorf("FLAC file does not record its length")
+	}
+	return samplesDuration(totalSamples, sampleRate), nil
+}
+
+// samplesDuration converts a number of samples per channel to playing time
+func samplesDuration(samples, sampleRate int64) time.Duration {
+	return time.Duration(float64(samples) / float64(sampleRate) * float64(time.Second))
+}
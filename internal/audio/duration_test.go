@@ -0,0 +1,102 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// oggPage returns an Ogg page holding one packet
+func oggPage(granule int64, packet []byte) []byte {
+	page := make([]byte, 27, 28+len(packet))
+	copy(page[0:4], "OggS")
+	binary.LittleEndian.PutUint64(page[6:14], uint64(granule))
+	page[26] = 1
+	page = append(page, byte(len(packet)))
+	return append(page, packet...)
+}
+
+func TestDuration(t *testing.T) {
+	t.Run("WAV", func(t *testing.T) {
+		fmtChunk := make([]byte, 16)
+		binary.LittleEndian.PutUint16(fmtChunk[0:2], 1)
+		binary.LittleEndian.PutUint16(fmtChunk[2:4], 1)
+		binary.LittleEndian.PutUint32(fmtChunk[4:8], 1000)
+		binary.LittleEndian.PutUint32(fmtChunk[8:12], 2000)
+		binary.LittleEndian.PutUint16(fmtChunk[12:14], 2)
+		binary.LittleEndian.PutUint16(fmtChunk[14:16], 16)
+		wav := EncodeWAV(fmtChunk, make([]byte, 3000))
+		if d, err := Duration(wav, "wav"); err != nil || d != 1500*time.Millisecond {
+			t.Errorf("Expected 1.5s, got %v (%v)", d, err)
+		}
+
+		// Streamed WAV files may not know their data size up front
+		binary.LittleEndian.PutUint32(wav[40:44], 0xFFFFFFFF)
+		if d, err := Duration(wav, "wav"); err != nil || d != 1500*time.Millisecond {
+			t.Errorf("Expected placeholder size to be capped at 1.5s, got %v (%v)", d, err)
+		}
+	})
+
+	t.Run("MP3", func(t *testing.T) {
+		// MPEG-1 layer III, 128 kbps, 44.1 kHz frames of 417 bytes
+		frame := make([]byte, 417)
+		binary.BigEndian.PutUint32(frame, 0xFFFB9000)
+		var mp3 bytes.Buffer
+		mp3.Write([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 5})
+		mp3.Write(make([]byte, 5))
+		for i := 0; i < 10; i++ {
+			mp3.Write(frame)
+		}
+		mp3.WriteString("TAG")
+		mp3.Write(make([]byte, 125))
+
+		// 10 frames of 1152 samples
+		if d, err := Duration(mp3.Bytes(), "mp3"); err != nil || (d-261224*time.Microsecond).Abs() > time.Millisecond {
+			t.Errorf("Expected about 261ms, got %v (%v)", d, err)
+		}
+		if _, err := Duration([]byte("not audio"), "mp3"); err == nil {
+			t.Error("Expected an error without MPEG frames")
+		}
+	})
+
+	t.Run("Ogg Vorbis", func(t *testing.T) {
+		id := make([]byte, 30)
+		copy(id, "\x01vorbis")
+		binary.LittleEndian.PutUint32(id[12:16], 44100)
+		ogg := append(oggPage(0, id), oggPage(-1, []byte("audio"))...)
+		ogg = append(ogg, oggPage(88200, []byte("audio"))...)
+		if d, err := Duration(ogg, "ogg"); err != nil || d != 2*time.Second {
+			t.Errorf("Expected 2s, got %v (%v)", d, err)
+		}
+	})
+
+	t.Run("Ogg Opus", func(t *testing.T) {
+		head := make([]byte, 19)
+		copy(head, "OpusHead")
+		binary.LittleEndian.PutUint16(head[10:12], 312)
+		ogg := append(oggPage(0, head), oggPage(48312, []byte("audio"))...)
+		if d, err := Duration(ogg, "ogg"); err != nil || d != time.Second {
+			t.Errorf("Expected 1s after pre-skip, got %v (%v)", d, err)
+		}
+	})
+
+	t.Run("FLAC", func(t *testing.T) {
+		flac := make([]byte, 42)
+		copy(flac, "fLaC")
+		flac[7] = 34 // STREAMINFO length
+		info := flac[8:]
+		// 16 kHz, mono, 16-bit, 8000 samples
+		info[10], info[11], info[12], info[13] = 0x03, 0xE8, 0x00, 0xF0
+		binary.BigEndian.PutUint32(info[14:18], 8000)
+		if d, err := Duration(flac, "flac"); err != nil || d != 500*time.Millisecond {
+			t.Errorf("Expected 0.5s, got %v (%v)", d, err)
+		}
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		if _, err := Duration([]byte{1, 2, 3}, "aac"); err == nil {
+			t.Error("Expected an error for an unsupported format")
+		}
+	})
+}
@@ -0,0 +1,378 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// M4BChapter is one chapter of PCM audio in an M4B file
+type M4BChapter struct {
+	Title    string
+	DataSize int64 // Bytes of PCM data
+}
+
+// M4BMetadata describes the book stored in an M4B file
+type M4BMetadata struct {
+	Title       string
+	Author      string
+	Cover       []byte
+	CoverFormat string // "jpg" or "png"; other formats are left out
+}
+
+// M4BLayout is an M4B file split around its PCM data. The file is Header,
+// followed by the PCM data of each chapter in order, followed by Trailer.
+type M4BLayout struct {
+	Header   []byte
+	Trailer  []byte
+	Duration time.Duration
+	Starts   []time.Duration // Where each chapter starts
+}
+
+// Size returns the size of the whole file
+func (l *M4BLayout) Size(dataSize int64) int64 {
+	return int64(len(l.Header)) + dataSize + int64(len(l.Trailer))
+}
+
+const (
+	// m4bMovieTimescale is the timescale of the movie and chapter track, in
+	// units per second
+	m4bMovieTimescale = 1000
+
+	// chplTimescale is the timescale of Nero chapter start times
+	chplTimescale = 10000000
+)
+
+// MuxM4B lays out an MP4 audiobook holding uncompressed PCM audio in the
+// format described by fmtChunk. Chapters are written twice: as a QuickTime
+// chapter text track, which Apple players read, and as a Nero chpl atom,
+// which most other players read. Only 8-bit and 16-bit PCM is supported.
+func MuxM4B(fmtChunk []byte, chapters []M4BChapter, meta M4BMetadata) (*M4BLayout, error) {
+	if len(fmtChunk) < 16 {
+		return nil, fmt.Errorf("invalid WAV fmt chunk")
+	}
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("no chapters")
+	}
+	pcm := &WAV{FmtChunk: fmtChunk}
+	audioFormat := binary.LittleEndian.Uint16(fmtChunk[0:2])
+	channels := binary.LittleEndian.Uint16(fmtChunk[2:4])
+	sampleRate := binary.LittleEndian.Uint32(fmtChunk[4:8])
+	bitsPerSample := binary.LittleEndian.Uint16(fmtChunk[14:16])
+	frameSize := int64(pcm.blockAlign())
+
+	// WAV stores 8-bit samples unsigned and 16-bit samples little-endian
+	var sampleEntry string
+	switch {
+	case audioFormat != 1:
+		return nil, fmt.Errorf("unsupported WAV encoding %d: only PCM can be muxed", audioFormat)
+	case bitsPerSample == 8:
+		sampleEntry = "raw "
+	case bitsPerSample == 16:
+		sampleEntry = "sowt"
+	default:
+		return nil, fmt.Errorf("unsupported sample size: %d bits", bitsPerSample)
+	}
+	if channels == 0 || sampleRate == 0 || sampleRate > math.MaxUint16 || frameSize <= 0 {
+		return nil, fmt.Errorf("unsupported WAV format: %d channels at %d Hz", channels, sampleRate)
+	}
+
+	layout := &M4BLayout{Starts: make([]time.Duration, len(chapters))}
+	var frames, dataSize int64
+	frameCounts := make([]int64, len(chapters))
+	durations := make([]uint32, len(chapters))
+	texts := make([][]byte, len(chapters))
+	var textSize int64
+	for i, chapter := range chapters {
+		if chapter.DataSize%frameSize != 0 {
+			return nil, fmt.Errorf("chapter %d is not a whole number of sample frames", i+1)
+		}
+		frameCounts[i] = chapter.DataSize / frameSize
+		layout.Starts[i] = framesDuration(frames, sampleRate)
+		startMs := frames * m4bMovieTimescale / int64(sampleRate)
+		frames += frameCounts[i]
+		dataSize += chapter.DataSize
+		durations[i] = uint32(frames*m4bMovieTimescale/int64(sampleRate) - startMs)
+		texts[i] = chapterText(chapter.Title)
+		textSize += int64(len(texts[i]))
+	}
+	if frames > math.MaxUint32 {
+		return nil, fmt.Errorf("audio is too long to mux")
+	}
+	layout.Duration = framesDuration(frames, sampleRate)
+	durationMs := uint32(frames * m4bMovieTimescale / int64(sampleRate))
+
+	ftyp := box("ftyp", []byte("M4B "), be32(0), []byte("M4B M4A mp42isom"))
+
+	// The size of moov depends only on whether 64-bit chunk offsets are needed,
+	// so it is built once to measure it and again with the real offsets
+	buildMoov := func(audioOffsets, textOffsets []int64, wide bool) []byte {
+		audioTrak := box("trak",
+			trackHeader(1, durationMs, 0x7, 0x0100),
+			box("tref", box("chap", be32(2))),
+			box("mdia",
+				fullBox("mdhd", 0, 0, be32(0), be32(0), be32(sampleRate), be32(uint32(frames)), be16(0x55c4), be16(0)),
+				handler("soun", "SoundHandler"),
+				box("minf",
+					fullBox("smhd", 0, 0, be16(0), be16(0)),
+					dataInformation(),
+					box("stbl",
+						fullBox("stsd", 0, 0, be32(1), box(sampleEntry,
+							make([]byte, 6), be16(1), // reserved, data reference index
+							be16(0), be16(0), be32(0), // version, revision, vendor
+							be16(channels), be16(bitsPerSample), be16(0), be16(0),
+							be32(sampleRate<<16),
+						)),
+						fullBox("stts", 0, 0, be32(1), be32(uint32(frames)), be32(1)),
+						sampleToChunk(frameCounts),
+						fullBox("stsz", 0, 0, be32(uint32(frameSize)), be32(uint32(frames))),
+						chunkOffsets(audioOffsets, wide),
+					),
+				),
+			),
+		)
+
+		var timeToSample, sampleSizes bytes.Buffer
+		for i := range chapters {
+			timeToSample.Write(be32(1))
+			timeToSample.Write(be32(durations[i]))
+			sampleSizes.Write(be32(uint32(len(texts[i]))))
+		}
+		chapterTrak := box("trak",
+			trackHeader(2, durationMs, 0x2, 0),
+			box("mdia",
+				fullBox("mdhd", 0, 0, be32(0), be32(0), be32(m4bMovieTimescale), be32(durationMs), be16(0x55c4), be16(0)),
+				handler("text", "ChapterHandler"),
+				box("minf",
+					box("gmhd",
+						fullBox("gmin", 0, 0, be16(0x40), be16(0x8000), be16(0x8000), be16(0x8000), be16(0), be16(0)),
+						box("text", be32(0x00010000), make([]byte, 12), be32(0x00010000), make([]byte, 12), be32(0x40000000)),
+					),
+					dataInformation(),
+					box("stbl",
+						fullBox("stsd", 0, 0, be32(1), box("text", make([]byte, 6), be16(1), make([]byte, 35))),
+						fullBox("stts", 0, 0, be32(uint32(len(chapters))), timeToSample.Bytes()),
+						fullBox("stsc", 0, 0, be32(1), be32(1), be32(1), be32(1)),
+						fullBox("stsz", 0, 0, be32(0), be32(uint32(len(chapters))), sampleSizes.Bytes()),
+						chunkOffsets(textOffsets, wide),
+					),
+				),
+			),
+		)
+
+		return box("moov",
+			fullBox("mvhd", 0, 0,
+				be32(0), be32(0), be32(m4bMovieTimescale), be32(durationMs),
+				be32(0x00010000), be16(0x0100), make([]byte, 10), // rate, volume, reserved
+				unityMatrix(), make([]byte, 24), be32(3), // matrix, pre-defined, next track ID
+			),
+			audioTrak,
+			chapterTrak,
+			box("udta",
+				neroChapters(chapters, layout.Starts),
+				itunesMetadata(meta),
+			),
+		)
+	}
+
+	offsets := func(moovSize int64, wide bool) ([]int64, []int64, int64) {
+		headerSize := mdatHeaderSize(dataSize + textSize)
+		pos := int64(len(ftyp)) + moovSize + headerSize
+		audioOffsets := make([]int64, len(chapters))
+		for i, chapter := range chapters {
+			audioOffsets[i] = pos
+			pos += chapter.DataSize
+		}
+		textOffsets := make([]int64, len(chapters))
+		for i, text := range texts {
+			textOffsets[i] = pos
+			pos += int64(len(text))
+		}
+		return audioOffsets, textOffsets, pos
+	}
+
+	placeholder := make([]int64, len(chapters))
+	moovSize := int64(len(buildMoov(placeholder, placeholder, false)))
+	_, _, end := offsets(moovSize, false)
+	wide := end > math.MaxUint32
+	if wide {
+		moovSize = int64(len(buildMoov(placeholder, placeholder, true)))
+	}
+	audioOffsets, textOffsets, _ := offsets(moovSize, wide)
+
+	var header bytes.Buffer
+	header.Write(ftyp)
+	header.Write(buildMoov(audioOffsets, textOffsets, wide))
+	header.Write(mdatHeader(dataSize + textSize))
+	layout.Header = header.Bytes()
+	layout.Trailer = bytes.Join(texts, nil)
+	return layout, nil
+}
+
+// chapterText encodes a chapter title as a QuickTime text sample marked as UTF-8
+func chapterText(title string) []byte {
+	if len(title) > math.MaxUint16 {
+		title = title[:math.MaxUint16]
+	}
+	return append(append(be16(uint16(len(title))), title...), box("encd", be32(0x00000100))...)
+}
+
+// trackHeader returns a tkhd box
+func trackHeader(trackID, durationMs uint32, flags uint32, volume uint16) []byte {
+	return fullBox("tkhd", 0, flags,
+		be32(0), be32(0), be32(trackID), be32(0), be32(durationMs),
+		make([]byte, 8), be16(0), be16(0), be16(volume), be16(0), // reserved, layer, alternate group
+		unityMatrix(), be32(0), be32(0), // matrix, width, height
+	)
+}
+
+// handler returns an hdlr box for the given handler type
+func handler(handlerType, name string) []byte {
+	return fullBox("hdlr", 0, 0, be32(0), []byte(handlerType), make([]byte, 12), []byte(name), []byte{0})
+}
+
+// dataInformation returns a dinf box saying the media is in this file
+func dataInformation() []byte {
+	return box("dinf", fullBox("dref", 0, 0, be32(1), fullBox("url ", 0, 1)))
+}
+
+// sampleToChunk returns an stsc box for chunks holding the given numbers of samples
+func sampleToChunk(samplesPerChunk []int64) []byte {
+	var entries bytes.Buffer
+	count := 0
+	for i, samples := range samplesPerChunk {
+		if i > 0 && samples == samplesPerChunk[i-1] {
+			continue
+		}
+		entries.Write(be32(uint32(i + 1)))
+		entries.Write(be32(uint32(samples)))
+		entries.Write(be32(1))
+		count++
+	}
+	return fullBox("stsc", 0, 0, be32(uint32(count)), entries.Bytes())
+}
+
+// chunkOffsets returns an stco box, or a co64 box when wide is set
+func chunkOffsets(offsets []int64, wide bool) []byte {
+	var entries bytes.Buffer
+	for _, offset := range offsets {
+		if wide {
+			entries.Write(be64(uint64(offset)))
+		} else {
+			entries.Write(be32(uint32(offset)))
+		}
+	}
+	if wide {
+		return fullBox("co64", 0, 0, be32(uint32(len(offsets))), entries.Bytes())
+	}
+	return fullBox("stco", 0, 0, be32(uint32(len(offsets))), entries.Bytes())
+}
+
+// neroChapters returns a chpl box listing chapter titles and start times. The
+// box holds at most 255 chapters.
+func neroChapters(chapters []M4BChapter, starts []time.Duration) []byte {
+	var entries bytes.Buffer
+	count := min(len(chapters), math.MaxUint8)
+	for i := 0; i < count; i++ {
+		title := chapters[i].Title
+		if len(title) > math.MaxUint8 {
+			title = title[:math.MaxUint8]
+		}
+		entries.Write(be64(uint64(starts[i] / (time.Second / chplTimescale))))
+		entries.WriteByte(byte(len(title)))
+		entries.WriteString(title)
+	}
+	return fullBox("chpl", 1, 0, be32(0), []byte{byte(count)}, entries.Bytes())
+}
+
+// itunesMetadata returns a meta box with the title, author and cover
+func itunesMetadata(meta M4BMetadata) []byte {
+	const (
+		dataUTF8    = 1
+		dataJPEG    = 13
+		dataPNG     = 14
+		dataInteger = 21
+	)
+	item := func(name string, dataType uint32, value []byte) []byte {
+		return box(name, box("data", be32(dataType), be32(0), value))
+	}
+
+	var items [][]byte
+	if meta.Title != "" {
+		items = append(items, item("\xa9nam", dataUTF8, []byte(meta.Title)), item("\xa9alb", dataUTF8, []byte(meta.Title)))
+	}
+	if meta.Author != "" {
+		items = append(items, item("\xa9ART", dataUTF8, []byte(meta.Author)), item("aART", dataUTF8, []byte(meta.Author)))
+	}
+	items = append(items, item("stik", dataInteger, []byte{2})) // Audiobook media kind
+	switch meta.CoverFormat {
+	case "jpg", "jpeg":
+		items = append(items, item("covr", dataJPEG, meta.Cover))
+	case "png":
+		items = append(items, item("covr", dataPNG, meta.Cover))
+	}
+
+	return fullBox("meta", 0, 0,
+		fullBox("hdlr", 0, 0, be32(0), []byte("mdirappl"), make([]byte, 8), []byte{0}),
+		box("ilst", items...),
+	)
+}
+
+// mdatHeaderSize returns the size of the mdat header for a payload of the
+// given size
+func mdatHeaderSize(payload int64) int64 {
+	if payload+8 > math.MaxUint32 {
+		return 16
+	}
+	return 8
+}
+
+// mdatHeader returns an mdat header, using a 64-bit size when needed
+func mdatHeader(payload int64) []byte {
+	if mdatHeaderSize(payload) == 16 {
+		return append(append(be32(1), "mdat"...), be64(uint64(payload+16))...)
+	}
+	return append(be32(uint32(payload+8)), "mdat"...)
+}
+
+// box returns an MP4 box of the given type around the payload
+func box(boxType string, payload ...[]byte) []byte {
+	size := 8
+	for _, part := range payload {
+		size += len(part)
+	}
+	out := make([]byte, 0, size)
+	out = append(out, be32(uint32(size))...)
+	out = append(out, boxType...)
+	for _, part := range payload {
+		out = append(out, part...)
+	}
+	return out
+}
+
+// fullBox returns an MP4 box with a version and flags before the payload
+func fullBox(boxType string, version byte, flags uint32, payload ...[]byte) []byte {
+	versionFlags := be32(flags)
+	versionFlags[0] = version
+	return box(boxType, append([][]byte{versionFlags}, payload...)...)
+}
+
+// unityMatrix returns the identity transformation matrix of movie and track headers
+func unityMatrix() []byte {
+	var out bytes.Buffer
+	for _, value := range []uint32{0x00010000, 0, 0, 0, 0x00010000, 0, 0, 0, 0x40000000} {
+		out.Write(be32(value))
+	}
+	return out.Bytes()
+}
+
+// framesDuration converts a number of sample frames to playing time
+func framesDuration(frames int64, sampleRate uint32) time.Duration {
+	return time.Duration(frames) * time.Second / time.Duration(sampleRate)
+}
+
+func be16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func be32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+func be64(v uint64) []byte { return binary.BigEndian.AppendUint64(nil, v) }
@@ -0,0 +1,145 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+)
+
+// findBox returns the payload of the first box at the given path, such as
+// "moov/udta/chpl", within data
+func findBox(data []byte, path string) []byte {
+	name, rest, _ := strings.Cut(path, "/")
+	for len(data) >= 8 {
+		size := int(binary.BigEndian.Uint32(data[0:4]))
+		if size < 8 || size > len(data) {
+			return nil
+		}
+		if string(data[4:8]) == name {
+			payload := data[8:size]
+			if rest == "" {
+				return payload
+			}
+			// Full boxes holding other boxes start with a version and flags
+			if name == "meta" || name == "stsd" || name == "dref" {
+				skip := 4
+				if name != "meta" {
+					skip = 8
+				}
+				payload = payload[skip:]
+			}
+			return findBox(payload, rest)
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+func TestMuxM4B(t *testing.T) {
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:2], 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:4], 1)
+	binary.LittleEndian.PutUint32(fmtChunk[4:8], 1000)
+	binary.LittleEndian.PutUint32(fmtChunk[8:12], 2000)
+	binary.LittleEndian.PutUint16(fmtChunk[12:14], 2)
+	binary.LittleEndian.PutUint16(fmtChunk[14:16], 16)
+
+	first := bytes.Repeat([]byte{1}, 1000)  // 500ms
+	second := bytes.Repeat([]byte{2}, 3000) // 1.5s
+	layout, err := MuxM4B(fmtChunk, []M4BChapter{
+		{Title: "Opening", DataSize: int64(len(first))},
+		{Title: "Ünïcode", DataSize: int64(len(second))},
+	}, M4BMetadata{Title: "My Book", Author: "Jane Doe", Cover: []byte("JPEGDATA"), CoverFormat: "jpg"})
+	if err != nil {
+		t.Fatalf("MuxM4B failed: %v", err)
+	}
+	file := bytes.Join([][]byte{layout.Header, first, second, layout.Trailer}, nil)
+
+	if layout.Duration != 2*time.Second || layout.Starts[1] != 500*time.Millisecond {
+		t.Errorf("Expected 2s with second chapter at 500ms, got %v and %v", layout.Duration, layout.Starts)
+	}
+	if layout.Size(int64(len(first)+len(second))) != int64(len(file)) {
+		t.Error("Expected Size to match the file size")
+	}
+	if ftyp := findBox(file, "ftyp"); ftyp == nil || string(ftyp[0:4]) != "M4B " {
+		t.Fatalf("Expected M4B file type")
+	}
+
+	t.Run("Audio chunks point at PCM data", func(t *testing.T) {
+		stbl := findBox(file, "moov/trak/mdia/minf/stbl")
+		if entry := findBox(stbl, "stsd/sowt"); entry == nil || binary.BigEndian.Uint16(entry[16:18]) != 1 || binary.BigEndian.Uint32(entry[24:28]) != 1000<<16 {
+			t.Fatalf("Expected mono 1000 Hz sowt sample entry")
+		}
+		stco := findBox(stbl, "stco")
+		if binary.BigEndian.Uint32(stco[4:8]) != 2 {
+			t.Fatalf("Expected one chunk per chapter")
+		}
+		firstOffset := binary.BigEndian.Uint32(stco[8:12])
+		secondOffset := binary.BigEndian.Uint32(stco[12:16])
+		if file[firstOffset] != 1 || file[secondOffset] != 2 || file[secondOffset-1] != 1 {
+			t.Errorf("Chunk offsets %d and %d do not point at chapter audio", firstOffset, secondOffset)
+		}
+	})
+
+	t.Run("Chapter track", func(t *testing.T) {
+		tracks := findBox(file, "moov")
+		var chapterStbl []byte
+		for rest := tracks; len(rest) >= 8; rest = rest[binary.BigEndian.Uint32(rest[0:4]):] {
+			trak := rest[8:binary.BigEndian.Uint32(rest[0:4])]
+			if hdlr := findBox(trak, "mdia/hdlr"); string(rest[4:8]) == "trak" && hdlr != nil && string(hdlr[8:12]) == "text" {
+				chapterStbl = findBox(trak, "mdia/minf/stbl")
+			}
+		}
+		if chapterStbl == nil {
+			t.Fatal("Expected a text chapter track")
+		}
+		stco := findBox(chapterStbl, "stco")
+		for i, title := range []string{"Opening", "Ünïcode"} {
+			offset := binary.BigEndian.Uint32(stco[8+4*i:])
+			length := binary.BigEndian.Uint16(file[offset:])
+			if got := string(file[offset+2 : offset+2+uint32(length)]); got != title {
+				t.Errorf("Expected chapter %d titled %q, got %q", i+1, title, got)
+			}
+		}
+		if chap := findBox(file, "moov/trak/tref/chap"); chap == nil || binary.BigEndian.Uint32(chap) != 2 {
+			t.Error("Expected the audio track to reference the chapter track")
+		}
+	})
+
+	t.Run("Nero chapters", func(t *testing.T) {
+		chpl := findBox(file, "moov/udta/chpl")
+		if chpl == nil || chpl[8] != 2 {
+			t.Fatalf("Expected 2 Nero chapters")
+		}
+		second := chpl[9+8+1+len("Opening"):]
+		if start := binary.BigEndian.Uint64(second[0:8]); start != 5000000 {
+			t.Errorf("Expected second chapter at 5000000 (100ns units), got %d", start)
+		}
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		ilst := findBox(file, "moov/udta/meta/ilst")
+		for name, value := range map[string]string{"\xa9nam": "My Book", "\xa9ART": "Jane Doe", "covr": "JPEGDATA"} {
+			data := findBox(ilst, name+"/data")
+			if data == nil || string(data[8:]) != value {
+				t.Errorf("Expected %q to be %q", name, value)
+			}
+		}
+		if covr := findBox(ilst, "covr/data"); covr != nil && binary.BigEndian.Uint32(covr[0:4]) != 13 {
+			t.Error("Expected JPEG cover data type")
+		}
+	})
+
+	t.Run("Rejects unsupported audio", func(t *testing.T) {
+		float := append([]byte(nil), fmtChunk...)
+		binary.LittleEndian.PutUint16(float[0:2], 3)
+		if _, err := MuxM4B(float, []M4BChapter{{DataSize: 2}}, M4BMetadata{}); err == nil {
+			t.Error("Expected float audio to be rejected")
+		}
+		if _, err := MuxM4B(fmtChunk, []M4BChapter{{DataSize: 3}}, M4BMetadata{}); err == nil {
+			t.Error("Expected partial sample frames to be rejected")
+		}
+	})
+}
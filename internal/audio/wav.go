@@ -0,0 +1,160 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// WAV is a PCM WAV file with a fmt chunk followed by a data chunk
+type WAV struct {
+	FmtChunk []byte
+	Data     []byte
+}
+
+// WAVHeader locates the fmt chunk and PCM data of a WAV file
+type WAVHeader struct {
+	FmtChunk   []byte
+	DataOffset int64 // Byte offset of the PCM data in the file
+	DataSize   int64
+}
+
+// ParseWAVHeader parses the start of a RIFF/WAVE file laid out as a fmt chunk
+// followed directly by a data chunk. It needs only the bytes up to the start
+// of the PCM data.
+func ParseWAVHeader(header []byte) (*WAVHeader, error) {
+	if len(header) < 44 || string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a RIFF/WAVE file")
+	}
+	if string(header[12:16]) != "fmt " {
+		return nil, fmt.Errorf("unsupported WAV layout: missing fmt chunk")
+	}
+	fmtLen := int(binary.LittleEndian.Uint32(header[16:20]))
+	if fmtLen < 16 || len(header) < 20+fmtLen+8 {
+		return nil, fmt.Errorf("invalid WAV fmt chunk")
+	}
+	dataHeader := 20 + fmtLen
+	if string(header[dataHeader:dataHeader+4]) != "data" {
+		return nil, fmt.Errorf("unsupported WAV layout: missing data chunk")
+	}
+	return &WAVHeader{
+		FmtChunk:   append([]byte(nil), header[20:20+fmtLen]...),
+		DataOffset: int64(dataHeader + 8),
+		DataSize:   int64(binary.LittleEndian.Uint32(header[dataHeader+4 : dataHeader+8])),
+	}, nil
+}
+
+// Duration returns the length of the audio
+func (h *WAVHeader) Duration() time.Duration {
+	return bytesDuration(int(h.DataSize), (&WAV{FmtChunk: h.FmtChunk}).ByteRate())
+}
+
+// ParseWAV parses a RIFF/WAVE file laid out as a fmt chunk followed directly
+// by a data chunk
+func ParseWAV(body []byte) (*WAV, error) {
+	header, err := ParseWAVHeader(body)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) < header.DataOffset+header.DataSize {
+		return nil, fmt.Errorf("truncated WAV data")
+	}
+	return &WAV{
+		FmtChunk: header.FmtChunk,
+		Data:     append([]byte(nil), body[header.DataOffset:header.DataOffset+header.DataSize]...),
+	}, nil
+}
+
+// ByteRate returns the number of data bytes per second of audio
+func (w *WAV) ByteRate() int {
+	return int(binary.LittleEndian.Uint32(w.FmtChunk[8:12]))
+}
+
+// blockAlign returns the size of one sample frame across all channels
+func (w *WAV) blockAlign() int {
+	return int(binary.LittleEndian.Uint16(w.FmtChunk[12:14]))
+}
+
+// Duration returns the length of the audio
+func (w *WAV) Duration() time.Duration {
+	return bytesDuration(len(w.Data), w.ByteRate())
+}
+
+// silence returns whole sample frames of silence lasting about d
+func (w *WAV) silence(d time.Duration) []byte {
+	frame := max(w.blockAlign(), 1)
+	frames := int(d.Seconds() * float64(w.ByteRate()) / float64(frame))
+	// 8-bit PCM is unsigned, so its midpoint is 0x80 rather than zero
+	fill := byte(0)
+	if binary.LittleEndian.Uint16(w.FmtChunk[14:16]) == 8 {
+		fill = 0x80
+	}
+	return bytes.Repeat([]byte{fill}, frames*frame)
+}
+
+// Span is where one input file plays within joined audio
+type Span struct {
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// ConcatWAV joins WAV files of the same format into one, with silence
+// between consecutive files. It also returns where each file plays in the
+// joined audio.
+func ConcatWAV(chunks [][]byte, silence time.Duration) ([]byte, []Span, error) {
+	if len(chunks) == 0 {
+		return nil, nil, fmt.Errorf("no wav chunks")
+	}
+
+	first, err := ParseWAV(chunks[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	spans := []Span{{Duration: first.Duration()}}
+	if len(chunks) == 1 {
+		return chunks[0], spans, nil
+	}
+
+	gap := first.silence(silence)
+	var data bytes.Buffer
+	data.Write(first.Data)
+	for i, chunk := range chunks[1:] {
+		parsed, err := ParseWAV(chunk)
+		if err != nil {
+			return nil, nil, fmt.Errorf("chunk %d: %w", i+2, err)
+		}
+		if !bytes.Equal(parsed.FmtChunk, first.FmtChunk) {
+			return nil, nil, fmt.Errorf("chunk %d has different WAV format", i+2)
+		}
+		data.Write(gap)
+		spans = append(spans, Span{Start: bytesDuration(data.Len(), first.ByteRate()), Duration: parsed.Duration()})
+		data.Write(parsed.Data)
+	}
+
+	return EncodeWAV(first.FmtChunk, data.Bytes()), spans, nil
+}
+
+// EncodeWAV writes a WAV file with the given fmt chunk around PCM data
+func EncodeWAV(fmtChunk, data []byte) []byte {
+	out := make([]byte, 20+len(fmtChunk)+8+len(data))
+	copy(out[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	copy(out[8:12], "WAVE")
+	copy(out[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(out[16:20], uint32(len(fmtChunk)))
+	copy(out[20:], fmtChunk)
+	dataHeader := 20 + len(fmtChunk)
+	copy(out[dataHeader:dataHeader+4], "data")
+	binary.LittleEndian.PutUint32(out[dataHeader+4:dataHeader+8], uint32(len(data)))
+	copy(out[dataHeader+8:], data)
+	return out
+}
+
+// bytesDuration converts a length of PCM data to its playing time
+func bytesDuration(n, byteRate int) time.Duration {
+	if byteRate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(n) / float64(byteRate) * float64(time.Second))
+}
@@ -0,0 +1,82 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// testFmtChunk describes mono PCM audio
+func testFmtChunk(sampleRate, bitsPerSample int) []byte {
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:2], 1) // PCM
+	binary.LittleEndian.PutUint16(fmtChunk[2:4], 1) // mono
+	binary.LittleEndian.PutUint32(fmtChunk[4:8], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(fmtChunk[8:12], uint32(sampleRate*bitsPerSample/8))
+	binary.LittleEndian.PutUint16(fmtChunk[12:14], uint16(bitsPerSample/8))
+	binary.LittleEndian.PutUint16(fmtChunk[14:16], uint16(bitsPerSample))
+	return fmtChunk
+}
+
+func TestConcatWAV(t *testing.T) {
+	fmtChunk := testFmtChunk(1000, 16) // 2000 bytes per second
+	first := EncodeWAV(fmtChunk, bytes.Repeat([]byte{1}, 1000))
+	second := EncodeWAV(fmtChunk, bytes.Repeat([]byte{2}, 500))
+
+	t.Run("With silence", func(t *testing.T) {
+		joined, spans, err := ConcatWAV([][]byte{first, second}, 250*time.Millisecond)
+		if err != nil {
+			t.Fatalf("ConcatWAV failed: %v", err)
+		}
+		parsed, err := ParseWAV(joined)
+		if err != nil {
+			t.Fatalf("Joined audio is not a WAV file: %v", err)
+		}
+		if len(parsed.Data) != 2000 || parsed.Duration() != time.Second {
+			t.Fatalf("Expected 2000 bytes lasting 1s, got %d bytes lasting %v", len(parsed.Data), parsed.Duration())
+		}
+		if !bytes.Equal(parsed.Data[1000:1500], make([]byte, 500)) {
+			t.Error("Expected silence between the files")
+		}
+		expected := []Span{{0, 500 * time.Millisecond}, {750 * time.Millisecond, 250 * time.Millisecond}}
+		if len(spans) != 2 || spans[0] != expected[0] || spans[1] != expected[1] {
+			t.Errorf("Expected spans %v, got %v", expected, spans)
+		}
+	})
+
+	t.Run("Single file", func(t *testing.T) {
+		joined, spans, err := ConcatWAV([][]byte{first}, time.Second)
+		if err != nil {
+			t.Fatalf("ConcatWAV failed: %v", err)
+		}
+		if !bytes.Equal(joined, first) || len(spans) != 1 || spans[0].Duration != 500*time.Millisecond {
+			t.Errorf("Expected the file unchanged, got spans %v", spans)
+		}
+	})
+
+	t.Run("Unsigned 8-bit silence", func(t *testing.T) {
+		eightBit := EncodeWAV(testFmtChunk(1000, 8), []byte{0x90})
+		joined, _, err := ConcatWAV([][]byte{eightBit, eightBit}, 3*time.Millisecond)
+		if err != nil {
+			t.Fatalf("ConcatWAV failed: %v", err)
+		}
+		parsed, _ := ParseWAV(joined)
+		if !bytes.Equal(parsed.Data, []byte{0x90, 0x80, 0x80, 0x80, 0x90}) {
+			t.Errorf("Expected midpoint silence, got %v", parsed.Data)
+		}
+	})
+
+	t.Run("Rejects mixed formats", func(t *testing.T) {
+		other := EncodeWAV(testFmtChunk(2000, 16), []byte{0, 0})
+		if _, _, err := ConcatWAV([][]byte{first, other}, 0); err == nil {
+			t.Error("Expected error for mixed formats")
+		}
+		if _, _, err := ConcatWAV([][]byte{first, []byte("ID3")}, 0); err == nil {
+			t.Error("Expected error for non-WAV audio")
+		}
+		if _, _, err := ConcatWAV(nil, 0); err == nil {
+			t.Error("Expected error without files")
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package book
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// SaveListeningProgress stores where the listener is in a book
+func (r *StorageRepository) SaveListeningProgress(ctx context.Context, progress *types.ListeningProgress) error {
+	if progress == nil {
+		return fmt.Errorf("listening progress is nil")
+	}
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal listening progress: %w", err)
+	}
+
+	path := filepath.Join("books", progress.BookID, "progress.json")
+	return r.storage.Put(ctx, path, bytesReader(data))
+}
+
+// GetListeningProgress retrieves where the listener is in a book. Missing
+// progress returns (nil, nil).
+func (r *StorageRepository) GetListeningProgress(ctx context.Context, bookID string) (*types.ListeningProgress, error) {
+	path := filepath.Join("books", bookID, "progress.json")
+	exists, err := r.storage.Exists(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check listening progress existence: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var progress types.ListeningProgress
+	if err := r.getJSON(ctx, path, &progress); err != nil {
+		return nil, fmt.Errorf("failed to get listening progress: %w", err)
+	}
+	return &progress, nil
+}
+
+// SaveBookmark stores a bookmark
+func (r *StorageRepository) SaveBookmark(ctx context.Context, bookmark *types.Bookmark) error {
+	data, err := json.Marshal(bookmark)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bookmark: %w", err)
+	}
+
+	path := filepath.Join("books", bookmark.BookID, "bookmarks", fmt.Sprintf("%s.json", bookmark.ID))
+	return r.storage.Put(ctx, path, bytesReader(data))
+}
+
+// ListBookmarks returns all bookmarks of a book, oldest first
+func (r *StorageRepository) ListBookmarks(ctx context.Context, bookID string) ([]*types.Bookmark, error) {
+	paths, err := r.storage.List(ctx, filepath.Join("books", bookID, "bookmarks")+string(filepath.Separator))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bookmarks: %w", err)
+	}
+
+	bookmarks := make([]*types.Bookmark, 0, len(paths))
+	for _, path := range paths {
+		var bookmark types.Bookmark
+		if err := r.getJSON(ctx, path, &bookmark); err != nil {
+			continue
+		}
+		bookmarks = append(bookmarks, &bookmark)
+	}
+	sort.Slice(bookmarks, func(i, j int) bool { return bookmarks[i].CreatedAt.Before(bookmarks[j].CreatedAt) })
+	return bookmarks, nil
+}
+
+// DeleteBookmark removes a bookmark
+func (r *StorageRepository) DeleteBookmark(ctx context.Context, bookID, bookmarkID string) error {
+	path := filepath.Join("books", bookID, "bookmarks", fmt.Sprintf("%s.json", bookmarkID))
+	return r.storage.Delete(ctx, path)
+}
+
+// SaveHighlight stores a highlight
+func (r *StorageRepository) SaveHighlight(ctx context.Context, highlight *types.Highlight) error {
+	data, err := json.Marshal(highlight)
+	if err != nil {
+		return fmt.Errorf("failed to marshal highlight: %w", err)
+	}
+
+	path := filepath.Join("books", highlight.BookID, "highlights", fmt.Sprintf("%s.json", highlight.ID))
+	return r.storage.Put(ctx, path, bytesReader(data))
+}
+
+// ListHighlights returns all highlights of a book, oldest first
+func (r *StorageRepository) ListHighlights(ctx context.Context, bookID string) ([]*types.Highlight, error) {
+	paths, err := r.storage.List(ctx, filepath.Join("books", bookID, "highlights")+string(filepath.Separator))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list highlights: %w", err)
+	}
+
+	highlights := make([]*types.Highlight, 0, len(paths))
+	for _, path := range paths {
+		var highlight types.Highlight
+		if err := r.getJSON(ctx, path, &highlight); err != nil {
+			continue
+		}
+		highlights = append(highlights, &highlight)
+	}
+	sort.Slice(highlights, func(i, j int) bool { return highlights[i].CreatedAt.Before(highlights[j].CreatedAt) })
+	return highlights, nil
+}
+
+// DeleteHighlight removes a highlight
+func (r *StorageRepository) DeleteHighlight(ctx context.Context, bookID, highlightID string) error {
+	path := filepath.Join("books", bookID, "highlights", fmt.Sprintf("%s.json", highlightID))
+	return r.storage.Delete(ctx, path)
+}
+
+// getJSON decodes the JSON stored at path into value
+func (r *StorageRepository) getJSON(ctx context.Context, path string, value any) error {
+	reader, err := r.storage.Get(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return json.NewDecoder(reader).Decode(value)
+}
@@ -0,0 +1,88 @@
+package book
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestListeningRepository(t *testing.T) {
+	storageAdapter, err := storage.NewLocalAdapter(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage adapter: %v", err)
+	}
+	defer storageAdapter.Close()
+
+	repo := NewRepository(storageAdapter)
+	ctx := context.Background()
+
+	t.Run("GetMissingProgressReturnsNil", func(t *testing.T) {
+		progress, err := repo.GetListeningProgress(ctx, "book_new")
+		if err != nil || progress != nil {
+			t.Errorf("Expected (nil, nil), got (%+v, %v)", progress, err)
+		}
+	})
+
+	t.Run("SaveAndGetProgress", func(t *testing.T) {
+		saved := &types.ListeningProgress{BookID: "book_1", SegmentID: "seg_00042", OffsetSeconds: 12.5, Device: "phone", UpdatedAt: time.Now().UTC()}
+		if err := repo.SaveListeningProgress(ctx, saved); err != nil {
+			t.Fatalf("Failed to save progress: %v", err)
+		}
+		progress, err := repo.GetListeningProgress(ctx, "book_1")
+		if err != nil || progress == nil {
+			t.Fatalf("Failed to get progress: %v", err)
+		}
+		if progress.SegmentID != "seg_00042" || progress.OffsetSeconds != 12.5 || progress.Device != "phone" {
+			t.Errorf("Unexpected progress %+v", progress)
+		}
+	})
+
+	t.Run("Bookmarks", func(t *testing.T) {
+		now := time.Now().UTC()
+		repo.SaveBookmark(ctx, &types.Bookmark{ID: "bookmark_2", BookID: "book_1", SegmentID: "seg_00002", Name: "Later", CreatedAt: now.Add(time.Minute)})
+		repo.SaveBookmark(ctx, &types.Bookmark{ID: "bookmark_1", BookID: "book_1", SegmentID: "seg_00001", Name: "Earlier", CreatedAt: now})
+
+		bookmarks, err := repo.ListBookmarks(ctx, "book_1")
+		if err != nil || len(bookmarks) != 2 || bookmarks[0].Name != "Earlier" {
+			t.Fatalf("Expected 2 bookmarks oldest first, got %+v (%v)", bookmarks, err)
+		}
+		if err := repo.DeleteBookmark(ctx, "book_1", "bookmark_1"); err != nil {
+			t.Fatalf("Failed to delete bookmark: %v", err)
+		}
+		if bookmarks, _ := repo.ListBookmarks(ctx, "book_1"); len(bookmarks) != 1 || bookmarks[0].ID != "bookmark_2" {
+			t.Errorf("Expected only bookmark_2 left, got %+v", bookmarks)
+		}
+	})
+
+	t.Run("ListsOnlyTheirDirectory", func(t *testing.T) {
+		// A sibling whose name starts like the directory must not be listed
+		storageAdapter.Put(ctx, "books/book_2/bookmarks_export.json", strings.NewReader(`{"id":"stray","name":"Stray"}`))
+		storageAdapter.Put(ctx, "books/book_2/highlights.json", strings.NewReader(`{"id":"stray"}`))
+
+		if bookmarks, err := repo.ListBookmarks(ctx, "book_2"); err != nil || len(bookmarks) != 0 {
+			t.Errorf("Expected no bookmarks, got %+v (%v)", bookmarks, err)
+		}
+		if highlights, err := repo.ListHighlights(ctx, "book_2"); err != nil || len(highlights) != 0 {
+			t.Errorf("Expected no highlights, got %+v (%v)", highlights, err)
+		}
+	})
+
+	t.Run("Highlights", func(t *testing.T) {
+		repo.SaveHighlight(ctx, &types.Highlight{ID: "highlight_1", BookID: "book_1", SegmentID: "seg_00003", Text: "Call me Ishmael.", Note: "Famous opening"})
+
+		highlights, err := repo.ListHighlights(ctx, "book_1")
+		if err != nil || len(highlights) != 1 || highlights[0].Note != "Famous opening" {
+			t.Fatalf("Unexpected highlights %+v (%v)", highlights, err)
+		}
+		if err := repo.DeleteHighlight(ctx, "book_1", "highlight_1"); err != nil {
+			t.Fatalf("Failed to delete highlight: %v", err)
+		}
+		if highlights, _ := repo.ListHighlights(ctx, "book_1"); len(highlights) != 0 {
+			t.Errorf("Expected no highlights, got %+v", highlights)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package book
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// RawFileInfo describes a stored raw file
+type RawFileInfo struct {
+	Size        int64  // bytes
+	ContentHash string // SHA-256, hex encoded
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// RawFile is an uploaded raw file opened for random access
+type RawFile struct {
+	Format string // File format the book was stored as, e.g. "epub"
+
+	reader io.ReaderAt
+	size   int64
+	close  func() error
+}
+
+// newRawFile wraps a storage reader for random access. Readers that support
+// it, such as local files, are used in place; other storage backends are
+// spooled to a temporary file first.
+func newRawFile(reader io.ReadCloser, format string) (*RawFile, error) {
+	if file, ok := reader.(*os.File); ok {
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to stat raw file: %w", err)
+		}
+		return &RawFile{Format: format, reader: file, size: info.Size(), close: file.Close}, nil
+	}
+	defer reader.Close()
+
+	spool, err := os.CreateTemp("", "twelvereader-raw-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	cleanup := func() error {
+		err := spool.Close()
+		os.Remove(spool.Name())
+		return err
+	}
+
+	size, err := io.Copy(spool, reader)
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to read raw file: %w", err)
+	}
+	return &RawFile{Format: format, reader: spool, size: size, close: cleanup}, nil
+}
+
+// ReadAt implements io.ReaderAt
+func (f *RawFile) ReadAt(p []byte, off int64) (int, error) {
+	return f.reader.ReadAt(p, off)
+}
+
+// Size returns the file size in bytes
+func (f *RawFile) Size() int64 {
+	return f.size
+}
+
+// Close releases the file
+func (f *RawFile) Close() error {
+	return f.close()
+}
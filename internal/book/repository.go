@@ -3,11 +3,14 @@ package book
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -47,6 +50,18 @@ type Repository interface {
 	// ListSegments returns all segments for a book
 	ListSegments(ctx context.Context, bookID string) ([]*types.Segment, error)
 
+	// DeleteSegment removes segment metadata
+	DeleteSegment(ctx context.Context, bookID, segmentID string) error
+
+	// DeleteChapters removes all chapters of a book
+	DeleteChapters(ctx context.Context, bookID string) error
+
+	// DeleteSegments removes all segments of a book
+	DeleteSegments(ctx context.Context, bookID string) error
+
+	// DeleteAudio removes all synthesized audio of a book
+	DeleteAudio(ctx context.Context, bookID string) error
+
 	// SaveVoiceMap stores voice mapping
 	SaveVoiceMap(ctx context.Context, voiceMap *types.VoiceMap) error
 
@@ -70,11 +85,52 @@ type Repository interface {
 	UpdatePersonaProfilesFromSegments(ctx context.Context, bookID string, segments []*types.Segment) error
 
 
-	// SaveRawFile stores the uploaded raw file
-	SaveRawFile(ctx context.Context, bookID string, data []byte, format string) error
+	// SaveRawFile streams the uploaded raw file into storage, hashing it on
+	// the way, and records the book under its content hash
+	SaveRawFile(ctx context.Context, bookID string, data io.Reader, format string) (*RawFileInfo, error)
+
+	// FindBooksByContentHash returns the books whose raw file has the given
+	// SHA-256 hash, in the order they were stored
+	FindBooksByContentHash(ctx context.Context, hash string) ([]*types.Book, error)
+
+	// CloneBook copies the chapters, segments, voice map, persona profiles,
+	// cover and audio of a book to another book ID
+	CloneBook(ctx context.Context, srcBookID, dstBookID string) error
+
+	// OpenRawFile opens the uploaded raw file for random access. The caller
+	// must close it.
+	OpenRawFile(ctx context.Context, bookID string) (*RawFile, error)
+
+	// SaveCover stores the book's cover image with the given file extension
+	SaveCover(ctx context.Context, bookID string, data []byte, ext string) error
+
+	// GetCover retrieves the book's cover image and its file extension
+	GetCover(ctx context.Context, bookID string) ([]byte, string, error)
 
-	// GetRawFile retrieves the uploaded raw file
-	GetRawFile(ctx context.Context, bookID string) ([]byte, string, error)
+	// SaveListeningProgress stores where the listener is in a book
+	SaveListeningProgress(ctx context.Context, progress *types.ListeningProgress) error
+
+	// GetListeningProgress retrieves where the listener is in a book.
+	// Missing progress returns (nil, nil).
+	GetListeningProgress(ctx context.Context, bookID string) (*types.ListeningProgress, error)
+
+	// SaveBookmark stores a bookmark
+	SaveBookmark(ctx context.Context, bookmark *types.Bookmark) error
+
+	// ListBookmarks returns all bookmarks of a book, oldest first
+	ListBookmarks(ctx context.Context, bookID string) ([]*types.Bookmark, error)
+
+	// DeleteBookmark removes a bookmark
+	DeleteBookmark(ctx context.Context, bookID, bookmarkID string) error
+
+	// SaveHighlight stores a highlight
+	SaveHighlight(ctx context.Context, highlight *types.Highlight) error
+
+	// ListHighlights returns all highlights of a book, oldest first
+	ListHighlights(ctx context.Context, bookID string) ([]*types.Highlight, error)
+
+	// DeleteHighlight removes a highlight
+	DeleteHighlight(ctx context.Context, bookID, highlightID string) error
 
 	// DeleteBook removes a book and all associated data
 	DeleteBook(ctx context.Context, bookID string) error
@@ -82,8 +138,9 @@ type Repository interface {
 
 // StorageRepository implements Repository using a storage adapter
 type StorageRepository struct {
-	storage  storage.Adapter
-	bookLock sync.Map // Per-book mutex for book metadata operations
+	storage   storage.Adapter
+	bookLock  sync.Map   // Per-book mutex for book metadata operations
+	indexLock sync.Mutex // Serializes content index updates
 }
 
 // NewRepository creates a new book repository
@@ -395,6 +452,27 @@ func (r *StorageRepository) ListSegments(ctx context.Context, bookID string) ([]
 	return segments, nil
 }
 
+// DeleteSegment removes segment metadata
+func (r *StorageRepository) DeleteSegment(ctx context.Context, bookID, segmentID string) error {
+	path := filepath.Join("books", bookID, "segments", fmt.Sprintf("%s.json", segmentID))
+	return r.storage.Delete(ctx, path)
+}
+
+// DeleteChapters removes all chapters of a book
+func (r *StorageRepository) DeleteChapters(ctx context.Context, bookID string) error {
+	return r.storage.DeleteAll(ctx, filepath.Join("books", bookID, "chapters")+string(filepath.Separator))
+}
+
+// DeleteSegments removes all segments of a book
+func (r *StorageRepository) DeleteSegments(ctx context.Context, bookID string) error {
+	return r.storage.DeleteAll(ctx, filepath.Join("books", bookID, "segments")+string(filepath.Separator))
+}
+
+// DeleteAudio removes all synthesized audio of a book
+func (r *StorageRepository) DeleteAudio(ctx context.Context, bookID string) error {
+	return r.storage.DeleteAll(ctx, filepath.Join("books", bookID, "audio")+string(filepath.Separator))
+}
+
 // SaveVoiceMap stores voice mapping
 func (r *StorageRepository) SaveVoiceMap(ctx context.Context, voiceMap *types.VoiceMap) error {
 	data, err := json.Marshal(voiceMap)
@@ -475,17 +553,212 @@ func (r *StorageRepository) GetDefaultVoice(ctx context.Context) (*types.Default
 	return &setting, nil
 }
 
-// SaveRawFile stores the uploaded raw file
-func (r *StorageRepository) SaveRawFile(ctx context.Context, bookID string, data []byte, format string) error {
+// SaveRawFile streams the uploaded raw file into storage, hashing it on the
+// way, and records the book under its content hash
+func (r *StorageRepository) SaveRawFile(ctx context.Context, bookID string, data io.Reader, format string) (*RawFileInfo, error) {
 	path := filepath.Join("books", bookID, fmt.Sprintf("raw.%s", format))
-	return r.storage.Put(ctx, path, bytesReader(data))
+	hasher := sha256.New()
+	counter := &countingReader{r: io.TeeReader(data, hasher)}
+	if err := r.storage.Put(ctx, path, counter); err != nil {
+		return nil, err
+	}
+
+	info := &RawFileInfo{Size: counter.n, ContentHash: hex.EncodeToString(hasher.Sum(nil))}
+	if err := r.addToContentIndex(ctx, info.ContentHash, bookID); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// contentIndexPath returns the storage path listing the books with a content hash
+func contentIndexPath(hash string) string {
+	return filepath.Join("content-index", fmt.Sprintf("%s.json", hash))
+}
+
+// addToContentIndex records a book ID under a content hash
+func (r *StorageRepository) addToContentIndex(ctx context.Context, hash, bookID string) error {
+	r.indexLock.Lock()
+	defer r.indexLock.Unlock()
+
+	bookIDs, err := r.readContentIndex(ctx, hash)
+	if err != nil {
+		return err
+	}
+	for _, id := range bookIDs {
+		if id == bookID {
+			return nil
+		}
+	}
+	return r.writeContentIndex(ctx, hash, append(bookIDs, bookID))
+}
+
+func (r *StorageRepository) readContentIndex(ctx context.Context, hash string) ([]string, error) {
+	path := contentIndexPath(hash)
+	exists, err := r.storage.Exists(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check content index existence: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	reader, err := r.storage.Get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get content index: %w", err)
+	}
+	defer reader.Close()
+
+	var bookIDs []string
+	if err := json.NewDecoder(reader).Decode(&bookIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode content index: %w", err)
+	}
+	return bookIDs, nil
+}
+
+func (r *StorageRepository) writeContentIndex(ctx context.Context, hash string, bookIDs []string) error {
+	if len(bookIDs) == 0 {
+		return r.storage.Delete(ctx, contentIndexPath(hash))
+	}
+	data, err := json.Marshal(bookIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal content index: %w", err)
+	}
+	return r.storage.Put(ctx, contentIndexPath(hash), bytesReader(data))
+}
+
+// FindBooksByContentHash returns the books whose raw file has the given hash.
+// Index entries of books that no longer have any data are pruned; entries
+// whose metadata is not saved yet are kept but not returned.
+func (r *StorageRepository) FindBooksByContentHash(ctx context.Context, hash string) ([]*types.Book, error) {
+	r.indexLock.Lock()
+	defer r.indexLock.Unlock()
+
+	bookIDs, err := r.readContentIndex(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	books := make([]*types.Book, 0, len(bookIDs))
+	kept := make([]string, 0, len(bookIDs))
+	for _, id := range bookIDs {
+		if book, err := r.GetBook(ctx, id); err == nil {
+			books = append(books, book)
+			kept = append(kept, id)
+			continue
+		}
+		if r.hasBookData(ctx, id) {
+			kept = append(kept, id)
+		}
+	}
+
+	if len(kept) != len(bookIDs) {
+		if err := r.writeContentIndex(ctx, hash, kept); err != nil {
+			return nil, err
+		}
+	}
+	return books, nil
 }
 
-// GetRawFile retrieves the uploaded raw file
-func (r *StorageRepository) GetRawFile(ctx context.Context, bookID string) ([]byte, string, error) {
-	// Try different formats
-	formats := []string{"pdf", "epub", "txt"}
-	for _, format := range formats {
+// hasBookData reports whether anything is stored for a book ID
+func (r *StorageRepository) hasBookData(ctx context.Context, bookID string) bool {
+	prefix := filepath.Join("books", bookID) + string(filepath.Separator)
+	paths, err := r.storage.List(ctx, prefix)
+	if err != nil {
+		return true // Keep entries that cannot be checked
+	}
+	for _, path := range paths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CloneBook copies the processed data of a book to another book ID so the
+// copy needs no further provider calls. Book metadata and the raw file are
+// not copied.
+func (r *StorageRepository) CloneBook(ctx context.Context, srcBookID, dstBookID string) error {
+	chapters, err := r.ListChapters(ctx, srcBookID)
+	if err != nil {
+		return err
+	}
+	for _, chapter := range chapters {
+		chapter.BookID = dstBookID
+		if err := r.SaveChapter(ctx, chapter); err != nil {
+			return fmt.Errorf("failed to clone chapter %s: %w", chapter.ID, err)
+		}
+	}
+
+	segments, err := r.ListSegments(ctx, srcBookID)
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		segment.BookID = dstBookID
+		if err := r.SaveSegment(ctx, segment); err != nil {
+			return fmt.Errorf("failed to clone segment %s: %w", segment.ID, err)
+		}
+	}
+
+	if voiceMap, err := r.GetVoiceMap(ctx, srcBookID); err == nil {
+		voiceMap.BookID = dstBookID
+		if err := r.SaveVoiceMap(ctx, voiceMap); err != nil {
+			return fmt.Errorf("failed to clone voice map: %w", err)
+		}
+	}
+
+	profiles, err := r.GetPersonaProfiles(ctx, srcBookID)
+	if err != nil {
+		return err
+	}
+	if len(profiles) > 0 {
+		if err := r.SavePersonaProfiles(ctx, dstBookID, profiles); err != nil {
+			return fmt.Errorf("failed to clone persona profiles: %w", err)
+		}
+	}
+
+	if cover, ext, err := r.GetCover(ctx, srcBookID); err == nil {
+		if err := r.SaveCover(ctx, dstBookID, cover, ext); err != nil {
+			return fmt.Errorf("failed to clone cover: %w", err)
+		}
+	}
+
+	return r.copyAudio(ctx, srcBookID, dstBookID)
+}
+
+// copyAudio copies every synthesized audio file of a book
+func (r *StorageRepository) copyAudio(ctx context.Context, srcBookID, dstBookID string) error {
+	srcPrefix := filepath.Join("books", srcBookID, "audio") + string(filepath.Separator)
+	paths, err := r.storage.List(ctx, srcPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list audio: %w", err)
+	}
+	for _, path := range paths {
+		if !strings.HasPrefix(path, srcPrefix) {
+			continue
+		}
+		if err := r.copyFile(ctx, path, filepath.Join("books", dstBookID, "audio", strings.TrimPrefix(path, srcPrefix))); err != nil {
+			return fmt.Errorf("failed to clone audio %s: %w", filepath.Base(path), err)
+		}
+	}
+	return nil
+}
+
+func (r *StorageRepository) copyFile(ctx context.Context, srcPath, dstPath string) error {
+	reader, err := r.storage.Get(ctx, srcPath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return r.storage.Put(ctx, dstPath, reader)
+}
+
+// rawFileFormats lists the formats a stored raw file may use
+var rawFileFormats = []string{"pdf", "epub", "txt", "docx", "md", "markdown", "html", "htm", "fb2"}
+
+// OpenRawFile opens the uploaded raw file for random access
+func (r *StorageRepository) OpenRawFile(ctx context.Context, bookID string) (*RawFile, error) {
+	for _, format := range rawFileFormats {
 		path := filepath.Join("books", bookID, fmt.Sprintf("raw.%s", format))
 		exists, err := r.storage.Exists(ctx, path)
 		if err != nil || !exists {
@@ -494,20 +767,46 @@ func (r *StorageRepository) GetRawFile(ctx context.Context, bookID string) ([]by
 
 		reader, err := r.storage.Get(ctx, path)
 		if err != nil {
+			return nil, fmt.Errorf("failed to open raw file: %w", err)
+		}
+		return newRawFile(reader, format)
+	}
+
+	return nil, fmt.Errorf("raw file not found")
+}
+
+// coverExtensions lists the image formats a stored cover may use
+var coverExtensions = []string{"jpg", "png", "gif", "webp"}
+
+// SaveCover stores the book's cover image
+func (r *StorageRepository) SaveCover(ctx context.Context, bookID string, data []byte, ext string) error {
+	path := filepath.Join("books", bookID, fmt.Sprintf("cover.%s", ext))
+	return r.storage.Put(ctx, path, bytesReader(data))
+}
+
+// GetCover retrieves the book's cover image
+func (r *StorageRepository) GetCover(ctx context.Context, bookID string) ([]byte, string, error) {
+	for _, ext := range coverExtensions {
+		path := filepath.Join("books", bookID, fmt.Sprintf("cover.%s", ext))
+		exists, err := r.storage.Exists(ctx, path)
+		if err != nil || !exists {
 			continue
 		}
+
+		reader, err := r.storage.Get(ctx, path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get cover: %w", err)
+		}
 		defer reader.Close()
 
-		// Use io.ReadAll for efficient reading
 		data, err := io.ReadAll(reader)
 		if err != nil {
-			continue
+			return nil, "", fmt.Errorf("failed to read cover: %w", err)
 		}
-
-		return data, format, nil
+		return data, ext, nil
 	}
 
-	return nil, "", fmt.Errorf("raw file not found")
+	return nil, "", fmt.Errorf("cover not found")
 }
 
 // bytesReader wraps a byte slice using standard library bytes.Reader
@@ -517,6 +816,30 @@ func bytesReader(data []byte) io.Reader {
 
 // DeleteBook removes a book and all associated data
 func (r *StorageRepository) DeleteBook(ctx context.Context, bookID string) error {
+	if book, err := r.GetBook(ctx, bookID); err == nil && book.ContentHash != "" {
+		r.removeFromContentIndex(ctx, book.ContentHash, bookID)
+	}
 	prefix := filepath.Join("books", bookID)
 	return r.storage.DeleteAll(ctx, prefix)
 }
+
+// removeFromContentIndex drops a book ID from a content hash entry. Failures
+// are ignored since lookups prune entries of deleted books anyway.
+func (r *StorageRepository) removeFromContentIndex(ctx context.Context, hash, bookID string) {
+	r.indexLock.Lock()
+	defer r.indexLock.Unlock()
+
+	bookIDs, err := r.readContentIndex(ctx, hash)
+	if err != nil {
+		return
+	}
+	kept := make([]string, 0, len(bookIDs))
+	for _, id := range bookIDs {
+		if id != bookID {
+			kept = append(kept, id)
+		}
+	}
+	if len(kept) != len(bookIDs) {
+		r.writeContentIndex(ctx, hash, kept)
+	}
+}
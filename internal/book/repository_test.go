@@ -3,6 +3,9 @@ package book
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"testing"
 	"time"
 
@@ -220,6 +223,130 @@ func TestBookRepository(t *testing.T) {
 			t.Error("Expected error for non-existent book")
 		}
 	})
+
+	t.Run("SaveAndGetCover", func(t *testing.T) {
+		if _, _, err := repo.GetCover(ctx, "book_cover"); err == nil {
+			t.Error("Expected error for missing cover")
+		}
+
+		cover := []byte{0x89, 'P', 'N', 'G'}
+		if err := repo.SaveCover(ctx, "book_cover", cover, "png"); err != nil {
+			t.Fatalf("Failed to save cover: %v", err)
+		}
+
+		data, ext, err := repo.GetCover(ctx, "book_cover")
+		if err != nil {
+			t.Fatalf("Failed to get cover: %v", err)
+		}
+		if ext != "png" || !bytes.Equal(data, cover) {
+			t.Errorf("Cover mismatch: got %q %v", ext, data)
+		}
+	})
+
+	t.Run("SaveAndOpenRawFile", func(t *testing.T) {
+		if _, err := repo.OpenRawFile(ctx, "book_raw"); err == nil {
+			t.Error("Expected error for missing raw file")
+		}
+
+		content := []byte("%PDF-1.4 raw content")
+		info, err := repo.SaveRawFile(ctx, "book_raw", bytes.NewReader(content), "pdf")
+		if err != nil {
+			t.Fatalf("Failed to save raw file: %v", err)
+		}
+		sum := sha256.Sum256(content)
+		if info.Size != int64(len(content)) || info.ContentHash != hex.EncodeToString(sum[:]) {
+			t.Errorf("Unexpected raw file info: %+v", info)
+		}
+
+		raw, err := repo.OpenRawFile(ctx, "book_raw")
+		if err != nil {
+			t.Fatalf("Failed to open raw file: %v", err)
+		}
+		defer raw.Close()
+		if raw.Format != "pdf" || raw.Size() != int64(len(content)) {
+			t.Errorf("Raw file mismatch: format %q, size %d", raw.Format, raw.Size())
+		}
+		buf := make([]byte, 3)
+		if _, err := raw.ReadAt(buf, 5); err != nil || string(buf) != "1.4" {
+			t.Errorf("ReadAt returned %q, %v", buf, err)
+		}
+	})
+
+	t.Run("ContentIndex", func(t *testing.T) {
+		content := []byte("same content")
+		first, err := repo.SaveRawFile(ctx, "book_first", bytes.NewReader(content), "txt")
+		if err != nil {
+			t.Fatalf("Failed to save raw file: %v", err)
+		}
+		if err := repo.SaveBook(ctx, &types.Book{ID: "book_first", ContentHash: first.ContentHash}); err != nil {
+			t.Fatalf("Failed to save book: %v", err)
+		}
+		// A second upload whose metadata is not saved yet
+		if _, err := repo.SaveRawFile(ctx, "book_second", bytes.NewReader(content), "txt"); err != nil {
+			t.Fatalf("Failed to save raw file: %v", err)
+		}
+
+		books, err := repo.FindBooksByContentHash(ctx, first.ContentHash)
+		if err != nil {
+			t.Fatalf("Failed to find books: %v", err)
+		}
+		if len(books) != 1 || books[0].ID != "book_first" {
+			t.Errorf("Expected only book_first, got %v", books)
+		}
+
+		if err := repo.DeleteBook(ctx, "book_first"); err != nil {
+			t.Fatalf("Failed to delete book: %v", err)
+		}
+		if err := repo.DeleteBook(ctx, "book_second"); err != nil {
+			t.Fatalf("Failed to delete book: %v", err)
+		}
+		books, err = repo.FindBooksByContentHash(ctx, first.ContentHash)
+		if err != nil || len(books) != 0 {
+			t.Errorf("Expected no books after deletion, got %v (%v)", books, err)
+		}
+		if exists, _ := storageAdapter.Exists(ctx, contentIndexPath(first.ContentHash)); exists {
+			t.Error("Expected empty content index entry to be removed")
+		}
+	})
+
+	t.Run("CloneBook", func(t *testing.T) {
+		repo.SaveChapter(ctx, &types.Chapter{ID: "chapter_001", BookID: "book_src", Title: "One"})
+		repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: "book_src", Text: "Hello."})
+		repo.SaveVoiceMap(ctx, &types.VoiceMap{BookID: "book_src", Persons: []types.PersonVoice{{ID: "narrator", ProviderVoice: "v1"}}})
+		storageAdapter.Put(ctx, "books/book_src/audio/seg_00001.wav", bytes.NewReader([]byte("RIFF")))
+
+		if err := repo.CloneBook(ctx, "book_src", "book_dst"); err != nil {
+			t.Fatalf("Failed to clone book: %v", err)
+		}
+
+		segment, err := repo.GetSegment(ctx, "book_dst", "seg_00001")
+		if err != nil || segment.BookID != "book_dst" || segment.Text != "Hello." {
+			t.Errorf("Unexpected cloned segment: %+v (%v)", segment, err)
+		}
+		if chapter, err := repo.GetChapter(ctx, "book_dst", "chapter_001"); err != nil || chapter.BookID != "book_dst" {
+			t.Errorf("Unexpected cloned chapter: %+v (%v)", chapter, err)
+		}
+		if voiceMap, err := repo.GetVoiceMap(ctx, "book_dst"); err != nil || voiceMap.BookID != "book_dst" {
+			t.Errorf("Unexpected cloned voice map: %+v (%v)", voiceMap, err)
+		}
+		if exists, _ := storageAdapter.Exists(ctx, "books/book_dst/audio/seg_00001.wav"); !exists {
+			t.Error("Expected cloned audio file")
+		}
+	})
+
+	t.Run("SpoolNonSeekableRawFile", func(t *testing.T) {
+		raw, err := newRawFile(io.NopCloser(bytes.NewReader([]byte("streamed"))), "txt")
+		if err != nil {
+			t.Fatalf("Failed to spool raw file: %v", err)
+		}
+		buf := make([]byte, 6)
+		if _, err := raw.ReadAt(buf, 2); err != nil || string(buf) != "reamed" {
+			t.Errorf("ReadAt returned %q, %v", buf, err)
+		}
+		if err := raw.Close(); err != nil {
+			t.Errorf("Close failed: %v", err)
+		}
+	})
 }
 
 func TestPersonaProfileRepository(t *testing.T) {
@@ -6,10 +6,15 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/unalkalkan/TwelveReader/internal/webhook"
 	"github.com/unalkalkan/TwelveReader/pkg/types"
 	"gopkg.in/yaml.v3"
 )
 
+// defaultChapterAudioSilenceMs is used when the configuration does not set
+// pipeline.chapter_audio_silence_ms; 0 is a valid setting for no silence
+const defaultChapterAudioSilenceMs = 300
+
 // Load reads and parses the configuration file
 // It also supports environment variable overrides with TR_ prefix
 func Load(configPath string) (*types.Config, error) {
@@ -19,8 +24,9 @@ func Load(configPath string) (*types.Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse YAML
+	// Parse YAML over the defaults of settings whose zero value is meaningful
 	var cfg types.Config
+	cfg.Pipeline.ChapterAudioSilenceMs = defaultChapterAudioSilenceMs
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
@@ -43,6 +49,10 @@ func Validate(cfg *types.Config) error {
 		return fmt.Errorf("invalid server port: %d", cfg.Server.Port)
 	}
 
+	if cfg.Server.MaxUploadMB <= 0 {
+		cfg.Server.MaxUploadMB = 100 // default
+	}
+
 	// Validate storage adapter
 	if cfg.Storage.Adapter != "local" && cfg.Storage.Adapter != "s3" {
 		return fmt.Errorf("invalid storage adapter: %s (must be 'local' or 's3')", cfg.Storage.Adapter)
@@ -74,6 +84,19 @@ func Validate(cfg *types.Config) error {
 	if cfg.Pipeline.MaxRetries < 0 {
 		cfg.Pipeline.MaxRetries = 3 // default
 	}
+	if cfg.Pipeline.ChapterAudioSilenceMs < 0 {
+		cfg.Pipeline.ChapterAudioSilenceMs = defaultChapterAudioSilenceMs
+	}
+
+	// Validate webhook subscriptions
+	for i, hook := range cfg.Webhooks {
+		if err := webhook.ValidateSubscription(hook.URL, hook.Events); err != nil {
+			return fmt.Errorf("webhook %d: %w", i+1, err)
+		}
+		if hook.Secret == "" {
+			return fmt.Errorf("webhook %d: secret is required to sign payloads", i+1)
+		}
+	}
 
 	return nil
 }
@@ -88,6 +111,9 @@ func applyEnvOverrides(cfg *types.Config) {
 	if val := os.Getenv("TR_SERVER_PORT"); val != "" {
 		fmt.Sscanf(val, "%d", &cfg.Server.Port)
 	}
+	if val := os.Getenv("TR_SERVER_MAX_UPLOAD_MB"); val != "" {
+		fmt.Sscanf(val, "%d", &cfg.Server.MaxUploadMB)
+	}
 
 	// Storage overrides
 	if val := os.Getenv("TR_STORAGE_ADAPTER"); val != "" {
@@ -193,6 +219,7 @@ func GetDefault() *types.Config {
 			Port:         8080,
 			ReadTimeout:  15,
 			WriteTimeout: 15,
+			MaxUploadMB:  100,
 		},
 		Storage: types.StorageConfig{
 			Adapter: "local",
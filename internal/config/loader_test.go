@@ -49,6 +49,12 @@ pipeline:
 	if cfg.Server.Port != 9090 {
 		t.Errorf("Expected port 9090, got %d", cfg.Server.Port)
 	}
+	if cfg.Server.MaxUploadMB != 100 {
+		t.Errorf("Expected default max_upload_mb 100, got %d", cfg.Server.MaxUploadMB)
+	}
+	if cfg.Pipeline.ChapterAudioSilenceMs != 300 {
+		t.Errorf("Expected default chapter_audio_silence_ms 300, got %d", cfg.Pipeline.ChapterAudioSilenceMs)
+	}
 	if cfg.Storage.Adapter != "local" {
 		t.Errorf("Expected adapter 'local', got '%s'", cfg.Storage.Adapter)
 	}
@@ -57,6 +63,41 @@ pipeline:
 	}
 }
 
+func TestLoad_ChapterAudioSilence(t *testing.T) {
+	tests := map[string]int{
+		"":                                300,
+		"  chapter_audio_silence_ms: 0":   0,
+		"  chapter_audio_silence_ms: 750": 750,
+		"  chapter_audio_silence_ms: -5":  300,
+	}
+	for setting, want := range tests {
+		configPath := filepath.Join(t.TempDir(), "test.yaml")
+		configContent := `
+server:
+  port: 9090
+
+storage:
+  adapter: "local"
+  local:
+    base_path: "/tmp/test"
+
+pipeline:
+  worker_pool_size: 2
+` + setting + "\n"
+		if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+			t.Fatalf("Failed to write test config: %v", err)
+		}
+
+		cfg, err := Load(configPath)
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if cfg.Pipeline.ChapterAudioSilenceMs != want {
+			t.Errorf("%q: expected chapter_audio_silence_ms %d, got %d", setting, want, cfg.Pipeline.ChapterAudioSilenceMs)
+		}
+	}
+}
+
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -98,6 +139,27 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid webhook",
+			modify: func(c *types.Config) {
+				c.Webhooks = []types.WebhookConfig{{URL: "https://cms.example.com/hooks", Secret: "s3cret", Events: []string{"synthesized"}}}
+			},
+			wantErr: false,
+		},
+		{
+			name: "webhook with unknown event",
+			modify: func(c *types.Config) {
+				c.Webhooks = []types.WebhookConfig{{URL: "https://cms.example.com/hooks", Secret: "s3cret", Events: []string{"uploaded"}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "webhook without secret",
+			modify: func(c *types.Config) {
+				c.Webhooks = []types.WebhookConfig{{URL: "https://cms.example.com/hooks"}}
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -135,9 +197,11 @@ pipeline:
 
 	// Set environment variables
 	os.Setenv("TR_SERVER_PORT", "9999")
+	os.Setenv("TR_SERVER_MAX_UPLOAD_MB", "250")
 	os.Setenv("TR_STORAGE_LOCAL_BASE_PATH", "/tmp/override")
 	defer func() {
 		os.Unsetenv("TR_SERVER_PORT")
+		os.Unsetenv("TR_SERVER_MAX_UPLOAD_MB")
 		os.Unsetenv("TR_STORAGE_LOCAL_BASE_PATH")
 	}()
 
@@ -151,6 +215,9 @@ pipeline:
 	if cfg.Server.Port != 9999 {
 		t.Errorf("Expected port 9999 from env override, got %d", cfg.Server.Port)
 	}
+	if cfg.Server.MaxUploadMB != 250 {
+		t.Errorf("Expected max_upload_mb 250 from env override, got %d", cfg.Server.MaxUploadMB)
+	}
 	if cfg.Storage.Local.BasePath != "/tmp/override" {
 		t.Errorf("Expected base_path '/tmp/override' from env override, got '%s'", cfg.Storage.Local.BasePath)
 	}
@@ -104,6 +104,38 @@ func (s *Store) ListPlaybackEvents(ctx context.Context, bookID string, limit int
 	return events, nil
 }
 
+func (s *Store) SaveWebhookDelivery(ctx context.Context, delivery *types.WebhookDelivery) error {
+	if delivery == nil {
+		return fmt.Errorf("webhook delivery is nil")
+	}
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now().UTC()
+	}
+	if delivery.ID == "" {
+		delivery.ID = NewID("delivery")
+	}
+	return s.putJSON(ctx, filepath.Join("books", delivery.BookID, "debug", "webhook-deliveries", delivery.ID+".json"), delivery)
+}
+
+func (s *Store) ListWebhookDeliveries(ctx context.Context, bookID string, limit int) ([]*types.WebhookDelivery, error) {
+	paths, err := s.storage.List(ctx, filepath.Join("books", bookID, "debug", "webhook-deliveries")+string(filepath.Separator))
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]*types.WebhookDelivery, 0, len(paths))
+	for _, path := range paths {
+		var delivery types.WebhookDelivery
+		if err := s.getJSON(ctx, path, &delivery); err == nil {
+			deliveries = append(deliveries, &delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
 func (s *Store) SaveEvent(ctx context.Context, event *types.DebugEvent) error {
 	if event == nil {
 		return fmt.Errorf("debug event is nil")
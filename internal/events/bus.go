@@ -0,0 +1,132 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is how many recent events of each book a bus keeps for
+// subscribers resuming after a disconnect
+const DefaultHistorySize = 512
+
+// subscriberBuffer is how many events a subscriber may fall behind by before
+// it is dropped
+const subscriberBuffer = 64
+
+// Event is something that happened to a book
+type Event struct {
+	ID     uint64    `json:"id"` // Increases with every event published on the bus
+	BookID string    `json:"book_id"`
+	Type   string    `json:"type"` // e.g. "stage", "personas", "segment_ready"
+	Time   time.Time `json:"time"`
+	Data   any       `json:"data,omitempty"`
+}
+
+// Bus passes book events from publishers to subscribers in process and
+// keeps a short history of each book's events
+type Bus struct {
+	mu          sync.Mutex
+	historySize int
+	lastID      uint64
+	history     map[string][]Event
+	subscribers map[string]map[*subscription]struct{}
+}
+
+type subscription struct {
+	events chan Event
+}
+
+// NewBus creates a bus keeping up to historySize events per book
+func NewBus(historySize int) *Bus {
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	return &Bus{
+		historySize: historySize,
+		history:     make(map[string][]Event),
+		subscribers: make(map[string]map[*subscription]struct{}),
+	}
+}
+
+// Publish records an event of a book and passes it to the book's
+// subscribers. Publishing to a nil bus does nothing.
+func (b *Bus) Publish(bookID, eventType string, data any) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{ID: b.lastID, BookID: bookID, Type: eventType, Time: time.Now().UTC(), Data: data}
+
+	history := append(b.history[bookID], event)
+	if len(history) > b.historySize {
+		history = append([]Event(nil), history[len(history)-b.historySize:]...)
+	}
+	b.history[bookID] = history
+
+	for sub := range b.subscribers[bookID] {
+		select {
+		case sub.events <- event:
+		default:
+			// A subscriber this far behind reconnects and resumes from history
+			b.removeLocked(bookID, sub)
+		}
+	}
+}
+
+// Subscribe returns the retained events of a book published after afterID,
+// and a channel of the book's events from then on. The channel is closed
+// when cancel is called or the subscriber falls too far behind. An afterID
+// the bus has not reached yet, such as one from before a restart, replays
+// all retained events.
+func (b *Bus) Subscribe(bookID string, afterID uint64) (replay []Event, events <-chan Event, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if afterID > b.lastID {
+		afterID = 0
+	}
+	for _, event := range b.history[bookID] {
+		if event.ID > afterID {
+			replay = append(replay, event)
+		}
+	}
+
+	sub := &subscription{events: make(chan Event, subscriberBuffer)}
+	if b.subscribers[bookID] == nil {
+		b.subscribers[bookID] = make(map[*subscription]struct{})
+	}
+	b.subscribers[bookID][sub] = struct{}{}
+
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			b.mu.Lock()
+			b.removeLocked(bookID, sub)
+			b.mu.Unlock()
+		})
+	}
+	return replay, sub.events, cancel
+}
+
+// Forget drops the history of a book, e.g. once it is deleted
+func (b *Bus) Forget(bookID string) {
+	b.mu.Lock()
+	delete(b.history, bookID)
+	b.mu.Unlock()
+}
+
+// removeLocked closes a subscription and removes it from its book
+func (b *Bus) removeLocked(bookID string, sub *subscription) {
+	subs := b.subscribers[bookID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.events)
+	if len(subs) == 0 {
+		delete(b.subscribers, bookID)
+	}
+}
@@ -0,0 +1,89 @@
+package events
+
+import "testing"
+
+func TestBus(t *testing.T) {
+	t.Run("Delivers events of the subscribed book", func(t *testing.T) {
+		bus := NewBus(10)
+		_, events, cancel := bus.Subscribe("book_a", 0)
+		defer cancel()
+
+		bus.Publish("book_b", "stage", nil)
+		bus.Publish("book_a", "segment_ready", "seg_00001")
+
+		event := <-events
+		if event.BookID != "book_a" || event.Type != "segment_ready" || event.Data != "seg_00001" || event.ID != 2 {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+	})
+
+	t.Run("Replays events after an ID", func(t *testing.T) {
+		bus := NewBus(10)
+		for i := 0; i < 3; i++ {
+			bus.Publish("book_a", "stage", i)
+		}
+		replay, _, cancel := bus.Subscribe("book_a", 1)
+		defer cancel()
+		if len(replay) != 2 || replay[0].ID != 2 || replay[1].ID != 3 {
+			t.Errorf("Expected events 2 and 3, got %+v", replay)
+		}
+
+		// IDs from before a restart replay everything retained
+		replay, _, cancel = bus.Subscribe("book_a", 99)
+		defer cancel()
+		if len(replay) != 3 {
+			t.Errorf("Expected all 3 events for an unknown ID, got %d", len(replay))
+		}
+	})
+
+	t.Run("Keeps a bounded history", func(t *testing.T) {
+		bus := NewBus(2)
+		for i := 0; i < 5; i++ {
+			bus.Publish("book_a", "stage", i)
+		}
+		replay, _, cancel := bus.Subscribe("book_a", 0)
+		defer cancel()
+		if len(replay) != 2 || replay[0].Data != 3 || replay[1].Data != 4 {
+			t.Errorf("Expected the last 2 events, got %+v", replay)
+		}
+
+		bus.Forget("book_a")
+		if replay, _, cancel := bus.Subscribe("book_a", 0); len(replay) != 0 {
+			t.Errorf("Expected no history after Forget, got %d events", len(replay))
+		} else {
+			cancel()
+		}
+	})
+
+	t.Run("Drops subscribers that fall behind", func(t *testing.T) {
+		bus := NewBus(10)
+		_, events, cancel := bus.Subscribe("book_a", 0)
+		defer cancel()
+		for i := 0; i <= subscriberBuffer; i++ {
+			bus.Publish("book_a", "stage", i)
+		}
+		received := 0
+		for range events {
+			received++
+		}
+		if received != subscriberBuffer {
+			t.Errorf("Expected %d buffered events before the channel closed, got %d", subscriberBuffer, received)
+		}
+	})
+
+	t.Run("Cancel closes the channel", func(t *testing.T) {
+		bus := NewBus(10)
+		_, events, cancel := bus.Subscribe("book_a", 0)
+		cancel()
+		cancel()
+		if _, ok := <-events; ok {
+			t.Error("Expected a closed channel")
+		}
+		bus.Publish("book_a", "stage", nil)
+	})
+
+	t.Run("Nil bus ignores events", func(t *testing.T) {
+		var bus *Bus
+		bus.Publish("book_a", "stage", nil)
+	})
+}
@@ -0,0 +1,104 @@
+// Package netguard keeps server-side HTTP requests to user supplied URLs,
+// such as book imports and webhooks, away from loopback, private and
+// link-local addresses. The check runs when a connection is dialed, on the
+// resolved address, so it also covers redirects and DNS names that resolve
+// to internal hosts.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrNotPublic is returned for addresses that are not publicly routable
+var ErrNotPublic = errors.New("address is not publicly routable")
+
+// nonPublicNets are special purpose ranges the net.IP predicates do not cover
+var nonPublicNets = mustParseCIDRs(
+	"0.0.0.0/8",     // "This" network
+	"100.64.0.0/10", // Carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // Benchmarking
+	"240.0.0.0/4",   // Reserved, including broadcast
+	"64:ff9b::/96",  // NAT64, which embeds IPv4 addresses
+	"2001:db8::/32", // Documentation
+)
+
+// IsPublic reports whether an IP address is publicly routable
+func IsPublic(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, ipNet := range nonPublicNets {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Control is a net.Dialer control function that refuses to connect to
+// addresses that are not publicly routable
+func Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid dial address %q: %w", address, err)
+	}
+	if ip := net.ParseIP(host); !IsPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrNotPublic, host)
+	}
+	return nil
+}
+
+// CheckURL rejects URLs whose host is a literal non-public IP address or
+// localhost. It catches obvious mistakes early; host names are checked when
+// they are dialed.
+func CheckURL(u *url.URL) error {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrNotPublic, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrNotPublic, host)
+	}
+	return nil
+}
+
+// NewTransport returns an HTTP transport that only connects to publicly
+// routable addresses. Proxies from the environment are not used, since
+// the proxy rather than the target would be checked.
+func NewTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   Control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, address)
+	}
+	return transport
+}
+
+// mustParseCIDRs parses constant CIDR ranges
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets
+}
@@ -0,0 +1,63 @@
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":          true,
+		"2606:4700::1111":        true,
+		"127.0.0.1":              false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.10":           false,
+		"169.254.169.254":        false,
+		"100.64.0.1":             false,
+		"0.0.0.0":                false,
+		"255.255.255.255":        false,
+		"::1":                    false,
+		"fe80::1":                false,
+		"fd00::1":                false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+		"64:ff9b::a9fe:a9fe":     false,
+	}
+	for address, want := range tests {
+		if got := IsPublic(net.ParseIP(address)); got != want {
+			t.Errorf("IsPublic(%s): expected %v, got %v", address, want, got)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com/book.epub":           true,
+		"http://localhost:8080/":                  false,
+		"http://api.localhost/":                   false,
+		"http://169.254.169.254/latest/meta-data": false,
+		"http://[::1]/":                           false,
+	}
+	for rawURL, ok := range tests {
+		u, _ := url.Parse(rawURL)
+		if err := CheckURL(u); (err == nil) != ok {
+			t.Errorf("CheckURL(%s): unexpected result %v", rawURL, err)
+		}
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport()}
+	_, err := client.Get(server.URL)
+	if !errors.Is(err, ErrNotPublic) {
+		t.Errorf("Expected a loopback server to be refused, got %v", err)
+	}
+}
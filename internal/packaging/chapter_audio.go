@@ -0,0 +1,214 @@
+package packaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/audio"
+	"github.com/unalkalkan/TwelveReader/internal/util"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+var (
+	// ErrChapterNotFound is returned when the chapter does not exist
+	ErrChapterNotFound = errors.New("chapter not found")
+
+	// ErrChapterAudioIncomplete is returned while segments of the chapter
+	// have no audio yet
+	ErrChapterAudioIncomplete = errors.New("chapter audio is incomplete")
+
+	// ErrChapterAudioFormat is returned when segment audio is not WAV, which
+	// is the only format that can be joined
+	ErrChapterAudioFormat = errors.New("chapter audio requires WAV segment audio")
+)
+
+// ChapterAudioIndex locates each segment within the concatenated audio of a chapter
+type ChapterAudioIndex struct {
+	BookID      string                `json:"book_id"`
+	ChapterID   string                `json:"chapter_id"`
+	Format      string                `json:"format"`
+	Duration    float64               `json:"duration_seconds"`
+	SilenceMs   int64                 `json:"silence_ms"`
+	Segments    []ChapterAudioSegment `json:"segments"`
+	Fingerprint string                `json:"fingerprint"` // Identifies the segment audio the file was built from
+}
+
+// ChapterAudioSegment is where one segment plays in the chapter audio
+type ChapterAudioSegment struct {
+	SegmentID string  `json:"segment_id"`
+	Start     float64 `json:"start_seconds"`
+	Duration  float64 `json:"duration_seconds"`
+}
+
+// ChapterAudio returns the index of a chapter's concatenated audio and the
+// storage path of the audio file. The file joins the WAV audio of the
+// chapter's segments in reading order with silence between them. It is built
+// on first request for each silence and kept until the segment audio changes.
+func (s *Service) ChapterAudio(ctx context.Context, bookID, chapterID string, silence time.Duration) (*ChapterAudioIndex, string, error) {
+	if _, err := s.bookRepo.GetChapter(ctx, bookID, chapterID); err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrChapterNotFound, chapterID)
+	}
+
+	segments, err := s.chapterSegments(ctx, bookID, chapterID)
+	if err != nil {
+		return nil, "", err
+	}
+	fingerprint, err := s.chapterAudioFingerprint(ctx, bookID, segments, silence)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Each silence gets its own files, so building one never rewrites audio
+	// another request is streaming with a different silence
+	audioPath := chapterAudioPath(bookID, chapterID, silence, "wav")
+	indexPath := chapterAudioPath(bookID, chapterID, silence, "json")
+
+	lockIface, _ := s.chapterLocks.LoadOrStore(indexPath, &sync.Mutex{})
+	lock := lockIface.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if index := s.loadChapterAudioIndex(ctx, indexPath); index != nil && index.Fingerprint == fingerprint {
+		if exists, _ := s.storage.Exists(ctx, audioPath); exists {
+			return index, audioPath, nil
+		}
+	}
+
+	index, err := s.buildChapterAudio(ctx, bookID, chapterID, segments, silence, audioPath)
+	if err != nil {
+		return nil, "", err
+	}
+	index.Fingerprint = fingerprint
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal chapter audio index: %w", err)
+	}
+	if err := s.storage.Put(ctx, indexPath, bytes.NewReader(data)); err != nil {
+		return nil, "", fmt.Errorf("failed to save chapter audio index: %w", err)
+	}
+	return index, audioPath, nil
+}
+
+// chapterSegments returns the segments of a chapter in reading order
+func (s *Service) chapterSegments(ctx context.Context, bookID, chapterID string) ([]*types.Segment, error) {
+	segments, err := s.bookRepo.ListSegments(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments: %w", err)
+	}
+
+	var chapterSegs []*types.Segment
+	for _, seg := range segments {
+		if seg.Chapter == chapterID {
+			chapterSegs = append(chapterSegs, seg)
+		}
+	}
+	if len(chapterSegs) == 0 {
+		return nil, fmt.Errorf("%w: chapter has no segments", ErrChapterAudioIncomplete)
+	}
+	sort.Slice(chapterSegs, func(i, j int) bool {
+		return chapterSegs[i].ID < chapterSegs[j].ID
+	})
+	return chapterSegs, nil
+}
+
+// chapterAudioFingerprint identifies the current audio of the segments. It
+// fails when any segment has no WAV audio.
+func (s *Service) chapterAudioFingerprint(ctx context.Context, bookID string, segments []*types.Segment, silence time.Duration) (string, error) {
+	hasher := sha256.New()
+	fmt.Fprintf(hasher, "silence=%d\n", silence.Milliseconds())
+
+	missing := 0
+	for _, seg := range segments {
+		metadata, err := s.storage.Stat(ctx, util.GetAudioPath(bookID, seg.ID, "wav"))
+		if err != nil {
+			for _, format := range util.AudioFormats() {
+				if exists, _ := s.storage.Exists(ctx, util.GetAudioPath(bookID, seg.ID, format)); exists {
+					return "", fmt.Errorf("%w: segment %s is %s", ErrChapterAudioFormat, seg.ID, format)
+				}
+			}
+			missing++
+			continue
+		}
+		fmt.Fprintf(hasher, "%s %s %d\n", seg.ID, metadata.ETag, metadata.Size)
+	}
+	if missing > 0 {
+		return "", fmt.Errorf("%w: %d of %d segments have no audio", ErrChapterAudioIncomplete, missing, len(segments))
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// buildChapterAudio joins the segment audio, stores it at audioPath and
+// returns its index
+func (s *Service) buildChapterAudio(ctx context.Context, bookID, chapterID string, segments []*types.Segment, silence time.Duration, audioPath string) (*ChapterAudioIndex, error) {
+	chunks := make([][]byte, 0, len(segments))
+	for _, seg := range segments {
+		reader, err := s.storage.Get(ctx, util.GetAudioPath(bookID, seg.ID, "wav"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get audio %s: %w", seg.ID, err)
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audio %s: %w", seg.ID, err)
+		}
+		chunks = append(chunks, data)
+	}
+
+	joined, spans, err := audio.ConcatWAV(chunks, silence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to concatenate chapter audio: %w", err)
+	}
+	if err := s.storage.Put(ctx, audioPath, bytes.NewReader(joined)); err != nil {
+		return nil, fmt.Errorf("failed to store chapter audio: %w", err)
+	}
+
+	index := &ChapterAudioIndex{
+		BookID:    bookID,
+		ChapterID: chapterID,
+		Format:    "wav",
+		SilenceMs: silence.Milliseconds(),
+		Segments:  make([]ChapterAudioSegment, len(segments)),
+	}
+	for i, seg := range segments {
+		index.Segments[i] = ChapterAudioSegment{
+			SegmentID: seg.ID,
+			Start:     spans[i].Start.Seconds(),
+			Duration:  spans[i].Duration.Seconds(),
+		}
+	}
+	last := spans[len(spans)-1]
+	index.Duration = (last.Start + last.Duration).Seconds()
+	return index, nil
+}
+
+// loadChapterAudioIndex reads a stored chapter audio index, or returns nil
+func (s *Service) loadChapterAudioIndex(ctx context.Context, indexPath string) *ChapterAudioIndex {
+	reader, err := s.storage.Get(ctx, indexPath)
+	if err != nil {
+		return nil
+	}
+	defer reader.Close()
+
+	var index ChapterAudioIndex
+	if err := json.NewDecoder(reader).Decode(&index); err != nil {
+		return nil
+	}
+	return &index
+}
+
+// chapterAudioPath returns the storage path of a chapter's concatenated audio
+// or its index for a silence between segments
+func chapterAudioPath(bookID, chapterID string, silence time.Duration, ext string) string {
+	return filepath.Join("books", bookID, "chapter-audio", fmt.Sprintf("%s_%dms.%s", chapterID, silence.Milliseconds(), ext))
+}
@@ -0,0 +1,125 @@
+package packaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/audio"
+	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// testSegmentWAV returns mono 16-bit audio at 1000 Hz lasting ms milliseconds
+func testSegmentWAV(ms int) []byte {
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:2], 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:4], 1)
+	binary.LittleEndian.PutUint32(fmtChunk[4:8], 1000)
+	binary.LittleEndian.PutUint32(fmtChunk[8:12], 2000)
+	binary.LittleEndian.PutUint16(fmtChunk[12:14], 2)
+	binary.LittleEndian.PutUint16(fmtChunk[14:16], 16)
+	return audio.EncodeWAV(fmtChunk, bytes.Repeat([]byte{1}, ms*2))
+}
+
+func TestService_ChapterAudio(t *testing.T) {
+	ctx := context.Background()
+	storageAdapter, err := storage.NewLocalAdapter(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage adapter: %v", err)
+	}
+	defer storageAdapter.Close()
+	repo := book.NewRepository(storageAdapter)
+	service := NewService(repo, storageAdapter)
+
+	repo.SaveChapter(ctx, &types.Chapter{ID: "chapter_001", BookID: "book_ch", Number: 1})
+	repo.SaveChapter(ctx, &types.Chapter{ID: "chapter_002", BookID: "book_ch", Number: 2})
+	for _, seg := range []*types.Segment{
+		{ID: "seg_002", BookID: "book_ch", Chapter: "chapter_001", Text: "Second."},
+		{ID: "seg_001", BookID: "book_ch", Chapter: "chapter_001", Text: "First."},
+		{ID: "seg_003", BookID: "book_ch", Chapter: "chapter_002", Text: "Third."},
+	} {
+		repo.SaveSegment(ctx, seg)
+	}
+	storageAdapter.Put(ctx, "books/book_ch/audio/seg_001.wav", bytes.NewReader(testSegmentWAV(500)))
+	storageAdapter.Put(ctx, "books/book_ch/audio/seg_002.wav", bytes.NewReader(testSegmentWAV(250)))
+
+	index, audioPath, err := service.ChapterAudio(ctx, "book_ch", "chapter_001", 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("ChapterAudio failed: %v", err)
+	}
+	expected := []ChapterAudioSegment{
+		{SegmentID: "seg_001", Start: 0, Duration: 0.5},
+		{SegmentID: "seg_002", Start: 0.6, Duration: 0.25},
+	}
+	if len(index.Segments) != 2 || index.Segments[0] != expected[0] || index.Segments[1] != expected[1] {
+		t.Fatalf("Expected segments %v in reading order, got %v", expected, index.Segments)
+	}
+	if index.Duration != 0.85 || index.SilenceMs != 100 || index.Format != "wav" {
+		t.Errorf("Unexpected index: %+v", index)
+	}
+
+	reader, err := storageAdapter.Get(ctx, audioPath)
+	if err != nil {
+		t.Fatalf("Expected chapter audio to be stored: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if parsed, err := audio.ParseWAV(data); err != nil || parsed.Duration() != 850*time.Millisecond {
+		t.Fatalf("Expected 850ms of WAV audio, got %v", err)
+	}
+
+	t.Run("Reuses built audio", func(t *testing.T) {
+		before, _ := storageAdapter.Stat(ctx, audioPath)
+		time.Sleep(10 * time.Millisecond)
+		again, _, err := service.ChapterAudio(ctx, "book_ch", "chapter_001", 100*time.Millisecond)
+		if err != nil {
+			t.Fatalf("ChapterAudio failed: %v", err)
+		}
+		after, _ := storageAdapter.Stat(ctx, audioPath)
+		if again.Fingerprint != index.Fingerprint || after.ETag != before.ETag {
+			t.Error("Expected chapter audio to be reused")
+		}
+	})
+
+	t.Run("Keeps audio of other silences", func(t *testing.T) {
+		before, _ := storageAdapter.Stat(ctx, audioPath)
+		_, otherPath, err := service.ChapterAudio(ctx, "book_ch", "chapter_001", 200*time.Millisecond)
+		if err != nil {
+			t.Fatalf("ChapterAudio failed: %v", err)
+		}
+		after, _ := storageAdapter.Stat(ctx, audioPath)
+		if otherPath == audioPath || after.ETag != before.ETag {
+			t.Errorf("Expected a separate file for another silence, got %s", otherPath)
+		}
+	})
+
+	t.Run("Rebuilds after changes", func(t *testing.T) {
+		storageAdapter.Put(ctx, "books/book_ch/audio/seg_002.wav", bytes.NewReader(testSegmentWAV(400)))
+		rebuilt, _, err := service.ChapterAudio(ctx, "book_ch", "chapter_001", 0)
+		if err != nil {
+			t.Fatalf("ChapterAudio failed: %v", err)
+		}
+		if rebuilt.Fingerprint == index.Fingerprint || rebuilt.Segments[1].Start != 0.5 || rebuilt.Duration != 0.9 {
+			t.Errorf("Expected chapter audio to be rebuilt, got %+v", rebuilt)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		if _, _, err := service.ChapterAudio(ctx, "book_ch", "chapter_404", 0); !errors.Is(err, ErrChapterNotFound) {
+			t.Errorf("Expected ErrChapterNotFound, got %v", err)
+		}
+		if _, _, err := service.ChapterAudio(ctx, "book_ch", "chapter_002", 0); !errors.Is(err, ErrChapterAudioIncomplete) {
+			t.Errorf("Expected ErrChapterAudioIncomplete, got %v", err)
+		}
+		storageAdapter.Put(ctx, "books/book_ch/audio/seg_003.mp3", bytes.NewReader([]byte("ID3")))
+		if _, _, err := service.ChapterAudio(ctx, "book_ch", "chapter_002", 0); !errors.Is(err, ErrChapterAudioFormat) {
+			t.Errorf("Expected ErrChapterAudioFormat, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,371 @@
+package packaging
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/audio"
+	"github.com/unalkalkan/TwelveReader/internal/util"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// epubActiveClass is the CSS class reading apps put on the text span whose
+// audio is playing
+const epubActiveClass = "-epub-media-overlay-active"
+
+// epubCoverTypes maps cover image extensions to their media types
+var epubCoverTypes = map[string]string{
+	"jpg":  "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+}
+
+// epubChapter is one chapter of an EPUB with its text, audio clips and
+// media overlay
+type epubChapter struct {
+	toc        TOCChapter
+	title      string
+	chapter    *types.Chapter
+	segments   []*types.Segment
+	paragraphs [][]*types.Segment  // Segments grouped into source paragraphs
+	clips      map[string]epubClip // By segment ID
+	duration   time.Duration
+	hasAudio   bool
+}
+
+// epubClip is the audio file of one segment
+type epubClip struct {
+	href     string // Relative to OEBPS
+	duration time.Duration
+}
+
+// PackageEPUB creates an EPUB3 read-along book. Each TOC chapter is an XHTML
+// page in which every segment is a text span, and a SMIL media overlay links
+// each span to the segment's audio clip so reading apps can highlight the
+// text while the audio plays. Like PackageBook, the archive is streamed while
+// it is read.
+func (s *Service) PackageEPUB(ctx context.Context, bookID string) (io.ReadCloser, error) {
+	book, err := s.bookRepo.GetBook(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+	if book.Status != "synthesized" {
+		return nil, fmt.Errorf("%w (status: %s)", ErrBookNotSynthesized, book.Status)
+	}
+
+	segments, err := s.bookRepo.ListSegments(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments: %w", err)
+	}
+	chapterList, err := s.bookRepo.ListChapters(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chapters: %w", err)
+	}
+
+	manifest := s.generateManifest(book, segments)
+	toc := s.generateTOC(chapterList, segments)
+	if manifest.Language == "" {
+		manifest.Language = "en"
+	}
+	if manifest.Title == "" {
+		manifest.Title = bookID
+	}
+
+	chaptersByID := make(map[string]*types.Chapter, len(chapterList))
+	for _, chapter := range chapterList {
+		chaptersByID[chapter.ID] = chapter
+	}
+	segmentsByID := make(map[string]*types.Segment, len(segments))
+	for _, seg := range segments {
+		segmentsByID[seg.ID] = seg
+	}
+
+	coverHref, coverType := "", ""
+	cover, ext, err := s.bookRepo.GetCover(ctx, bookID)
+	if err == nil && epubCoverTypes[ext] != "" {
+		coverHref, coverType = "images/cover."+ext, epubCoverTypes[ext]
+	}
+
+	return streamZip(func(zipWriter *zip.Writer) error {
+		// The mimetype entry must come first and be stored uncompressed
+		mimetype, err := zipWriter.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+		if err != nil {
+			return fmt.Errorf("failed to add mimetype: %w", err)
+		}
+		if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+			return fmt.Errorf("failed to add mimetype: %w", err)
+		}
+
+		if err := s.addTextFile(zipWriter, "META-INF/container.xml", epubContainerXML); err != nil {
+			return fmt.Errorf("failed to add container: %w", err)
+		}
+		if err := s.addTextFile(zipWriter, "OEBPS/style.css", fmt.Sprintf(".%s { background-color: #ffe58a; }\n", epubActiveClass)); err != nil {
+			return fmt.Errorf("failed to add stylesheet: %w", err)
+		}
+
+		chapters := make([]*epubChapter, 0, len(toc.Chapters))
+		for i, tocChapter := range toc.Chapters {
+			chapter := &epubChapter{
+				toc:     tocChapter,
+				title:   tocChapterTitle(tocChapter, i),
+				chapter: chaptersByID[tocChapter.ID],
+				clips:   make(map[string]epubClip),
+			}
+			for _, segID := range tocChapter.Segments {
+				seg := segmentsByID[segID]
+				chapter.segments = append(chapter.segments, seg)
+
+				clip, ok, err := s.addSegmentAudio(ctx, zipWriter, bookID, seg)
+				if err != nil {
+					return err
+				}
+				if ok {
+					chapter.clips[seg.ID] = clip
+					chapter.duration += clip.duration
+					chapter.hasAudio = true
+				}
+			}
+			chapter.paragraphs = groupParagraphs(chapter.segments)
+			chapters = append(chapters, chapter)
+
+			if err := s.addTextFile(zipWriter, "OEBPS/"+epubTextHref(chapter), epubChapterXHTML(chapter, manifest.Language)); err != nil {
+				return fmt.Errorf("failed to add chapter %s: %w", tocChapter.ID, err)
+			}
+			if chapter.hasAudio {
+				if err := s.addTextFile(zipWriter, "OEBPS/"+epubOverlayHref(chapter), epubChapterSMIL(chapter)); err != nil {
+					return fmt.Errorf("failed to add media overlay %s: %w", tocChapter.ID, err)
+				}
+			}
+		}
+
+		if coverHref != "" {
+			if err := s.addFileFromReader(zipWriter, "OEBPS/"+coverHref, bytes.NewReader(cover)); err != nil {
+				return fmt.Errorf("failed to add cover: %w", err)
+			}
+		}
+
+		if err := s.addTextFile(zipWriter, "OEBPS/nav.xhtml", epubNavXHTML(manifest, chapters)); err != nil {
+			return fmt.Errorf("failed to add navigation: %w", err)
+		}
+		if err := s.addTextFile(zipWriter, "OEBPS/content.opf", epubPackageOPF(manifest, chapters, coverHref, coverType)); err != nil {
+			return fmt.Errorf("failed to add package document: %w", err)
+		}
+		return nil
+	}), nil
+}
+
+// addSegmentAudio adds a segment's audio file to the EPUB and returns its
+// clip. It reports false when the segment has no audio.
+func (s *Service) addSegmentAudio(ctx context.Context, zipWriter *zip.Writer, bookID string, seg *types.Segment) (epubClip, bool, error) {
+	for _, format := range util.AudioFormats() {
+		reader, err := s.storage.Get(ctx, util.GetAudioPath(bookID, seg.ID, format))
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return epubClip{}, false, fmt.Errorf("failed to read audio %s: %w", seg.ID, err)
+		}
+
+		clip := epubClip{href: fmt.Sprintf("audio/%s.%s", seg.ID, format), duration: segmentAudioDuration(seg, format, data)}
+		if err := s.addFileFromReader(zipWriter, "OEBPS/"+clip.href, bytes.NewReader(data)); err != nil {
+			return epubClip{}, false, fmt.Errorf("failed to add audio %s: %w", seg.ID, err)
+		}
+		return clip, true, nil
+	}
+	return epubClip{}, false, nil
+}
+
+// segmentAudioDuration returns the length of a segment's audio: recorded at
+// synthesis, else measured from the file, else the end of its last
+// timestamp, or zero when unknown
+func segmentAudioDuration(seg *types.Segment, format string, data []byte) time.Duration {
+	if seg.AudioDuration <= 0 {
+		if duration, err := audio.Duration(data, format); err == nil {
+			return duration
+		}
+	}
+	return time.Duration(segmentDuration(seg) * float64(time.Second))
+}
+
+// groupParagraphs groups consecutive segments that came from the same source
+// paragraph, which share the same neighbouring paragraph references
+func groupParagraphs(segments []*types.Segment) [][]*types.Segment {
+	var groups [][]*types.Segment
+	for i, seg := range segments {
+		if i > 0 && seg.SourceContext != nil && segments[i-1].SourceContext != nil && *seg.SourceContext == *segments[i-1].SourceContext {
+			groups[len(groups)-1] = append(groups[len(groups)-1], seg)
+			continue
+		}
+		groups = append(groups, []*types.Segment{seg})
+	}
+	return groups
+}
+
+// addTextFile adds a text file to the ZIP
+func (s *Service) addTextFile(zipWriter *zip.Writer, path, content string) error {
+	return s.addFileFromReader(zipWriter, path, strings.NewReader(content))
+}
+
+func epubTextHref(chapter *epubChapter) string {
+	return fmt.Sprintf("text/%s.xhtml", chapter.toc.ID)
+}
+
+func epubOverlayHref(chapter *epubChapter) string {
+	return fmt.Sprintf("smil/%s.smil", chapter.toc.ID)
+}
+
+const epubContainerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// epubChapterXHTML renders a chapter page. Chapters with segments show each
+// segment as a span the media overlay can point at; others show the parsed
+// paragraphs.
+func epubChapterXHTML(chapter *epubChapter, language string) string {
+	var b strings.Builder
+	writeXHTMLHead(&b, chapter.title, language, "../style.css")
+	b.WriteString("<section epub:type=\"chapter\">\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", html.EscapeString(chapter.title))
+	if len(chapter.segments) > 0 {
+		for _, group := range chapter.paragraphs {
+			b.WriteString("<p>")
+			for i, seg := range group {
+				if i > 0 {
+					b.WriteString(" ")
+				}
+				fmt.Fprintf(&b, "<span id=\"%s\">%s</span>", html.EscapeString(seg.ID), html.EscapeString(seg.Text))
+			}
+			b.WriteString("</p>\n")
+		}
+	} else if chapter.chapter != nil {
+		for _, paragraph := range chapter.chapter.Paragraphs {
+			fmt.Fprintf(&b, "<p>%s</p>\n", html.EscapeString(paragraph))
+		}
+	}
+	b.WriteString("</section>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// epubChapterSMIL renders the media overlay of a chapter, pairing each
+// segment's text span with its audio clip
+func epubChapterSMIL(chapter *epubChapter) string {
+	textHref := "../" + epubTextHref(chapter)
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	b.WriteString("<smil xmlns=\"http://www.w3.org/ns/SMIL\" xmlns:epub=\"http://www.idpf.org/2007/ops\" version=\"3.0\">\n<body>\n")
+	fmt.Fprintf(&b, "<seq id=\"seq_%s\" epub:textref=\"%s\" epub:type=\"chapter\">\n", html.EscapeString(chapter.toc.ID), html.EscapeString(textHref))
+	for _, seg := range chapter.segments {
+		clip, ok := chapter.clips[seg.ID]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "<par id=\"par_%s\">\n", html.EscapeString(seg.ID))
+		fmt.Fprintf(&b, "  <text src=\"%s#%s\"/>\n", html.EscapeString(textHref), html.EscapeString(seg.ID))
+		fmt.Fprintf(&b, "  <audio src=\"../%s\" clipBegin=\"%s\"", html.EscapeString(clip.href), clockValue(0))
+		if clip.duration > 0 {
+			fmt.Fprintf(&b, " clipEnd=\"%s\"", clockValue(clip.duration))
+		}
+		b.WriteString("/>\n</par>\n")
+	}
+	b.WriteString("</seq>\n</body>\n</smil>\n")
+	return b.String()
+}
+
+// epubNavXHTML renders the navigation document from the TOC
+func epubNavXHTML(manifest *Manifest, chapters []*epubChapter) string {
+	var b strings.Builder
+	writeXHTMLHead(&b, manifest.Title, manifest.Language, "style.css")
+	b.WriteString("<nav epub:type=\"toc\" id=\"toc\">\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<ol>\n", html.EscapeString(manifest.Title))
+	for _, chapter := range chapters {
+		fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(epubTextHref(chapter)), html.EscapeString(chapter.title))
+	}
+	b.WriteString("</ol>\n</nav>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// epubPackageOPF renders the package document: metadata, including media
+// overlay durations, every file of the book and the reading order
+func epubPackageOPF(manifest *Manifest, chapters []*epubChapter, coverHref, coverType string) string {
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(&b, "<package xmlns=\"http://www.idpf.org/2007/opf\" version=\"3.0\" unique-identifier=\"book-id\" xml:lang=\"%s\">\n", html.EscapeString(manifest.Language))
+
+	b.WriteString("<metadata xmlns:dc=\"http://purl.org/dc/elements/1.1/\">\n")
+	fmt.Fprintf(&b, "  <dc:identifier id=\"book-id\">urn:twelvereader:%s</dc:identifier>\n", html.EscapeString(manifest.BookID))
+	fmt.Fprintf(&b, "  <dc:title>%s</dc:title>\n", html.EscapeString(manifest.Title))
+	if manifest.Author != "" {
+		fmt.Fprintf(&b, "  <dc:creator>%s</dc:creator>\n", html.EscapeString(manifest.Author))
+	}
+	fmt.Fprintf(&b, "  <dc:language>%s</dc:language>\n", html.EscapeString(manifest.Language))
+	fmt.Fprintf(&b, "  <meta property=\"dcterms:modified\">%s</meta>\n", manifest.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"))
+	var total time.Duration
+	for _, chapter := range chapters {
+		if chapter.hasAudio {
+			fmt.Fprintf(&b, "  <meta property=\"media:duration\" refines=\"#smil_%s\">%s</meta>\n", html.EscapeString(chapter.toc.ID), clockValue(chapter.duration))
+			total += chapter.duration
+		}
+	}
+	fmt.Fprintf(&b, "  <meta property=\"media:duration\">%s</meta>\n", clockValue(total))
+	fmt.Fprintf(&b, "  <meta property=\"media:active-class\">%s</meta>\n", epubActiveClass)
+	b.WriteString("</metadata>\n")
+
+	b.WriteString("<manifest>\n")
+	b.WriteString("  <item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n")
+	b.WriteString("  <item id=\"style\" href=\"style.css\" media-type=\"text/css\"/>\n")
+	if coverHref != "" {
+		fmt.Fprintf(&b, "  <item id=\"cover\" href=\"%s\" media-type=\"%s\" properties=\"cover-image\"/>\n", coverHref, coverType)
+	}
+	for _, chapter := range chapters {
+		id := html.EscapeString(chapter.toc.ID)
+		if chapter.hasAudio {
+			fmt.Fprintf(&b, "  <item id=\"text_%s\" href=\"%s\" media-type=\"application/xhtml+xml\" media-overlay=\"smil_%s\"/>\n", id, html.EscapeString(epubTextHref(chapter)), id)
+			fmt.Fprintf(&b, "  <item id=\"smil_%s\" href=\"%s\" media-type=\"application/smil+xml\"/>\n", id, html.EscapeString(epubOverlayHref(chapter)))
+		} else {
+			fmt.Fprintf(&b, "  <item id=\"text_%s\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", id, html.EscapeString(epubTextHref(chapter)))
+		}
+		for _, seg := range chapter.segments {
+			if clip, ok := chapter.clips[seg.ID]; ok {
+				format := strings.TrimPrefix(path.Ext(clip.href), ".")
+				fmt.Fprintf(&b, "  <item id=\"audio_%s\" href=\"%s\" media-type=\"%s\"/>\n", html.EscapeString(seg.ID), html.EscapeString(clip.href), util.AudioMimeType(format))
+			}
+		}
+	}
+	b.WriteString("</manifest>\n")
+
+	b.WriteString("<spine>\n")
+	for _, chapter := range chapters {
+		fmt.Fprintf(&b, "  <itemref idref=\"text_%s\"/>\n", html.EscapeString(chapter.toc.ID))
+	}
+	b.WriteString("</spine>\n</package>\n")
+	return b.String()
+}
+
+// writeXHTMLHead writes the start of an XHTML content document up to the
+// opening body tag
+func writeXHTMLHead(b *strings.Builder, title, language, stylesheet string) {
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<!DOCTYPE html>\n")
+	fmt.Fprintf(b, "<html xmlns=\"http://www.w3.org/1999/xhtml\" xmlns:epub=\"http://www.idpf.org/2007/ops\" xml:lang=\"%s\" lang=\"%s\">\n", html.EscapeString(language), html.EscapeString(language))
+	fmt.Fprintf(b, "<head>\n<meta charset=\"UTF-8\"/>\n<title>%s</title>\n", html.EscapeString(title))
+	fmt.Fprintf(b, "<link rel=\"stylesheet\" type=\"text/css\" href=\"%s\"/>\n</head>\n<body>\n", stylesheet)
+}
+
+// clockValue formats a duration as a SMIL clock value, h:mm:ss.fff
+func clockValue(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,152 @@
+package packaging
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestService_PackageEPUB(t *testing.T) {
+	ctx := context.Background()
+	storageAdapter, err := storage.NewLocalAdapter(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage adapter: %v", err)
+	}
+	defer storageAdapter.Close()
+	repo := book.NewRepository(storageAdapter)
+	service := NewService(repo, storageAdapter)
+
+	repo.SaveBook(ctx, &types.Book{ID: "book_epub", Title: "Read & Listen", Author: "Test Author", Language: "en", Status: "synthesized"})
+	repo.SaveCover(ctx, "book_epub", []byte("JPEGDATA"), "jpg")
+	repo.SaveChapter(ctx, &types.Chapter{ID: "chapter_001", BookID: "book_epub", Number: 1, Title: "Front", Paragraphs: []string{"Copyright <2024>."}})
+	repo.SaveChapter(ctx, &types.Chapter{ID: "chapter_002", BookID: "book_epub", Number: 2, Title: "Story"})
+	paragraph := &types.SourceContext{PrevParagraphID: "chapter_002_para_-01", NextParagraphID: "chapter_002_para_001"}
+	for _, seg := range []*types.Segment{
+		{ID: "seg_00001", BookID: "book_epub", Chapter: "chapter_002", Text: "\"Hello,\" she said.", SourceContext: paragraph},
+		{ID: "seg_00002", BookID: "book_epub", Chapter: "chapter_002", Text: "He waved.", SourceContext: paragraph},
+		{ID: "seg_00003", BookID: "book_epub", Chapter: "chapter_002", Text: "The end.", Timestamps: &types.TimestampData{
+			Items: []types.TimestampItem{{Word: "The", Start: 0, End: 0.2}, {Word: "end", Start: 0.2, End: 1.25}},
+		}},
+	} {
+		repo.SaveSegment(ctx, seg)
+	}
+	storageAdapter.Put(ctx, "books/book_epub/audio/seg_00001.wav", bytes.NewReader(testSegmentWAV(1500)))
+	storageAdapter.Put(ctx, "books/book_epub/audio/seg_00002.wav", bytes.NewReader(testSegmentWAV(250)))
+	storageAdapter.Put(ctx, "books/book_epub/audio/seg_00003.mp3", bytes.NewReader([]byte("ID3")))
+
+	reader, err := service.PackageEPUB(ctx, "book_epub")
+	if err != nil {
+		t.Fatalf("PackageEPUB failed: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to open EPUB: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, file := range zipReader.File {
+		rc, _ := file.Open()
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[file.Name] = string(content)
+	}
+
+	t.Run("Container", func(t *testing.T) {
+		first := zipReader.File[0]
+		if first.Name != "mimetype" || first.Method != zip.Store || files["mimetype"] != "application/epub+zip" {
+			t.Error("Expected an uncompressed mimetype entry first")
+		}
+		for _, name := range []string{"META-INF/container.xml", "OEBPS/content.opf", "OEBPS/nav.xhtml", "OEBPS/images/cover.jpg", "OEBPS/audio/seg_00001.wav", "OEBPS/audio/seg_00003.mp3"} {
+			if _, ok := files[name]; !ok {
+				t.Errorf("Expected %s in the EPUB", name)
+			}
+		}
+		for name, content := range files {
+			if strings.HasSuffix(name, ".xml") || strings.HasSuffix(name, ".opf") || strings.HasSuffix(name, ".xhtml") || strings.HasSuffix(name, ".smil") {
+				decoder := xml.NewDecoder(strings.NewReader(content))
+				for {
+					if _, err := decoder.Token(); err != nil {
+						if err != io.EOF {
+							t.Errorf("%s is not well-formed: %v", name, err)
+						}
+						break
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("Package document", func(t *testing.T) {
+		opf := files["OEBPS/content.opf"]
+		for _, want := range []string{
+			`<dc:title>Read &amp; Listen</dc:title>`,
+			`<dc:creator>Test Author</dc:creator>`,
+			`href="text/chapter_002.xhtml" media-type="application/xhtml+xml" media-overlay="smil_chapter_002"`,
+			`<meta property="media:duration" refines="#smil_chapter_002">0:00:03.000</meta>`,
+			`<meta property="media:active-class">-epub-media-overlay-active</meta>`,
+			`properties="cover-image"`,
+			`href="audio/seg_00003.mp3" media-type="audio/mpeg"`,
+		} {
+			if !strings.Contains(opf, want) {
+				t.Errorf("Expected %s in content.opf:\n%s", want, opf)
+			}
+		}
+		if strings.Contains(opf, "smil_chapter_001") {
+			t.Error("Expected no media overlay for a chapter without audio")
+		}
+	})
+
+	t.Run("Text and media overlay", func(t *testing.T) {
+		xhtml := files["OEBPS/text/chapter_002.xhtml"]
+		if !strings.Contains(xhtml, `<p><span id="seg_00001">&#34;Hello,&#34; she said.</span> <span id="seg_00002">He waved.</span></p>`) {
+			t.Errorf("Expected segments of one paragraph in one <p>:\n%s", xhtml)
+		}
+		if !strings.Contains(files["OEBPS/text/chapter_001.xhtml"], "<p>Copyright &lt;2024&gt;.</p>") {
+			t.Error("Expected chapter paragraphs without segments as plain text")
+		}
+
+		var smil struct {
+			Pars []struct {
+				Text struct {
+					Src string `xml:"src,attr"`
+				} `xml:"text"`
+				Audio struct {
+					Src       string `xml:"src,attr"`
+					ClipBegin string `xml:"clipBegin,attr"`
+					ClipEnd   string `xml:"clipEnd,attr"`
+				} `xml:"audio"`
+			} `xml:"body>seq>par"`
+		}
+		if err := xml.Unmarshal([]byte(files["OEBPS/smil/chapter_002.smil"]), &smil); err != nil {
+			t.Fatalf("Failed to parse media overlay: %v", err)
+		}
+		if len(smil.Pars) != 3 {
+			t.Fatalf("Expected 3 pars, got %d", len(smil.Pars))
+		}
+		first, last := smil.Pars[0], smil.Pars[2]
+		if first.Text.Src != "../text/chapter_002.xhtml#seg_00001" || first.Audio.Src != "../audio/seg_00001.wav" || first.Audio.ClipEnd != "0:00:01.500" {
+			t.Errorf("Unexpected first par: %+v", first)
+		}
+		if last.Audio.ClipEnd != "0:00:01.250" {
+			t.Errorf("Expected clip end from timestamps, got %q", last.Audio.ClipEnd)
+		}
+	})
+
+	t.Run("Requires synthesis", func(t *testing.T) {
+		repo.SaveBook(ctx, &types.Book{ID: "book_epub_pending", Status: "segmenting"})
+		if _, err := service.PackageEPUB(ctx, "book_epub_pending"); !errors.Is(err, ErrBookNotSynthesized) {
+			t.Errorf("Expected ErrBookNotSynthesized, got %v", err)
+		}
+	})
+}
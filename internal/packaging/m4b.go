@@ -0,0 +1,253 @@
+package packaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/audio"
+)
+
+// wavHeaderProbeSize is how much of a WAV file is read to find its PCM data
+const wavHeaderProbeSize = 512
+
+// DetectEncoder returns the path of a local ffmpeg binary, or "" when none
+// is installed
+func DetectEncoder() string {
+	path, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// SetEncoder sets the ffmpeg binary used to compress M4B audio to AAC. Without
+// one, M4B files hold uncompressed PCM audio.
+func (s *Service) SetEncoder(path string) {
+	s.encoderPath = path
+}
+
+// M4BBook is an audiobook ready to be written as one M4B file
+type M4BBook struct {
+	io.Reader
+	size    int64
+	parts   []*storageRange
+	cleanup func()
+}
+
+// Size returns the size of the file in bytes
+func (b *M4BBook) Size() int64 {
+	return b.size
+}
+
+// Close releases storage reads and temporary files held by the book
+func (b *M4BBook) Close() error {
+	for _, part := range b.parts {
+		part.Close()
+	}
+	if b.cleanup != nil {
+		b.cleanup()
+	}
+	return nil
+}
+
+// PackageM4B builds an M4B audiobook of a synthesized book. Chapters follow
+// the TOC and hold each chapter's joined audio, with the given silence
+// between segments. The book's title, author and JPEG or PNG cover are
+// stored as iTunes metadata.
+func (s *Service) PackageM4B(ctx context.Context, bookID string, silence time.Duration) (*M4BBook, error) {
+	book, err := s.bookRepo.GetBook(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+	if book.Status != "synthesized" {
+		return nil, fmt.Errorf("%w (status: %s)", ErrBookNotSynthesized, book.Status)
+	}
+
+	segments, err := s.bookRepo.ListSegments(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments: %w", err)
+	}
+	chapterList, err := s.bookRepo.ListChapters(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chapters: %w", err)
+	}
+	toc := s.generateTOC(chapterList, segments)
+
+	var fmtChunk []byte
+	var chapters []audio.M4BChapter
+	var parts []*storageRange
+	for i, chapter := range toc.Chapters {
+		if len(chapter.Segments) == 0 {
+			continue
+		}
+		_, audioPath, err := s.ChapterAudio(ctx, bookID, chapter.ID, silence)
+		if err != nil {
+			return nil, err
+		}
+		header, err := s.readWAVHeader(ctx, audioPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: chapter %s: %v", ErrChapterAudioFormat, chapter.ID, err)
+		}
+		if fmtChunk == nil {
+			fmtChunk = header.FmtChunk
+		} else if !bytes.Equal(fmtChunk, header.FmtChunk) {
+			return nil, fmt.Errorf("%w: chapter %s has a different WAV format", ErrChapterAudioFormat, chapter.ID)
+		}
+
+		chapters = append(chapters, audio.M4BChapter{Title: tocChapterTitle(chapter, i), DataSize: header.DataSize})
+		parts = append(parts, &storageRange{ctx: ctx, s: s, path: audioPath, offset: header.DataOffset, length: header.DataSize})
+	}
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("%w: book has no segments", ErrChapterAudioIncomplete)
+	}
+
+	meta := audio.M4BMetadata{Title: book.Title, Author: book.Author}
+	if cover, ext, err := s.bookRepo.GetCover(ctx, bookID); err == nil {
+		meta.Cover, meta.CoverFormat = cover, ext
+	}
+
+	layout, err := audio.MuxM4B(fmtChunk, chapters, meta)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrChapterAudioFormat, err)
+	}
+
+	readers := []io.Reader{bytes.NewReader(layout.Header)}
+	var dataSize int64
+	for i, part := range parts {
+		readers = append(readers, part)
+		dataSize += chapters[i].DataSize
+	}
+	readers = append(readers, bytes.NewReader(layout.Trailer))
+	m4b := &M4BBook{Reader: io.MultiReader(readers...), size: layout.Size(dataSize), parts: parts}
+
+	if s.encoderPath == "" {
+		return m4b, nil
+	}
+	defer m4b.Close()
+	return s.encodeM4B(ctx, m4b)
+}
+
+// encodeM4B compresses the PCM audio of an M4B file to AAC with ffmpeg,
+// keeping its chapters, metadata and cover
+func (s *Service) encodeM4B(ctx context.Context, pcm io.Reader) (*M4BBook, error) {
+	dir, err := os.MkdirTemp("", "twelvereader-m4b-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	input := filepath.Join(dir, "pcm.m4b")
+	output := filepath.Join(dir, "aac.m4b")
+	file, err := os.Create(input)
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	_, err = io.Copy(file, pcm)
+	file.Close()
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to write pcm audio: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, s.encoderPath,
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-i", input,
+		"-map", "0:a", "-map", "0:v?", "-map_metadata", "0", "-map_chapters", "0",
+		"-c:a", "aac", "-b:a", "64k", "-c:v", "copy", "-disposition:v", "attached_pic",
+		"-movflags", "+faststart", "-f", "ipod", output,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to encode m4b: %w: %s", err, bytes.TrimSpace(out))
+	}
+
+	encoded, err := os.Open(output)
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to open encoded m4b: %w", err)
+	}
+	info, err := encoded.Stat()
+	if err != nil {
+		encoded.Close()
+		cleanup()
+		return nil, fmt.Errorf("failed to stat encoded m4b: %w", err)
+	}
+	log.Printf("[encodeM4B] Encoded %d bytes of AAC audio", info.Size())
+	return &M4BBook{Reader: encoded, size: info.Size(), cleanup: func() {
+		encoded.Close()
+		cleanup()
+	}}, nil
+}
+
+// readWAVHeader parses the header of the WAV file at path
+func (s *Service) readWAVHeader(ctx context.Context, path string) (*audio.WAVHeader, error) {
+	reader, err := s.storage.GetRange(ctx, path, 0, wavHeaderProbeSize)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return audio.ParseWAVHeader(data)
+}
+
+// tocChapterTitle returns the display title of the i-th TOC chapter
+func tocChapterTitle(chapter TOCChapter, i int) string {
+	if chapter.Title != "" {
+		return chapter.Title
+	}
+	if len(chapter.TOCPath) > 0 {
+		return chapter.TOCPath[len(chapter.TOCPath)-1]
+	}
+	return fmt.Sprintf("Chapter %d", i+1)
+}
+
+// storageRange reads a byte range of stored data, opening the read on first use
+type storageRange struct {
+	ctx    context.Context
+	s      *Service
+	path   string
+	offset int64
+	length int64
+	reader io.ReadCloser
+	done   bool
+}
+
+func (r *storageRange) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, io.EOF
+	}
+	if r.reader == nil {
+		reader, err := r.s.storage.GetRange(r.ctx, r.path, r.offset, r.length)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", r.path, err)
+		}
+		r.reader = reader
+	}
+	n, err := r.reader.Read(p)
+	if err == io.EOF {
+		r.done = true
+		r.Close()
+	}
+	return n, err
+}
+
+// Close closes the open read, if any
+func (r *storageRange) Close() error {
+	if r.reader == nil {
+		return nil
+	}
+	err := r.reader.Close()
+	r.reader = nil
+	return err
+}
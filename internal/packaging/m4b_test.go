@@ -0,0 +1,82 @@
+package packaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestService_PackageM4B(t *testing.T) {
+	ctx := context.Background()
+	storageAdapter, err := storage.NewLocalAdapter(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage adapter: %v", err)
+	}
+	defer storageAdapter.Close()
+	repo := book.NewRepository(storageAdapter)
+	service := NewService(repo, storageAdapter)
+
+	testBook := &types.Book{ID: "book_m4b", Title: "Audio Book", Author: "Test Author", Status: "synthesized"}
+	repo.SaveBook(ctx, testBook)
+	repo.SaveCover(ctx, "book_m4b", []byte("PNGDATA"), "png")
+	repo.SaveChapter(ctx, &types.Chapter{ID: "chapter_001", BookID: "book_m4b", Number: 1, Title: "The Beginning"})
+	repo.SaveChapter(ctx, &types.Chapter{ID: "chapter_002", BookID: "book_m4b", Number: 2, TOCPath: []string{"Part One", "The End"}})
+	repo.SaveChapter(ctx, &types.Chapter{ID: "chapter_003", BookID: "book_m4b", Number: 3, Title: "Empty"})
+	for _, seg := range []*types.Segment{
+		{ID: "seg_001", BookID: "book_m4b", Chapter: "chapter_001", Text: "First."},
+		{ID: "seg_002", BookID: "book_m4b", Chapter: "chapter_001", Text: "Second."},
+		{ID: "seg_003", BookID: "book_m4b", Chapter: "chapter_002", Text: "Third."},
+	} {
+		repo.SaveSegment(ctx, seg)
+	}
+	storageAdapter.Put(ctx, "books/book_m4b/audio/seg_001.wav", bytes.NewReader(testSegmentWAV(500)))
+	storageAdapter.Put(ctx, "books/book_m4b/audio/seg_002.wav", bytes.NewReader(testSegmentWAV(250)))
+	storageAdapter.Put(ctx, "books/book_m4b/audio/seg_003.wav", bytes.NewReader(testSegmentWAV(100)))
+
+	m4b, err := service.PackageM4B(ctx, "book_m4b", 0)
+	if err != nil {
+		t.Fatalf("PackageM4B failed: %v", err)
+	}
+	data, err := io.ReadAll(m4b)
+	m4b.Close()
+	if err != nil {
+		t.Fatalf("Failed to read M4B: %v", err)
+	}
+
+	if int64(len(data)) != m4b.Size() {
+		t.Errorf("Expected %d bytes, got %d", m4b.Size(), len(data))
+	}
+	if len(data) < 12 || string(data[4:12]) != "ftypM4B " {
+		t.Fatalf("Expected an M4B file")
+	}
+	// 850ms of 16-bit mono audio at 1000 Hz
+	if !bytes.Contains(data, bytes.Repeat([]byte{1}, 1700)) {
+		t.Error("Expected the segment audio in the file")
+	}
+	for _, want := range []string{"The Beginning", "The End", "Audio Book", "Test Author", "PNGDATA"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("Expected %q in the file", want)
+		}
+	}
+	if bytes.Contains(data, []byte("Empty")) {
+		t.Error("Expected chapters without segments to be left out")
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		repo.SaveBook(ctx, &types.Book{ID: "book_m4b_pending", Status: "synthesizing"})
+		if _, err := service.PackageM4B(ctx, "book_m4b_pending", 0); !errors.Is(err, ErrBookNotSynthesized) {
+			t.Errorf("Expected ErrBookNotSynthesized, got %v", err)
+		}
+
+		storageAdapter.Delete(ctx, "books/book_m4b/audio/seg_003.wav")
+		if _, err := service.PackageM4B(ctx, "book_m4b", 0); !errors.Is(err, ErrChapterAudioIncomplete) {
+			t.Errorf("Expected ErrChapterAudioIncomplete, got %v", err)
+		}
+	})
+}
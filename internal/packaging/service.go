@@ -2,12 +2,13 @@ package packaging
 
 import (
 	"archive/zip"
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/unalkalkan/TwelveReader/internal/book"
@@ -16,10 +17,15 @@ import (
 	"github.com/unalkalkan/TwelveReader/pkg/types"
 )
 
-// Service handles book packaging into ZIP archives
+// ErrBookNotSynthesized is returned when a book is exported before synthesis finishes
+var ErrBookNotSynthesized = errors.New("book is not synthesized")
+
+// Service handles book packaging into ZIP archives and chapter audio files
 type Service struct {
-	bookRepo book.Repository
-	storage  storage.Adapter
+	bookRepo     book.Repository
+	storage      storage.Adapter
+	chapterLocks sync.Map // Serializes builds of each chapter's audio
+	encoderPath  string   // ffmpeg binary for AAC M4B files, if any
 }
 
 // NewService creates a new packaging service
@@ -56,8 +62,10 @@ type TOCChapter struct {
 	Duration  float64  `json:"duration_seconds"`
 }
 
-// PackageBook creates a ZIP archive for a book
-func (s *Service) PackageBook(ctx context.Context, bookID string) (io.Reader, error) {
+// PackageBook streams a ZIP archive of a book. The archive is written while
+// it is read, so it is never held in memory; the caller must read it to the
+// end or close it.
+func (s *Service) PackageBook(ctx context.Context, bookID string) (io.ReadCloser, error) {
 	// Get book metadata
 	book, err := s.bookRepo.GetBook(ctx, bookID)
 	if err != nil {
@@ -66,7 +74,7 @@ func (s *Service) PackageBook(ctx context.Context, bookID string) (io.Reader, er
 
 	// Check if book is synthesized
 	if book.Status != "synthesized" {
-		return nil, fmt.Errorf("book is not synthesized (status: %s)", book.Status)
+		return nil, fmt.Errorf("%w (status: %s)", ErrBookNotSynthesized, book.Status)
 	}
 
 	// Get segments
@@ -87,68 +95,80 @@ func (s *Service) PackageBook(ctx context.Context, bookID string) (io.Reader, er
 		return nil, fmt.Errorf("failed to get voice map: %w", err)
 	}
 
-	// Create ZIP in memory
-	buf := new(bytes.Buffer)
-	zipWriter := zip.NewWriter(buf)
-
-	// Generate manifest
-	manifest := s.generateManifest(book, segments)
-	if err := s.addJSONFile(zipWriter, "manifest.json", manifest); err != nil {
-		return nil, fmt.Errorf("failed to add manifest: %w", err)
-	}
+	return streamZip(func(zipWriter *zip.Writer) error {
+		// Generate manifest
+		manifest := s.generateManifest(book, segments)
+		if err := s.addJSONFile(zipWriter, "manifest.json", manifest); err != nil {
+			return fmt.Errorf("failed to add manifest: %w", err)
+		}
 
-	// Generate TOC
-	toc := s.generateTOC(chapters, segments)
-	if err := s.addJSONFile(zipWriter, "toc.json", toc); err != nil {
-		return nil, fmt.Errorf("failed to add toc: %w", err)
-	}
+		// Generate TOC
+		toc := s.generateTOC(chapters, segments)
+		if err := s.addJSONFile(zipWriter, "toc.json", toc); err != nil {
+			return fmt.Errorf("failed to add toc: %w", err)
+		}
 
-	// Add voice map
-	if err := s.addJSONFile(zipWriter, "voice-map.json", voiceMap); err != nil {
-		return nil, fmt.Errorf("failed to add voice-map: %w", err)
-	}
+		// Add voice map
+		if err := s.addJSONFile(zipWriter, "voice-map.json", voiceMap); err != nil {
+			return fmt.Errorf("failed to add voice-map: %w", err)
+		}
 
-	// Add segments (metadata + audio)
-	for i, segment := range segments {
-		// Shard segments into directories (100 per folder)
-		shardDir := fmt.Sprintf("segments/%03d", i/100)
+		// Add segments (metadata + audio)
+		for i, segment := range segments {
+			// Shard segments into directories (100 per folder)
+			shardDir := fmt.Sprintf("segments/%03d", i/100)
 
-		// Add segment metadata
-		metadataPath := filepath.Join(shardDir, fmt.Sprintf("%s.json", segment.ID))
-		if err := s.addJSONFile(zipWriter, metadataPath, segment); err != nil {
-			return nil, fmt.Errorf("failed to add segment metadata %s: %w", segment.ID, err)
-		}
+			// Add segment metadata
+			metadataPath := filepath.Join(shardDir, fmt.Sprintf("%s.json", segment.ID))
+			if err := s.addJSONFile(zipWriter, metadataPath, segment); err != nil {
+				return fmt.Errorf("failed to add segment metadata %s: %w", segment.ID, err)
+			}
 
-		// Add audio file if it exists
-		var audioPath string
-		var audioReader io.ReadCloser
-		var err error
+			// Add audio file if it exists
+			var audioPath string
+			var audioReader io.ReadCloser
+			var err error
+
+			// Try different audio formats
+			for _, format := range util.AudioFormats() {
+				audioPath = util.GetAudioPath(bookID, segment.ID, format)
+				audioReader, err = s.storage.Get(ctx, audioPath)
+				if err == nil {
+					break
+				}
+			}
 
-		// Try different audio formats
-		for _, format := range util.AudioFormats() {
-			audioPath = util.GetAudioPath(bookID, segment.ID, format)
-			audioReader, err = s.storage.Get(ctx, audioPath)
 			if err == nil {
-				break
+				audioZipPath := filepath.Join(shardDir, filepath.Base(audioPath))
+				if err := s.addFileFromReader(zipWriter, audioZipPath, audioReader); err != nil {
+					audioReader.Close()
+					return fmt.Errorf("failed to add audio %s: %w", segment.ID, err)
+				}
+				audioReader.Close()
 			}
 		}
 
+		return nil
+	}), nil
+}
+
+// streamZip returns a reader of the ZIP archive that write produces. write
+// runs in its own goroutine and blocks until the archive is read; an error
+// from it ends the reader with that error. Closing the reader early makes the
+// remaining writes fail, which stops write.
+func streamZip(write func(zipWriter *zip.Writer) error) io.ReadCloser {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		zipWriter := zip.NewWriter(pipeWriter)
+		err := write(zipWriter)
 		if err == nil {
-			audioZipPath := filepath.Join(shardDir, filepath.Base(audioPath))
-			if err := s.addFileFromReader(zipWriter, audioZipPath, audioReader); err != nil {
-				audioReader.Close()
-				return nil, fmt.Errorf("failed to add audio %s: %w", segment.ID, err)
+			if closeErr := zipWriter.Close(); closeErr != nil {
+				err = fmt.Errorf("failed to close zip: %w", closeErr)
 			}
-			audioReader.Close()
 		}
-	}
-
-	// Close ZIP writer
-	if err := zipWriter.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close zip: %w", err)
-	}
-
-	return bytes.NewReader(buf.Bytes()), nil
+		pipeWriter.CloseWithError(err)
+	}()
+	return pipeReader
 }
 
 // generateManifest creates the manifest file
@@ -156,10 +176,7 @@ func (s *Service) generateManifest(book *types.Book, segments []*types.Segment)
 	// Calculate total duration
 	var totalDuration float64
 	for _, seg := range segments {
-		if seg.Timestamps != nil && len(seg.Timestamps.Items) > 0 {
-			lastItem := seg.Timestamps.Items[len(seg.Timestamps.Items)-1]
-			totalDuration += lastItem.End
-		}
+		totalDuration += segmentDuration(seg)
 	}
 
 	return &Manifest{
@@ -194,10 +211,7 @@ func (s *Service) generateTOC(chapters []*types.Chapter, segments []*types.Segme
 
 		for i, seg := range chapterSegs {
 			segIDs[i] = seg.ID
-			if seg.Timestamps != nil && len(seg.Timestamps.Items) > 0 {
-				lastItem := seg.Timestamps.Items[len(seg.Timestamps.Items)-1]
-				chapterDuration += lastItem.End
-			}
+			chapterDuration += segmentDuration(seg)
 		}
 
 		tocChapter := TOCChapter{
@@ -216,6 +230,18 @@ func (s *Service) generateTOC(chapters []*types.Chapter, segments []*types.Segme
 	return toc
 }
 
+// segmentDuration returns the length of a segment's audio in seconds: the
+// duration measured at synthesis, or else the end of its last timestamp
+func segmentDuration(seg *types.Segment) float64 {
+	if seg.AudioDuration > 0 {
+		return seg.AudioDuration
+	}
+	if seg.Timestamps != nil && len(seg.Timestamps.Items) > 0 {
+		return seg.Timestamps.Items[len(seg.Timestamps.Items)-1].End
+	}
+	return 0
+}
+
 // addJSONFile adds a JSON file to the ZIP
 func (s *Service) addJSONFile(zipWriter *zip.Writer, path string, data interface{}) error {
 	jsonData, err := json.MarshalIndent(data, "", "  ")
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"testing"
 	"time"
@@ -140,6 +141,8 @@ func TestService_PackageBook(t *testing.T) {
 		t.Fatalf("Failed to package book: %v", err)
 	}
 
+	defer zipReader.Close()
+
 	// Read ZIP into memory
 	zipData, err := io.ReadAll(zipReader)
 	if err != nil {
@@ -240,8 +243,79 @@ func TestService_PackageBook_NotSynthesized(t *testing.T) {
 
 	// Try to package book - should fail
 	_, pkgErr := service.PackageBook(ctx, "book_pkg_002")
-	if pkgErr == nil {
-		t.Fatal("Expected error when packaging non-synthesized book")
+	if !errors.Is(pkgErr, ErrBookNotSynthesized) {
+		t.Fatalf("Expected ErrBookNotSynthesized, got %v", pkgErr)
+	}
+}
+
+func TestStreamZip(t *testing.T) {
+	t.Run("Write errors end the stream", func(t *testing.T) {
+		reader := streamZip(func(zipWriter *zip.Writer) error {
+			if _, err := zipWriter.Create("partial.txt"); err != nil {
+				return err
+			}
+			return errors.New("storage unavailable")
+		})
+		defer reader.Close()
+		if _, err := io.ReadAll(reader); err == nil || err.Error() != "storage unavailable" {
+			t.Errorf("Expected the write error from Read, got %v", err)
+		}
+	})
+
+	t.Run("Closing early stops the writer", func(t *testing.T) {
+		done := make(chan error, 1)
+		reader := streamZip(func(zipWriter *zip.Writer) error {
+			writer, err := zipWriter.Create("large.bin")
+			if err != nil {
+				return err
+			}
+			chunk := bytes.Repeat([]byte{0xAB}, 64<<10)
+			for {
+				if _, err := writer.Write(chunk); err != nil {
+					done <- err
+					return err
+				}
+				// Compressed output reaches the pipe only when flushed
+				if err := zipWriter.Flush(); err != nil {
+					done <- err
+					return err
+				}
+			}
+		})
+		buf := make([]byte, 1024)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			t.Fatalf("Failed to read start of archive: %v", err)
+		}
+		reader.Close()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, io.ErrClosedPipe) {
+				t.Errorf("Expected io.ErrClosedPipe, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the writer to stop after the reader closed")
+		}
+	})
+}
+
+func TestSegmentDuration(t *testing.T) {
+	timestamps := &types.TimestampData{Items: []types.TimestampItem{{Word: "Hi", Start: 0, End: 0.4}}}
+	cases := []struct {
+		name    string
+		segment *types.Segment
+		want    float64
+	}{
+		{"Measured audio", &types.Segment{AudioDuration: 1.5, Timestamps: timestamps}, 1.5},
+		{"Timestamps only", &types.Segment{Timestamps: timestamps}, 0.4},
+		{"Unknown", &types.Segment{}, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := segmentDuration(tc.segment); got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
 	}
 }
 
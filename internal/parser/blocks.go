@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// defaultChapterTitle names content that appears before the first heading
+const defaultChapterTitle = "Main Content"
+
+// documentBlock is a paragraph or heading extracted from a structured document
+type documentBlock struct {
+	text  string
+	level int // Heading level starting at 1, or 0 for body text
+}
+
+// buildChaptersFromBlocks groups blocks into chapters. The two outermost
+// heading levels present start chapters and form the TOC path; deeper
+// headings are kept as paragraphs. Documents without heading markup fall back
+// to the plain text heading heuristics.
+func buildChaptersFromBlocks(blocks []documentBlock) []*types.Chapter {
+	splitLevels := headingLevels(blocks)
+	if len(splitLevels) == 0 {
+		blocks = detectHeadingBlocks(blocks)
+		splitLevels = headingLevels(blocks)
+	}
+	if len(splitLevels) > 2 {
+		splitLevels = splitLevels[:2]
+	}
+
+	chapters := make([]*types.Chapter, 0)
+	var tocPath []string
+	var current *types.Chapter
+
+	for _, block := range blocks {
+		depth := sort.SearchInts(splitLevels, block.level)
+		if block.level > 0 && depth < len(splitLevels) && splitLevels[depth] == block.level {
+			if depth > len(tocPath) {
+				depth = len(tocPath)
+			}
+			tocPath = append(tocPath[:depth:depth], block.text)
+			current = nil
+			continue
+		}
+
+		if current == nil {
+			chapterNum := len(chapters) + 1
+			path := append([]string(nil), tocPath...)
+			if len(path) == 0 {
+				path = []string{defaultChapterTitle}
+			}
+			current = &types.Chapter{
+				ID:         fmt.Sprintf("chapter_%03d", chapterNum),
+				Number:     chapterNum,
+				Title:      path[len(path)-1],
+				TOCPath:    path,
+				Paragraphs: make([]string, 0),
+			}
+			chapters = append(chapters, current)
+		}
+		current.Paragraphs = append(current.Paragraphs, block.text)
+	}
+
+	return chapters
+}
+
+// headingLevels returns the distinct heading levels used, outermost first
+func headingLevels(blocks []documentBlock) []int {
+	seen := make(map[int]bool)
+	var levels []int
+	for _, block := range blocks {
+		if block.level > 0 && !seen[block.level] {
+			seen[block.level] = true
+			levels = append(levels, block.level)
+		}
+	}
+	sort.Ints(levels)
+	return levels
+}
+
+// detectHeadingBlocks marks body paragraphs that read like chapter headings
+func detectHeadingBlocks(blocks []documentBlock) []documentBlock {
+	detected := make([]documentBlock, len(blocks))
+	for i, block := range blocks {
+		detected[i] = block
+		if isChapterHeading(block.text) {
+			detected[i].level = 1
+		}
+	}
+	return detected
+}
@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	// SniffLen is the amount of leading content DetectMIMEType needs to
+	// identify a document when the whole file is not at hand
+	SniffLen = 64 << 10
+
+	// detectPDFHeaderWindow is how far into a file a %PDF header is accepted;
+	// readers tolerate leading garbage before it
+	detectPDFHeaderWindow = 1024
+
+	// detectSniffLen is the amount of text inspected for markup signatures
+	detectSniffLen = 4096
+
+	mimeOctetStream = "application/octet-stream"
+	mimeZip         = "application/zip"
+	mimeDOCX        = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	mimeFB2         = "application/x-fictionbook+xml"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// DetectMIMEType sniffs the media type of a document from its magic bytes:
+// %PDF headers, ZIP containers (EPUB by their mimetype entry, DOCX by
+// word/document.xml), FictionBook and HTML markup, and UTF-8/UTF-16 text with
+// or without a byte order mark. Unrecognised binary data yields
+// "application/octet-stream". The data may be just the first SniffLen bytes of
+// a file.
+func DetectMIMEType(data []byte) string {
+	if len(data) == 0 {
+		return mimeOctetStream
+	}
+
+	head := data
+	if len(head) > detectPDFHeaderWindow {
+		head = head[:detectPDFHeaderWindow]
+	}
+	if bytes.Contains(head, []byte("%PDF-")) {
+		return "application/pdf"
+	}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return detectZipMIMEType(data)
+	}
+
+	hasBOM := bytes.HasPrefix(data, bomUTF8) || bytes.HasPrefix(data, bomUTF16LE) || bytes.HasPrefix(data, bomUTF16BE)
+	sample := data
+	if len(sample) > detectSniffLen {
+		sample = sample[:detectSniffLen]
+	}
+	text := decodeText(sample)
+
+	trimmed := strings.ToLower(strings.TrimSpace(string(text)))
+	if (strings.HasPrefix(trimmed, "<?xml") || strings.HasPrefix(trimmed, "<fictionbook")) && strings.Contains(trimmed, "<fictionbook") {
+		return mimeFB2
+	}
+
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(text))
+	switch {
+	case detected == "text/html":
+		return "text/html"
+	case detected == "text/xml":
+		if strings.Contains(trimmed, "<html") {
+			return "application/xhtml+xml"
+		}
+		return "text/xml"
+	case strings.HasPrefix(detected, "text/"), hasBOM && utf8.Valid(text):
+		return "text/plain"
+	}
+	return mimeOctetStream
+}
+
+// IsTextMIMEType reports whether documents of a media type are text, as
+// opposed to binary containers such as PDF, EPUB and DOCX
+func IsTextMIMEType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = strings.ToLower(mimeType)
+	}
+	return strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+xml") || mediaType == "application/xml"
+}
+
+// detectZipMIMEType identifies ZIP based document formats by their entries
+func detectZipMIMEType(data []byte) string {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		// Truncated archives have no central directory to read
+		return scanZipLocalHeaders(data)
+	}
+	for _, f := range r.File {
+		switch f.Name {
+		case "mimetype":
+			rc, err := f.Open()
+			if err != nil {
+				continue
+			}
+			declared, _ := io.ReadAll(io.LimitReader(rc, 256))
+			rc.Close()
+			if mimeType := strings.TrimSpace(string(declared)); mimeType != "" {
+				return mimeType
+			}
+		case "word/document.xml":
+			return mimeDOCX
+		}
+	}
+	return mimeZip
+}
+
+// scanZipLocalHeaders identifies a ZIP based format from the local file
+// headers at the start of an archive. EPUB requires the mimetype entry to come
+// first and be stored uncompressed, so its content can be read directly.
+func scanZipLocalHeaders(data []byte) string {
+	signature := []byte("PK\x03\x04")
+	offset := 0
+	for offset+30 <= len(data) && bytes.Equal(data[offset:offset+4], signature) {
+		flags := binary.LittleEndian.Uint16(data[offset+6:])
+		method := binary.LittleEndian.Uint16(data[offset+8:])
+		compressedSize := int(binary.LittleEndian.Uint32(data[offset+18:]))
+		nameLen := int(binary.LittleEndian.Uint16(data[offset+26:]))
+		extraLen := int(binary.LittleEndian.Uint16(data[offset+28:]))
+		dataStart := offset + 30 + nameLen + extraLen
+		if dataStart > len(data) {
+			break
+		}
+
+		name := string(data[offset+30 : offset+30+nameLen])
+		switch {
+		case name == "mimetype" && method == zip.Store:
+			content := data[dataStart:min(len(data), dataStart+256)]
+			if flags&0x8 == 0 {
+				content = content[:min(len(content), compressedSize)]
+			} else if end := bytes.Index(content, []byte("PK")); end >= 0 {
+				// The data descriptor follows the content
+				content = content[:end]
+			}
+			if mimeType := strings.TrimSpace(string(content)); mimeType != "" {
+				return mimeType
+			}
+		case strings.HasPrefix(name, "word/"):
+			return mimeDOCX
+		}
+
+		if flags&0x8 == 0 {
+			offset = dataStart + compressedSize
+			continue
+		}
+		// Sizes follow the data in a descriptor; skip to the next header
+		next := bytes.Index(data[dataStart:], signature)
+		if next < 0 {
+			break
+		}
+		offset = dataStart + next
+	}
+	return mimeZip
+}
+
+// decodeText returns text as UTF-8, removing a UTF-8 byte order mark and
+// converting UTF-16 text marked with a byte order mark
+func decodeText(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return data[len(bomUTF8):]
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[len(bomUTF16LE):], false)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[len(bomUTF16BE):], true)
+	}
+	return data
+}
+
+func decodeUTF16(data []byte, bigEndian bool) []byte {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	decoded := make([]byte, 0, len(data))
+	for _, r := range utf16.Decode(units) {
+		decoded = utf8.AppendRune(decoded, r)
+	}
+	return decoded
+}
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func utf16Bytes(s string, bigEndian bool) []byte {
+	data := []byte{0xFF, 0xFE}
+	if bigEndian {
+		data = []byte{0xFE, 0xFF}
+	}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			data = append(data, byte(unit>>8), byte(unit))
+		} else {
+			data = append(data, byte(unit), byte(unit>>8))
+		}
+	}
+	return data
+}
+
+func TestDetectMIMEType(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"PDF", []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n1 0 obj"), "application/pdf"},
+		{"PDF after junk", append([]byte("garbage\r\n"), []byte("%PDF-1.4")...), "application/pdf"},
+		{"EPUB", createEpubZip(map[string]string{"mimetype": "application/epub+zip"}), "application/epub+zip"},
+		{"DOCX", createEpubZip(map[string]string{"word/document.xml": "<w:document/>"}), mimeDOCX},
+		{"Plain ZIP", createEpubZip(map[string]string{"notes.txt": "hi"}), "application/zip"},
+		{"FB2", []byte(`<?xml version="1.0" encoding="utf-8"?><FictionBook xmlns="http://www.gribuser.ru/xml/fictionbook/2.0">`), mimeFB2},
+		{"HTML", []byte("<!DOCTYPE html><html><body>Hi</body></html>"), "text/html"},
+		{"Plain text", []byte("Once upon a time."), "text/plain"},
+		{"UTF-8 BOM", []byte("\xEF\xBB\xBFOnce upon a time."), "text/plain"},
+		{"UTF-16LE BOM", utf16Bytes("Once upon a time.", false), "text/plain"},
+		{"UTF-16BE BOM", utf16Bytes("Once upon a time.", true), "text/plain"},
+		{"UTF-16 HTML", utf16Bytes("<html><body>Hi</body></html>", false), "text/html"},
+		{"Binary", []byte{0x00, 0x01, 0x02, 0x03, 0xFF, 0x00}, "application/octet-stream"},
+		{"Empty", nil, "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectMIMEType(tt.data); got != tt.expected {
+				t.Errorf("DetectMIMEType() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetectMIMEType_TruncatedZip(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	header := &zip.FileHeader{Name: "mimetype", Method: zip.Store}
+	f, _ := w.CreateHeader(header)
+	f.Write([]byte("application/epub+zip"))
+	f, _ = w.Create("OEBPS/chapter1.xhtml")
+	f.Write(bytes.Repeat([]byte("<p>Lorem ipsum dolor sit amet.</p>"), 4096))
+	w.Close()
+	epub := buf.Bytes()
+
+	docx := createEpubZip(map[string]string{
+		"[Content_Types].xml": "<Types/>",
+		"word/document.xml":   strings.Repeat("<w:p/>", 20000),
+	})
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"EPUB head", epub[:256], "application/epub+zip"},
+		{"DOCX head", docx[:len(docx)/2], mimeDOCX},
+		{"Signature only", []byte("PK\x03\x04"), "application/zip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectMIMEType(tt.data); got != tt.expected {
+				t.Errorf("DetectMIMEType() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsTextMIMEType(t *testing.T) {
+	for _, mimeType := range []string{"text/plain", "text/markdown; charset=utf-8", "application/x-fictionbook+xml", "application/xhtml+xml"} {
+		if !IsTextMIMEType(mimeType) {
+			t.Errorf("Expected %q to be text", mimeType)
+		}
+	}
+	for _, mimeType := range []string{"application/pdf", "application/epub+zip", mimeDOCX} {
+		if IsTextMIMEType(mimeType) {
+			t.Errorf("Expected %q not to be text", mimeType)
+		}
+	}
+}
+
+func TestTXTParser_ParseUTF16(t *testing.T) {
+	for _, bigEndian := range []bool{false, true} {
+		chapters, err := NewTXTParser().Parse(context.Background(), utf16Bytes("Première ligne.\n\nDeuxième ligne.", bigEndian))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if len(chapters[0].Paragraphs) != 2 || chapters[0].Paragraphs[0] != "Première ligne." {
+			t.Errorf("Unexpected paragraphs (big endian %v): %q", bigEndian, chapters[0].Paragraphs)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// DOCXParser parses Word documents, using heading paragraph styles as chapters
+type DOCXParser struct{}
+
+const (
+	docxMaxPartSize     = 50 << 20
+	docxDefaultDocument = "word/document.xml"
+	docxOfficeDocRel    = "/officeDocument"
+)
+
+var docxHeadingNameRe = regexp.MustCompile(`(?i)^heading\s*([1-9])$`)
+
+// NewDOCXParser creates a new DOCX parser
+func NewDOCXParser() *DOCXParser {
+	return &DOCXParser{}
+}
+
+// Parse extracts chapters from the main document part
+func (p *DOCXParser) Parse(ctx context.Context, data []byte) ([]*types.Chapter, error) {
+	return p.ParseSource(ctx, bytes.NewReader(data))
+}
+
+// ParseSource extracts chapters from a DOCX read in place
+func (p *DOCXParser) ParseSource(ctx context.Context, src Source) ([]*types.Chapter, error) {
+	r, err := openDOCX(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	documentPath := docxMainDocumentPath(r)
+	document, err := readDOCXPart(r, documentPath)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, fmt.Errorf("docx: missing %s", documentPath)
+	}
+
+	styles, err := readDOCXPart(r, path.Join(path.Dir(documentPath), "styles.xml"))
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := docxBlocks(document, parseDOCXStyles(styles).headingLevels())
+	if err != nil {
+		return nil, err
+	}
+	chapters := buildChaptersFromBlocks(blocks)
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("docx: no readable text found")
+	}
+	return chapters, nil
+}
+
+// Metadata reads the core properties, falling back to the default document
+// language declared in the styles part
+func (p *DOCXParser) Metadata(ctx context.Context, data []byte) (*Metadata, error) {
+	return p.MetadataSource(ctx, bytes.NewReader(data))
+}
+
+// MetadataSource reads the metadata of a DOCX read in place
+func (p *DOCXParser) MetadataSource(ctx context.Context, src Source) (*Metadata, error) {
+	r, err := openDOCX(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &Metadata{}
+	core, err := readDOCXPart(r, "docProps/core.xml")
+	if err != nil {
+		return nil, err
+	}
+	if core != nil {
+		var props struct {
+			Title    string `xml:"title"`
+			Creator  string `xml:"creator"`
+			Language string `xml:"language"`
+		}
+		if err := xml.Unmarshal(core, &props); err == nil {
+			metadata.Title = strings.TrimSpace(props.Title)
+			metadata.Author = strings.TrimSpace(props.Creator)
+			metadata.Language = normalizeLanguage(props.Language)
+		}
+	}
+
+	if metadata.Language == "" {
+		styles, err := readDOCXPart(r, path.Join(path.Dir(docxMainDocumentPath(r)), "styles.xml"))
+		if err != nil {
+			return nil, err
+		}
+		metadata.Language = normalizeLanguage(parseDOCXStyles(styles).DocDefaults.Lang.Val)
+	}
+	return metadata, nil
+}
+
+// SupportedFormats returns the formats this parser supports
+func (p *DOCXParser) SupportedFormats() []string {
+	return []string{"docx"}
+}
+
+// SupportedMIMETypes returns the media types this parser supports
+func (p *DOCXParser) SupportedMIMETypes() []string {
+	return []string{"application/vnd.openxmlformats-officedocument.wordprocessingml.document"}
+}
+
+// openDOCX opens the ZIP package of a DOCX document
+func openDOCX(ctx context.Context, src Source) (*zip.Reader, error) {
+	if src.Size() == 0 {
+		return nil, fmt.Errorf("docx: empty data")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r, err := zip.NewReader(src, src.Size())
+	if err != nil {
+		return nil, fmt.Errorf("docx: invalid zip: %w", err)
+	}
+	return r, nil
+}
+
+// readDOCXPart returns the content of a package part, or nil if it does not exist
+func readDOCXPart(r *zip.Reader, name string) ([]byte, error) {
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		if f.UncompressedSize64 > docxMaxPartSize {
+			return nil, fmt.Errorf("docx: part %s exceeds size limit", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("docx: failed to open %s: %w", name, err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(io.LimitReader(rc, docxMaxPartSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("docx: failed to read %s: %w", name, err)
+		}
+		if len(content) > docxMaxPartSize {
+			return nil, fmt.Errorf("docx: part %s exceeds size limit", name)
+		}
+		return content, nil
+	}
+	return nil, nil
+}
+
+// docxMainDocumentPath resolves the main document part from the package relationships
+func docxMainDocumentPath(r *zip.Reader) string {
+	rels, err := readDOCXPart(r, "_rels/.rels")
+	if err != nil || rels == nil {
+		return docxDefaultDocument
+	}
+	var relationships struct {
+		Items []struct {
+			Type   string `xml:"Type,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(rels, &relationships); err != nil {
+		return docxDefaultDocument
+	}
+	for _, rel := range relationships.Items {
+		if strings.HasSuffix(rel.Type, docxOfficeDocRel) && rel.Target != "" {
+			return strings.TrimPrefix(path.Clean("/"+rel.Target), "/")
+		}
+	}
+	return docxDefaultDocument
+}
+
+// docxStyles is the subset of the styles part needed to recognise headings
+type docxStyles struct {
+	DocDefaults struct {
+		Lang struct {
+			Val string `xml:"val,attr"`
+		} `xml:"rPrDefault>rPr>lang"`
+	} `xml:"docDefaults"`
+	Styles []docxStyle `xml:"style"`
+}
+
+type docxStyle struct {
+	Type string `xml:"type,attr"`
+	ID   string `xml:"styleId,attr"`
+	Name struct {
+		Val string `xml:"val,attr"`
+	} `xml:"name"`
+	BasedOn struct {
+		Val string `xml:"val,attr"`
+	} `xml:"basedOn"`
+	OutlineLevel *struct {
+		Val string `xml:"val,attr"`
+	} `xml:"pPr>outlineLvl"`
+}
+
+func parseDOCXStyles(content []byte) *docxStyles {
+	styles := &docxStyles{}
+	if content != nil {
+		_ = xml.Unmarshal(content, styles)
+	}
+	return styles
+}
+
+// headingLevels maps paragraph style IDs to heading levels. The Title style
+// shares level 1 with "heading 1"; outline levels are inherited via basedOn.
+// Styles missing from the map are matched by ID in docxBlocks.
+func (s *docxStyles) headingLevels() map[string]int {
+	byID := make(map[string]docxStyle, len(s.Styles))
+	for _, style := range s.Styles {
+		if style.Type == "" || style.Type == "paragraph" {
+			byID[style.ID] = style
+		}
+	}
+
+	var levelOf func(id string, depth int) int
+	levelOf = func(id string, depth int) int {
+		style, ok := byID[id]
+		if !ok || depth > 10 {
+			return docxStyleNameLevel(id)
+		}
+		if level := docxStyleNameLevel(style.Name.Val); level > 0 {
+			return level
+		}
+		if style.OutlineLevel != nil {
+			return docxOutlineLevel(style.OutlineLevel.Val)
+		}
+		if style.BasedOn.Val != "" {
+			return levelOf(style.BasedOn.Val, depth+1)
+		}
+		return 0
+	}
+
+	levels := make(map[string]int)
+	for id := range byID {
+		if level := levelOf(id, 0); level > 0 {
+			levels[id] = level
+		}
+	}
+	return levels
+}
+
+func docxStyleNameLevel(name string) int {
+	if strings.EqualFold(strings.TrimSpace(name), "title") {
+		return 1
+	}
+	if match := docxHeadingNameRe.FindStringSubmatch(strings.TrimSpace(name)); len(match) > 1 {
+		level, _ := strconv.Atoi(match[1])
+		return level
+	}
+	return 0
+}
+
+// docxOutlineLevel converts a zero-based w:outlineLvl value; 9 marks body text
+func docxOutlineLevel(value string) int {
+	level, err := strconv.Atoi(value)
+	if err != nil || level < 0 || level > 8 {
+		return 0
+	}
+	return level + 1
+}
+
+// docxBlocks walks the document body and returns one block per non-empty
+// paragraph. Text boxes nested inside a paragraph become their own blocks.
+func docxBlocks(document []byte, styleLevels map[string]int) ([]documentBlock, error) {
+	type docxParagraph struct {
+		text  strings.Builder
+		level int
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	var blocks []documentBlock
+	var stack []*docxParagraph
+	inText := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("docx: invalid document xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(stack) == 0 && t.Name.Local != "p" {
+				continue
+			}
+			switch t.Name.Local {
+			case "p":
+				stack = append(stack, &docxParagraph{})
+			case "pStyle":
+				styleID := xmlAttr(t, "val")
+				level, ok := styleLevels[styleID]
+				if !ok {
+					level = docxStyleNameLevel(styleID)
+				}
+				stack[len(stack)-1].level = level
+			case "outlineLvl":
+				stack[len(stack)-1].level = docxOutlineLevel(xmlAttr(t, "val"))
+			case "t":
+				inText = true
+			case "tab", "br", "cr":
+				stack[len(stack)-1].text.WriteString(" ")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if len(stack) == 0 {
+					continue
+				}
+				paragraph := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if text := strings.Join(strings.Fields(paragraph.text.String()), " "); text != "" {
+					blocks = append(blocks, documentBlock{text: text, level: paragraph.level})
+				}
+			}
+		case xml.CharData:
+			if inText && len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+
+	return blocks, nil
+}
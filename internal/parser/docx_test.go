@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// docxParagraph renders a WordprocessingML paragraph with an optional style
+func docxParagraph(style, text string) string {
+	props := ""
+	if style != "" {
+		props = fmt.Sprintf(`<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, style)
+	}
+	return fmt.Sprintf(`<w:p>%s<w:r><w:t xml:space="preserve">%s</w:t></w:r></w:p>`, props, text)
+}
+
+func createDocx(paragraphs []string, extra map[string]string) []byte {
+	files := map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"/>`,
+		"_rels/.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`,
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:body>` + strings.Join(paragraphs, "\n") + `<w:sectPr/></w:body></w:document>`,
+	}
+	for name, content := range extra {
+		files[name] = content
+	}
+	return createEpubZip(files)
+}
+
+func TestDOCXParser_Parse(t *testing.T) {
+	parser := NewDOCXParser()
+	ctx := context.Background()
+
+	t.Run("Heading styles", func(t *testing.T) {
+		styles := `<?xml version="1.0" encoding="UTF-8"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:style w:type="paragraph" w:styleId="Titre1"><w:name w:val="heading 1"/></w:style>
+  <w:style w:type="paragraph" w:styleId="ChapterHead"><w:name w:val="Chapter Head"/><w:basedOn w:val="Titre2"/></w:style>
+  <w:style w:type="paragraph" w:styleId="Titre2"><w:name w:val="Custom"/><w:pPr><w:outlineLvl w:val="1"/></w:pPr></w:style>
+  <w:style w:type="paragraph" w:styleId="BodyText"><w:name w:val="Body Text"/></w:style>
+</w:styles>`
+		data := createDocx([]string{
+			docxParagraph("Titre1", "Part One"),
+			docxParagraph("ChapterHead", "Chapter 1"),
+			`<w:p><w:r><w:t>It was a </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>dark</w:t></w:r><w:r><w:tab/><w:t>night.</w:t></w:r></w:p>`,
+			docxParagraph("BodyText", ""),
+			docxParagraph("Heading3", "A scene"),
+			`<w:p><w:r><w:delText>deleted</w:delText><w:t>Kept text.</w:t></w:r></w:p>`,
+			docxParagraph("Titre2", "Chapter 2"),
+			docxParagraph("", "Second chapter text."),
+		}, map[string]string{"word/styles.xml": styles})
+
+		chapters, err := parser.Parse(ctx, data)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if len(chapters) != 2 {
+			t.Fatalf("Expected 2 chapters, got %d: %+v", len(chapters), chapters)
+		}
+		if !reflect.DeepEqual(chapters[0].TOCPath, []string{"Part One", "Chapter 1"}) {
+			t.Errorf("Unexpected TOC path: %v", chapters[0].TOCPath)
+		}
+		expected := []string{"It was a dark night.", "A scene", "Kept text."}
+		if !reflect.DeepEqual(chapters[0].Paragraphs, expected) {
+			t.Errorf("Unexpected paragraphs: %q", chapters[0].Paragraphs)
+		}
+		if chapters[1].Title != "Chapter 2" || !reflect.DeepEqual(chapters[1].Paragraphs, []string{"Second chapter text."}) {
+			t.Errorf("Unexpected second chapter: %+v", chapters[1])
+		}
+	})
+
+	t.Run("Built-in style IDs without styles part", func(t *testing.T) {
+		data := createDocx([]string{
+			docxParagraph("Title", "My Book"),
+			docxParagraph("Heading1", "Introduction"),
+			docxParagraph("", "Opening text."),
+			docxParagraph("Heading1", "The End"),
+			docxParagraph("", "Closing text."),
+		}, nil)
+
+		chapters, err := parser.Parse(ctx, data)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if len(chapters) != 2 || chapters[0].Title != "Introduction" || chapters[1].Title != "The End" {
+			t.Fatalf("Unexpected chapters: %+v", chapters)
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		if _, err := parser.Parse(ctx, []byte("not a zip")); err == nil {
+			t.Error("Expected error for invalid zip")
+		}
+		if _, err := parser.Parse(ctx, createEpubZip(map[string]string{"other.xml": "<x/>"})); err == nil {
+			t.Error("Expected error for missing document part")
+		}
+	})
+}
+
+func TestDOCXParser_Metadata(t *testing.T) {
+	core := `<?xml version="1.0" encoding="UTF-8"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <dc:title>The Voyage</dc:title>
+  <dc:creator>Jane Doe</dc:creator>
+</cp:coreProperties>`
+	styles := `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:docDefaults><w:rPrDefault><w:rPr><w:lang w:val="es-ES"/></w:rPr></w:rPrDefault></w:docDefaults>
+</w:styles>`
+	data := createDocx([]string{docxParagraph("", "Text.")}, map[string]string{
+		"docProps/core.xml": core,
+		"word/styles.xml":   styles,
+	})
+
+	metadata, err := NewDOCXParser().Metadata(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	if metadata.Title != "The Voyage" || metadata.Author != "Jane Doe" || metadata.Language != "es" {
+		t.Errorf("Unexpected metadata: %#v", metadata)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"html"
 	"io"
+	"path"
 	"regexp"
 	"sort"
 	"strings"
@@ -33,6 +34,13 @@ var (
 	epubBlockCloseRe = regexp.MustCompile(`(?is)</(p|div|li|blockquote|dt|dd|td|tr|br|h[1-6])>`)
 	epubTagRe        = regexp.MustCompile(`<[^>]+>`)
 	errEPUBSizeLimit = errors.New("epub: extracted content exceeds safety limit")
+
+	// epubMediaExts are resources that never hold book text
+	epubMediaExts = map[string]bool{
+		".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".bmp": true,
+		".mp3": true, ".m4a": true, ".mp4": true, ".ogg": true, ".wav": true, ".webm": true,
+		".ttf": true, ".otf": true, ".woff": true, ".woff2": true,
+	}
 )
 
 func NewEPUBParser() *EPUBParser {
@@ -40,94 +48,33 @@ func NewEPUBParser() *EPUBParser {
 }
 
 func (p *EPUBParser) Parse(ctx context.Context, data []byte) ([]*types.Chapter, error) {
-	if len(data) == 0 {
-		return nil, fmt.Errorf("epub: empty data")
-	}
-	if err := ctx.Err(); err != nil {
-		return nil, err
-	}
+	return p.ParseSource(ctx, bytes.NewReader(data))
+}
 
-	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+// ParseSource extracts chapters from an EPUB read in place
+func (p *EPUBParser) ParseSource(ctx context.Context, src Source) ([]*types.Chapter, error) {
+	r, err := openEPUB(ctx, src)
 	if err != nil {
-		return nil, fmt.Errorf("epub: invalid zip: %w", err)
+		return nil, err
 	}
 
-	fileMap := make(map[string]string, len(r.File))
-	totalSize := int64(0)
-	for _, f := range r.File {
-		if f.UncompressedSize64 > epubMaxFileSize {
-			return nil, fmt.Errorf("%w: %s", errEPUBSizeLimit, f.Name)
-		}
-		totalSize += int64(f.UncompressedSize64)
-		if totalSize > epubMaxTotalSize {
-			return nil, errEPUBSizeLimit
-		}
-		rc, err := f.Open()
-		if err != nil {
-			continue
-		}
-		content, err := io.ReadAll(io.LimitReader(rc, epubMaxFileSize+1))
-		rc.Close()
-		if err != nil {
-			continue
-		}
-		if len(content) > epubMaxFileSize {
-			return nil, fmt.Errorf("%w: %s", errEPUBSizeLimit, f.Name)
-		}
-		fileMap[f.Name] = string(content)
+	fileMap, err := readEPUBFiles(r)
+	if err != nil {
+		return nil, err
 	}
 
 	opfPath := findOPFPath(fileMap)
 
-	opfDir := ""
-	var spine []string
-	manifest := make(map[string]string)
-
+	var pkg *epubPackage
 	if opfPath != "" {
-		opfContent, ok := fileMap[opfPath]
-		if ok {
-			if idx := strings.LastIndex(opfPath, "/"); idx >= 0 {
-				opfDir = opfPath[:idx+1]
-			}
-			spine, manifest = parseOPF(opfContent)
+		if opfContent, ok := fileMap[opfPath]; ok {
+			pkg = parseOPF(opfContent, epubDir(opfPath))
 		}
 	}
 
-	type htmlEntry struct {
-		href  string
-		path  string
-		title string
-	}
-
 	var chapters []*types.Chapter
-
-	if len(spine) > 0 {
-		for i, idref := range spine {
-			href, ok := manifest[idref]
-			if !ok {
-				continue
-			}
-			fullPath := opfDir + href
-			htmlContent, ok := fileMap[fullPath]
-			if !ok {
-				continue
-			}
-			title := extractTitle(htmlContent)
-			if title == "" {
-				title = fmt.Sprintf("Chapter %d", i+1)
-			}
-			paragraphs := extractParagraphs(htmlContent)
-			if len(paragraphs) == 0 {
-				continue
-			}
-			chapters = append(chapters, &types.Chapter{
-				ID:         fmt.Sprintf("chapter_%03d", i+1),
-				Number:     i + 1,
-				Title:      title,
-				TOCPath:    []string{title},
-				Paragraphs: paragraphs,
-			})
-		}
+	if pkg != nil && len(pkg.spine) > 0 {
+		chapters = buildEPUBChapters(pkg, fileMap)
 	}
 
 	if len(chapters) == 0 {
@@ -156,11 +103,12 @@ func (p *EPUBParser) Parse(ctx context.Context, data []byte) ([]*types.Chapter,
 				continue
 			}
 			chapters = append(chapters, &types.Chapter{
-				ID:         fmt.Sprintf("chapter_%03d", i+1),
-				Number:     i + 1,
-				Title:      title,
-				TOCPath:    []string{title},
-				Paragraphs: paragraphs,
+				ID:          fmt.Sprintf("chapter_%03d", i+1),
+				Number:      i + 1,
+				Title:       title,
+				TOCPath:     []string{title},
+				Paragraphs:  paragraphs,
+				FrontMatter: frontMatterKind(nil, title, htmlContent, path),
 			})
 		}
 	}
@@ -172,6 +120,121 @@ func (p *EPUBParser) Parse(ctx context.Context, data []byte) ([]*types.Chapter,
 	return chapters, nil
 }
 
+// Metadata reads the OPF title, author, language and cover image
+func (p *EPUBParser) Metadata(ctx context.Context, data []byte) (*Metadata, error) {
+	return p.MetadataSource(ctx, bytes.NewReader(data))
+}
+
+// MetadataSource reads the metadata of an EPUB read in place
+func (p *EPUBParser) MetadataSource(ctx context.Context, src Source) (*Metadata, error) {
+	r, err := openEPUB(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	fileMap, err := readEPUBFiles(r)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &Metadata{}
+	opfPath := findOPFPath(fileMap)
+	opfContent, ok := fileMap[opfPath]
+	if opfPath == "" || !ok {
+		return metadata, nil
+	}
+
+	pkg := parseOPF(opfContent, epubDir(opfPath))
+	metadata.Title = pkg.title
+	metadata.Author = pkg.author
+	metadata.Language = normalizeLanguage(pkg.language)
+	if pkg.coverPath != "" {
+		cover, err := readEPUBEntry(r, pkg.coverPath)
+		if err != nil {
+			return nil, err
+		}
+		if cover != nil {
+			metadata.Cover = cover
+			metadata.CoverType = pkg.coverType
+		}
+	}
+	return metadata, nil
+}
+
+// openEPUB opens the ZIP container of an EPUB
+func openEPUB(ctx context.Context, src Source) (*zip.Reader, error) {
+	if src.Size() == 0 {
+		return nil, fmt.Errorf("epub: empty data")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r, err := zip.NewReader(src, src.Size())
+	if err != nil {
+		return nil, fmt.Errorf("epub: invalid zip: %w", err)
+	}
+	return r, nil
+}
+
+// readEPUBFiles unzips the markup files of the container into a path to
+// content map, enforcing size limits. Images, audio and fonts are skipped.
+func readEPUBFiles(r *zip.Reader) (map[string]string, error) {
+	fileMap := make(map[string]string, len(r.File))
+	totalSize := int64(0)
+	for _, f := range r.File {
+		if epubMediaExts[strings.ToLower(path.Ext(f.Name))] {
+			continue
+		}
+		if f.UncompressedSize64 > epubMaxFileSize {
+			return nil, fmt.Errorf("%w: %s", errEPUBSizeLimit, f.Name)
+		}
+		totalSize += int64(f.UncompressedSize64)
+		if totalSize > epubMaxTotalSize {
+			return nil, errEPUBSizeLimit
+		}
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, epubMaxFileSize+1))
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		if len(content) > epubMaxFileSize {
+			return nil, fmt.Errorf("%w: %s", errEPUBSizeLimit, f.Name)
+		}
+		fileMap[f.Name] = string(content)
+	}
+	return fileMap, nil
+}
+
+// readEPUBEntry returns the content of a single entry, or nil if it does not exist
+func readEPUBEntry(r *zip.Reader, name string) ([]byte, error) {
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		if f.UncompressedSize64 > epubMaxFileSize {
+			return nil, fmt.Errorf("%w: %s", errEPUBSizeLimit, f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("epub: failed to open %s: %w", name, err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(io.LimitReader(rc, epubMaxFileSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("epub: failed to read %s: %w", name, err)
+		}
+		if len(content) > epubMaxFileSize {
+			return nil, fmt.Errorf("%w: %s", errEPUBSizeLimit, f.Name)
+		}
+		return content, nil
+	}
+	return nil, nil
+}
+
 func findOPFPath(fileMap map[string]string) string {
 	if containerXML, ok := fileMap["META-INF/container.xml"]; ok {
 		var container struct {
@@ -197,43 +260,236 @@ func findOPFPath(fileMap map[string]string) string {
 	return ""
 }
 
-func parseOPF(content string) (spine []string, manifest map[string]string) {
-	manifest = make(map[string]string)
+// epubPackage holds the parts of the OPF package document the parser uses.
+// All paths are full paths within the container.
+type epubPackage struct {
+	spine   []string          // Content document paths in reading order
+	navPath string            // EPUB3 navigation document
+	ncxPath string            // EPUB2 NCX table of contents
+	guide   map[string]string // EPUB2 guide reference types keyed by path
+
+	title     string
+	author    string
+	language  string
+	coverPath string
+	coverType string
+}
 
-	var pkg struct {
+func parseOPF(content, opfDir string) *epubPackage {
+	pkg := &epubPackage{guide: make(map[string]string)}
+
+	var opf struct {
+		Metadata struct {
+			Titles   []string `xml:"title"`
+			Creators []struct {
+				ID   string `xml:"id,attr"`
+				Role string `xml:"role,attr"`
+				Name string `xml:",chardata"`
+			} `xml:"creator"`
+			Languages []string `xml:"language"`
+			Metas     []struct {
+				Name     string `xml:"name,attr"`
+				Content  string `xml:"content,attr"`
+				Property string `xml:"property,attr"`
+				Refines  string `xml:"refines,attr"`
+				Value    string `xml:",chardata"`
+			} `xml:"meta"`
+		} `xml:"metadata"`
 		Manifest struct {
 			Items []struct {
-				ID        string `xml:"id,attr"`
-				Href      string `xml:"href,attr"`
-				MediaType string `xml:"media-type,attr"`
+				ID         string `xml:"id,attr"`
+				Href       string `xml:"href,attr"`
+				MediaType  string `xml:"media-type,attr"`
+				Properties string `xml:"properties,attr"`
 			} `xml:"item"`
 		} `xml:"manifest"`
 		Spine struct {
+			TOC      string `xml:"toc,attr"`
 			ItemRefs []struct {
 				IDRef string `xml:"idref,attr"`
 			} `xml:"itemref"`
 		} `xml:"spine"`
+		Guide struct {
+			References []struct {
+				Type string `xml:"type,attr"`
+				Href string `xml:"href,attr"`
+			} `xml:"reference"`
+		} `xml:"guide"`
 	}
 
-	if err := xml.Unmarshal([]byte(content), &pkg); err != nil {
-		return spine, manifest
+	if err := xml.Unmarshal([]byte(content), &opf); err != nil {
+		return pkg
+	}
+
+	// EPUB2 names the cover image item in <meta name="cover">; EPUB3 declares
+	// creator roles in <meta refines="#id" property="role">
+	coverID := ""
+	creatorRoles := make(map[string]string)
+	for _, meta := range opf.Metadata.Metas {
+		switch {
+		case meta.Name == "cover":
+			coverID = meta.Content
+		case meta.Property == "role" && strings.HasPrefix(meta.Refines, "#"):
+			creatorRoles[strings.TrimPrefix(meta.Refines, "#")] = strings.TrimSpace(meta.Value)
+		}
 	}
 
-	for _, item := range pkg.Manifest.Items {
+	for _, title := range opf.Metadata.Titles {
+		if title = strings.Join(strings.Fields(title), " "); title != "" {
+			pkg.title = title
+			break
+		}
+	}
+	var creators, authors []string
+	for _, creator := range opf.Metadata.Creators {
+		name := strings.Join(strings.Fields(creator.Name), " ")
+		if name == "" {
+			continue
+		}
+		creators = append(creators, name)
+		role := creator.Role
+		if role == "" {
+			role = creatorRoles[creator.ID]
+		}
+		if role == "aut" {
+			authors = append(authors, name)
+		}
+	}
+	if len(authors) == 0 {
+		authors = creators
+	}
+	pkg.author = strings.Join(authors, ", ")
+	if len(opf.Metadata.Languages) > 0 {
+		pkg.language = strings.TrimSpace(opf.Metadata.Languages[0])
+	}
+
+	manifest := make(map[string]string)
+	for _, item := range opf.Manifest.Items {
 		mediaType := strings.ToLower(strings.TrimSpace(strings.Split(item.MediaType, ";")[0]))
+		itemPath := resolveEPUBPath(opfDir, item.Href)
 		if item.MediaType == "" ||
 			mediaType == "application/xhtml+xml" ||
 			mediaType == "text/html" ||
 			mediaType == "application/xml" {
-			manifest[item.ID] = item.Href
+			manifest[item.ID] = itemPath
+		}
+		isImage := strings.HasPrefix(mediaType, "image/")
+		for _, property := range strings.Fields(item.Properties) {
+			if property == "nav" {
+				pkg.navPath = itemPath
+			}
+			if property == "cover-image" && isImage {
+				pkg.coverPath, pkg.coverType = itemPath, mediaType
+			}
+		}
+		if isImage && item.ID == coverID && pkg.coverPath == "" {
+			pkg.coverPath, pkg.coverType = itemPath, mediaType
+		}
+		if mediaType == "application/x-dtbncx+xml" || (opf.Spine.TOC != "" && item.ID == opf.Spine.TOC) {
+			pkg.ncxPath = itemPath
+		}
+	}
+
+	for _, ref := range opf.Spine.ItemRefs {
+		if itemPath, ok := manifest[ref.IDRef]; ok {
+			pkg.spine = append(pkg.spine, itemPath)
+		}
+	}
+
+	for _, reference := range opf.Guide.References {
+		if itemPath := resolveEPUBPath(opfDir, reference.Href); itemPath != "" {
+			pkg.guide[itemPath] = reference.Type
+		}
+	}
+
+	return pkg
+}
+
+// buildEPUBChapters turns the spine into chapters. When the book has a table
+// of contents, each spine item a TOC entry points at starts a chapter titled
+// by that entry; items without an entry of their own (a chapter split across
+// files) are merged into the preceding chapter. Without a TOC every spine
+// item becomes a chapter titled from its own markup.
+func buildEPUBChapters(pkg *epubPackage, fileMap map[string]string) []*types.Chapter {
+	var toc []epubTOCEntry
+	landmarks := map[string]string{}
+	if navContent, ok := fileMap[pkg.navPath]; ok && pkg.navPath != "" {
+		toc, landmarks = parseNavDocument(navContent, pkg.navPath)
+	}
+	if len(toc) == 0 && pkg.ncxPath != "" {
+		if ncxContent, ok := fileMap[pkg.ncxPath]; ok {
+			toc = parseNCX(ncxContent, pkg.ncxPath)
 		}
 	}
 
-	for _, ref := range pkg.Spine.ItemRefs {
-		spine = append(spine, ref.IDRef)
+	// Only the first TOC entry for a document names it; later entries usually
+	// point at fragments within the same file.
+	tocByPath := make(map[string]epubTOCEntry, len(toc))
+	for _, entry := range toc {
+		if _, exists := tocByPath[entry.path]; !exists {
+			tocByPath[entry.path] = entry
+		}
 	}
 
-	return spine, manifest
+	var chapters []*types.Chapter
+	var current *types.Chapter
+	for i, itemPath := range pkg.spine {
+		htmlContent, ok := fileMap[itemPath]
+		if !ok {
+			continue
+		}
+		paragraphs := extractParagraphs(htmlContent)
+
+		entry, hasEntry := tocByPath[itemPath]
+		title := ""
+		tocPath := []string(nil)
+		if hasEntry {
+			title = entry.tocPath[len(entry.tocPath)-1]
+			tocPath = entry.tocPath
+		} else {
+			title = extractTitle(htmlContent)
+		}
+
+		// A TOC title outranks the file name
+		docPath := itemPath
+		if hasEntry {
+			docPath = ""
+		}
+		frontMatter := frontMatterKind([]string{pkg.guide[itemPath], landmarks[itemPath]}, title, htmlContent, docPath)
+		if itemPath == pkg.navPath {
+			frontMatter = frontMatterTOC
+		}
+
+		if len(toc) > 0 && !hasEntry && current != nil && current.FrontMatter == frontMatter {
+			current.Paragraphs = append(current.Paragraphs, paragraphs...)
+			continue
+		}
+
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+		if tocPath == nil {
+			tocPath = []string{title}
+		}
+		current = &types.Chapter{
+			ID:          fmt.Sprintf("chapter_%03d", i+1),
+			Number:      i + 1,
+			Title:       title,
+			TOCPath:     tocPath,
+			Paragraphs:  paragraphs,
+			FrontMatter: frontMatter,
+		}
+		chapters = append(chapters, current)
+	}
+
+	// Drop documents without text, such as image-only cover pages
+	readable := chapters[:0]
+	for _, chapter := range chapters {
+		if len(chapter.Paragraphs) > 0 {
+			readable = append(readable, chapter)
+		}
+	}
+	return readable
 }
 
 func extractTitle(htmlContent string) string {
@@ -263,9 +519,12 @@ func extractParagraphs(htmlContent string) []string {
 		body = bodyMatch[1]
 	}
 
-	body = removeScriptStyle(body)
-	body = addBlockBreaks(body)
-	text := stripTags(body)
+	return htmlParagraphs(removeScriptStyle(body))
+}
+
+// htmlParagraphs converts an HTML fragment to plain text paragraphs
+func htmlParagraphs(fragment string) []string {
+	text := stripTags(addBlockBreaks(fragment))
 	text = html.UnescapeString(text)
 	return splitParagraphs(text)
 }
@@ -313,3 +572,8 @@ func splitParagraphs(text string) []string {
 func (p *EPUBParser) SupportedFormats() []string {
 	return []string{"epub"}
 }
+
+// SupportedMIMETypes returns the media types this parser supports
+func (p *EPUBParser) SupportedMIMETypes() []string {
+	return []string{"application/epub+zip"}
+}
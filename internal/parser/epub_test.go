@@ -595,3 +595,325 @@ func makeMinimalEpub(chapterXHTML string) []byte {
 </html>`, chapterXHTML),
 	})
 }
+
+func TestEPUBParser_Parse_NavDocumentTOC(t *testing.T) {
+	p := NewEPUBParser()
+
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="cover" href="text/cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="rights" href="text/rights.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1a" href="text/ch1a.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch1b" href="text/ch1b.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="text/ch2.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="cover"/>
+    <itemref idref="rights"/>
+    <itemref idref="nav"/>
+    <itemref idref="ch1a"/>
+    <itemref idref="ch1b"/>
+    <itemref idref="ch2"/>
+  </spine>
+</package>`
+
+	nav := `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>Contents</title></head>
+<body>
+  <nav epub:type="toc">
+    <h1>Contents</h1>
+    <ol>
+      <li><a href="text/cover.xhtml">Cover</a></li>
+      <li><span>Part I: Arrival</span>
+        <ol>
+          <li><a href="text/ch1a.xhtml#start">The Message</a></li>
+          <li><a href="text/ch2.xhtml">The Reply &amp; After</a></li>
+        </ol>
+      </li>
+    </ol>
+  </nav>
+  <nav epub:type="landmarks">
+    <ol>
+      <li><a epub:type="copyright-page" href="text/rights.xhtml">Copyright</a></li>
+    </ol>
+  </nav>
+</body>
+</html>`
+
+	page := func(title, body string) string {
+		return `<html xmlns="http://www.w3.org/1999/xhtml"><head><title>` + title + `</title></head><body>` + body + `</body></html>`
+	}
+
+	data := createEpubZip(map[string]string{
+		"META-INF/container.xml":  `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf":       opf,
+		"OEBPS/nav.xhtml":         nav,
+		"OEBPS/text/cover.xhtml":  page("Cover", `<p>The Signal</p>`),
+		"OEBPS/text/rights.xhtml": page("Rights", `<p>All rights reserved.</p>`),
+		"OEBPS/text/ch1a.xhtml":   page("Split 1", `<p>The radio crackled.</p>`),
+		"OEBPS/text/ch1b.xhtml":   page("Split 2", `<p>It was not noise.</p>`),
+		"OEBPS/text/ch2.xhtml":    page("Ignored", `<p>Sarah answered.</p>`),
+	})
+
+	chapters, err := p.Parse(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []struct {
+		title       string
+		tocPath     []string
+		paragraphs  []string
+		frontMatter string
+	}{
+		{"Cover", []string{"Cover"}, []string{"The Signal"}, "cover"},
+		{"Rights", []string{"Rights"}, []string{"All rights reserved."}, "copyright"},
+		{"Contents", []string{"Contents"}, []string{"Contents", "Cover", "Part I: Arrival", "The Message", "The Reply & After", "Copyright"}, "toc"},
+		{"The Message", []string{"Part I: Arrival", "The Message"}, []string{"The radio crackled.", "It was not noise."}, ""},
+		{"The Reply & After", []string{"Part I: Arrival", "The Reply & After"}, []string{"Sarah answered."}, ""},
+	}
+	if len(chapters) != len(want) {
+		for _, ch := range chapters {
+			t.Logf("chapter %q %v %v %q", ch.Title, ch.TOCPath, ch.Paragraphs, ch.FrontMatter)
+		}
+		t.Fatalf("Expected %d chapters, got %d", len(want), len(chapters))
+	}
+	for i, w := range want {
+		ch := chapters[i]
+		if ch.Title != w.title {
+			t.Errorf("chapter %d: expected title %q, got %q", i, w.title, ch.Title)
+		}
+		if strings.Join(ch.TOCPath, "|") != strings.Join(w.tocPath, "|") {
+			t.Errorf("chapter %d: expected TOC path %v, got %v", i, w.tocPath, ch.TOCPath)
+		}
+		if strings.Join(ch.Paragraphs, "|") != strings.Join(w.paragraphs, "|") {
+			t.Errorf("chapter %d: expected paragraphs %v, got %v", i, w.paragraphs, ch.Paragraphs)
+		}
+		if ch.FrontMatter != w.frontMatter {
+			t.Errorf("chapter %d: expected front matter %q, got %q", i, w.frontMatter, ch.FrontMatter)
+		}
+	}
+}
+
+func TestEPUBParser_Parse_NCXTOC(t *testing.T) {
+	p := NewEPUBParser()
+
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0">
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="ch1" href="Text/Chapter%201.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="Text/chapter2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="idx" href="Text/back.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine toc="ncx">
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+    <itemref idref="idx"/>
+  </spine>
+  <guide>
+    <reference type="index" title="Index" href="Text/back.xhtml"/>
+  </guide>
+</package>`
+
+	ncx := `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <navMap>
+    <navPoint id="p1" playOrder="1">
+      <navLabel><text>Book One</text></navLabel>
+      <content src="Text/Chapter%201.xhtml"/>
+      <navPoint id="p2" playOrder="2">
+        <navLabel><text>Chapter 1</text></navLabel>
+        <content src="Text/Chapter%201.xhtml#c1"/>
+      </navPoint>
+      <navPoint id="p3" playOrder="3">
+        <navLabel><text>Chapter 2</text></navLabel>
+        <content src="Text/chapter2.xhtml"/>
+      </navPoint>
+    </navPoint>
+  </navMap>
+</ncx>`
+
+	data := createEpubZip(map[string]string{
+		"OEBPS/content.opf":          opf,
+		"OEBPS/toc.ncx":              ncx,
+		"OEBPS/Text/Chapter 1.xhtml": `<html><body><p>One.</p></body></html>`,
+		"OEBPS/Text/chapter2.xhtml":  `<html><body><p>Two.</p></body></html>`,
+		"OEBPS/Text/back.xhtml":      `<html><body><p>Aliens, 1, 2</p></body></html>`,
+	})
+
+	chapters, err := p.Parse(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(chapters) != 3 {
+		t.Fatalf("Expected 3 chapters, got %d", len(chapters))
+	}
+	if chapters[0].Title != "Book One" || strings.Join(chapters[0].TOCPath, "|") != "Book One" {
+		t.Errorf("unexpected first chapter %q %v", chapters[0].Title, chapters[0].TOCPath)
+	}
+	if chapters[1].Title != "Chapter 2" || strings.Join(chapters[1].TOCPath, "|") != "Book One|Chapter 2" {
+		t.Errorf("unexpected second chapter %q %v", chapters[1].Title, chapters[1].TOCPath)
+	}
+	if chapters[2].FrontMatter != "index" {
+		t.Errorf("Expected guide index to be detected, got %q", chapters[2].FrontMatter)
+	}
+}
+
+func TestEPUBParser_Parse_CalibreSplitFiles(t *testing.T) {
+	p := NewEPUBParser()
+
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0">
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="s0" href="index_split_000.html" media-type="application/xhtml+xml"/>
+    <item id="s1" href="index_split_001.html" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine toc="ncx">
+    <itemref idref="s0"/>
+    <itemref idref="s1"/>
+  </spine>
+</package>`
+
+	ncx := `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <navMap>
+    <navPoint id="p1" playOrder="1">
+      <navLabel><text>Loomings</text></navLabel>
+      <content src="index_split_000.html"/>
+    </navPoint>
+    <navPoint id="p2" playOrder="2">
+      <navLabel><text>The Carpet-Bag</text></navLabel>
+      <content src="index_split_001.html"/>
+    </navPoint>
+  </navMap>
+</ncx>`
+
+	data := createEpubZip(map[string]string{
+		"content.opf":          opf,
+		"toc.ncx":              ncx,
+		"index_split_000.html": `<html><body><p>Call me Ishmael.</p></body></html>`,
+		"index_split_001.html": `<html><body><p>I stuffed a shirt or two.</p></body></html>`,
+	})
+
+	chapters, err := p.Parse(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(chapters) != 2 {
+		t.Fatalf("Expected 2 chapters, got %d", len(chapters))
+	}
+	for _, ch := range chapters {
+		if ch.FrontMatter != "" {
+			t.Errorf("Expected chapter %q not to be front matter, got %q", ch.Title, ch.FrontMatter)
+		}
+	}
+}
+
+func TestFrontMatterKind_FileName(t *testing.T) {
+	tests := map[string]string{
+		"text/cover.xhtml":          "cover",
+		"text/cover_01.xhtml":       "cover",
+		"text/book-copyright.xhtml": "copyright",
+		"text/copyright_page.xhtml": "copyright",
+		"toc.xhtml":                 "toc",
+		"back_index.xhtml":          "index",
+		"index_split_000.html":      "",
+		"index_split_012.html":      "",
+		"discovery.xhtml":           "",
+		"chapter_01.xhtml":          "",
+	}
+	for docPath, want := range tests {
+		if got := frontMatterKind(nil, "", "", docPath); got != want {
+			t.Errorf("%s: expected %q, got %q", docPath, want, got)
+		}
+	}
+}
+
+func TestEPUBParser_Metadata(t *testing.T) {
+	p := NewEPUBParser()
+
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>  The   Signal </dc:title>
+    <dc:creator id="ed">Jane Editor</dc:creator>
+    <dc:creator id="au">A. N. Author</dc:creator>
+    <dc:language>en-GB</dc:language>
+    <meta refines="#ed" property="role" scheme="marc:relators">edt</meta>
+    <meta refines="#au" property="role" scheme="marc:relators">aut</meta>
+  </metadata>
+  <manifest>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="img" href="images/front.jpg" media-type="image/jpeg" properties="cover-image"/>
+  </manifest>
+  <spine><itemref idref="ch1"/></spine>
+</package>`
+
+	data := createEpubZip(map[string]string{
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf":      opf,
+		"OEBPS/ch1.xhtml":        `<html><body><p>Text.</p></body></html>`,
+		"OEBPS/images/front.jpg": "\xff\xd8\xffcover",
+	})
+
+	metadata, err := p.Metadata(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	if metadata.Title != "The Signal" {
+		t.Errorf("Expected title 'The Signal', got %q", metadata.Title)
+	}
+	if metadata.Author != "A. N. Author" {
+		t.Errorf("Expected author role to win over editor, got %q", metadata.Author)
+	}
+	if metadata.Language != "en" {
+		t.Errorf("Expected language 'en', got %q", metadata.Language)
+	}
+	if string(metadata.Cover) != "\xff\xd8\xffcover" || metadata.CoverType != "image/jpeg" {
+		t.Errorf("Expected JPEG cover, got %q (%s)", metadata.Cover, metadata.CoverType)
+	}
+}
+
+func TestEPUBParser_Metadata_EPUB2Cover(t *testing.T) {
+	p := NewEPUBParser()
+
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" xmlns:opf="http://www.idpf.org/2007/opf" version="2.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Old Book</dc:title>
+    <dc:creator opf:role="aut">First Writer</dc:creator>
+    <dc:creator opf:role="aut">Second Writer</dc:creator>
+    <meta name="cover" content="cover-img"/>
+  </metadata>
+  <manifest>
+    <item id="cover-img" href="cover.png" media-type="image/png"/>
+    <item id="ch1" href="ch1.html" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="ch1"/></spine>
+</package>`
+
+	data := createEpubZip(map[string]string{
+		"content.opf": opf,
+		"ch1.html":    `<html><body><p>Text.</p></body></html>`,
+		"cover.png":   "\x89PNG",
+	})
+
+	metadata, err := p.Metadata(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	if metadata.Author != "First Writer, Second Writer" {
+		t.Errorf("Expected both authors, got %q", metadata.Author)
+	}
+	if metadata.Language != "" {
+		t.Errorf("Expected no language, got %q", metadata.Language)
+	}
+	if metadata.CoverType != "image/png" || string(metadata.Cover) != "\x89PNG" {
+		t.Errorf("Expected PNG cover from <meta name=\"cover\">, got %q (%s)", metadata.Cover, metadata.CoverType)
+	}
+}
@@ -0,0 +1,252 @@
+package parser
+
+import (
+	"encoding/xml"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Front matter kinds recorded on types.Chapter.FrontMatter
+const (
+	frontMatterCover     = "cover"
+	frontMatterCopyright = "copyright"
+	frontMatterTOC       = "toc"
+	frontMatterIndex     = "index"
+)
+
+var (
+	epubSemanticTypeRe   = regexp.MustCompile(`(?is)<(?:body|section)\b[^>]*\bepub:type\s*=\s*["']([^"']*)["']`)
+	epubFrontMatterTitle = regexp.MustCompile(`(?i)^(cover|copyright( page)?|index|(table of )?contents|toc)$`)
+	epubFrontMatterFile  = regexp.MustCompile(`(?i)(^|[-_ ])(cover|copyright|toc|index|contents)([-_ ]?(\d+|page))?$`)
+)
+
+// epubTOCEntry is a table of contents entry resolved to a content document
+type epubTOCEntry struct {
+	path    string   // Full path of the target document within the container
+	tocPath []string // Titles from the top-level entry down to this one
+}
+
+// resolveEPUBPath resolves an href relative to baseDir, dropping any fragment
+func resolveEPUBPath(baseDir, href string) string {
+	if idx := strings.IndexByte(href, '#'); idx >= 0 {
+		href = href[:idx]
+	}
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	if href == "" {
+		return ""
+	}
+	return path.Join(baseDir, href)
+}
+
+// epubDir returns the directory part of a container path ("" for the root)
+func epubDir(p string) string {
+	dir := path.Dir(p)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+// parseNavDocument reads the EPUB3 navigation document. It returns the
+// entries of the "toc" nav in document order and the landmark types keyed
+// by target path.
+func parseNavDocument(content, navPath string) ([]epubTOCEntry, map[string]string) {
+	baseDir := epubDir(navPath)
+	landmarks := make(map[string]string)
+	var entries []epubTOCEntry
+
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	mode := ""
+	var titles []string // Title of each open <li>
+	var label strings.Builder
+	labelDepth := 0
+	labelHref := ""
+	labelType := ""
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			// io.EOF or malformed markup; keep whatever was parsed so far
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if labelDepth > 0 {
+				labelDepth++
+				continue
+			}
+			switch {
+			case name == "nav":
+				mode = ""
+				for _, navType := range strings.Fields(xmlAttr(t, "type")) {
+					if navType == "toc" || navType == "landmarks" {
+						mode = navType
+					}
+				}
+				titles = titles[:0]
+			case mode != "" && name == "li":
+				titles = append(titles, "")
+			case mode != "" && (name == "a" || name == "span"):
+				labelDepth = 1
+				label.Reset()
+				labelHref = xmlAttr(t, "href")
+				labelType = xmlAttr(t, "type")
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if labelDepth > 0 {
+				labelDepth--
+				if labelDepth > 0 {
+					continue
+				}
+				title := strings.Join(strings.Fields(label.String()), " ")
+				target := ""
+				if name == "a" && labelHref != "" {
+					target = resolveEPUBPath(baseDir, labelHref)
+				}
+				switch mode {
+				case "toc":
+					if len(titles) > 0 && titles[len(titles)-1] == "" {
+						titles[len(titles)-1] = title
+					}
+					if target != "" && title != "" {
+						entries = append(entries, epubTOCEntry{path: target, tocPath: nonEmptyStrings(titles)})
+					}
+				case "landmarks":
+					if target != "" && labelType != "" {
+						if _, exists := landmarks[target]; !exists {
+							landmarks[target] = labelType
+						}
+					}
+				}
+				continue
+			}
+			switch {
+			case name == "nav":
+				mode = ""
+			case mode != "" && name == "li" && len(titles) > 0:
+				titles = titles[:len(titles)-1]
+			}
+		case xml.CharData:
+			if labelDepth > 0 {
+				label.Write(t)
+			}
+		}
+	}
+
+	return entries, landmarks
+}
+
+// ncxNavPoint is a navPoint in an EPUB2 NCX document
+type ncxNavPoint struct {
+	Label   string `xml:"navLabel>text"`
+	Content struct {
+		Src string `xml:"src,attr"`
+	} `xml:"content"`
+	Children []ncxNavPoint `xml:"navPoint"`
+}
+
+// parseNCX reads the navMap of an EPUB2 NCX document in document order
+func parseNCX(content, ncxPath string) []epubTOCEntry {
+	var ncx struct {
+		Points []ncxNavPoint `xml:"navMap>navPoint"`
+	}
+	if err := xml.Unmarshal([]byte(content), &ncx); err != nil {
+		return nil
+	}
+
+	baseDir := epubDir(ncxPath)
+	var entries []epubTOCEntry
+	var walk func(points []ncxNavPoint, parent []string)
+	walk = func(points []ncxNavPoint, parent []string) {
+		for _, point := range points {
+			title := strings.Join(strings.Fields(point.Label), " ")
+			tocPath := parent
+			if title != "" {
+				tocPath = append(append([]string(nil), parent...), title)
+				if target := resolveEPUBPath(baseDir, point.Content.Src); target != "" {
+					entries = append(entries, epubTOCEntry{path: target, tocPath: tocPath})
+				}
+			}
+			walk(point.Children, tocPath)
+		}
+	}
+	walk(ncx.Points, nil)
+	return entries
+}
+
+// frontMatterKind classifies a content document as cover, copyright, table of
+// contents or index from its declared semantics, title and file name.
+// It returns "" for narrative content. Pass an empty docPath for documents
+// titled by the TOC: generated names such as Calibre's index_split_000.html
+// say nothing about their content.
+func frontMatterKind(declaredTypes []string, title, htmlContent, docPath string) string {
+	if match := epubSemanticTypeRe.FindStringSubmatch(htmlContent); len(match) > 1 {
+		declaredTypes = append(declaredTypes, match[1])
+	}
+	for _, declared := range declaredTypes {
+		for _, semantic := range strings.Fields(strings.ToLower(declared)) {
+			if kind := frontMatterFromKeyword(semantic); kind != "" {
+				return kind
+			}
+		}
+	}
+
+	if match := epubFrontMatterTitle.FindStringSubmatch(strings.TrimSpace(title)); len(match) > 1 {
+		return frontMatterFromKeyword(strings.ToLower(strings.Fields(match[1])[0]))
+	}
+
+	if docPath == "" {
+		return ""
+	}
+	base := strings.TrimSuffix(path.Base(docPath), path.Ext(docPath))
+	if match := epubFrontMatterFile.FindStringSubmatch(base); len(match) > 2 {
+		return frontMatterFromKeyword(strings.ToLower(match[2]))
+	}
+	return ""
+}
+
+// frontMatterFromKeyword maps EPUB structural semantics and guide types to a front matter kind
+func frontMatterFromKeyword(keyword string) string {
+	switch keyword {
+	case "cover", "cover-image":
+		return frontMatterCover
+	case "copyright", "copyright-page":
+		return frontMatterCopyright
+	case "toc", "contents", "table":
+		return frontMatterTOC
+	case "index":
+		return frontMatterIndex
+	}
+	return ""
+}
+
+// xmlAttr returns an attribute value by local name, ignoring namespaces
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if strings.EqualFold(attr.Name.Local, name) {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func nonEmptyStrings(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -3,32 +3,57 @@ package parser
 import (
 	"fmt"
 	"strings"
+
+	"github.com/unalkalkan/TwelveReader/internal/provider"
 )
 
 // DefaultFactory creates parsers for supported formats
 type DefaultFactory struct {
-	parsers map[string]Parser
+	parsers     map[string]Parser
+	mimeFormats map[string]string // Media type to the parser's primary format
 }
 
 // NewFactory creates a new parser factory with default parsers
 func NewFactory() Factory {
+	return NewFactoryWithOCR(nil)
+}
+
+// NewFactoryWithOCR creates a parser factory whose PDF parser falls back to
+// the given OCR provider for scanned pages. A nil provider disables OCR.
+func NewFactoryWithOCR(ocr provider.OCRProvider) Factory {
 	f := &DefaultFactory{
-		parsers: make(map[string]Parser),
+		parsers:     make(map[string]Parser),
+		mimeFormats: make(map[string]string),
 	}
 
 	// Register default parsers
 	f.registerParser(NewTXTParser())
-	f.registerParser(NewPDFParser())
+	if ocr != nil {
+		f.registerParser(NewPDFParserWithOCR(ocr))
+	} else {
+		f.registerParser(NewPDFParser())
+	}
 	f.registerParser(NewEPUBParser())
+	f.registerParser(NewDOCXParser())
+	f.registerParser(NewMarkdownParser())
+	f.registerParser(NewHTMLParser())
+	f.registerParser(NewFB2Parser())
 
 	return f
 }
 
-// registerParser registers a parser for its supported formats
+// registerParser registers a parser for its supported formats and media types
 func (f *DefaultFactory) registerParser(p Parser) {
-	for _, format := range p.SupportedFormats() {
+	formats := p.SupportedFormats()
+	for _, format := range formats {
 		f.parsers[strings.ToLower(format)] = p
 	}
+	if len(formats) == 0 {
+		return
+	}
+	for _, mimeType := range p.SupportedMIMETypes() {
+		f.mimeFormats[strings.ToLower(mimeType)] = strings.ToLower(formats[0])
+	}
 }
 
 // GetParser returns a parser for the given format
@@ -40,3 +65,17 @@ func (f *DefaultFactory) GetParser(format string) (Parser, error) {
 	}
 	return parser, nil
 }
+
+// GetParserByMIME returns a parser for the given media type. Parameters such
+// as "; charset=utf-8" are ignored.
+func (f *DefaultFactory) GetParserByMIME(mimeType string) (Parser, string, error) {
+	mediaType := strings.ToLower(strings.TrimSpace(mimeType))
+	if idx := strings.IndexByte(mediaType, ';'); idx >= 0 {
+		mediaType = strings.TrimSpace(mediaType[:idx])
+	}
+	format, ok := f.mimeFormats[mediaType]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported media type: %s", mediaType)
+	}
+	return f.parsers[format], format, nil
+}
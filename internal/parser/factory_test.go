@@ -37,6 +37,18 @@ func TestFactory(t *testing.T) {
 		}
 	})
 
+	t.Run("Get document parsers", func(t *testing.T) {
+		for _, format := range []string{"docx", "md", "markdown", "html", "htm", "fb2"} {
+			parser, err := factory.GetParser(format)
+			if err != nil {
+				t.Fatalf("Failed to get %s parser: %v", format, err)
+			}
+			if parser == nil {
+				t.Fatalf("Got nil parser for %s", format)
+			}
+		}
+	})
+
 	t.Run("Case insensitive", func(t *testing.T) {
 		parser1, err1 := factory.GetParser("TXT")
 		parser2, err2 := factory.GetParser("txt")
@@ -50,6 +62,31 @@ func TestFactory(t *testing.T) {
 		}
 	})
 
+	t.Run("Get parser by MIME type", func(t *testing.T) {
+		tests := map[string]string{
+			"application/epub+zip":      "epub",
+			"text/plain; charset=UTF-8": "txt",
+			"TEXT/HTML":                 "html",
+			"text/markdown":             "md",
+			"application/pdf":           "pdf",
+			"application/x-fictionbook": "fb2",
+			"application/vnd.openxmlformats-officedocument.wordprocessingml.document": "docx",
+		}
+		for mimeType, expected := range tests {
+			parser, format, err := factory.GetParserByMIME(mimeType)
+			if err != nil {
+				t.Fatalf("Failed to get parser for %s: %v", mimeType, err)
+			}
+			if parser == nil || format != expected {
+				t.Errorf("GetParserByMIME(%q) = %v, %q; expected format %q", mimeType, parser, format, expected)
+			}
+		}
+
+		if _, _, err := factory.GetParserByMIME("image/png"); err == nil {
+			t.Error("Expected error for unsupported media type")
+		}
+	})
+
 	t.Run("Unsupported format", func(t *testing.T) {
 		_, err := factory.GetParser("doc")
 		if err == nil {
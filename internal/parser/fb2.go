@@ -0,0 +1,324 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// FB2Parser parses FictionBook 2 documents, using nested sections as chapters
+type FB2Parser struct{}
+
+// fb2Windows1251High maps windows-1251 bytes 0x80-0xBF; 0xC0-0xFF map
+// linearly onto U+0410-U+044F
+var fb2Windows1251High = [64]rune{
+	0x0402, 0x0403, 0x201A, 0x0453, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x20AC, 0x2030, 0x0409, 0x2039, 0x040A, 0x040C, 0x040B, 0x040F,
+	0x0452, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0xFFFD, 0x2122, 0x0459, 0x203A, 0x045A, 0x045C, 0x045B, 0x045F,
+	0x00A0, 0x040E, 0x045E, 0x0408, 0x00A4, 0x0490, 0x00A6, 0x00A7,
+	0x0401, 0x00A9, 0x0404, 0x00AB, 0x00AC, 0x00AD, 0x00AE, 0x0407,
+	0x00B0, 0x00B1, 0x0406, 0x0456, 0x0491, 0x00B5, 0x00B6, 0x00B7,
+	0x0451, 0x2116, 0x0454, 0x00BB, 0x0458, 0x0405, 0x0455, 0x0457,
+}
+
+// fb2Book is the subset of a FictionBook document read for metadata
+type fb2Book struct {
+	TitleInfo struct {
+		BookTitle string      `xml:"book-title"`
+		Authors   []fb2Author `xml:"author"`
+		Lang      string      `xml:"lang"`
+		Coverpage struct {
+			Images []struct {
+				Href string `xml:"href,attr"`
+			} `xml:"image"`
+		} `xml:"coverpage"`
+	} `xml:"description>title-info"`
+	Binaries []struct {
+		ID          string `xml:"id,attr"`
+		ContentType string `xml:"content-type,attr"`
+		Data        string `xml:",chardata"`
+	} `xml:"binary"`
+}
+
+type fb2Author struct {
+	FirstName  string `xml:"first-name"`
+	MiddleName string `xml:"middle-name"`
+	LastName   string `xml:"last-name"`
+	Nickname   string `xml:"nickname"`
+}
+
+// NewFB2Parser creates a new FB2 parser
+func NewFB2Parser() *FB2Parser {
+	return &FB2Parser{}
+}
+
+// Parse extracts chapters from the section tree of the main body
+func (p *FB2Parser) Parse(ctx context.Context, data []byte) ([]*types.Chapter, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("fb2: empty data")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	blocks, err := fb2Blocks(data)
+	if err != nil {
+		return nil, err
+	}
+	chapters := buildChaptersFromBlocks(blocks)
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("fb2: no readable text found")
+	}
+	return chapters, nil
+}
+
+// Metadata reads the title-info block and the embedded cover image
+func (p *FB2Parser) Metadata(ctx context.Context, data []byte) (*Metadata, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("fb2: empty data")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var book fb2Book
+	if err := newFB2Decoder(data).Decode(&book); err != nil {
+		return nil, fmt.Errorf("fb2: invalid xml: %w", err)
+	}
+
+	info := book.TitleInfo
+	metadata := &Metadata{
+		Title:    strings.Join(strings.Fields(info.BookTitle), " "),
+		Language: normalizeLanguage(info.Lang),
+	}
+
+	var authors []string
+	for _, author := range info.Authors {
+		name := strings.Join(strings.Fields(strings.Join([]string{author.FirstName, author.MiddleName, author.LastName}, " ")), " ")
+		if name == "" {
+			name = strings.TrimSpace(author.Nickname)
+		}
+		if name != "" {
+			authors = append(authors, name)
+		}
+	}
+	metadata.Author = strings.Join(authors, ", ")
+
+	if len(info.Coverpage.Images) > 0 {
+		coverID := strings.TrimPrefix(info.Coverpage.Images[0].Href, "#")
+		for _, binary := range book.Binaries {
+			if binary.ID != coverID {
+				continue
+			}
+			cover, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(binary.Data), ""))
+			if err == nil && len(cover) > 0 {
+				metadata.Cover = cover
+				metadata.CoverType = binary.ContentType
+			}
+			break
+		}
+	}
+	return metadata, nil
+}
+
+// SupportedFormats returns the formats this parser supports
+func (p *FB2Parser) SupportedFormats() []string {
+	return []string{"fb2"}
+}
+
+// SupportedMIMETypes returns the media types this parser supports
+func (p *FB2Parser) SupportedMIMETypes() []string {
+	return []string{"application/x-fictionbook+xml", "application/x-fictionbook", "text/fb2+xml"}
+}
+
+func newFB2Decoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(decodeText(data)))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = fb2CharsetReader
+	return decoder
+}
+
+// fb2CharsetReader decodes the single-byte encodings common in FB2 files
+func fb2CharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	var decode func(b byte) rune
+	switch strings.ToLower(charset) {
+	case "windows-1251", "cp1251", "cp-1251":
+		decode = func(b byte) rune {
+			switch {
+			case b < 0x80:
+				return rune(b)
+			case b < 0xC0:
+				return fb2Windows1251High[b-0x80]
+			default:
+				return 0x0410 + rune(b-0xC0)
+			}
+		}
+	case "iso-8859-1", "latin1", "latin-1":
+		decode = func(b byte) rune { return rune(b) }
+	case "utf-16", "utf-16le", "utf-16be":
+		// newFB2Decoder already converted text with a byte order mark
+		return input, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", charset)
+	}
+
+	raw, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	decoded := make([]byte, 0, len(raw)*2)
+	for _, b := range raw {
+		decoded = utf8.AppendRune(decoded, decode(b))
+	}
+	return bytes.NewReader(decoded), nil
+}
+
+// fb2Blocks walks the main body. Section titles become headings at their
+// nesting depth; paragraphs, verses, poem titles and table cells become body text. Bodies
+// holding notes or comments and footnote links are skipped.
+func fb2Blocks(data []byte) ([]documentBlock, error) {
+	decoder := newFB2Decoder(data)
+
+	var blocks []documentBlock
+	var paragraph strings.Builder
+	var titleParts []string
+	var stanza []string
+	inBody := false
+	sectionDepth := 0
+	textDepth := 0
+	skipDepth := 0
+	inTitle := false
+	sectionTitle := false
+	inStanza := false
+	lastStart := ""
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fb2: invalid xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 {
+				skipDepth++
+				continue
+			}
+			if !inBody && t.Name.Local != "body" {
+				continue
+			}
+			previous := lastStart
+			lastStart = t.Name.Local
+			switch t.Name.Local {
+			case "body":
+				name := xmlAttr(t, "name")
+				if name == "notes" || name == "comments" {
+					skipDepth = 1
+					continue
+				}
+				inBody = true
+			case "section":
+				sectionDepth++
+			case "title":
+				inTitle = true
+				sectionTitle = previous == "section"
+				titleParts = nil
+			case "stanza":
+				inStanza = true
+				stanza = nil
+			case "a":
+				if xmlAttr(t, "type") == "note" {
+					skipDepth = 1
+				}
+			case "p", "v", "subtitle", "text-author", "td", "th":
+				if textDepth == 0 {
+					paragraph.Reset()
+				}
+				textDepth++
+			}
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			if !inBody {
+				continue
+			}
+			switch t.Name.Local {
+			case "body":
+				inBody = false
+			case "section":
+				sectionDepth--
+			case "title":
+				inTitle = false
+				title := joinFB2Title(titleParts)
+				switch {
+				case title == "" || sectionDepth == 0:
+					// The body title repeats the book title and authors
+				case sectionTitle:
+					blocks = append(blocks, documentBlock{text: title, level: sectionDepth})
+				default:
+					// Poem titles are read as part of the text
+					blocks = append(blocks, documentBlock{text: title})
+				}
+			case "stanza":
+				inStanza = false
+				if len(stanza) > 0 {
+					blocks = append(blocks, documentBlock{text: strings.Join(stanza, " ")})
+				}
+			case "p", "v", "subtitle", "text-author", "td", "th":
+				if textDepth == 0 {
+					continue
+				}
+				textDepth--
+				if textDepth > 0 {
+					continue
+				}
+				text := strings.Join(strings.Fields(paragraph.String()), " ")
+				switch {
+				case text == "":
+				case inTitle:
+					titleParts = append(titleParts, text)
+				case inStanza:
+					stanza = append(stanza, text)
+				default:
+					blocks = append(blocks, documentBlock{text: text})
+				}
+			}
+		case xml.CharData:
+			if inBody && skipDepth == 0 && textDepth > 0 {
+				paragraph.Write(t)
+			}
+		}
+	}
+
+	return blocks, nil
+}
+
+// joinFB2Title joins the lines of a multi-paragraph section title into one heading
+func joinFB2Title(parts []string) string {
+	var title strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			if strings.ContainsAny(title.String()[title.Len()-1:], ".!?:;") {
+				title.WriteString(" ")
+			} else {
+				title.WriteString(". ")
+			}
+		}
+		title.WriteString(part)
+	}
+	return title.String()
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+const testFB2 = `<?xml version="1.0" encoding="UTF-8"?>
+<FictionBook xmlns="http://www.gribuser.ru/xml/fictionbook/2.0" xmlns:l="http://www.w3.org/1999/xlink">
+  <description>
+    <title-info>
+      <author><first-name>Jane</first-name><middle-name>Q</middle-name><last-name>Doe</last-name></author>
+      <author><nickname>anon</nickname></author>
+      <book-title>The Voyage</book-title>
+      <annotation><p>Not part of the text.</p></annotation>
+      <lang>ru</lang>
+      <coverpage><image l:href="#cover.png"/></coverpage>
+    </title-info>
+  </description>
+  <body>
+    <title><p>Jane Doe</p><p>The Voyage</p></title>
+    <section>
+      <title><p>Part One</p></title>
+      <section>
+        <title><p>Chapter 1</p><p>Departure</p></title>
+        <epigraph><p>All journeys begin.</p></epigraph>
+        <p>It was a <emphasis>dark</emphasis> night.<a l:href="#n1" type="note">[1]</a></p>
+        <empty-line/>
+        <poem>
+          <title><p>Song</p></title>
+          <stanza><v>Row, row,</v><v>row your boat.</v></stanza>
+        </poem>
+      </section>
+      <section>
+        <title><p>Chapter 2</p></title>
+        <p>Second chapter.</p>
+      </section>
+    </section>
+  </body>
+  <body name="notes">
+    <section id="n1"><title><p>1</p></title><p>A footnote.</p></section>
+  </body>
+  <binary id="cover.png" content-type="image/png">iVBORw0K
+GgoA</binary>
+</FictionBook>`
+
+func TestFB2Parser_Parse(t *testing.T) {
+	parser := NewFB2Parser()
+	ctx := context.Background()
+
+	chapters, err := parser.Parse(ctx, []byte(testFB2))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(chapters) != 2 {
+		t.Fatalf("Expected 2 chapters, got %d: %+v", len(chapters), chapters)
+	}
+
+	first := chapters[0]
+	if first.ID != "chapter_001" || first.Title != "Chapter 1. Departure" {
+		t.Errorf("Unexpected chapter: %s %q", first.ID, first.Title)
+	}
+	if !reflect.DeepEqual(first.TOCPath, []string{"Part One", "Chapter 1. Departure"}) {
+		t.Errorf("Unexpected TOC path: %v", first.TOCPath)
+	}
+	expected := []string{"All journeys begin.", "It was a dark night.", "Song", "Row, row, row your boat."}
+	if !reflect.DeepEqual(first.Paragraphs, expected) {
+		t.Errorf("Unexpected paragraphs: %q", first.Paragraphs)
+	}
+
+	if !reflect.DeepEqual(chapters[1].TOCPath, []string{"Part One", "Chapter 2"}) {
+		t.Errorf("Unexpected TOC path: %v", chapters[1].TOCPath)
+	}
+
+	t.Run("Windows-1251 encoding", func(t *testing.T) {
+		// "Глава" / "Текст" encoded as windows-1251
+		data := []byte(`<?xml version="1.0" encoding="windows-1251"?><FictionBook><body><section><title><p>` +
+			"\xc3\xeb\xe0\xe2\xe0" + `</p></title><p>` + "\xd2\xe5\xea\xf1\xf2" + `</p></section></body></FictionBook>`)
+		chapters, err := parser.Parse(ctx, data)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if chapters[0].Title != "Глава" || chapters[0].Paragraphs[0] != "Текст" {
+			t.Errorf("Unexpected decoding: %q %q", chapters[0].Title, chapters[0].Paragraphs)
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		if _, err := parser.Parse(ctx, nil); err == nil {
+			t.Error("Expected error for empty data")
+		}
+		if _, err := parser.Parse(ctx, []byte(`<FictionBook><body></body></FictionBook>`)); err == nil {
+			t.Error("Expected error for book without text")
+		}
+	})
+}
+
+func TestFB2Parser_Metadata(t *testing.T) {
+	metadata, err := NewFB2Parser().Metadata(context.Background(), []byte(testFB2))
+	if err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	if metadata.Title != "The Voyage" || metadata.Author != "Jane Q Doe, anon" || metadata.Language != "ru" {
+		t.Errorf("Unexpected metadata: %#v", metadata)
+	}
+	if metadata.CoverType != "image/png" || !bytes.HasPrefix(metadata.Cover, []byte("\x89PNG")) {
+		t.Errorf("Unexpected cover: %q %x", metadata.CoverType, metadata.Cover)
+	}
+}
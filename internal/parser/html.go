@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// HTMLParser parses standalone HTML documents, using h1-h6 headings as chapters
+type HTMLParser struct{}
+
+var (
+	htmlCommentRe   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlHeadRe      = regexp.MustCompile(`(?is)<head\b[^>]*>.*?</head>`)
+	htmlMainRe      = regexp.MustCompile(`(?is)<main\b[^>]*>(.*)</main>`)
+	htmlArticleRe   = regexp.MustCompile(`(?is)<article\b[^>]*>(.*)</article>`)
+	htmlHeadingRe   = regexp.MustCompile(`(?is)<h([1-6])\b[^>]*>(.*?)</h[1-6]\s*>`)
+	htmlMetaRe      = regexp.MustCompile(`(?is)<meta\b[^>]*>`)
+	htmlRootRe      = regexp.MustCompile(`(?is)<html\b[^>]*>`)
+	htmlAttrRe      = regexp.MustCompile(`(?s)([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	htmlBoilerplate = []*regexp.Regexp{
+		regexp.MustCompile(`(?is)<nav\b[^>]*>.*?</nav>`),
+		regexp.MustCompile(`(?is)<aside\b[^>]*>.*?</aside>`),
+		regexp.MustCompile(`(?is)<footer\b[^>]*>.*?</footer>`),
+		regexp.MustCompile(`(?is)<noscript\b[^>]*>.*?</noscript>`),
+		regexp.MustCompile(`(?is)<template\b[^>]*>.*?</template>`),
+		regexp.MustCompile(`(?is)<svg\b[^>]*>.*?</svg>`),
+	}
+)
+
+// NewHTMLParser creates a new HTML parser
+func NewHTMLParser() *HTMLParser {
+	return &HTMLParser{}
+}
+
+// Parse extracts chapters from the document's headings and text blocks
+func (p *HTMLParser) Parse(ctx context.Context, data []byte) ([]*types.Chapter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	chapters := buildChaptersFromBlocks(htmlBlocks(string(decodeText(data))))
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("no content found in html file")
+	}
+	return chapters, nil
+}
+
+// Metadata reads the document title, author meta tag and root language
+func (p *HTMLParser) Metadata(ctx context.Context, data []byte) (*Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	content := string(decodeText(data))
+	metadata := &Metadata{}
+	if match := epubTitleRe.FindStringSubmatch(content); len(match) > 1 {
+		metadata.Title = htmlInlineText(match[1])
+	}
+
+	for _, tag := range htmlMetaRe.FindAllString(content, -1) {
+		attrs := htmlAttributes(tag)
+		name := attrs["name"]
+		if name == "" {
+			name = attrs["property"]
+		}
+		name = strings.ToLower(name)
+		value := strings.TrimSpace(html.UnescapeString(attrs["content"]))
+		if value == "" {
+			continue
+		}
+		switch name {
+		case "author", "dc.creator", "dcterms.creator", "book:author":
+			if metadata.Author == "" {
+				metadata.Author = value
+			}
+		case "og:title", "dc.title", "dcterms.title":
+			if metadata.Title == "" {
+				metadata.Title = value
+			}
+		case "dc.language", "dcterms.language":
+			if metadata.Language == "" {
+				metadata.Language = normalizeLanguage(value)
+			}
+		}
+		if strings.EqualFold(attrs["http-equiv"], "content-language") && metadata.Language == "" {
+			metadata.Language = normalizeLanguage(value)
+		}
+	}
+
+	if root := htmlRootRe.FindString(content); root != "" {
+		if lang := normalizeLanguage(htmlAttributes(root)["lang"]); lang != "" {
+			metadata.Language = lang
+		}
+	}
+	return metadata, nil
+}
+
+// SupportedFormats returns the formats this parser supports
+func (p *HTMLParser) SupportedFormats() []string {
+	return []string{"html", "htm"}
+}
+
+// SupportedMIMETypes returns the media types this parser supports
+func (p *HTMLParser) SupportedMIMETypes() []string {
+	return []string{"text/html", "application/xhtml+xml"}
+}
+
+// htmlBlocks extracts heading and paragraph blocks from the main content of a
+// page, skipping navigation and other page chrome
+func htmlBlocks(content string) []documentBlock {
+	body := content
+	if match := epubBodyRe.FindStringSubmatch(body); len(match) > 1 {
+		body = match[1]
+	} else {
+		body = htmlHeadRe.ReplaceAllString(body, "")
+	}
+	body = removeScriptStyle(htmlCommentRe.ReplaceAllString(body, ""))
+	if match := htmlMainRe.FindStringSubmatch(body); len(match) > 1 {
+		body = match[1]
+	} else if match := htmlArticleRe.FindStringSubmatch(body); len(match) > 1 {
+		body = match[1]
+	}
+	for _, re := range htmlBoilerplate {
+		body = re.ReplaceAllString(body, "")
+	}
+
+	var blocks []documentBlock
+	addText := func(fragment string) {
+		for _, paragraph := range htmlParagraphs(fragment) {
+			blocks = append(blocks, documentBlock{text: paragraph})
+		}
+	}
+
+	offset := 0
+	for _, loc := range htmlHeadingRe.FindAllStringSubmatchIndex(body, -1) {
+		addText(body[offset:loc[0]])
+		offset = loc[1]
+		if title := htmlInlineText(body[loc[4]:loc[5]]); title != "" {
+			blocks = append(blocks, documentBlock{text: title, level: int(body[loc[2]] - '0')})
+		}
+	}
+	addText(body[offset:])
+
+	return blocks
+}
+
+// htmlInlineText returns the text of an inline fragment on a single line
+func htmlInlineText(fragment string) string {
+	text := html.UnescapeString(stripTags(epubBreakRe.ReplaceAllString(fragment, " ")))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// htmlAttributes parses the attributes of a start tag keyed by lowercase name
+func htmlAttributes(tag string) map[string]string {
+	attrs := make(map[string]string)
+	tag = strings.TrimPrefix(tag, "<")
+	if idx := strings.IndexAny(tag, " \t\r\n"); idx >= 0 {
+		tag = tag[idx:]
+	} else {
+		return attrs
+	}
+	for _, match := range htmlAttrRe.FindAllStringSubmatch(tag, -1) {
+		attrs[strings.ToLower(match[1])] = match[2] + match[3] + match[4]
+	}
+	return attrs
+}
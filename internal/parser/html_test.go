@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestHTMLParser_Parse(t *testing.T) {
+	parser := NewHTMLParser()
+	ctx := context.Background()
+
+	data := []byte(`<!DOCTYPE html>
+<html lang="fr-CA">
+<head><title>Ignored &amp; Title</title><style>p { color: red; }</style></head>
+<body>
+<nav><a href="/">Home</a> <a href="/about">About</a></nav>
+<article>
+  <h1>The <em>Long</em> Road</h1>
+  <h2 id="c1">Chapter 1</h2>
+  <p>First paragraph of the<br>first chapter.</p>
+  <!-- a comment that should not be read -->
+  <h4>Minor heading</h4>
+  <p>Second paragraph &amp; more.</p>
+  <h2>Chapter 2</h2>
+  <div>Text of chapter two.</div>
+  <script>console.log("skip me")</script>
+</article>
+<footer>Copyright notice</footer>
+</body>
+</html>`)
+
+	chapters, err := parser.Parse(ctx, data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(chapters) != 2 {
+		t.Fatalf("Expected 2 chapters, got %d: %+v", len(chapters), chapters)
+	}
+
+	if !reflect.DeepEqual(chapters[0].TOCPath, []string{"The Long Road", "Chapter 1"}) {
+		t.Errorf("Unexpected TOC path: %v", chapters[0].TOCPath)
+	}
+	expected := []string{"First paragraph of the first chapter.", "Minor heading", "Second paragraph & more."}
+	if !reflect.DeepEqual(chapters[0].Paragraphs, expected) {
+		t.Errorf("Unexpected paragraphs: %q", chapters[0].Paragraphs)
+	}
+
+	if chapters[1].ID != "chapter_002" || chapters[1].Title != "Chapter 2" {
+		t.Errorf("Unexpected chapter: %s %q", chapters[1].ID, chapters[1].Title)
+	}
+	if !reflect.DeepEqual(chapters[1].Paragraphs, []string{"Text of chapter two."}) {
+		t.Errorf("Unexpected paragraphs: %q", chapters[1].Paragraphs)
+	}
+
+	t.Run("No text", func(t *testing.T) {
+		if _, err := parser.Parse(ctx, []byte("<html><body><script>x()</script></body></html>")); err == nil {
+			t.Error("Expected error for document without text")
+		}
+	})
+}
+
+func TestHTMLParser_Metadata(t *testing.T) {
+	data := []byte(`<html lang="fr-CA"><head>
+<title>The Long &amp; Winding Road</title>
+<meta name="author" content="Jane Doe">
+<meta property="og:title" content="Ignored">
+</head><body><p>Text</p></body></html>`)
+
+	metadata, err := NewHTMLParser().Metadata(context.Background(), data)
+	if err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	if metadata.Title != "The Long & Winding Road" || metadata.Author != "Jane Doe" || metadata.Language != "fr" {
+		t.Errorf("Unexpected metadata: %#v", metadata)
+	}
+}
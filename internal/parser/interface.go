@@ -2,6 +2,7 @@ package parser
 
 import (
 	"context"
+	"io"
 
 	"github.com/unalkalkan/TwelveReader/pkg/types"
 )
@@ -11,12 +12,51 @@ type Parser interface {
 	// Parse extracts chapters and text from the document
 	Parse(ctx context.Context, data []byte) ([]*types.Chapter, error)
 
+	// Metadata extracts document-level metadata such as title and author.
+	// Fields the document does not declare are left empty.
+	Metadata(ctx context.Context, data []byte) (*Metadata, error)
+
 	// SupportedFormats returns the file formats this parser supports
 	SupportedFormats() []string
+
+	// SupportedMIMETypes returns the media types this parser supports
+	SupportedMIMETypes() []string
+}
+
+// Source is random access document content, such as an open raw file.
+// *bytes.Reader and *io.SectionReader satisfy it.
+type Source interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// SourceParser is implemented by parsers that read documents directly from a
+// Source instead of needing the whole file in memory
+type SourceParser interface {
+	Parser
+
+	// ParseSource extracts chapters and text from the document
+	ParseSource(ctx context.Context, src Source) ([]*types.Chapter, error)
+
+	// MetadataSource extracts document-level metadata
+	MetadataSource(ctx context.Context, src Source) (*Metadata, error)
+}
+
+// Metadata is descriptive information embedded in a document
+type Metadata struct {
+	Title     string
+	Author    string
+	Language  string // ISO-639-1 code
+	Cover     []byte // Cover image, nil when the document has none
+	CoverType string // MIME type of Cover, e.g. "image/jpeg"
 }
 
 // Factory creates parsers for different formats
 type Factory interface {
 	// GetParser returns a parser for the given format
 	GetParser(format string) (Parser, error)
+
+	// GetParserByMIME returns a parser for the given media type together
+	// with the file format documents of that type are stored as
+	GetParserByMIME(mimeType string) (Parser, string, error)
 }
@@ -0,0 +1,233 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// MarkdownParser parses Markdown documents, using ATX and setext headings as chapters
+type MarkdownParser struct{}
+
+var (
+	mdATXHeadingRe    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdSetextH1Re      = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	mdSetextH2Re      = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	mdThematicBreakRe = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdFenceRe         = regexp.MustCompile("^ {0,3}(```|~~~)")
+	mdListItemRe      = regexp.MustCompile(`^[ \t]*(?:[-*+]|\d{1,9}[.)])[ \t]+`)
+	mdBlockquoteRe    = regexp.MustCompile(`^[ \t]*(?:>[ \t]?)+`)
+	mdLinkDefRe       = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:[ \t]*\S+`)
+	mdImageRe         = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)|!\[[^\]]*\]\[[^\]]*\]`)
+	mdLinkRe          = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)|\[([^\]]+)\]\[[^\]]*\]`)
+	mdAutolinkRe      = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	mdCodeSpanRe      = regexp.MustCompile("`+([^`]+)`+")
+	mdStrongRe        = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdEmphasisRe      = regexp.MustCompile(`\*([^*\s][^*]*)\*|(?:^|\b)_([^_]+)_(?:\b|$)`)
+	mdStrikeRe        = regexp.MustCompile(`~~([^~]+)~~`)
+	mdEscapeRe        = regexp.MustCompile("\\\\([\\\\`*_{}\\[\\]()#+\\-.!>~|])")
+)
+
+// NewMarkdownParser creates a new Markdown parser
+func NewMarkdownParser() *MarkdownParser {
+	return &MarkdownParser{}
+}
+
+// Parse extracts chapters from Markdown headings and paragraphs
+func (p *MarkdownParser) Parse(ctx context.Context, data []byte) ([]*types.Chapter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, body := splitMarkdownFrontMatter(normalizeMarkdown(data))
+	chapters := buildChaptersFromBlocks(markdownBlocks(body))
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("no content found in markdown file")
+	}
+	return chapters, nil
+}
+
+// Metadata reads title, author and language from YAML front matter, falling
+// back to the first top-level heading for the title
+func (p *MarkdownParser) Metadata(ctx context.Context, data []byte) (*Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	metadata := &Metadata{}
+	frontMatter, body := splitMarkdownFrontMatter(normalizeMarkdown(data))
+	if frontMatter != "" {
+		var fields map[string]interface{}
+		if err := yaml.Unmarshal([]byte(frontMatter), &fields); err == nil {
+			metadata.Title = frontMatterString(fields["title"])
+			metadata.Author = frontMatterString(fields["author"])
+			if metadata.Author == "" {
+				metadata.Author = frontMatterString(fields["authors"])
+			}
+			metadata.Language = normalizeLanguage(frontMatterString(fields["lang"]))
+			if metadata.Language == "" {
+				metadata.Language = normalizeLanguage(frontMatterString(fields["language"]))
+			}
+		}
+	}
+
+	if metadata.Title == "" {
+		for _, block := range markdownBlocks(body) {
+			if block.level == 1 {
+				metadata.Title = block.text
+				break
+			}
+		}
+	}
+	return metadata, nil
+}
+
+// SupportedFormats returns the formats this parser supports
+func (p *MarkdownParser) SupportedFormats() []string {
+	return []string{"md", "markdown"}
+}
+
+// SupportedMIMETypes returns the media types this parser supports
+func (p *MarkdownParser) SupportedMIMETypes() []string {
+	return []string{"text/markdown", "text/x-markdown"}
+}
+
+func normalizeMarkdown(data []byte) string {
+	text := string(decodeText(data))
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// splitMarkdownFrontMatter separates a leading "---" delimited YAML block from the body
+func splitMarkdownFrontMatter(text string) (string, string) {
+	if !strings.HasPrefix(text, "---\n") {
+		return "", text
+	}
+	rest := text[len("---\n"):]
+	for _, delimiter := range []string{"\n---\n", "\n...\n"} {
+		if idx := strings.Index(rest, delimiter); idx >= 0 {
+			return rest[:idx], rest[idx+len(delimiter):]
+		}
+	}
+	for _, delimiter := range []string{"\n---", "\n..."} {
+		if strings.HasSuffix(rest, delimiter) {
+			return strings.TrimSuffix(rest, delimiter), ""
+		}
+	}
+	return "", text
+}
+
+// frontMatterString flattens a scalar or list front matter value
+func frontMatterString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []interface{}:
+		var parts []string
+		for _, item := range v {
+			if s := frontMatterString(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		return frontMatterString(v["name"])
+	}
+	return ""
+}
+
+// markdownBlocks splits Markdown into heading and paragraph blocks. Code
+// blocks, images and link definitions are dropped since they are not narrated.
+func markdownBlocks(text string) []documentBlock {
+	var blocks []documentBlock
+	var paragraph []string
+	fence := ""
+
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		if cleaned := markdownInline(strings.Join(paragraph, " ")); cleaned != "" {
+			blocks = append(blocks, documentBlock{text: cleaned})
+		}
+		paragraph = nil
+	}
+
+	for _, line := range strings.Split(htmlCommentRe.ReplaceAllString(text, ""), "\n") {
+		if match := mdFenceRe.FindStringSubmatch(line); len(match) > 1 {
+			if fence == "" {
+				flush()
+				fence = match[1]
+			} else if match[1] == fence {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		if match := mdATXHeadingRe.FindStringSubmatch(line); len(match) > 1 {
+			flush()
+			if title := markdownInline(match[2]); title != "" {
+				blocks = append(blocks, documentBlock{text: title, level: len(match[1])})
+			}
+			continue
+		}
+
+		if len(paragraph) > 0 && (mdSetextH1Re.MatchString(line) || mdSetextH2Re.MatchString(line)) {
+			level := 1
+			if mdSetextH2Re.MatchString(line) {
+				level = 2
+			}
+			if title := markdownInline(strings.Join(paragraph, " ")); title != "" {
+				blocks = append(blocks, documentBlock{text: title, level: level})
+			}
+			paragraph = nil
+			continue
+		}
+
+		if mdThematicBreakRe.MatchString(line) || mdLinkDefRe.MatchString(line) {
+			flush()
+			continue
+		}
+
+		line = mdBlockquoteRe.ReplaceAllString(line, "")
+		if loc := mdListItemRe.FindStringIndex(line); loc != nil {
+			flush()
+			line = line[loc[1]:]
+		}
+		paragraph = append(paragraph, strings.TrimSpace(line))
+	}
+	flush()
+
+	return blocks
+}
+
+// markdownInline removes inline Markdown syntax, leaving the readable text
+func markdownInline(text string) string {
+	text = mdImageRe.ReplaceAllString(text, "")
+	text = mdLinkRe.ReplaceAllString(text, "$1$2")
+	text = mdAutolinkRe.ReplaceAllString(text, "$1")
+	text = mdCodeSpanRe.ReplaceAllString(text, "$1")
+	text = mdStrongRe.ReplaceAllString(text, "$1$2")
+	text = mdEmphasisRe.ReplaceAllStringFunc(text, func(match string) string {
+		return strings.Trim(match, "*_")
+	})
+	text = mdStrikeRe.ReplaceAllString(text, "$1")
+	text = stripTags(text)
+	text = mdEscapeRe.ReplaceAllString(text, "$1")
+	text = html.UnescapeString(text)
+	return strings.Join(strings.Fields(text), " ")
+}
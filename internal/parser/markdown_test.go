@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownParser_Parse(t *testing.T) {
+	parser := NewMarkdownParser()
+	ctx := context.Background()
+
+	t.Run("ATX and setext headings", func(t *testing.T) {
+		data := []byte(`---
+title: Ignored Front Matter
+---
+Opening words before any heading.
+
+Part One
+========
+
+## Chapter 1 ##
+
+It was a **dark** and _stormy_ night, see [the map](map.html).
+
+![A map](map.png)
+
+### A scene break
+
+The rain fell.
+
+Chapter 2
+---------
+
+` + "```" + `
+code that should not be read
+` + "```" + `
+
+- First item
+- Second item
+continued here
+`)
+
+		chapters, err := parser.Parse(ctx, data)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if len(chapters) != 3 {
+			t.Fatalf("Expected 3 chapters, got %d: %+v", len(chapters), chapters)
+		}
+
+		if chapters[0].Title != defaultChapterTitle || chapters[0].Paragraphs[0] != "Opening words before any heading." {
+			t.Errorf("Unexpected leading chapter: %+v", chapters[0])
+		}
+
+		first := chapters[1]
+		if first.ID != "chapter_002" || first.Title != "Chapter 1" {
+			t.Errorf("Unexpected chapter: %s %q", first.ID, first.Title)
+		}
+		if !reflect.DeepEqual(first.TOCPath, []string{"Part One", "Chapter 1"}) {
+			t.Errorf("Unexpected TOC path: %v", first.TOCPath)
+		}
+		expected := []string{
+			"It was a dark and stormy night, see the map.",
+			"A scene break",
+			"The rain fell.",
+		}
+		if !reflect.DeepEqual(first.Paragraphs, expected) {
+			t.Errorf("Unexpected paragraphs: %q", first.Paragraphs)
+		}
+
+		second := chapters[2]
+		if !reflect.DeepEqual(second.TOCPath, []string{"Part One", "Chapter 2"}) {
+			t.Errorf("Unexpected TOC path: %v", second.TOCPath)
+		}
+		if !reflect.DeepEqual(second.Paragraphs, []string{"First item", "Second item continued here"}) {
+			t.Errorf("Unexpected paragraphs: %q", second.Paragraphs)
+		}
+		for _, paragraph := range second.Paragraphs {
+			if strings.Contains(paragraph, "code") {
+				t.Errorf("Fenced code should be skipped, got %q", paragraph)
+			}
+		}
+	})
+
+	t.Run("Falls back to text heading detection", func(t *testing.T) {
+		data := []byte("CHAPTER ONE\n\nThis is the first chapter.\n\nCHAPTER TWO\n\nThis is the second chapter.\n")
+
+		chapters, err := parser.Parse(ctx, data)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if len(chapters) != 2 || chapters[0].Title != "CHAPTER ONE" || chapters[1].Title != "CHAPTER TWO" {
+			t.Fatalf("Unexpected chapters: %+v", chapters)
+		}
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		if _, err := parser.Parse(ctx, []byte("```\nonly code\n```\n")); err == nil {
+			t.Error("Expected error for document without text")
+		}
+	})
+}
+
+func TestMarkdownParser_Metadata(t *testing.T) {
+	parser := NewMarkdownParser()
+	ctx := context.Background()
+
+	t.Run("Front matter", func(t *testing.T) {
+		data := []byte("---\ntitle: The Voyage\nauthor:\n  - Jane Doe\n  - John Roe\nlang: de-DE\n---\n# Heading\n\nText.\n")
+		metadata, err := parser.Metadata(ctx, data)
+		if err != nil {
+			t.Fatalf("Metadata failed: %v", err)
+		}
+		if metadata.Title != "The Voyage" || metadata.Author != "Jane Doe, John Roe" || metadata.Language != "de" {
+			t.Errorf("Unexpected metadata: %#v", metadata)
+		}
+	})
+
+	t.Run("Title from first heading", func(t *testing.T) {
+		metadata, err := parser.Metadata(ctx, []byte("Intro text.\n\n# The Voyage\n\nText.\n"))
+		if err != nil {
+			t.Fatalf("Metadata failed: %v", err)
+		}
+		if metadata.Title != "The Voyage" || metadata.Author != "" {
+			t.Errorf("Unexpected metadata: %#v", metadata)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package parser
+
+import "strings"
+
+// normalizeLanguage reduces a language tag such as "en-US" to its ISO-639
+// primary subtag. Values that are not language codes yield "".
+func normalizeLanguage(tag string) string {
+	primary := strings.ToLower(strings.TrimSpace(tag))
+	if idx := strings.IndexAny(primary, "-_"); idx >= 0 {
+		primary = primary[:idx]
+	}
+	if len(primary) < 2 || len(primary) > 3 {
+		return ""
+	}
+	for _, r := range primary {
+		if r < 'a' || r > 'z' {
+			return ""
+		}
+	}
+	return primary
+}
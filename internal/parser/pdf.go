@@ -6,22 +6,23 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/unalkalkan/TwelveReader/internal/provider"
 	"github.com/unalkalkan/TwelveReader/pkg/types"
 )
 
-type PDFParser struct{}
+type PDFParser struct {
+	ocr provider.OCRProvider // Optional, used for pages without a usable text layer
+}
 
 var (
-	pdfStreamRe       = regexp.MustCompile(`(?m)(?:^|\r?\n)stream\r?\n`)
-	pdfEndStreamRe    = regexp.MustCompile(`\r?\nendstream`)
-	flateDecodeRe     = regexp.MustCompile(`/FlateDecode`)
-	filterRe          = regexp.MustCompile(`/Filter\s*/FlateDecode`)
 	chapterHeadingRe  = regexp.MustCompile(`(?i)^chapter\s+([0-9]+|[ivxlcdm]+|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve)\b`)
 	numberedHeadingRe = regexp.MustCompile(`^\d{1,3}\.\s+\S`)
 )
@@ -30,84 +31,169 @@ func NewPDFParser() *PDFParser {
 	return &PDFParser{}
 }
 
+// NewPDFParserWithOCR creates a PDF parser that sends scanned pages through OCR
+func NewPDFParserWithOCR(ocr provider.OCRProvider) *PDFParser {
+	return &PDFParser{ocr: ocr}
+}
+
 func (p *PDFParser) Parse(ctx context.Context, data []byte) ([]*types.Chapter, error) {
-	if len(data) == 0 {
+	return p.ParseSource(ctx, bytes.NewReader(data))
+}
+
+// ParseSource extracts chapters from a PDF read in place
+func (p *PDFParser) ParseSource(ctx context.Context, src Source) ([]*types.Chapter, error) {
+	if src.Size() == 0 {
 		return nil, fmt.Errorf("pdf: empty data")
 	}
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	if !bytes.HasPrefix(data, []byte("%PDF")) {
-		return nil, fmt.Errorf("pdf: invalid header, not a PDF file")
+	doc, err := openPDFDocument(src)
+	if err != nil {
+		return nil, err
 	}
-
-	streams := extractStreams(data)
-	if len(streams) == 0 {
-		return nil, fmt.Errorf("pdf: no content streams found")
+	pages := doc.pages()
+	if len(pages) == 0 {
+		// No usable page tree; scan every stream in the file instead
+		return parsePDFStreams(doc)
 	}
 
-	var allText []string
-	for _, stream := range streams {
-		texts := extractTextFromStream(stream)
-		allText = append(allText, texts...)
+	pageLines := make([][]string, len(pages))
+	var ocrPages []types.OCRPage
+	lineCount := 0
+	for i, page := range pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var pageText []string
+		for _, content := range doc.pageContent(page) {
+			pageText = append(pageText, extractTextFromStream(content)...)
+		}
+
+		if p.ocr != nil && pdfTextLength(pageText) < pdfMinPageTextChars {
+			lines, confidence, err := p.ocrPage(ctx, doc, page, i+1)
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
+				log.Printf("[PDFParser] OCR skipped for page %d: %v", i+1, err)
+			} else if len(lines) > 0 {
+				pageText = lines
+				ocrPages = append(ocrPages, types.OCRPage{
+					Page:          i + 1,
+					Confidence:    confidence,
+					LowConfidence: confidence < pdfLowOCRConfidence,
+				})
+			}
+		}
+
+		pageLines[i] = pageText
+		lineCount += len(pageText)
 	}
 
-	if len(allText) == 0 {
+	if lineCount == 0 {
 		return nil, fmt.Errorf("pdf: no extractable text found")
 	}
-	allText = reflowExtractedPDFParagraphs(allText)
 
-	chapter := &types.Chapter{
-		ID:         "chapter_001",
-		Number:     1,
-		Title:      "PDF Content",
-		TOCPath:    []string{"PDF Content"},
-		Paragraphs: allText,
+	return buildPDFChapters(pageLines, doc.outline(pages), ocrPages), nil
+}
+
+// Metadata reads the document information dictionary and the catalog's /Lang
+func (p *PDFParser) Metadata(ctx context.Context, data []byte) (*Metadata, error) {
+	return p.MetadataSource(ctx, bytes.NewReader(data))
+}
+
+// MetadataSource reads the metadata of a PDF read in place
+func (p *PDFParser) MetadataSource(ctx context.Context, src Source) (*Metadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	return []*types.Chapter{chapter}, nil
+	doc, err := openPDFDocument(src)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &Metadata{}
+	if doc.trailer != nil {
+		info := doc.dict(doc.trailer["Info"])
+		metadata.Title = pdfTextString(doc.resolve(info["Title"]))
+		metadata.Author = pdfTextString(doc.resolve(info["Author"]))
+	}
+	if catalog := doc.catalog(); catalog != nil {
+		metadata.Language = normalizeLanguage(pdfTextString(doc.resolve(catalog["Lang"])))
+	}
+	return metadata, nil
 }
 
-func extractStreams(data []byte) [][]byte {
-	var streams [][]byte
+// openPDFDocument checks the PDF header and indexes the document's objects
+func openPDFDocument(src Source) (*pdfDocument, error) {
+	header, err := readPDFAt(src, make([]byte, min(src.Size(), 4)), 0)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header, []byte("%PDF")) {
+		return nil, fmt.Errorf("pdf: invalid header, not a PDF file")
+	}
+	return parsePDFDocument(src)
+}
 
-	positions := pdfStreamRe.FindAllIndex(data, -1)
-	for _, pos := range positions {
-		start := pos[1]
-		endMatch := pdfEndStreamRe.FindIndex(data[start:])
-		if endMatch == nil || endMatch[0] == 0 {
-			continue
-		}
-		end := start + endMatch[0]
-		rawStream := data[start:end]
-
-		// Check if this stream uses FlateDecode by looking at the
-		// stream dictionary (the object bytes before the "stream" keyword)
-		streamDictStart := pos[0] - 500
-		if streamDictStart < 0 {
-			streamDictStart = 0
+// parsePDFStreams extracts text from every stream in the file. It is used
+// for files whose page tree cannot be located.
+func parsePDFStreams(doc *pdfDocument) ([]*types.Chapter, error) {
+	var streams []*pdfObject
+	for _, obj := range doc.objects {
+		if obj.hasStream {
+			streams = append(streams, obj)
 		}
-		streamDict := data[streamDictStart:pos[0]]
+	}
+	if len(streams) == 0 {
+		return nil, fmt.Errorf("pdf: no content streams found")
+	}
+	sort.Slice(streams, func(i, j int) bool { return streams[i].streamOffset < streams[j].streamOffset })
 
-		if usesFlateDecode(streamDict) {
-			decompressed, err := decompressFlate(rawStream)
-			if err == nil && len(decompressed) > 0 {
-				streams = append(streams, decompressed)
-				continue
+	var allText []string
+	for _, obj := range streams {
+		stream, err := doc.decodeStream(obj)
+		if err != nil {
+			// Undecodable streams are scanned raw
+			if stream, err = doc.streamData(obj); err != nil {
+				return nil, err
 			}
-			// On decompression failure, include raw data as fallback
 		}
+		allText = append(allText, extractTextFromStream(stream)...)
+	}
 
-		streams = append(streams, rawStream)
+	if len(allText) == 0 {
+		return nil, fmt.Errorf("pdf: no extractable text found")
 	}
 
-	return streams
+	paragraphs := reflowExtractedPDFParagraphs(allText)
+	return []*types.Chapter{newPDFChapter(1, pdfDefaultChapterTitle, []string{pdfDefaultChapterTitle}, paragraphs)}, nil
+}
+
+func newPDFChapter(number int, title string, tocPath []string, paragraphs []string) *types.Chapter {
+	return &types.Chapter{
+		ID:         fmt.Sprintf("chapter_%03d", number),
+		Number:     number,
+		Title:      title,
+		TOCPath:    tocPath,
+		Paragraphs: paragraphs,
+	}
 }
 
-// usesFlateDecode checks if the stream dictionary contains a FlateDecode filter
-func usesFlateDecode(dict []byte) bool {
-	return filterRe.Match(dict) || flateDecodeRe.Match(dict)
+// pdfTextLength counts the non-whitespace characters in extracted lines
+func pdfTextLength(lines []string) int {
+	count := 0
+	for _, line := range lines {
+		for _, r := range line {
+			if !unicode.IsSpace(r) {
+				count++
+			}
+		}
+	}
+	return count
 }
 
 // decompressFlate decompresses a zlib/deflate (FlateDecode) compressed stream
@@ -445,3 +531,8 @@ func parseLiteralString(content string, start int) (string, int) {
 func (p *PDFParser) SupportedFormats() []string {
 	return []string{"pdf"}
 }
+
+// SupportedMIMETypes returns the media types this parser supports
+func (p *PDFParser) SupportedMIMETypes() []string {
+	return []string{"application/pdf", "application/x-pdf"}
+}
@@ -0,0 +1,767 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// pdfMaxTreeDepth bounds recursion through page trees and form XObjects so
+	// malformed or cyclic documents cannot loop forever.
+	pdfMaxTreeDepth = 32
+
+	// pdfScanWindow is how much of the file is searched at a time
+	pdfScanWindow = 1 << 20
+
+	// pdfScanOverlap keeps object headers split between windows intact
+	pdfScanOverlap = 256
+
+	// pdfObjectValueChunk is the first read of an object's value
+	pdfObjectValueChunk = 16 << 10
+)
+
+var (
+	pdfObjHeaderRe = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	pdfRefRe       = regexp.MustCompile(`(\d+)\s+(\d+)\s+R\b`)
+	pdfSingleRefRe = regexp.MustCompile(`^(\d+)\s+(\d+)\s+R$`)
+	pdfIntTokenRe  = regexp.MustCompile(`^-?\d+$`)
+	pdfTrailingRe  = regexp.MustCompile(`^\s+\d+\s+R\b`)
+)
+
+// pdfObject is an indirect object found by scanning the file body
+type pdfObject struct {
+	num   int
+	value string // Raw object value, usually a dictionary
+
+	// Location of the raw (still encoded) stream data in the file
+	hasStream    bool
+	streamOffset int64
+	streamLength int64
+}
+
+// pdfDocument is a lightweight, xref-independent view of a PDF's objects.
+// Objects are located by scanning for "N G obj" headers, which also tolerates
+// files with broken cross-reference tables.
+type pdfDocument struct {
+	src     Source
+	objects map[int]*pdfObject
+	trailer map[string]string // Last trailer dictionary or cross-reference stream dictionary
+}
+
+// pdfPageInfo holds a page dictionary with inherited attributes applied
+type pdfPageInfo struct {
+	num       int               // Object number of the page dictionary
+	entries   map[string]string // Page dictionary entries
+	resources map[string]string // Resolved (possibly inherited) resources
+}
+
+// parsePDFDocument indexes every indirect object in the file, including
+// objects packed into compressed object streams. The file is scanned in
+// windows and stream data is left in the source until it is decoded, so
+// large scanned PDFs are never held in memory as a whole.
+func parsePDFDocument(src Source) (*pdfDocument, error) {
+	doc := &pdfDocument{src: src, objects: make(map[int]*pdfObject)}
+
+	headers, err := scanPDFObjectHeaders(src)
+	if err != nil {
+		return nil, err
+	}
+	for i, header := range headers {
+		limit := src.Size()
+		if i+1 < len(headers) {
+			limit = headers[i+1].offset
+		}
+		obj, err := readPDFObject(src, header.num, header.bodyOffset, limit)
+		if err != nil {
+			return nil, err
+		}
+		if obj != nil {
+			doc.objects[header.num] = obj
+		}
+	}
+
+	doc.expandObjectStreams()
+	if doc.trailer, err = findPDFTrailer(src, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// pdfObjectHeader is the position of an "N G obj" header
+type pdfObjectHeader struct {
+	num        int
+	offset     int64 // Start of the header
+	bodyOffset int64 // First byte after the "obj" keyword
+}
+
+// scanPDFObjectHeaders finds every object header in the file
+func scanPDFObjectHeaders(src Source) ([]pdfObjectHeader, error) {
+	var headers []pdfObjectHeader
+	size := src.Size()
+	buf := make([]byte, min(size+1, pdfScanWindow+pdfScanOverlap+1))
+	for pos := int64(0); pos < size; pos += pdfScanWindow {
+		// Start one byte early to see what precedes a header at pos
+		start := max(pos-1, 0)
+		chunk, err := readPDFAt(src, buf, start)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range pdfObjHeaderRe.FindAllSubmatchIndex(chunk, -1) {
+			offset := start + int64(match[0])
+			if offset < pos || offset >= pos+pdfScanWindow {
+				continue // Found by the neighbouring window
+			}
+			if match[0] > 0 && !isPDFWhitespace(chunk[match[0]-1]) {
+				continue
+			}
+			num, err := strconv.Atoi(string(chunk[match[2]:match[3]]))
+			if err != nil {
+				continue
+			}
+			headers = append(headers, pdfObjectHeader{num: num, offset: offset, bodyOffset: start + int64(match[1])})
+		}
+	}
+	return headers, nil
+}
+
+// readPDFObject parses the value (and locates the optional stream) of the
+// object whose body spans start to limit
+func readPDFObject(src Source, num int, start, limit int64) (*pdfObject, error) {
+	// Values are read in growing chunks until they end well before the
+	// chunk does; stream data after the value is not read
+	var body string
+	var value string
+	var end int
+	for n := min(limit-start, pdfObjectValueChunk); ; n = min(limit-start, n*4) {
+		data, err := readPDFAt(src, make([]byte, n), start)
+		if err != nil {
+			return nil, err
+		}
+		body = string(data)
+		value, end = readPDFValue(body, 0)
+		rest := strings.TrimLeft(body[end:], " \t\r\n\f")
+		if n == limit-start || len(rest) >= len("endstream") {
+			break
+		}
+	}
+	if value == "" {
+		return nil, nil
+	}
+	obj := &pdfObject{num: num, value: value}
+
+	rest := body[end:]
+	trimmed := strings.TrimLeft(rest, " \t\r\n\f")
+	if !strings.HasPrefix(trimmed, "stream") {
+		return obj, nil
+	}
+
+	streamStart := end + (len(rest) - len(trimmed)) + len("stream")
+	if streamStart < len(body) && body[streamStart] == '\r' {
+		streamStart++
+	}
+	if streamStart < len(body) && body[streamStart] == '\n' {
+		streamStart++
+	}
+	offset := start + int64(streamStart)
+	obj.hasStream = true
+	obj.streamOffset = offset
+
+	entries := pdfDictEntries(value)
+	if length, err := strconv.ParseInt(entries["Length"], 10, 64); err == nil && length >= 0 && offset+length <= src.Size() {
+		after, err := readPDFAt(src, make([]byte, min(src.Size()-offset-length, 32)), offset+length)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(bytes.TrimLeft(after, " \t\r\n\f"), []byte("endstream")) {
+			obj.streamLength = length
+			return obj, nil
+		}
+	}
+
+	// Indirect or wrong /Length: fall back to the endstream keyword
+	endIdx, err := indexPDFSource(src, []byte("endstream"), offset)
+	if err != nil {
+		return nil, err
+	}
+	if endIdx < 0 {
+		obj.hasStream = false
+		return obj, nil
+	}
+	length := endIdx - offset
+	if length > 0 {
+		tail, err := readPDFAt(src, make([]byte, min(length, 2)), endIdx-min(length, 2))
+		if err != nil {
+			return nil, err
+		}
+		trimmedTail := bytes.TrimSuffix(bytes.TrimSuffix(tail, []byte("\n")), []byte("\r"))
+		length -= int64(len(tail) - len(trimmedTail))
+	}
+	obj.streamLength = length
+	return obj, nil
+}
+
+// findPDFTrailer returns the most recent trailer. Files using cross-reference
+// streams carry the trailer entries in the /XRef stream dictionary instead.
+func findPDFTrailer(src Source, doc *pdfDocument) (map[string]string, error) {
+	idx, err := lastIndexPDFSource(src, []byte("trailer"))
+	if err != nil {
+		return nil, err
+	}
+	if idx >= 0 {
+		start := idx + int64(len("trailer"))
+		data, err := readPDFAt(src, make([]byte, min(src.Size()-start, 4096)), start)
+		if err != nil {
+			return nil, err
+		}
+		value, _ := readPDFValue(string(data), 0)
+		if entries := pdfDictEntries(value); len(entries) > 0 {
+			return entries, nil
+		}
+	}
+
+	var trailer map[string]string
+	latest := -1
+	for num, obj := range doc.objects {
+		entries := pdfDictEntries(obj.value)
+		if entries["Type"] == "/XRef" && num > latest {
+			trailer = entries
+			latest = num
+		}
+	}
+	return trailer, nil
+}
+
+// streamData reads an object's raw (still encoded) stream from the source
+func (d *pdfDocument) streamData(obj *pdfObject) ([]byte, error) {
+	if !obj.hasStream {
+		return nil, fmt.Errorf("pdf: object %d has no stream", obj.num)
+	}
+	return readPDFAt(d.src, make([]byte, obj.streamLength), obj.streamOffset)
+}
+
+// readPDFAt fills buf from offset, or as much of it as the source holds
+func readPDFAt(src Source, buf []byte, offset int64) ([]byte, error) {
+	n, err := src.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("pdf: failed to read: %w", err)
+	}
+	return buf[:n], nil
+}
+
+// indexPDFSource returns the offset of the first occurrence of sep at or
+// after from, or -1
+func indexPDFSource(src Source, sep []byte, from int64) (int64, error) {
+	buf := make([]byte, pdfScanWindow+len(sep))
+	for pos := from; pos < src.Size(); pos += pdfScanWindow {
+		chunk, err := readPDFAt(src, buf, pos)
+		if err != nil {
+			return -1, err
+		}
+		if idx := bytes.Index(chunk, sep); idx >= 0 {
+			return pos + int64(idx), nil
+		}
+	}
+	return -1, nil
+}
+
+// lastIndexPDFSource returns the offset of the last occurrence of sep, or -1
+func lastIndexPDFSource(src Source, sep []byte) (int64, error) {
+	buf := make([]byte, pdfScanWindow+len(sep))
+	for end := src.Size(); end > 0; end -= pdfScanWindow {
+		start := max(end-pdfScanWindow, 0)
+		chunk, err := readPDFAt(src, buf[:min(int64(len(buf)), src.Size()-start)], start)
+		if err != nil {
+			return -1, err
+		}
+		if idx := bytes.LastIndex(chunk, sep); idx >= 0 {
+			return start + int64(idx), nil
+		}
+	}
+	return -1, nil
+}
+
+// expandObjectStreams adds objects stored inside /Type /ObjStm streams.
+// Objects defined directly in the file body take precedence.
+func (d *pdfDocument) expandObjectStreams() {
+	for _, obj := range d.objects {
+		if !obj.hasStream {
+			continue
+		}
+		entries := pdfDictEntries(obj.value)
+		if entries["Type"] != "/ObjStm" {
+			continue
+		}
+		decoded, err := d.decodeStream(obj)
+		if err != nil {
+			continue
+		}
+		count, _ := strconv.Atoi(entries["N"])
+		first, _ := strconv.Atoi(entries["First"])
+		if count <= 0 || first <= 0 || first > len(decoded) {
+			continue
+		}
+
+		header := strings.Fields(string(decoded[:first]))
+		type entry struct{ num, offset int }
+		items := make([]entry, 0, count)
+		for i := 0; i+1 < len(header) && len(items) < count; i += 2 {
+			num, err1 := strconv.Atoi(header[i])
+			offset, err2 := strconv.Atoi(header[i+1])
+			if err1 != nil || err2 != nil {
+				break
+			}
+			items = append(items, entry{num, offset})
+		}
+
+		content := string(decoded[first:])
+		for _, item := range items {
+			if _, exists := d.objects[item.num]; exists || item.offset >= len(content) {
+				continue
+			}
+			value, _ := readPDFValue(content, item.offset)
+			if value != "" {
+				d.objects[item.num] = &pdfObject{num: item.num, value: value}
+			}
+		}
+	}
+}
+
+// resolve follows an indirect reference and returns the referenced object value.
+// Direct values are returned unchanged.
+func (d *pdfDocument) resolve(value string) string {
+	for depth := 0; depth < pdfMaxTreeDepth; depth++ {
+		match := pdfSingleRefRe.FindStringSubmatch(strings.TrimSpace(value))
+		if match == nil {
+			return value
+		}
+		num, _ := strconv.Atoi(match[1])
+		obj, ok := d.objects[num]
+		if !ok {
+			return ""
+		}
+		value = obj.value
+	}
+	return value
+}
+
+// objectFor returns the object an indirect reference points to
+func (d *pdfDocument) objectFor(value string) *pdfObject {
+	match := pdfSingleRefRe.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return nil
+	}
+	num, _ := strconv.Atoi(match[1])
+	return d.objects[num]
+}
+
+// dict resolves a value and parses it as a dictionary
+func (d *pdfDocument) dict(value string) map[string]string {
+	return pdfDictEntries(d.resolve(value))
+}
+
+// catalog returns the document catalog dictionary
+func (d *pdfDocument) catalog() map[string]string {
+	if root := d.dict(d.trailer["Root"]); root["Type"] == "/Catalog" {
+		return root
+	}
+	for _, obj := range d.objects {
+		entries := pdfDictEntries(obj.value)
+		if entries["Type"] == "/Catalog" {
+			return entries
+		}
+	}
+	return nil
+}
+
+// pages walks the page tree from the catalog and returns pages in reading order
+func (d *pdfDocument) pages() []pdfPageInfo {
+	catalog := d.catalog()
+	if catalog == nil {
+		return nil
+	}
+	var pages []pdfPageInfo
+	visited := make(map[int]bool)
+	d.walkPageTree(catalog["Pages"], nil, visited, 0, &pages)
+	return pages
+}
+
+func (d *pdfDocument) walkPageTree(ref string, inherited map[string]string, visited map[int]bool, depth int, pages *[]pdfPageInfo) {
+	if depth > pdfMaxTreeDepth {
+		return
+	}
+	obj := d.objectFor(ref)
+	if obj == nil || visited[obj.num] {
+		return
+	}
+	visited[obj.num] = true
+
+	entries := pdfDictEntries(obj.value)
+	resources := inherited
+	if value, ok := entries["Resources"]; ok {
+		resources = d.dict(value)
+	}
+
+	switch entries["Type"] {
+	case "/Pages":
+		for _, kid := range pdfArrayRefs(d.resolve(entries["Kids"])) {
+			d.walkPageTree(kid, resources, visited, depth+1, pages)
+		}
+	case "/Page":
+		*pages = append(*pages, pdfPageInfo{num: obj.num, entries: entries, resources: resources})
+	default:
+		// Some producers omit /Type on leaves; treat dictionaries with kids as nodes
+		if kids, ok := entries["Kids"]; ok {
+			for _, kid := range pdfArrayRefs(d.resolve(kids)) {
+				d.walkPageTree(kid, resources, visited, depth+1, pages)
+			}
+		} else if _, ok := entries["Contents"]; ok {
+			*pages = append(*pages, pdfPageInfo{num: obj.num, entries: entries, resources: resources})
+		}
+	}
+}
+
+// pageContent returns the decoded content streams of a page
+func (d *pdfDocument) pageContent(page pdfPageInfo) [][]byte {
+	contents, ok := page.entries["Contents"]
+	if !ok {
+		return nil
+	}
+	refs := pdfArrayRefs(d.resolve(contents))
+	if len(refs) == 0 {
+		refs = []string{contents}
+	}
+
+	var streams [][]byte
+	for _, ref := range refs {
+		obj := d.objectFor(ref)
+		if obj == nil || !obj.hasStream {
+			continue
+		}
+		decoded, err := d.decodeStream(obj)
+		if err != nil {
+			continue
+		}
+		streams = append(streams, decoded)
+	}
+	return streams
+}
+
+// decodeStream applies the stream's filters. Image codecs (DCT, JPX) are left
+// encoded because their output is already a standalone image file.
+func (d *pdfDocument) decodeStream(obj *pdfObject) ([]byte, error) {
+	entries := pdfDictEntries(obj.value)
+	filters := pdfNames(d.resolve(entries["Filter"]))
+	params := d.resolve(entries["DecodeParms"])
+	data, err := d.streamData(obj)
+	if err != nil {
+		return nil, err
+	}
+	for i, filter := range filters {
+		switch filter {
+		case "FlateDecode", "Fl":
+			decoded, err := decompressFlate(data)
+			if err != nil {
+				return nil, err
+			}
+			decoded, err = applyPDFPredictor(decoded, d.filterParams(params, i))
+			if err != nil {
+				return nil, err
+			}
+			data = decoded
+		case "DCTDecode", "DCT", "JPXDecode":
+			return data, nil
+		default:
+			return nil, fmt.Errorf("pdf: unsupported stream filter %s", filter)
+		}
+	}
+	return data, nil
+}
+
+// filterParams returns the decode parameters for the filter at index i
+func (d *pdfDocument) filterParams(params string, i int) map[string]string {
+	params = strings.TrimSpace(params)
+	if strings.HasPrefix(params, "[") {
+		items := pdfArrayItems(params)
+		if i < len(items) {
+			return d.dict(items[i])
+		}
+		return nil
+	}
+	return pdfDictEntries(params)
+}
+
+// applyPDFPredictor reverses PNG predictors used with FlateDecode
+func applyPDFPredictor(data []byte, params map[string]string) ([]byte, error) {
+	predictor, _ := strconv.Atoi(params["Predictor"])
+	if predictor < 10 {
+		return data, nil
+	}
+	columns := pdfIntOr(params["Columns"], 1)
+	colors := pdfIntOr(params["Colors"], 1)
+	bpc := pdfIntOr(params["BitsPerComponent"], 8)
+	bpp := (colors*bpc + 7) / 8
+	rowLen := (columns*colors*bpc + 7) / 8
+	if rowLen <= 0 {
+		return nil, fmt.Errorf("pdf: invalid predictor row length")
+	}
+
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+	for pos := 0; pos+rowLen+1 <= len(data); pos += rowLen + 1 {
+		filterType := data[pos]
+		row := make([]byte, rowLen)
+		copy(row, data[pos+1:pos+1+rowLen])
+		for x := 0; x < rowLen; x++ {
+			var left, upLeft byte
+			if x >= bpp {
+				left = row[x-bpp]
+				upLeft = prev[x-bpp]
+			}
+			up := prev[x]
+			switch filterType {
+			case 1:
+				row[x] += left
+			case 2:
+				row[x] += up
+			case 3:
+				row[x] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[x] += paethPredictor(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paethPredictor(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := absInt(p-int(a)), absInt(p-int(b)), absInt(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func pdfIntOr(value string, fallback int) int {
+	parsed, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
+
+// pdfDictEntries parses the top-level entries of a dictionary value.
+// Keys are returned without the leading slash; values are raw PDF syntax.
+func pdfDictEntries(value string) map[string]string {
+	entries := make(map[string]string)
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "<<") || !strings.HasSuffix(value, ">>") {
+		return entries
+	}
+	inner := value[2 : len(value)-2]
+	i := 0
+	for i < len(inner) {
+		i = skipPDFWhitespace(inner, i)
+		if i >= len(inner) {
+			break
+		}
+		if inner[i] != '/' {
+			// Skip unexpected tokens rather than failing the whole dictionary
+			_, i = readPDFValue(inner, i)
+			continue
+		}
+		key, next := readPDFValue(inner, i)
+		val, end := readPDFValue(inner, next)
+		entries[strings.TrimPrefix(key, "/")] = val
+		if end <= i {
+			break
+		}
+		i = end
+	}
+	return entries
+}
+
+// pdfArrayItems splits an array value into its raw items
+func pdfArrayItems(value string) []string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil
+	}
+	inner := value[1 : len(value)-1]
+	var items []string
+	for i := 0; i < len(inner); {
+		item, next := readPDFValue(inner, i)
+		if next <= i {
+			break
+		}
+		if item != "" {
+			items = append(items, item)
+		}
+		i = next
+	}
+	return items
+}
+
+// pdfArrayRefs returns the indirect references contained in an array value
+func pdfArrayRefs(value string) []string {
+	var refs []string
+	for _, item := range pdfArrayItems(value) {
+		if pdfSingleRefRe.MatchString(item) {
+			refs = append(refs, item)
+		}
+	}
+	return refs
+}
+
+// pdfNames returns filter names from a name or array of names, without slashes
+func pdfNames(value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if strings.HasPrefix(value, "/") {
+		return []string{strings.TrimPrefix(value, "/")}
+	}
+	var names []string
+	for _, item := range pdfArrayItems(value) {
+		if strings.HasPrefix(item, "/") {
+			names = append(names, strings.TrimPrefix(item, "/"))
+		}
+	}
+	return names
+}
+
+// readPDFValue reads one PDF value starting at i and returns it with the index after it
+func readPDFValue(s string, i int) (string, int) {
+	i = skipPDFWhitespace(s, i)
+	if i >= len(s) {
+		return "", len(s)
+	}
+	start := i
+	switch {
+	case strings.HasPrefix(s[i:], "<<"):
+		end := matchPDFDelimited(s, i)
+		return s[start:end], end
+	case s[i] == '<':
+		end := strings.IndexByte(s[i:], '>')
+		if end < 0 {
+			return s[start:], len(s)
+		}
+		return s[start : i+end+1], i + end + 1
+	case s[i] == '[':
+		end := matchPDFDelimited(s, i)
+		return s[start:end], end
+	case s[i] == '(':
+		_, end := parseLiteralString(s, i)
+		return s[start:end], end
+	case s[i] == '/':
+		i++
+		for i < len(s) && !isPDFDelimiter(s[i]) {
+			i++
+		}
+		return s[start:i], i
+	case s[i] == ']' || s[i] == '>' || s[i] == ')':
+		return "", i + 1
+	}
+
+	for i < len(s) && !isPDFDelimiter(s[i]) {
+		i++
+	}
+	token := s[start:i]
+	if pdfIntTokenRe.MatchString(token) {
+		if loc := pdfTrailingRe.FindStringIndex(s[i:]); loc != nil {
+			// "N G R" indirect reference
+			candidate := strings.Fields(s[start : i+loc[1]])
+			if len(candidate) == 3 && pdfIntTokenRe.MatchString(candidate[1]) {
+				return strings.Join(candidate, " "), i + loc[1]
+			}
+		}
+	}
+	return token, i
+}
+
+// matchPDFDelimited returns the index after the array or dictionary starting at i
+func matchPDFDelimited(s string, i int) int {
+	depth := 0
+	for i < len(s) {
+		switch {
+		case strings.HasPrefix(s[i:], "<<"):
+			depth++
+			i += 2
+			continue
+		case strings.HasPrefix(s[i:], ">>"):
+			depth--
+			i += 2
+		case s[i] == '[':
+			depth++
+			i++
+		case s[i] == ']':
+			depth--
+			i++
+		case s[i] == '(':
+			_, i = parseLiteralString(s, i)
+			continue
+		case s[i] == '<':
+			end := strings.IndexByte(s[i:], '>')
+			if end < 0 {
+				return len(s)
+			}
+			i += end + 1
+			continue
+		case s[i] == '%':
+			for i < len(s) && s[i] != '\n' && s[i] != '\r' {
+				i++
+			}
+			continue
+		default:
+			i++
+			continue
+		}
+		if depth == 0 {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func skipPDFWhitespace(s string, i int) int {
+	for i < len(s) {
+		if isPDFWhitespace(s[i]) {
+			i++
+			continue
+		}
+		if s[i] == '%' {
+			for i < len(s) && s[i] != '\n' && s[i] != '\r' {
+				i++
+			}
+			continue
+		}
+		break
+	}
+	return i
+}
+
+func isPDFWhitespace(ch byte) bool {
+	switch ch {
+	case ' ', '\t', '\n', '\r', '\f', 0:
+		return true
+	default:
+		return false
+	}
+}
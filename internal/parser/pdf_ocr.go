@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/unalkalkan/TwelveReader/internal/provider"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+const (
+	// pdfMinPageTextChars is the amount of extracted text below which a page
+	// is treated as scanned and sent through OCR
+	pdfMinPageTextChars = 20
+
+	// pdfLowOCRConfidence flags OCR results that likely need manual review
+	pdfLowOCRConfidence = 0.6
+
+	// pdfMaxImagePixels guards against decoding absurdly large raw images
+	pdfMaxImagePixels = 64 * 1024 * 1024
+)
+
+var pdfDoOperatorRe = regexp.MustCompile(`/([^\s/\[\]<>()]+)\s+Do\b`)
+
+// pdfPageImage is an embedded page image encoded as a standalone image file
+type pdfPageImage struct {
+	name string
+	data []byte
+}
+
+// ocrPage runs OCR over the images drawn on a page and returns the recognized
+// lines together with the average confidence across images
+func (p *PDFParser) ocrPage(ctx context.Context, doc *pdfDocument, page pdfPageInfo, pageNumber int) ([]string, float64, error) {
+	images := doc.pageImages(page)
+	if len(images) == 0 {
+		return nil, 0, fmt.Errorf("no supported images on page %d", pageNumber)
+	}
+
+	var lines []string
+	var totalConfidence float64
+	recognized := 0
+	for _, img := range images {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		resp, err := p.ocr.ExtractText(ctx, provider.OCRRequest{ImageData: img.data})
+		if err != nil {
+			log.Printf("[PDFParser] OCR failed for image %s on page %d: %v", img.name, pageNumber, err)
+			continue
+		}
+		recognized++
+		totalConfidence += resp.Confidence
+		for _, line := range strings.Split(resp.Text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	if recognized == 0 {
+		return nil, 0, fmt.Errorf("OCR failed for all images on page %d", pageNumber)
+	}
+	return lines, totalConfidence / float64(recognized), nil
+}
+
+// summarizeOCR computes the chapter-level confidence from per-page results
+func summarizeOCR(pages []types.OCRPage) float64 {
+	if len(pages) == 0 {
+		return 0
+	}
+	var total float64
+	for _, page := range pages {
+		total += page.Confidence
+	}
+	return total / float64(len(pages))
+}
+
+// pageImages returns the image XObjects painted by a page, in drawing order,
+// including images nested inside form XObjects
+func (d *pdfDocument) pageImages(page pdfPageInfo) []pdfPageImage {
+	var images []pdfPageImage
+	seen := make(map[int]bool)
+	for _, content := range d.pageContent(page) {
+		d.collectImages(content, page.resources, seen, 0, &images)
+	}
+	return images
+}
+
+func (d *pdfDocument) collectImages(content []byte, resources map[string]string, seen map[int]bool, depth int, images *[]pdfPageImage) {
+	if depth > pdfMaxTreeDepth {
+		return
+	}
+	xobjects := d.dict(resources["XObject"])
+	for _, match := range pdfDoOperatorRe.FindAllSubmatch(content, -1) {
+		name := string(match[1])
+		obj := d.objectFor(xobjects[name])
+		if obj == nil || !obj.hasStream || seen[obj.num] {
+			continue
+		}
+		seen[obj.num] = true
+
+		entries := pdfDictEntries(obj.value)
+		switch entries["Subtype"] {
+		case "/Image":
+			data, err := d.encodeImage(obj, entries)
+			if err != nil {
+				log.Printf("[PDFParser] Skipping image %s: %v", name, err)
+				continue
+			}
+			*images = append(*images, pdfPageImage{name: name, data: data})
+		case "/Form":
+			formContent, err := d.decodeStream(obj)
+			if err != nil {
+				continue
+			}
+			formResources := resources
+			if value, ok := entries["Resources"]; ok {
+				formResources = d.dict(value)
+			}
+			d.collectImages(formContent, formResources, seen, depth+1, images)
+		}
+	}
+}
+
+// encodeImage converts an image XObject to a file an OCR provider accepts.
+// JPEG streams are passed through and raw samples are re-encoded as PNG.
+// JPEG 2000 (JPXDecode) is rejected like other codecs, since OCR providers
+// do not accept it and it cannot be decoded here.
+func (d *pdfDocument) encodeImage(obj *pdfObject, entries map[string]string) ([]byte, error) {
+	filters := pdfNames(d.resolve(entries["Filter"]))
+	for _, filter := range filters {
+		switch filter {
+		case "DCTDecode", "DCT":
+			return d.decodeStream(obj)
+		case "FlateDecode", "Fl":
+		default:
+			return nil, fmt.Errorf("unsupported image filter %s", filter)
+		}
+	}
+
+	width := pdfIntOr(d.resolve(entries["Width"]), 0)
+	height := pdfIntOr(d.resolve(entries["Height"]), 0)
+	if width == 0 || height == 0 || width*height > pdfMaxImagePixels {
+		return nil, fmt.Errorf("invalid image dimensions %dx%d", width, height)
+	}
+	bpc := pdfIntOr(d.resolve(entries["BitsPerComponent"]), 8)
+	components, err := d.colorComponents(entries["ColorSpace"])
+	if err != nil {
+		return nil, err
+	}
+
+	samples, err := d.decodeStream(obj)
+	if err != nil {
+		return nil, err
+	}
+	img, err := rasterizePDFImage(samples, width, height, components, bpc)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// colorComponents returns the number of color components of a color space
+func (d *pdfDocument) colorComponents(value string) (int, error) {
+	value = strings.TrimSpace(d.resolve(value))
+	switch value {
+	case "/DeviceGray", "/CalGray", "/G":
+		return 1, nil
+	case "/DeviceRGB", "/CalRGB", "/RGB":
+		return 3, nil
+	case "/DeviceCMYK", "/CMYK":
+		return 4, nil
+	}
+
+	items := pdfArrayItems(value)
+	if len(items) >= 2 && items[0] == "/ICCBased" {
+		if n, err := strconv.Atoi(d.dict(items[1])["N"]); err == nil {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported color space %q", value)
+}
+
+// rasterizePDFImage builds an image from raw PDF samples
+func rasterizePDFImage(samples []byte, width, height, components, bpc int) (image.Image, error) {
+	if bpc != 1 && bpc != 8 {
+		return nil, fmt.Errorf("unsupported bits per component %d", bpc)
+	}
+	if bpc == 1 && components != 1 {
+		return nil, fmt.Errorf("unsupported 1-bit color image")
+	}
+
+	rowLen := (width*components*bpc + 7) / 8
+	if len(samples) < rowLen*height {
+		return nil, fmt.Errorf("image data too short: got %d bytes, need %d", len(samples), rowLen*height)
+	}
+
+	if components == 1 {
+		img := image.NewGray(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			row := samples[y*rowLen : (y+1)*rowLen]
+			for x := 0; x < width; x++ {
+				if bpc == 8 {
+					img.Pix[y*img.Stride+x] = row[x]
+					continue
+				}
+				if row[x/8]&(0x80>>(x%8)) != 0 {
+					img.Pix[y*img.Stride+x] = 0xFF
+				}
+			}
+		}
+		return img, nil
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		row := samples[y*rowLen : (y+1)*rowLen]
+		for x := 0; x < width; x++ {
+			px := row[x*components : (x+1)*components]
+			switch components {
+			case 3:
+				img.Set(x, y, color.RGBA{R: px[0], G: px[1], B: px[2], A: 0xFF})
+			case 4:
+				img.Set(x, y, color.CMYK{C: px[0], M: px[1], Y: px[2], K: px[3]})
+			default:
+				return nil, fmt.Errorf("unsupported component count %d", components)
+			}
+		}
+	}
+	return img, nil
+}
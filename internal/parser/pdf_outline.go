@@ -0,0 +1,458 @@
+package parser
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+const (
+	// pdfDefaultChapterTitle is used when a PDF has no outline and no headings
+	pdfDefaultChapterTitle = "PDF Content"
+
+	// pdfFrontMatterTitle names the pages before the first outline entry or heading
+	pdfFrontMatterTitle = "Front Matter"
+)
+
+// pdfOutlineEntry is a bookmark resolved to the page it points at
+type pdfOutlineEntry struct {
+	tocPath []string
+	page    int // 0-based index into the page tree
+}
+
+// pdfParagraph is a reflowed paragraph and the page it starts on
+type pdfParagraph struct {
+	text string
+	page int
+}
+
+// pdfChapterSpan is a chapter's title and its range of paragraphs
+type pdfChapterSpan struct {
+	title      string
+	tocPath    []string
+	paragraphs []pdfParagraph
+}
+
+// outline reads the document's /Outlines tree in reading order. Entries whose
+// destination cannot be resolved to a page are dropped, but their children are kept.
+func (d *pdfDocument) outline(pages []pdfPageInfo) []pdfOutlineEntry {
+	catalog := d.catalog()
+	if catalog == nil {
+		return nil
+	}
+	root := d.dict(catalog["Outlines"])
+	if root["First"] == "" {
+		return nil
+	}
+
+	pageIndex := make(map[int]int, len(pages))
+	for i, page := range pages {
+		pageIndex[page.num] = i
+	}
+	dests := d.namedDestinations(catalog)
+
+	var entries []pdfOutlineEntry
+	visited := make(map[int]bool)
+	d.walkOutline(root["First"], nil, pageIndex, dests, visited, 0, &entries)
+	return entries
+}
+
+func (d *pdfDocument) walkOutline(ref string, parentPath []string, pageIndex map[int]int, dests map[string]string, visited map[int]bool, depth int, entries *[]pdfOutlineEntry) {
+	if depth > pdfMaxTreeDepth {
+		return
+	}
+	for ref != "" {
+		obj := d.objectFor(ref)
+		if obj == nil || visited[obj.num] {
+			return
+		}
+		visited[obj.num] = true
+
+		item := pdfDictEntries(obj.value)
+		title := pdfTextString(d.resolve(item["Title"]))
+		if title == "" {
+			title = fmt.Sprintf("Section %d", len(*entries)+1)
+		}
+		path := append(append([]string(nil), parentPath...), title)
+
+		if page, ok := d.outlineTarget(item, pageIndex, dests); ok {
+			*entries = append(*entries, pdfOutlineEntry{tocPath: path, page: page})
+		}
+		if first := item["First"]; first != "" {
+			d.walkOutline(first, path, pageIndex, dests, visited, depth+1, entries)
+		}
+		ref = item["Next"]
+	}
+}
+
+// outlineTarget resolves an outline item's /Dest or GoTo action to a page index
+func (d *pdfDocument) outlineTarget(item map[string]string, pageIndex map[int]int, dests map[string]string) (int, bool) {
+	dest := item["Dest"]
+	if dest == "" {
+		action := d.dict(item["A"])
+		if action["S"] != "/GoTo" {
+			return 0, false
+		}
+		dest = action["D"]
+	}
+	return d.destinationPage(dest, pageIndex, dests, 0)
+}
+
+// destinationPage resolves an explicit or named destination to a page index
+func (d *pdfDocument) destinationPage(dest string, pageIndex map[int]int, dests map[string]string, depth int) (int, bool) {
+	if depth > pdfMaxTreeDepth {
+		return 0, false
+	}
+	dest = strings.TrimSpace(d.resolve(dest))
+	switch {
+	case strings.HasPrefix(dest, "/"):
+		named, ok := dests[dest]
+		if !ok {
+			return 0, false
+		}
+		return d.destinationPage(named, pageIndex, dests, depth+1)
+	case strings.HasPrefix(dest, "(") || (strings.HasPrefix(dest, "<") && !strings.HasPrefix(dest, "<<")):
+		named, ok := dests[string(pdfStringBytes(dest))]
+		if !ok {
+			return 0, false
+		}
+		return d.destinationPage(named, pageIndex, dests, depth+1)
+	case strings.HasPrefix(dest, "<<"):
+		// Named destinations may map to a dictionary wrapping the array in /D
+		return d.destinationPage(pdfDictEntries(dest)["D"], pageIndex, dests, depth+1)
+	}
+
+	items := pdfArrayItems(dest)
+	if len(items) == 0 {
+		return 0, false
+	}
+	if obj := d.objectFor(items[0]); obj != nil {
+		page, ok := pageIndex[obj.num]
+		return page, ok
+	}
+	// Some producers write a page number instead of a page reference
+	if page, err := strconv.Atoi(items[0]); err == nil && page >= 0 && page < len(pageIndex) {
+		return page, true
+	}
+	return 0, false
+}
+
+// namedDestinations collects the catalog's /Dests dictionary (keyed by name,
+// including the slash) and the /Names /Dests name tree (keyed by string bytes)
+func (d *pdfDocument) namedDestinations(catalog map[string]string) map[string]string {
+	dests := make(map[string]string)
+	for name, value := range d.dict(catalog["Dests"]) {
+		dests["/"+name] = value
+	}
+	names := d.dict(catalog["Names"])
+	if tree, ok := names["Dests"]; ok {
+		d.walkNameTree(tree, dests, make(map[int]bool), 0)
+	}
+	return dests
+}
+
+func (d *pdfDocument) walkNameTree(ref string, out map[string]string, visited map[int]bool, depth int) {
+	if depth > pdfMaxTreeDepth {
+		return
+	}
+	if obj := d.objectFor(ref); obj != nil {
+		if visited[obj.num] {
+			return
+		}
+		visited[obj.num] = true
+	}
+
+	node := d.dict(ref)
+	items := pdfArrayItems(d.resolve(node["Names"]))
+	for i := 0; i+1 < len(items); i += 2 {
+		out[string(pdfStringBytes(items[i]))] = items[i+1]
+	}
+	for _, kid := range pdfArrayRefs(d.resolve(node["Kids"])) {
+		d.walkNameTree(kid, out, visited, depth+1)
+	}
+}
+
+// pdfStringBytes decodes a literal or hex string value to its raw bytes
+func pdfStringBytes(value string) []byte {
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(value, "("):
+		return unescapePDFLiteral(value)
+	case strings.HasPrefix(value, "<"):
+		digits := strings.Map(func(r rune) rune {
+			if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return r
+			}
+			return -1
+		}, value)
+		if len(digits)%2 == 1 {
+			digits += "0"
+		}
+		decoded, err := hex.DecodeString(digits)
+		if err != nil {
+			return nil
+		}
+		return decoded
+	}
+	return nil
+}
+
+// unescapePDFLiteral returns the bytes of a "(...)" literal string
+func unescapePDFLiteral(value string) []byte {
+	var out []byte
+	depth := 0
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+		switch {
+		case ch == '\\' && i+1 < len(value):
+			i++
+			switch next := value[i]; next {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				// Line continuation
+				if i+1 < len(value) && value[i+1] == '\n' {
+					i++
+				}
+			case '\n':
+			default:
+				if next >= '0' && next <= '7' {
+					end := i
+					for end < len(value) && end < i+3 && value[end] >= '0' && value[end] <= '7' {
+						end++
+					}
+					code, _ := strconv.ParseUint(value[i:end], 8, 16)
+					out = append(out, byte(code))
+					i = end - 1
+				} else {
+					out = append(out, next)
+				}
+			}
+		case ch == '(':
+			if depth > 0 {
+				out = append(out, ch)
+			}
+			depth++
+		case ch == ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+			out = append(out, ch)
+		default:
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+// pdfTextString decodes a PDF text string: UTF-16BE or UTF-8 with a byte order
+// mark, otherwise PDFDocEncoding (treated as Latin-1)
+func pdfTextString(value string) string {
+	raw := pdfStringBytes(value)
+	var text string
+	switch {
+	case len(raw) >= 2 && raw[0] == 0xFE && raw[1] == 0xFF:
+		units := make([]uint16, 0, (len(raw)-2)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		text = string(utf16.Decode(units))
+	case len(raw) >= 3 && raw[0] == 0xEF && raw[1] == 0xBB && raw[2] == 0xBF:
+		text = string(raw[3:])
+	case utf8.Valid(raw):
+		text = string(raw)
+	default:
+		runes := make([]rune, len(raw))
+		for i, b := range raw {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// buildPDFChapters splits per-page text into chapters, using the outline when
+// it resolves to pages and heading detection otherwise
+func buildPDFChapters(pageLines [][]string, outline []pdfOutlineEntry, ocrPages []types.OCRPage) []*types.Chapter {
+	spans := outlineChapterSpans(pageLines, outline)
+	if len(spans) == 0 {
+		spans = headingChapterSpans(reflowPDFPages(pageLines, 0))
+	}
+
+	chapters := make([]*types.Chapter, 0, len(spans))
+	for _, span := range spans {
+		paragraphs := make([]string, len(span.paragraphs))
+		for i, paragraph := range span.paragraphs {
+			paragraphs[i] = paragraph.text
+		}
+		chapter := newPDFChapter(len(chapters)+1, span.title, span.tocPath, paragraphs)
+
+		// Attribute OCR results to the chapter whose pages they cover
+		firstPage := span.paragraphs[0].page + 1
+		lastPage := span.paragraphs[len(span.paragraphs)-1].page + 1
+		for _, page := range ocrPages {
+			if page.Page >= firstPage && page.Page <= lastPage {
+				chapter.OCRPages = append(chapter.OCRPages, page)
+			}
+		}
+		chapter.OCRConfidence = summarizeOCR(chapter.OCRPages)
+
+		chapters = append(chapters, chapter)
+	}
+	return chapters
+}
+
+// outlineChapterSpans starts a chapter at each outline entry's page. Entries
+// sharing a page with the next entry (such as a part and its first chapter)
+// contribute only to the TOC path of that next entry.
+func outlineChapterSpans(pageLines [][]string, outline []pdfOutlineEntry) []pdfChapterSpan {
+	if len(outline) == 0 {
+		return nil
+	}
+	entries := append([]pdfOutlineEntry(nil), outline...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].page < entries[j].page
+	})
+
+	var spans []pdfChapterSpan
+	if front := reflowPDFPages(pageLines[:entries[0].page], 0); len(front) > 0 {
+		spans = append(spans, pdfChapterSpan{
+			title:      pdfFrontMatterTitle,
+			tocPath:    []string{pdfFrontMatterTitle},
+			paragraphs: front,
+		})
+	}
+
+	for i, entry := range entries {
+		end := len(pageLines)
+		if i+1 < len(entries) {
+			end = entries[i+1].page
+		}
+		if end <= entry.page {
+			continue
+		}
+		paragraphs := reflowPDFPages(pageLines[entry.page:end], entry.page)
+		if len(paragraphs) == 0 {
+			continue
+		}
+		spans = append(spans, pdfChapterSpan{
+			title:      entry.tocPath[len(entry.tocPath)-1],
+			tocPath:    entry.tocPath,
+			paragraphs: paragraphs,
+		})
+	}
+	return spans
+}
+
+// headingChapterSpans starts a new chapter at each heading that follows body
+// text; title-page lines are not body text, so a title page stays with the
+// first chapter. Headings stay in the paragraphs so they are narrated.
+// When the text has explicit "Chapter N" or numbered headings, only those
+// split chapters, so short title-case lines in the body are not mistaken for them.
+func headingChapterSpans(paragraphs []pdfParagraph) []pdfChapterSpan {
+	if len(paragraphs) == 0 {
+		return nil
+	}
+
+	isHeading := isPDFHeadingLine
+	for _, paragraph := range paragraphs {
+		if isStrongPDFHeading(paragraph.text) {
+			isHeading = isStrongPDFHeading
+			break
+		}
+	}
+
+	var spans []pdfChapterSpan
+	current := pdfChapterSpan{}
+	hasBody := false
+	for _, paragraph := range paragraphs {
+		if isHeading(paragraph.text) && hasBody {
+			spans = append(spans, current)
+			current = pdfChapterSpan{}
+			hasBody = false
+		}
+		if !isPDFHeadingLine(paragraph.text) {
+			hasBody = true
+		}
+		current.paragraphs = append(current.paragraphs, paragraph)
+	}
+	if !hasBody && len(spans) > 0 {
+		// Trailing headings without body text belong to the previous chapter
+		last := &spans[len(spans)-1]
+		last.paragraphs = append(last.paragraphs, current.paragraphs...)
+	} else {
+		spans = append(spans, current)
+	}
+
+	for i := range spans {
+		spans[i].title = pdfHeadingRunTitle(spans[i].paragraphs)
+		if spans[i].title == "" {
+			spans[i].title = pdfDefaultChapterTitle
+			if len(spans) > 1 {
+				spans[i].title = pdfFrontMatterTitle
+			}
+		}
+		spans[i].tocPath = []string{spans[i].title}
+	}
+	return spans
+}
+
+// pdfHeadingRunTitle picks a title from consecutive heading lines, preferring
+// explicit chapter or numbered headings over generic title-case lines
+func pdfHeadingRunTitle(paragraphs []pdfParagraph) string {
+	title := ""
+	for _, paragraph := range paragraphs {
+		if !isPDFHeadingLine(paragraph.text) {
+			break
+		}
+		if isStrongPDFHeading(paragraph.text) {
+			return paragraph.text
+		}
+		if title == "" {
+			title = paragraph.text
+		}
+	}
+	return title
+}
+
+// isStrongPDFHeading reports whether a line is an explicit chapter or numbered heading
+func isStrongPDFHeading(line string) bool {
+	line = strings.TrimSpace(line)
+	return chapterHeadingRe.MatchString(line) || numberedHeadingRe.MatchString(line)
+}
+
+// reflowPDFPages reflows lines across page boundaries, recording the page
+// each paragraph starts on. firstPage is the index of pageLines[0].
+func reflowPDFPages(pageLines [][]string, firstPage int) []pdfParagraph {
+	var paragraphs []pdfParagraph
+	for offset, lines := range pageLines {
+		for _, raw := range lines {
+			line := strings.TrimSpace(raw)
+			if line == "" {
+				continue
+			}
+			last := len(paragraphs) - 1
+			if last < 0 || shouldStartNewPDFParagraph(paragraphs[last].text, line) {
+				paragraphs = append(paragraphs, pdfParagraph{text: line, page: firstPage + offset})
+				continue
+			}
+			paragraphs[last].text = joinPDFWrappedLines(paragraphs[last].text, line)
+		}
+	}
+	return paragraphs
+}
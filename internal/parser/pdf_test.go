@@ -5,8 +5,11 @@ import (
 	"compress/zlib"
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
+
+	"github.com/unalkalkan/TwelveReader/internal/provider"
 )
 
 func TestPDFParser_SupportedFormats(t *testing.T) {
@@ -463,3 +466,324 @@ func buildFlateDecodePDF(pages []pdfPage) []byte {
 
 	return []byte(b.String())
 }
+
+func TestPDFParser_Parse_ScannedPagesUseOCR(t *testing.T) {
+	ocr := &fakeOCRProvider{results: []provider.OCRResponse{
+		{Text: "Chapter One\nIt was a dark and stormy night.", Confidence: 0.9},
+		{Text: "The rain fell in torrents.", Confidence: 0.4},
+	}}
+	p := NewPDFParserWithOCR(ocr)
+
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 'f', 'a', 'k', 'e'}
+	pdfData := buildScannedPDF([]scannedPDFPage{
+		{image: jpeg, imageDict: "/Width 1 /Height 1 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode"},
+		{image: zlibCompress([]byte{0x00, 0xFF, 0xFF, 0x00}), imageDict: "/Width 2 /Height 2 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode"},
+	})
+
+	chapters, err := p.Parse(context.Background(), pdfData)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []string{"Chapter One", "It was a dark and stormy night.", "The rain fell in torrents."}
+	if got := chapters[0].Paragraphs; !equalStringSlices(got, want) {
+		t.Fatalf("unexpected paragraphs:\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	if len(ocr.requests) != 2 {
+		t.Fatalf("Expected 2 OCR requests, got %d", len(ocr.requests))
+	}
+	if !bytes.Equal(ocr.requests[0].ImageData, jpeg) {
+		t.Errorf("Expected JPEG image to be passed through unchanged")
+	}
+	if !bytes.HasPrefix(ocr.requests[1].ImageData, []byte("\x89PNG")) {
+		t.Errorf("Expected raw image samples to be encoded as PNG")
+	}
+
+	ch := chapters[0]
+	if len(ch.OCRPages) != 2 {
+		t.Fatalf("Expected 2 OCR pages, got %#v", ch.OCRPages)
+	}
+	if ch.OCRPages[0].Page != 1 || ch.OCRPages[0].LowConfidence {
+		t.Errorf("Expected page 1 to be recognized with good confidence, got %#v", ch.OCRPages[0])
+	}
+	if ch.OCRPages[1].Page != 2 || !ch.OCRPages[1].LowConfidence {
+		t.Errorf("Expected page 2 to be flagged as low confidence, got %#v", ch.OCRPages[1])
+	}
+	if ch.OCRConfidence < 0.649 || ch.OCRConfidence > 0.651 {
+		t.Errorf("Expected chapter OCR confidence 0.65, got %f", ch.OCRConfidence)
+	}
+}
+
+func TestPDFParser_Parse_OCROnlyForPagesWithoutText(t *testing.T) {
+	ocr := &fakeOCRProvider{results: []provider.OCRResponse{
+		{Text: "Recognized from the scanned page.", Confidence: 0.95},
+	}}
+	p := NewPDFParserWithOCR(ocr)
+
+	pdfData := buildScannedPDF([]scannedPDFPage{
+		{text: "This page already has a proper text layer."},
+		{text: "12", image: []byte{0xFF, 0xD8, 0xFF, 0xE0}, imageDict: "/Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode"},
+	})
+
+	chapters, err := p.Parse(context.Background(), pdfData)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(ocr.requests) != 1 {
+		t.Fatalf("Expected OCR only for the page without text, got %d requests", len(ocr.requests))
+	}
+	want := []string{"This page already has a proper text layer.", "Recognized from the scanned page."}
+	if got := chapters[0].Paragraphs; !equalStringSlices(got, want) {
+		t.Fatalf("unexpected paragraphs:\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if len(chapters[0].OCRPages) != 1 || chapters[0].OCRPages[0].Page != 2 {
+		t.Errorf("Expected only page 2 to be recorded as OCR, got %#v", chapters[0].OCRPages)
+	}
+}
+
+func TestPDFParser_Parse_SkipsJPEG2000Images(t *testing.T) {
+	ocr := &fakeOCRProvider{results: []provider.OCRResponse{
+		{Text: "Recognized from the JPEG page.", Confidence: 0.95},
+	}}
+	p := NewPDFParserWithOCR(ocr)
+
+	pdfData := buildScannedPDF([]scannedPDFPage{
+		{image: []byte{0xFF, 0x4F, 0xFF, 0x51}, imageDict: "/Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /JPXDecode"},
+		{image: []byte{0xFF, 0xD8, 0xFF, 0xE0}, imageDict: "/Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode"},
+	})
+
+	if _, err := p.Parse(context.Background(), pdfData); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(ocr.requests) != 1 || !bytes.HasPrefix(ocr.requests[0].ImageData, []byte{0xFF, 0xD8}) {
+		t.Fatalf("Expected only the JPEG image to be sent to OCR, got %d requests", len(ocr.requests))
+	}
+}
+
+func TestPDFParser_Parse_ScannedPDFWithoutOCR(t *testing.T) {
+	p := NewPDFParser()
+
+	pdfData := buildScannedPDF([]scannedPDFPage{
+		{image: []byte{0xFF, 0xD8, 0xFF, 0xE0}, imageDict: "/Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode"},
+	})
+
+	_, err := p.Parse(context.Background(), pdfData)
+	if err == nil || !strings.Contains(err.Error(), "no extractable text") {
+		t.Fatalf("Expected no extractable text error, got %v", err)
+	}
+}
+
+// fakeOCRProvider returns canned results in order and records requests
+type fakeOCRProvider struct {
+	results  []provider.OCRResponse
+	requests []provider.OCRRequest
+}
+
+func (f *fakeOCRProvider) Name() string { return "fake-ocr" }
+
+func (f *fakeOCRProvider) ExtractText(ctx context.Context, req provider.OCRRequest) (*provider.OCRResponse, error) {
+	f.requests = append(f.requests, req)
+	if len(f.requests) > len(f.results) {
+		return nil, fmt.Errorf("unexpected OCR request %d", len(f.requests))
+	}
+	resp := f.results[len(f.requests)-1]
+	return &resp, nil
+}
+
+func (f *fakeOCRProvider) Close() error { return nil }
+
+type scannedPDFPage struct {
+	text      string
+	image     []byte
+	imageDict string
+}
+
+// buildScannedPDF builds a PDF whose pages paint an image XObject and
+// optionally a short text string
+func buildScannedPDF(pages []scannedPDFPage) []byte {
+	var b bytes.Buffer
+
+	b.WriteString("%PDF-1.4\n")
+	b.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	b.WriteString("2 0 obj\n<< /Type /Pages /Kids [")
+	for i := range pages {
+		b.WriteString(fmt.Sprintf(" %d 0 R", 3+i*3))
+	}
+	b.WriteString(fmt.Sprintf(" ] /Count %d >>\nendobj\n", len(pages)))
+
+	for i, page := range pages {
+		pageObj, contentObj, imageObj := 3+i*3, 4+i*3, 5+i*3
+
+		content := ""
+		if page.image != nil {
+			content += "q 612 0 0 792 0 0 cm /Im1 Do Q\n"
+		}
+		if page.text != "" {
+			content += fmt.Sprintf("BT /F1 12 Tf 100 700 Td (%s) Tj ET\n", page.text)
+		}
+
+		b.WriteString(fmt.Sprintf("%d 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792]", pageObj))
+		b.WriteString(fmt.Sprintf(" /Resources << /XObject << /Im1 %d 0 R >> >> /Contents %d 0 R >>\nendobj\n", imageObj, contentObj))
+
+		b.WriteString(fmt.Sprintf("%d 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", contentObj, len(content), content))
+
+		b.WriteString(fmt.Sprintf("%d 0 obj\n<< /Type /XObject /Subtype /Image %s /Length %d >>\nstream\n", imageObj, page.imageDict, len(page.image)))
+		b.Write(page.image)
+		b.WriteString("\nendstream\nendobj\n")
+	}
+
+	b.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+func zlibCompress(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestPDFParser_Parse_OutlineChapters(t *testing.T) {
+	p := NewPDFParser()
+
+	// Objects 1-2 are the catalog and page tree, pages are 3-6 with content 7-10
+	objects := map[int]string{
+		1:  "<< /Type /Catalog /Pages 2 0 R /Outlines 11 0 R /Names << /Dests 16 0 R >> >>",
+		2:  "<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R 6 0 R] /Count 4 >>",
+		11: "<< /Type /Outlines /First 12 0 R /Last 15 0 R /Count 4 >>",
+		12: "<< /Title (Part One) /Parent 11 0 R /Next 15 0 R /First 13 0 R /Last 14 0 R /Dest [4 0 R /Fit] >>",
+		13: "<< /Title (Chapter 1) /Parent 12 0 R /Next 14 0 R /Dest [4 0 R /XYZ 0 792 0] >>",
+		14: "<< /Title <FEFF004300680061007000740065007200200032> /Parent 12 0 R /Prev 13 0 R /A << /S /GoTo /D [5 0 R /Fit] >> >>",
+		15: "<< /Title (Part Two) /Parent 11 0 R /Prev 12 0 R /A << /S /GoTo /D (part2) >> >>",
+		16: "<< /Names [(part2) [6 0 R /Fit]] >>",
+	}
+	pageTexts := [][]string{
+		{"The Signal"},
+		{"Part One", "Chapter 1", "The radio crackled to life."},
+		{"Chapter 2", "Sarah saved the data."},
+		{"Part Two", "The ship arrived at dawn."},
+	}
+	for i, lines := range pageTexts {
+		content := ""
+		for _, line := range lines {
+			content += fmt.Sprintf("BT /F1 12 Tf 100 700 Td (%s) Tj ET\n", line)
+		}
+		objects[3+i] = fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R >>", 7+i)
+		objects[7+i] = fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+	}
+
+	chapters, err := p.Parse(context.Background(), buildPDFFromObjects(objects))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []struct {
+		title      string
+		tocPath    []string
+		paragraphs []string
+	}{
+		{"Front Matter", []string{"Front Matter"}, []string{"The Signal"}},
+		{"Chapter 1", []string{"Part One", "Chapter 1"}, []string{"Part One", "Chapter 1", "The radio crackled to life."}},
+		{"Chapter 2", []string{"Part One", "Chapter 2"}, []string{"Chapter 2", "Sarah saved the data."}},
+		{"Part Two", []string{"Part Two"}, []string{"Part Two", "The ship arrived at dawn."}},
+	}
+	if len(chapters) != len(want) {
+		t.Fatalf("Expected %d chapters, got %d: %#v", len(want), len(chapters), chapters)
+	}
+	for i, w := range want {
+		ch := chapters[i]
+		if ch.Number != i+1 || ch.ID != fmt.Sprintf("chapter_%03d", i+1) {
+			t.Errorf("chapter %d: unexpected number/id %d/%s", i, ch.Number, ch.ID)
+		}
+		if ch.Title != w.title {
+			t.Errorf("chapter %d: expected title %q, got %q", i, w.title, ch.Title)
+		}
+		if !equalStringSlices(ch.TOCPath, w.tocPath) {
+			t.Errorf("chapter %d: expected TOC path %v, got %v", i, w.tocPath, ch.TOCPath)
+		}
+		if !equalStringSlices(ch.Paragraphs, w.paragraphs) {
+			t.Errorf("chapter %d: expected paragraphs %#v, got %#v", i, w.paragraphs, ch.Paragraphs)
+		}
+	}
+}
+
+func TestPDFParser_Parse_HeadingChaptersWithoutOutline(t *testing.T) {
+	p := NewPDFParser()
+
+	pdfData := buildSimplePDF([]pdfPage{
+		{textStrings: []string{"The Signal", "Chapter One", "The radio crackled to life."}},
+		{textStrings: []string{"It was not random noise.", "Chapter Two", "Sarah saved the data."}},
+	})
+
+	chapters, err := p.Parse(context.Background(), pdfData)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(chapters) != 2 {
+		t.Fatalf("Expected 2 chapters, got %d: %#v", len(chapters), chapters)
+	}
+	if chapters[0].Title != "Chapter One" || !equalStringSlices(chapters[0].TOCPath, []string{"Chapter One"}) {
+		t.Errorf("unexpected first chapter title %q / %v", chapters[0].Title, chapters[0].TOCPath)
+	}
+	wantFirst := []string{"The Signal", "Chapter One", "The radio crackled to life.", "It was not random noise."}
+	if !equalStringSlices(chapters[0].Paragraphs, wantFirst) {
+		t.Errorf("unexpected first chapter paragraphs %#v", chapters[0].Paragraphs)
+	}
+	if chapters[1].Title != "Chapter Two" || chapters[1].ID != "chapter_002" {
+		t.Errorf("unexpected second chapter %q (%s)", chapters[1].Title, chapters[1].ID)
+	}
+	wantSecond := []string{"Chapter Two", "Sarah saved the data."}
+	if !equalStringSlices(chapters[1].Paragraphs, wantSecond) {
+		t.Errorf("unexpected second chapter paragraphs %#v", chapters[1].Paragraphs)
+	}
+}
+
+// buildPDFFromObjects writes the given numbered objects into a minimal PDF
+func buildPDFFromObjects(objects map[int]string) []byte {
+	nums := make([]int, 0, len(objects))
+	for num := range objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n")
+	for _, num := range nums {
+		b.WriteString(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", num, objects[num]))
+	}
+	b.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return []byte(b.String())
+}
+
+func TestPDFParser_Metadata(t *testing.T) {
+	p := NewPDFParser()
+
+	pdfData := buildPDFFromObjects(map[int]string{
+		1: "<< /Type /Catalog /Pages 2 0 R /Lang (de-DE) >>",
+		2: "<< /Type /Pages /Kids [] /Count 0 >>",
+		3: "<< /Title <FEFF0044006900650020005300690067006E0061006C> /Author (A. N. Author) /Producer (Test) >>",
+	})
+	pdfData = bytes.Replace(pdfData, []byte("<< /Root 1 0 R >>"), []byte("<< /Root 1 0 R /Info 3 0 R >>"), 1)
+
+	metadata, err := p.Metadata(context.Background(), pdfData)
+	if err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	if metadata.Title != "Die Signal" {
+		t.Errorf("Expected UTF-16 title 'Die Signal', got %q", metadata.Title)
+	}
+	if metadata.Author != "A. N. Author" {
+		t.Errorf("Expected author 'A. N. Author', got %q", metadata.Author)
+	}
+	if metadata.Language != "de" {
+		t.Errorf("Expected language 'de', got %q", metadata.Language)
+	}
+	if metadata.Cover != nil {
+		t.Errorf("Expected no cover for PDF")
+	}
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// Document is a document stored as a Source together with its parser.
+// Parsers implementing SourceParser read the source directly; the content is
+// loaded into memory, at most once, for the others.
+type Document struct {
+	parser Parser
+	src    Source
+	data   []byte
+}
+
+// NewDocument creates a document read by the given parser
+func NewDocument(p Parser, src Source) *Document {
+	return &Document{parser: p, src: src}
+}
+
+// Parse extracts chapters and text from the document
+func (d *Document) Parse(ctx context.Context) ([]*types.Chapter, error) {
+	if sp, ok := d.parser.(SourceParser); ok {
+		return sp.ParseSource(ctx, d.src)
+	}
+	data, err := d.bytes()
+	if err != nil {
+		return nil, err
+	}
+	return d.parser.Parse(ctx, data)
+}
+
+// Metadata extracts document-level metadata
+func (d *Document) Metadata(ctx context.Context) (*Metadata, error) {
+	if sp, ok := d.parser.(SourceParser); ok {
+		return sp.MetadataSource(ctx, d.src)
+	}
+	data, err := d.bytes()
+	if err != nil {
+		return nil, err
+	}
+	return d.parser.Metadata(ctx, data)
+}
+
+// bytes reads the whole source into memory
+func (d *Document) bytes() ([]byte, error) {
+	if d.data != nil {
+		return d.data, nil
+	}
+	data, err := io.ReadAll(io.NewSectionReader(d.src, 0, d.src.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	d.data = data
+	return data, nil
+}
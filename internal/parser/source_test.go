@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// countingSource records how many bytes are read from a source
+type countingSource struct {
+	*bytes.Reader
+	read int64
+}
+
+func (s *countingSource) ReadAt(p []byte, off int64) (int, error) {
+	n, err := s.Reader.ReadAt(p, off)
+	s.read += int64(n)
+	return n, err
+}
+
+func TestDocument_LoadsContentOnce(t *testing.T) {
+	content := []byte("Chapter 1\n\nIt was a bright cold day in April.")
+	src := &countingSource{Reader: bytes.NewReader(content)}
+	doc := NewDocument(NewTXTParser(), src)
+
+	if _, err := doc.Parse(context.Background()); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, err := doc.Metadata(context.Background()); err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	if src.read != int64(len(content)) {
+		t.Errorf("Expected content to be read once (%d bytes), read %d", len(content), src.read)
+	}
+}
+
+func TestDocument_ReadsZipSourceInPlace(t *testing.T) {
+	cover := bytes.Repeat([]byte{0xFF, 0xD8, 0xFF}, 4096)
+	data := createEpubZip(map[string]string{
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="content.opf"/></rootfiles></container>`,
+		"content.opf": `<package xmlns="http://www.idpf.org/2007/opf"><metadata xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>In Place</dc:title><meta name="cover" content="cover"/></metadata>
+<manifest><item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/><item id="cover" href="cover.jpg" media-type="image/jpeg"/></manifest>
+<spine><itemref idref="ch1"/></spine></package>`,
+		"ch1.xhtml": `<html><body><h1>One</h1><p>Text of chapter one.</p></body></html>`,
+		"cover.jpg": string(cover),
+	})
+	src := &countingSource{Reader: bytes.NewReader(data)}
+	doc := NewDocument(NewEPUBParser(), src)
+
+	chapters, err := doc.Parse(context.Background())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(chapters) != 1 || chapters[0].Paragraphs[len(chapters[0].Paragraphs)-1] != "Text of chapter one." {
+		t.Errorf("Unexpected chapters: %+v", chapters)
+	}
+
+	metadata, err := doc.Metadata(context.Background())
+	if err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	if metadata.Title != "In Place" || !bytes.Equal(metadata.Cover, cover) {
+		t.Errorf("Unexpected metadata: title %q, cover %d bytes", metadata.Title, len(metadata.Cover))
+	}
+}
+
+func TestDocument_ReadsPDFSourceInPlace(t *testing.T) {
+	// An image-sized stream pushes the page content past the first scan windows
+	content := "BT /F1 12 Tf 100 700 Td (Text after a scanned page.) Tj ET"
+	padding := bytes.Repeat([]byte{0xAB}, 3*pdfScanWindow+17)
+	data := buildPDFFromObjects(map[int]string{
+		1: "<< /Type /Catalog /Pages 2 0 R >>",
+		2: "<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		3: "<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+		4: fmt.Sprintf("<< /Type /XObject /Subtype /Image /Length %d >>\nstream\n%s\nendstream", len(padding), padding),
+		5: fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	})
+	src := &countingSource{Reader: bytes.NewReader(data)}
+	doc := NewDocument(NewPDFParser(), src)
+
+	chapters, err := doc.Parse(context.Background())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(chapters) != 1 || strings.Join(chapters[0].Paragraphs, " ") != "Text after a scanned page." {
+		t.Errorf("Unexpected chapters: %+v", chapters)
+	}
+	if doc.data != nil {
+		t.Error("Expected the PDF to be parsed without loading it into memory")
+	}
+}
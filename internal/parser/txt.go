@@ -25,7 +25,7 @@ func NewTXTParser() *TXTParser {
 
 // Parse extracts chapters and text from a TXT file
 func (p *TXTParser) Parse(ctx context.Context, data []byte) ([]*types.Chapter, error) {
-	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner := bufio.NewScanner(bytes.NewReader(decodeText(data)))
 
 	chapters := make([]*types.Chapter, 0)
 	currentChapter := &types.Chapter{
@@ -110,6 +110,12 @@ func (p *TXTParser) Parse(ctx context.Context, data []byte) ([]*types.Chapter, e
 
 // isChapterHeading checks if a line looks like a chapter heading
 func (p *TXTParser) isChapterHeading(line string) bool {
+	return isChapterHeading(line)
+}
+
+// isChapterHeading checks if a line of unstructured text looks like a chapter
+// heading. Parsers use it when the document carries no heading markup.
+func isChapterHeading(line string) bool {
 	if len(line) == 0 {
 		return false
 	}
@@ -179,3 +185,13 @@ func isTitleCase(s string) bool {
 func (p *TXTParser) SupportedFormats() []string {
 	return []string{"txt"}
 }
+
+// SupportedMIMETypes returns the media types this parser supports
+func (p *TXTParser) SupportedMIMETypes() []string {
+	return []string{"text/plain"}
+}
+
+// Metadata returns empty metadata; plain text has nowhere to declare it
+func (p *TXTParser) Metadata(ctx context.Context, data []byte) (*Metadata, error) {
+	return &Metadata{}, nil
+}
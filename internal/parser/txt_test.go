@@ -146,3 +146,13 @@ func TestTXTParser_SupportedFormats(t *testing.T) {
 		t.Errorf("Expected format 'txt', got %q", formats[0])
 	}
 }
+
+func TestTXTParser_Metadata(t *testing.T) {
+	metadata, err := NewTXTParser().Metadata(context.Background(), []byte("Some text"))
+	if err != nil {
+		t.Fatalf("Metadata failed: %v", err)
+	}
+	if metadata.Title != "" || metadata.Author != "" || metadata.Language != "" || metadata.Cover != nil {
+		t.Errorf("Expected empty metadata, got %#v", metadata)
+	}
+}
@@ -0,0 +1,289 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/util"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// ErrNoCheckpoint is returned by ResumePipeline when a book has no saved checkpoint.
+var ErrNoCheckpoint = errors.New("pipeline: no checkpoint found")
+
+// PipelineCheckpoint is the persisted progress of a hybrid pipeline. It holds
+// everything needed to rebuild hybridPipelineState after a restart without
+// re-running segmentation on paragraphs that already produced segments.
+type PipelineCheckpoint struct {
+	BookID string `json:"book_id"`
+
+	// Segmentation position: the next paragraph to send to the LLM
+	NextChapter         int `json:"next_chapter"`
+	NextParagraph       int `json:"next_paragraph"`
+	ProcessedParagraphs int `json:"processed_paragraphs"`
+	SegmentCounter      int `json:"segment_counter"`
+
+	// Persona tracking
+	DiscoveredPersonas     []string          `json:"discovered_personas"`
+	MappedPersonas         map[string]string `json:"mapped_personas"`
+	UnmappedPersonas       []string          `json:"unmapped_personas"`
+	InitialMappingDone     bool              `json:"initial_mapping_done"`
+	InitialMappingReceived bool              `json:"initial_mapping_received"`
+
+	// Segment queue and retry state
+	Queue QueueSnapshot   `json:"queue"`
+	Scope *SynthesisScope `json:"scope,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// checkpointPath returns the storage path of a book's pipeline checkpoint
+func checkpointPath(bookID string) string {
+	return filepath.Join("books", bookID, "pipeline", "checkpoint.json")
+}
+
+// saveCheckpoint persists the current pipeline state. Failures are logged
+// rather than returned so checkpointing never interrupts the pipeline.
+func (o *HybridOrchestrator) saveCheckpoint(ctx context.Context, state *hybridPipelineState) {
+	state.checkpointMu.Lock()
+	defer state.checkpointMu.Unlock()
+
+	if err := o.storeCheckpoint(ctx, state.checkpoint()); err != nil {
+		log.Printf("[saveCheckpoint] Failed to store checkpoint for book %s: %v", state.bookID, err)
+	}
+}
+
+// storeCheckpoint writes a checkpoint to storage
+func (o *HybridOrchestrator) storeCheckpoint(ctx context.Context, checkpoint *PipelineCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	return o.storage.Put(ctx, checkpointPath(checkpoint.BookID), bytes.NewReader(data))
+}
+
+// loadCheckpoint reads a book's pipeline checkpoint from storage
+func (o *HybridOrchestrator) loadCheckpoint(ctx context.Context, bookID string) (*PipelineCheckpoint, error) {
+	path := checkpointPath(bookID)
+	exists, err := o.storage.Exists(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check checkpoint existence: %w", err)
+	}
+	if !exists {
+		return nil, ErrNoCheckpoint
+	}
+
+	reader, err := o.storage.Get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint: %w", err)
+	}
+	defer reader.Close()
+
+	var checkpoint PipelineCheckpoint
+	if err := json.NewDecoder(reader).Decode(&checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// deleteCheckpoint removes a book's checkpoint once the pipeline has finished
+func (o *HybridOrchestrator) deleteCheckpoint(ctx context.Context, bookID string) {
+	if err := o.storage.Delete(ctx, checkpointPath(bookID)); err != nil {
+		log.Printf("[deleteCheckpoint] Failed to delete checkpoint for book %s: %v", bookID, err)
+	}
+}
+
+// checkpoint captures a consistent snapshot of the pipeline state
+func (state *hybridPipelineState) checkpoint() *PipelineCheckpoint {
+	checkpoint := &PipelineCheckpoint{
+		BookID:    state.bookID,
+		UpdatedAt: time.Now(),
+	}
+
+	state.segmentsMu.RLock()
+	checkpoint.NextChapter = state.nextChapter
+	checkpoint.Scope = state.scope
+	checkpoint.NextParagraph = state.nextParagraph
+	checkpoint.ProcessedParagraphs = state.processedParagraphs
+	// Segments of a batch still being segmented are left out: the checkpoint
+	// position points at the batch start, so a resume segments it again
+	checkpoint.SegmentCounter = state.committedCounter
+	state.segmentsMu.RUnlock()
+
+	state.personaMu.RLock()
+	checkpoint.DiscoveredPersonas = keysFromMap(state.discoveredPersonas)
+	sort.Strings(checkpoint.DiscoveredPersonas)
+	checkpoint.MappedPersonas = make(map[string]string, len(state.mappedPersonas))
+	for persona, voiceID := range state.mappedPersonas {
+		checkpoint.MappedPersonas[persona] = voiceID
+	}
+	checkpoint.UnmappedPersonas = append([]string(nil), state.unmappedPersonas...)
+	checkpoint.InitialMappingDone = state.initialMappingDone
+	state.personaMu.RUnlock()
+
+	checkpoint.InitialMappingReceived = state.initialMappingApplied()
+	checkpoint.Queue = state.segmentQueue.Snapshot()
+	return checkpoint
+}
+
+// restoreCheckpoint rebuilds pipeline state from a checkpoint and the segments
+// already persisted for the book. Segments created after the checkpoint was
+// written belong to a batch that never completed; they are deleted, along
+// with any audio synthesized for them, so the batch can be segmented again
+// without leaving duplicates or stale audio under reused segment IDs.
+func (o *HybridOrchestrator) restoreCheckpoint(ctx context.Context, state *hybridPipelineState, checkpoint *PipelineCheckpoint) error {
+	segments, err := o.repo.ListSegments(ctx, state.bookID)
+	if err != nil {
+		return fmt.Errorf("failed to list segments: %w", err)
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].ID < segments[j].ID
+	})
+
+	kept := make([]*types.Segment, 0, len(segments))
+	byID := make(map[string]*types.Segment, len(segments))
+	for _, segment := range segments {
+		if segmentNumber(segment.ID) > checkpoint.SegmentCounter {
+			if err := o.repo.DeleteSegment(ctx, state.bookID, segment.ID); err != nil {
+				log.Printf("[restoreCheckpoint] Failed to delete uncheckpointed segment %s: %v", segment.ID, err)
+			}
+			for _, format := range util.AudioFormats() {
+				if err := o.storage.Delete(ctx, util.GetAudioPath(state.bookID, segment.ID, format)); err != nil {
+					log.Printf("[restoreCheckpoint] Failed to delete audio of uncheckpointed segment %s: %v", segment.ID, err)
+				}
+			}
+			continue
+		}
+		kept = append(kept, segment)
+		byID[segment.ID] = segment
+	}
+
+	state.allSegments = kept
+	state.segmentCounter = checkpoint.SegmentCounter
+	state.committedCounter = checkpoint.SegmentCounter
+	state.processedParagraphs = checkpoint.ProcessedParagraphs
+	state.nextChapter = checkpoint.NextChapter
+	state.nextParagraph = checkpoint.NextParagraph
+
+	for _, persona := range checkpoint.DiscoveredPersonas {
+		state.discoveredPersonas[persona] = true
+	}
+	for persona, voiceID := range checkpoint.MappedPersonas {
+		state.mappedPersonas[persona] = voiceID
+	}
+	state.unmappedPersonas = append(state.unmappedPersonas, checkpoint.UnmappedPersonas...)
+	state.segmentQueue.RestoreRetryState(checkpoint.Queue)
+	state.applySynthesisScope(checkpoint.Scope)
+
+	state.synthesizedCount = 0
+	for _, segment := range kept {
+		if segment.VoiceID != "" {
+			state.synthesizedCount++
+		}
+	}
+	state.permanentlyFailedCount = state.segmentQueue.PermanentlyFailedCount()
+
+	// Until the initial mapping has been received every segment is queued by
+	// applyVoiceMapping, so the initial mapping request is simply repeated.
+	if !checkpoint.InitialMappingReceived {
+		state.initialMappingDone = false
+		return nil
+	}
+	state.initialMappingDone = true
+	state.closeInitialMappingOnce.Do(func() {
+		close(state.initialMappingReceived)
+	})
+
+	// Requeue in checkpoint order first, then anything that was in flight.
+	queued := make(map[string]bool, len(kept))
+	requeue := func(segmentID string) {
+		segment := byID[segmentID]
+		if segment == nil || queued[segmentID] || state.segmentQueue.IsPermanentlyFailed(segmentID) {
+			return
+		}
+		if segment.AudioStale {
+			queued[segmentID] = true
+			state.segmentQueue.EnqueueStale(segment)
+			return
+		}
+		if segment.VoiceID != "" {
+			return
+		}
+		queued[segmentID] = true
+		state.segmentQueue.Enqueue(segment, state.mappedPersonas[segment.Person] != "")
+	}
+	for _, ids := range [][]string{checkpoint.Queue.Priority, checkpoint.Queue.Mapped, checkpoint.Queue.Unmapped, checkpoint.Queue.Stale, checkpoint.Queue.Deferred} {
+		for _, segmentID := range ids {
+			requeue(segmentID)
+		}
+	}
+	for _, segment := range kept {
+		requeue(segment.ID)
+	}
+
+	return nil
+}
+
+// resynthesisCheckpoint builds a checkpoint that resumes a book after
+// segmentation with every segment still to be synthesized. Personas keep
+// their voice from the saved voice map; if any persona has none, the initial
+// voice mapping is requested again.
+func resynthesisCheckpoint(bookID string, chapters []*types.Chapter, segments []*types.Segment, voiceMap *types.VoiceMap) *PipelineCheckpoint {
+	checkpoint := &PipelineCheckpoint{
+		BookID:         bookID,
+		NextChapter:    len(chapters),
+		MappedPersonas: make(map[string]string),
+		UpdatedAt:      time.Now(),
+	}
+	for _, chapter := range chapters {
+		checkpoint.ProcessedParagraphs += len(chapter.Paragraphs)
+	}
+
+	voices := make(map[string]string)
+	if voiceMap != nil {
+		for _, person := range voiceMap.Persons {
+			voices[person.ID] = person.ProviderVoice
+		}
+	}
+
+	discovered := make(map[string]bool)
+	for _, segment := range segments {
+		if n := segmentNumber(segment.ID); n > checkpoint.SegmentCounter {
+			checkpoint.SegmentCounter = n
+		}
+		if discovered[segment.Person] {
+			continue
+		}
+		discovered[segment.Person] = true
+		checkpoint.DiscoveredPersonas = append(checkpoint.DiscoveredPersonas, segment.Person)
+		if voiceID := voices[segment.Person]; voiceID != "" {
+			checkpoint.MappedPersonas[segment.Person] = voiceID
+		} else {
+			checkpoint.UnmappedPersonas = append(checkpoint.UnmappedPersonas, segment.Person)
+		}
+	}
+	sort.Strings(checkpoint.DiscoveredPersonas)
+	sort.Strings(checkpoint.UnmappedPersonas)
+
+	checkpoint.InitialMappingDone = len(checkpoint.UnmappedPersonas) == 0
+	checkpoint.InitialMappingReceived = checkpoint.InitialMappingDone
+	return checkpoint
+}
+
+// segmentNumber extracts the counter from a "seg_%05d" segment ID
+func segmentNumber(segmentID string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(segmentID, "seg_"))
+	if err != nil {
+		return 0
+	}
+	return n
+}
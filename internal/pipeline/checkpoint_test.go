@@ -0,0 +1,287 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/provider"
+	"github.com/unalkalkan/TwelveReader/internal/util"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestCheckpointRoundTripsPipelineState(t *testing.T) {
+	store := newPipelineTestStorage()
+	orchestrator := NewHybridOrchestrator(DefaultPipelineConfig(), newPipelineTestRepository(), store, &pipelineTestLLMProvider{}, provider.NewRegistry())
+
+	segment := &types.Segment{ID: "seg_00001", BookID: "book_cp", Person: "narrator"}
+	state := newWorkerTestState("book_cp", segment)
+	state.segmentCounter = 3 // seg_00002 and seg_00003 belong to a batch still being segmented
+	state.committedCounter = 1
+	state.nextChapter = 2
+	state.nextParagraph = 7
+	state.processedParagraphs = 12
+	state.mappedPersonas["narrator"] = "voice-a"
+	state.initialMappingDone = true
+	close(state.initialMappingReceived)
+	state.segmentQueue.Enqueue(segment, true)
+	state.segmentQueue.RecordFailure(segment.ID)
+
+	orchestrator.saveCheckpoint(context.Background(), state)
+
+	checkpoint, err := orchestrator.loadCheckpoint(context.Background(), "book_cp")
+	if err != nil {
+		t.Fatalf("load checkpoint: %v", err)
+	}
+	if checkpoint.NextChapter != 2 || checkpoint.NextParagraph != 7 || checkpoint.ProcessedParagraphs != 12 {
+		t.Fatalf("unexpected segmentation position: %+v", checkpoint)
+	}
+	if checkpoint.SegmentCounter != 1 {
+		t.Fatalf("expected the committed segment counter 1, got %d", checkpoint.SegmentCounter)
+	}
+	if checkpoint.MappedPersonas["narrator"] != "voice-a" || !checkpoint.InitialMappingReceived {
+		t.Fatalf("expected mapping state to be checkpointed, got %+v", checkpoint)
+	}
+	if len(checkpoint.Queue.Mapped) != 1 || checkpoint.Queue.Mapped[0] != segment.ID {
+		t.Fatalf("expected queued segment in checkpoint, got %#v", checkpoint.Queue.Mapped)
+	}
+	if checkpoint.Queue.RetryCounts[segment.ID] != 1 {
+		t.Fatalf("expected retry count 1, got %d", checkpoint.Queue.RetryCounts[segment.ID])
+	}
+
+	orchestrator.deleteCheckpoint(context.Background(), "book_cp")
+	if _, err := orchestrator.loadCheckpoint(context.Background(), "book_cp"); !errors.Is(err, ErrNoCheckpoint) {
+		t.Fatalf("expected ErrNoCheckpoint after delete, got %v", err)
+	}
+}
+
+func TestResumePipelineSkipsSegmentedParagraphsAndSynthesizedAudio(t *testing.T) {
+	ctx := context.Background()
+	repo := newPipelineTestRepository()
+	store := newPipelineTestStorage()
+	ttsProvider := &pipelineTestTTSProvider{}
+	registry := provider.NewRegistry()
+	if err := registry.RegisterTTS(ttsProvider); err != nil {
+		t.Fatalf("register tts provider: %v", err)
+	}
+	llmProvider := &recordingLLMProvider{}
+
+	book := &types.Book{ID: "book_resume", Title: "Resume", Status: "synthesizing"}
+	if err := repo.SaveBook(ctx, book); err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+	chapters := []*types.Chapter{{
+		ID:         "ch_001",
+		BookID:     book.ID,
+		Number:     1,
+		Paragraphs: []string{"first", "second", "third"},
+	}}
+
+	// Two paragraphs were segmented; the first segment already has audio.
+	// seg_00003 was saved and synthesized by a batch that never reached a
+	// checkpoint.
+	for _, segment := range []*types.Segment{
+		{ID: "seg_00001", BookID: book.ID, Chapter: "ch_001", Text: "first", Person: "narrator", VoiceID: "voice-a"},
+		{ID: "seg_00002", BookID: book.ID, Chapter: "ch_001", Text: "second", Person: "narrator"},
+		{ID: "seg_00003", BookID: book.ID, Chapter: "ch_001", Text: "orphan", Person: "narrator"},
+	} {
+		if err := repo.SaveSegment(ctx, segment); err != nil {
+			t.Fatalf("save segment: %v", err)
+		}
+	}
+	orphanAudio := util.GetAudioPath(book.ID, "seg_00003", "mp3")
+	if err := store.Put(ctx, orphanAudio, bytes.NewReader([]byte("orphan audio"))); err != nil {
+		t.Fatalf("put audio: %v", err)
+	}
+
+	orchestrator := NewHybridOrchestrator(
+		PipelineConfig{TTSConcurrency: 1, MinSegmentsBeforeTTS: 1, SegmentationBatchSize: 1},
+		repo,
+		store,
+		llmProvider,
+		registry,
+	)
+
+	checkpointState := newHybridPipelineState(book.ID, chapters, nil)
+	checkpointState.segmentCounter = 2
+	checkpointState.committedCounter = 2
+	checkpointState.nextParagraph = 2
+	checkpointState.processedParagraphs = 2
+	checkpointState.discoveredPersonas["narrator"] = true
+	checkpointState.mappedPersonas["narrator"] = "voice-a"
+	checkpointState.initialMappingDone = true
+	close(checkpointState.initialMappingReceived)
+	orchestrator.saveCheckpoint(ctx, checkpointState)
+
+	if err := orchestrator.ResumePipeline(ctx, book.ID, chapters, nil); err != nil {
+		t.Fatalf("resume pipeline: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		updated, err := repo.GetBook(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("get book: %v", err)
+		}
+		if updated.Status == "synthesized" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected resumed pipeline to finish, book status %q", updated.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if got := llmProvider.paragraphIndexes(); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("expected only paragraph 2 to be segmented, got %v", got)
+	}
+	if ttsProvider.callsFor("first") != 0 {
+		t.Fatalf("expected already synthesized segment to be skipped")
+	}
+	if ttsProvider.callsFor("second") != 1 || ttsProvider.callsFor("third") != 1 {
+		t.Fatalf("expected unsynthesized segments to be synthesized once, got calls %v", ttsProvider.callOrder())
+	}
+	if ttsProvider.callsFor("orphan") != 0 {
+		t.Fatalf("expected uncheckpointed segment to be discarded")
+	}
+	segment, err := repo.GetSegment(ctx, book.ID, "seg_00003")
+	if err != nil {
+		t.Fatalf("get re-segmented segment: %v", err)
+	}
+	if segment.Text != "third" {
+		t.Fatalf("expected seg_00003 to be re-created from paragraph 3, got %q", segment.Text)
+	}
+	if exists, _ := store.Exists(ctx, orphanAudio); exists {
+		t.Fatalf("expected audio of the uncheckpointed segment to be deleted")
+	}
+	if _, err := orchestrator.loadCheckpoint(ctx, book.ID); !errors.Is(err, ErrNoCheckpoint) {
+		t.Fatalf("expected checkpoint removed after completion, got %v", err)
+	}
+}
+
+func TestResumePipelineWithoutCheckpointReturnsErrNoCheckpoint(t *testing.T) {
+	orchestrator := NewHybridOrchestrator(DefaultPipelineConfig(), newPipelineTestRepository(), newPipelineTestStorage(), &pipelineTestLLMProvider{}, provider.NewRegistry())
+
+	err := orchestrator.ResumePipeline(context.Background(), "book_missing", nil, nil)
+	if !errors.Is(err, ErrNoCheckpoint) {
+		t.Fatalf("expected ErrNoCheckpoint, got %v", err)
+	}
+}
+
+// recordingLLMProvider returns one narrator segment per paragraph and records
+// which paragraph indexes were sent for batch segmentation
+type recordingLLMProvider struct {
+	mu      sync.Mutex
+	indexes []int
+}
+
+func (p *recordingLLMProvider) Name() string { return "recording-llm" }
+func (p *recordingLLMProvider) Segment(ctx context.Context, req provider.SegmentRequest) (*provider.SegmentResponse, error) {
+	return &provider.SegmentResponse{Segments: []provider.Segment{{Text: req.Text, Person: "narrator", Language: "en"}}}, nil
+}
+func (p *recordingLLMProvider) BatchSegment(ctx context.Context, req provider.BatchSegmentRequest) (*provider.BatchSegmentResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	resp := &provider.BatchSegmentResponse{}
+	for _, paragraph := range req.Paragraphs {
+		p.indexes = append(p.indexes, paragraph.Index)
+		resp.Results = append(resp.Results, provider.BatchParagraphResult{
+			ParagraphIndex: paragraph.Index,
+			Segments:       []provider.Segment{{Text: paragraph.Text, Person: "narrator", Language: "en"}},
+		})
+	}
+	return resp, nil
+}
+func (p *recordingLLMProvider) Close() error { return nil }
+
+func (p *recordingLLMProvider) paragraphIndexes() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]int(nil), p.indexes...)
+}
+
+func TestResynthesizePipelineSynthesizesSavedSegmentsWithoutSegmentation(t *testing.T) {
+	ctx := context.Background()
+	repo := newPipelineTestRepository()
+	ttsProvider := &pipelineTestTTSProvider{}
+	registry := provider.NewRegistry()
+	if err := registry.RegisterTTS(ttsProvider); err != nil {
+		t.Fatalf("register tts provider: %v", err)
+	}
+	llmProvider := &recordingLLMProvider{}
+
+	book := &types.Book{ID: "book_resynth", Title: "Resynthesize", Status: "synthesized"}
+	if err := repo.SaveBook(ctx, book); err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+	chapters := []*types.Chapter{{
+		ID:         "ch_001",
+		BookID:     book.ID,
+		Number:     1,
+		Paragraphs: []string{"first", "second"},
+	}}
+	for _, segment := range []*types.Segment{
+		{ID: "seg_00001", BookID: book.ID, Chapter: "ch_001", Text: "first", Person: "narrator", VoiceID: "voice-old"},
+		{ID: "seg_00002", BookID: book.ID, Chapter: "ch_001", Text: "second", Person: "narrator", VoiceID: "voice-old"},
+	} {
+		if err := repo.SaveSegment(ctx, segment); err != nil {
+			t.Fatalf("save segment: %v", err)
+		}
+	}
+	if err := repo.SaveVoiceMap(ctx, &types.VoiceMap{BookID: book.ID, Persons: []types.PersonVoice{{ID: "narrator", ProviderVoice: "voice-a"}}}); err != nil {
+		t.Fatalf("save voice map: %v", err)
+	}
+
+	orchestrator := NewHybridOrchestrator(
+		PipelineConfig{TTSConcurrency: 1, MinSegmentsBeforeTTS: 1, SegmentationBatchSize: 1},
+		repo,
+		newPipelineTestStorage(),
+		llmProvider,
+		registry,
+	)
+	if err := orchestrator.ResynthesizePipeline(ctx, book.ID, chapters, nil); err != nil {
+		t.Fatalf("resynthesize pipeline: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		updated, err := repo.GetBook(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("get book: %v", err)
+		}
+		if updated.Status == "synthesized" && ttsProvider.callsFor("second") > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected resynthesis to finish, book status %q", updated.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if got := llmProvider.paragraphIndexes(); len(got) != 0 {
+		t.Fatalf("expected no paragraphs to be segmented, got %v", got)
+	}
+	if ttsProvider.callsFor("first") != 1 || ttsProvider.callsFor("second") != 1 {
+		t.Fatalf("expected every segment to be synthesized once, got calls %v", ttsProvider.callOrder())
+	}
+	for _, segmentID := range []string{"seg_00001", "seg_00002"} {
+		segment, err := repo.GetSegment(ctx, book.ID, segmentID)
+		if err != nil {
+			t.Fatalf("get segment: %v", err)
+		}
+		if segment.VoiceID != "voice-a" {
+			t.Fatalf("expected %s to use the saved voice map, got %q", segmentID, segment.VoiceID)
+		}
+	}
+}
+
+func TestResynthesizePipelineWithoutSegmentsFails(t *testing.T) {
+	orchestrator := NewHybridOrchestrator(DefaultPipelineConfig(), newPipelineTestRepository(), newPipelineTestStorage(), &pipelineTestLLMProvider{}, provider.NewRegistry())
+
+	if err := orchestrator.ResynthesizePipeline(context.Background(), "book_empty", nil, nil); err == nil {
+		t.Fatal("expected error for a book without segments")
+	}
+}
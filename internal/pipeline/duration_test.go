@@ -0,0 +1,52 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/internal/audio"
+	"github.com/unalkalkan/TwelveReader/internal/provider"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestSynthesisRecordsMeasuredAudioDuration(t *testing.T) {
+	ctx := context.Background()
+	repo := newPipelineTestRepository()
+	store := newPipelineTestStorage()
+
+	// 1.25s of mono 16-bit audio at 8 kHz
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:2], 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:4], 1)
+	binary.LittleEndian.PutUint32(fmtChunk[4:8], 8000)
+	binary.LittleEndian.PutUint32(fmtChunk[8:12], 16000)
+	binary.LittleEndian.PutUint16(fmtChunk[12:14], 2)
+	binary.LittleEndian.PutUint16(fmtChunk[14:16], 16)
+	ttsProvider := &pipelineTestTTSProvider{audio: audio.EncodeWAV(fmtChunk, make([]byte, 20000))}
+
+	registry := provider.NewRegistry()
+	if err := registry.RegisterTTS(ttsProvider); err != nil {
+		t.Fatalf("register tts provider: %v", err)
+	}
+	orchestrator := NewHybridOrchestrator(PipelineConfig{TTSConcurrency: 1}, repo, store, &pipelineTestLLMProvider{}, registry)
+
+	segment := &types.Segment{ID: "seg_00001", BookID: "book_duration", Text: "timed", Person: "narrator", AudioDuration: 9}
+	if err := repo.SaveSegment(ctx, segment); err != nil {
+		t.Fatalf("save segment: %v", err)
+	}
+	if err := repo.SaveVoiceMap(ctx, &types.VoiceMap{BookID: "book_duration", Persons: []types.PersonVoice{{ID: "narrator", ProviderVoice: "voice-a"}}}); err != nil {
+		t.Fatalf("save voice map: %v", err)
+	}
+
+	if err := orchestrator.SynthesizeSegmentNow(ctx, "book_duration", "seg_00001"); err != nil {
+		t.Fatalf("synthesize segment: %v", err)
+	}
+	updated, err := repo.GetSegment(ctx, "book_duration", "seg_00001")
+	if err != nil {
+		t.Fatalf("get segment: %v", err)
+	}
+	if updated.AudioDuration != 1.25 {
+		t.Fatalf("expected measured duration 1.25s to replace the old one, got %v", updated.AudioDuration)
+	}
+}
@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"log"
+	"sync"
+
+	"github.com/unalkalkan/TwelveReader/internal/events"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// Types of the events pipelines publish to an event bus
+const (
+	EventStage         = "stage"          // Data is a StageProgress
+	EventPersonas      = "personas"       // Data is a PersonaEvent
+	EventSegmentReady  = "segment_ready"  // Data is a SegmentEvent
+	EventSegmentFailed = "segment_failed" // Data is a SegmentEvent
+)
+
+// PersonaEvent reports personas that were discovered and need a voice
+type PersonaEvent struct {
+	Personas          []string `json:"personas"`
+	IsInitial         bool     `json:"is_initial"`
+	BlockingSegmentID string   `json:"blocking_segment_id,omitempty"`
+}
+
+// SegmentEvent reports a segment whose audio was synthesized or failed
+type SegmentEvent struct {
+	SegmentID     string  `json:"segment_id"`
+	Chapter       string  `json:"chapter,omitempty"`
+	Person        string  `json:"person,omitempty"`
+	VoiceID       string  `json:"voice_id,omitempty"`
+	AudioDuration float64 `json:"audio_duration_seconds,omitempty"`
+	Error         string  `json:"error,omitempty"`
+	Attempt       int     `json:"attempt,omitempty"`
+	Permanent     bool    `json:"permanent,omitempty"` // No more retries follow a failure
+}
+
+// stagePublisher publishes the stages of a pipeline status that changed
+// since the last one it published
+type stagePublisher struct {
+	mu   sync.Mutex
+	last map[string]StageProgress
+}
+
+func (p *stagePublisher) publish(bus *events.Bus, status *PipelineStatus) {
+	if bus == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.last == nil {
+		p.last = make(map[string]StageProgress)
+	}
+	for _, stage := range status.Stages {
+		last, seen := p.last[stage.Stage]
+		if seen && last.Status == stage.Status && last.Current == stage.Current &&
+			last.Total == stage.Total && last.Message == stage.Message {
+			continue
+		}
+		p.last[stage.Stage] = stage
+		bus.Publish(status.BookID, EventStage, stage)
+	}
+}
+
+// SetEventBus sets the bus the orchestrator publishes pipeline events to
+func (o *HybridOrchestrator) SetEventBus(bus *events.Bus) {
+	o.events = bus
+}
+
+// SetEventBus sets the bus the orchestrator publishes pipeline events to
+func (o *Orchestrator) SetEventBus(bus *events.Bus) {
+	o.events = bus
+}
+
+// requestVoiceMapping signals that personas need voices
+func (o *HybridOrchestrator) requestVoiceMapping(state *hybridPipelineState, event PersonaDiscoveryEvent) {
+	data := PersonaEvent{Personas: event.Personas, IsInitial: event.IsInitial}
+	if event.BlockingSegment != nil {
+		data.BlockingSegmentID = event.BlockingSegment.ID
+	}
+	o.events.Publish(state.bookID, EventPersonas, data)
+
+	select {
+	case state.voiceMappingNeeded <- event:
+	default:
+		log.Printf("[requestVoiceMapping] Warning: voiceMappingNeeded channel full")
+	}
+}
+
+// segmentEvent describes a synthesized or failed segment
+func segmentEvent(segment *types.Segment) SegmentEvent {
+	return SegmentEvent{
+		SegmentID:     segment.ID,
+		Chapter:       segment.Chapter,
+		Person:        segment.Person,
+		VoiceID:       segment.VoiceID,
+		AudioDuration: segment.AudioDuration,
+	}
+}
@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/internal/events"
+	"github.com/unalkalkan/TwelveReader/internal/provider"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestStagePublisherPublishesChangedStages(t *testing.T) {
+	bus := events.NewBus(10)
+	var publisher stagePublisher
+	status := &PipelineStatus{BookID: "book_events", Stages: []StageProgress{
+		{Stage: "segmenting", Status: "in_progress", Current: 1},
+		{Stage: "synthesizing", Status: "pending"},
+	}}
+
+	publisher.publish(bus, status)
+	publisher.publish(bus, status)
+	status.Stages[0].Current = 2
+	publisher.publish(bus, status)
+
+	replay, _, cancel := bus.Subscribe("book_events", 0)
+	defer cancel()
+	if len(replay) != 3 {
+		t.Fatalf("Expected 2 initial stages and 1 change, got %d events", len(replay))
+	}
+	if stage := replay[2].Data.(StageProgress); replay[2].Type != EventStage || stage.Stage != "segmenting" || stage.Current != 2 {
+		t.Errorf("Unexpected stage event: %+v", replay[2])
+	}
+}
+
+func TestHybridOrchestratorPublishesSegmentEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := newPipelineTestRepository()
+	store := newPipelineTestStorage()
+	registry := provider.NewRegistry()
+	if err := registry.RegisterTTS(&pipelineTestTTSProvider{}); err != nil {
+		t.Fatalf("register tts provider: %v", err)
+	}
+	orchestrator := NewHybridOrchestrator(PipelineConfig{TTSConcurrency: 1}, repo, store, &pipelineTestLLMProvider{}, registry)
+	bus := events.NewBus(10)
+	orchestrator.SetEventBus(bus)
+
+	repo.SaveSegment(ctx, &types.Segment{ID: "seg_00001", BookID: "book_events", Chapter: "ch_001", Text: "Hello", Person: "narrator"})
+	repo.SaveVoiceMap(ctx, &types.VoiceMap{BookID: "book_events", Persons: []types.PersonVoice{{ID: "narrator", ProviderVoice: "voice-a"}}})
+
+	if err := orchestrator.SynthesizeSegmentNow(ctx, "book_events", "seg_00001"); err != nil {
+		t.Fatalf("synthesize segment: %v", err)
+	}
+
+	state := newHybridPipelineState("book_events", nil, nil)
+	orchestrator.requestVoiceMapping(state, PersonaDiscoveryEvent{Personas: []string{"villain"}, BlockingSegment: &types.Segment{ID: "seg_00002"}})
+
+	replay, _, cancel := bus.Subscribe("book_events", 0)
+	defer cancel()
+	if len(replay) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(replay))
+	}
+	if ready := replay[0].Data.(SegmentEvent); replay[0].Type != EventSegmentReady || ready.SegmentID != "seg_00001" || ready.VoiceID != "voice-a" || ready.Chapter != "ch_001" {
+		t.Errorf("Unexpected segment event: %+v", replay[0])
+	}
+	if personas := replay[1].Data.(PersonaEvent); replay[1].Type != EventPersonas || personas.Personas[0] != "villain" || personas.BlockingSegmentID != "seg_00002" {
+		t.Errorf("Unexpected persona event: %+v", replay[1])
+	}
+}
@@ -0,0 +1,177 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// ErrNoVoice is returned by SynthesizeSegmentNow when neither the voice
+// mapping nor a default voice covers the segment's persona.
+var ErrNoVoice = errors.New("pipeline: no voice for segment persona")
+
+// segmentFlight is a synthesis of one segment that concurrent callers share
+type segmentFlight struct {
+	done chan struct{}
+	err  error
+}
+
+// synthesizeShared runs synthesize unless the same segment is already being
+// synthesized, in which case it waits for that synthesis instead. It reports
+// whether the result came from another caller.
+func (o *HybridOrchestrator) synthesizeShared(ctx context.Context, bookID, segmentID string, synthesize func() error) (bool, error) {
+	key := bookID + "/" + segmentID
+
+	o.flightMu.Lock()
+	if flight, running := o.flights[key]; running {
+		o.flightMu.Unlock()
+		select {
+		case <-flight.done:
+			return true, flight.err
+		case <-ctx.Done():
+			return true, ctx.Err()
+		}
+	}
+	flight := &segmentFlight{done: make(chan struct{})}
+	o.flights[key] = flight
+	o.flightMu.Unlock()
+
+	defer func() {
+		o.flightMu.Lock()
+		delete(o.flights, key)
+		o.flightMu.Unlock()
+		close(flight.done)
+	}()
+	flight.err = synthesize()
+	return false, flight.err
+}
+
+// synthesizeSegmentOnce synthesizes a queued segment for a TTS worker. A
+// segment that was synthesized on demand with the same voice in the meantime
+// is not synthesized again.
+func (o *HybridOrchestrator) synthesizeSegmentOnce(ctx context.Context, state *hybridPipelineState, segment *types.Segment, voiceID string) error {
+	synthesize := func() error {
+		if segment.VoiceID == voiceID && !segment.AudioStale {
+			log.Printf("[synthesizeSegmentOnce] Segment %s already synthesized on demand, skipping", segment.ID)
+			return nil
+		}
+		return o.synthesizeSegment(ctx, state, segment, voiceID)
+	}
+
+	shared, err := o.synthesizeShared(ctx, state.bookID, segment.ID, synthesize)
+	if shared && err != nil && ctx.Err() == nil {
+		// The on-demand request failed or timed out; synthesize it here instead
+		_, err = o.synthesizeShared(ctx, state.bookID, segment.ID, synthesize)
+	}
+	return err
+}
+
+// SynthesizeSegmentNow synthesizes a segment that has no audio yet while the
+// caller waits, e.g. when a listener requests it before the pipeline got to
+// it. The voice comes from the running pipeline's mapping, the saved voice
+// map or the default voice, in that order. With the pipeline's own voice the
+// segment is taken off its queue so TTS workers skip it.
+func (o *HybridOrchestrator) SynthesizeSegmentNow(ctx context.Context, bookID, segmentID string) error {
+	o.mu.RLock()
+	state := o.pipelines[bookID]
+	o.mu.RUnlock()
+
+	var segment *types.Segment
+	if state != nil {
+		segment = state.findSegment(segmentID)
+	}
+	if segment == nil {
+		// Not (yet) known to a running pipeline
+		state = nil
+		saved, err := o.repo.GetSegment(ctx, bookID, segmentID)
+		if err != nil {
+			return fmt.Errorf("failed to get segment: %w", err)
+		}
+		segment = saved
+	}
+
+	pipelineVoice := ""
+	if state != nil {
+		pipelineVoice = state.currentVoiceForPersona(segment.Person)
+	}
+	voiceID := pipelineVoice
+	if voiceID == "" {
+		voiceID = o.fallbackVoice(ctx, bookID, segment.Person)
+	}
+	if voiceID == "" {
+		return ErrNoVoice
+	}
+
+	wasStale := segment.AudioStale
+	shared, err := o.synthesizeShared(ctx, bookID, segmentID, func() error {
+		if pipelineVoice != "" {
+			return o.synthesizeSegment(ctx, state, segment, voiceID)
+		}
+		if err := o.storeSegmentAudio(ctx, bookID, segment, voiceID); err != nil {
+			return err
+		}
+		// Audio made with a fallback voice while the pipeline still waits for
+		// the persona's mapping is regenerated once the persona is mapped
+		segment.AudioStale = state != nil
+		segment.StaleVoiceID = ""
+		if segment.AudioStale {
+			segment.StaleVoiceID = voiceID
+		}
+		if err := o.repo.SaveSegment(ctx, segment); err != nil {
+			return fmt.Errorf("failed to update segment: %w", err)
+		}
+		o.events.Publish(bookID, EventSegmentReady, segmentEvent(segment))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[SynthesizeSegmentNow] Synthesized segment %s of book %s on demand (voice: %s, shared: %v)", segmentID, bookID, voiceID, shared)
+
+	// A worker holding the segment counts it itself; otherwise take it off the
+	// queue and count it here. Reserving an active slot first keeps idle
+	// workers from finishing the pipeline before it is counted.
+	if pipelineVoice != "" {
+		atomic.AddInt32(&state.activeSynthesis, 1)
+		if state.segmentQueue.Remove(segmentID) {
+			currentCount, totalSegments := state.recordSynthesized(segmentID, wasStale)
+			atomic.AddInt32(&state.activeSynthesis, -1)
+			o.publishSynthesisProgress(context.Background(), state, currentCount, totalSegments)
+		} else {
+			atomic.AddInt32(&state.activeSynthesis, -1)
+		}
+	}
+	return nil
+}
+
+// fallbackVoice returns the voice of a persona outside a running pipeline:
+// the book's saved voice map, then the default voice
+func (o *HybridOrchestrator) fallbackVoice(ctx context.Context, bookID, persona string) string {
+	if voiceMap, err := o.repo.GetVoiceMap(ctx, bookID); err == nil && voiceMap != nil {
+		for _, person := range voiceMap.Persons {
+			if person.ID == persona && person.ProviderVoice != "" {
+				return person.ProviderVoice
+			}
+		}
+	}
+	if defaultVoice, err := o.repo.GetDefaultVoice(ctx); err == nil && defaultVoice != nil {
+		return defaultVoice.VoiceID
+	}
+	return ""
+}
+
+// findSegment returns the pipeline's copy of a segment, or nil
+func (state *hybridPipelineState) findSegment(segmentID string) *types.Segment {
+	state.segmentsMu.RLock()
+	defer state.segmentsMu.RUnlock()
+	for _, segment := range state.allSegments {
+		if segment.ID == segmentID {
+			return segment
+		}
+	}
+	return nil
+}
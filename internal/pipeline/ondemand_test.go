@@ -0,0 +1,131 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/unalkalkan/TwelveReader/internal/provider"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestSynthesizeSegmentNowWithoutPipelineUsesSavedVoices(t *testing.T) {
+	ctx := context.Background()
+	repo := newPipelineTestRepository()
+	store := newPipelineTestStorage()
+	ttsProvider := &pipelineTestTTSProvider{}
+	registry := provider.NewRegistry()
+	if err := registry.RegisterTTS(ttsProvider); err != nil {
+		t.Fatalf("register tts provider: %v", err)
+	}
+	orchestrator := NewHybridOrchestrator(PipelineConfig{TTSConcurrency: 1}, repo, store, &pipelineTestLLMProvider{}, registry)
+
+	segments := []*types.Segment{
+		{ID: "seg_00001", BookID: "book_ondemand", Text: "mapped", Person: "narrator"},
+		{ID: "seg_00002", BookID: "book_ondemand", Text: "unmapped", Person: "stranger"},
+	}
+	for _, segment := range segments {
+		if err := repo.SaveSegment(ctx, segment); err != nil {
+			t.Fatalf("save segment: %v", err)
+		}
+	}
+	if err := repo.SaveVoiceMap(ctx, &types.VoiceMap{BookID: "book_ondemand", Persons: []types.PersonVoice{{ID: "narrator", ProviderVoice: "voice-a"}}}); err != nil {
+		t.Fatalf("save voice map: %v", err)
+	}
+
+	if err := orchestrator.SynthesizeSegmentNow(ctx, "book_ondemand", "seg_00001"); err != nil {
+		t.Fatalf("synthesize mapped segment: %v", err)
+	}
+	if exists, _ := store.Exists(ctx, "books/book_ondemand/audio/seg_00001.wav"); !exists {
+		t.Fatalf("expected audio to be stored")
+	}
+	updated, err := repo.GetSegment(ctx, "book_ondemand", "seg_00001")
+	if err != nil {
+		t.Fatalf("get segment: %v", err)
+	}
+	if updated.VoiceID != "voice-a" || updated.AudioStale {
+		t.Fatalf("expected segment voiced with voice-a, got voice %q stale %v", updated.VoiceID, updated.AudioStale)
+	}
+
+	if err := orchestrator.SynthesizeSegmentNow(ctx, "book_ondemand", "seg_00002"); !errors.Is(err, ErrNoVoice) {
+		t.Fatalf("expected ErrNoVoice for unmapped persona, got %v", err)
+	}
+	if err := repo.SaveDefaultVoice(ctx, &types.DefaultVoice{VoiceID: "voice-default"}); err != nil {
+		t.Fatalf("save default voice: %v", err)
+	}
+	if err := orchestrator.SynthesizeSegmentNow(ctx, "book_ondemand", "seg_00002"); err != nil {
+		t.Fatalf("synthesize with default voice: %v", err)
+	}
+	if got := ttsProvider.callOrder(); len(got) != 2 || got[1] != "unmapped:voice-default" {
+		t.Fatalf("expected default voice to be used, got calls %v", got)
+	}
+
+	if err := orchestrator.SynthesizeSegmentNow(ctx, "book_ondemand", "seg_09999"); err == nil {
+		t.Fatalf("expected error for unknown segment")
+	}
+}
+
+func TestSynthesizeSegmentNowTakesSegmentFromWorkers(t *testing.T) {
+	ctx := context.Background()
+	repo := newPipelineTestRepository()
+	ttsProvider := &pipelineTestTTSProvider{}
+	registry := provider.NewRegistry()
+	if err := registry.RegisterTTS(ttsProvider); err != nil {
+		t.Fatalf("register tts provider: %v", err)
+	}
+
+	book := &types.Book{ID: "book_ondemand", Title: "On Demand", Status: "synthesizing"}
+	if err := repo.SaveBook(ctx, book); err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+	segment := &types.Segment{ID: "seg_00001", BookID: book.ID, Text: "listen now", Person: "narrator"}
+	if err := repo.SaveSegment(ctx, segment); err != nil {
+		t.Fatalf("save segment: %v", err)
+	}
+
+	orchestrator := NewHybridOrchestrator(
+		PipelineConfig{TTSConcurrency: 1, MinSegmentsBeforeTTS: 1, SegmentationBatchSize: 1},
+		repo,
+		newPipelineTestStorage(),
+		&pipelineTestLLMProvider{},
+		registry,
+	)
+	state := newWorkerTestState(book.ID, segment)
+	state.mappedPersonas["narrator"] = "voice-a"
+	state.segmentQueue.Enqueue(segment, true)
+	orchestrator.pipelines[book.ID] = state
+
+	if err := orchestrator.SynthesizeSegmentNow(ctx, book.ID, segment.ID); err != nil {
+		t.Fatalf("synthesize segment now: %v", err)
+	}
+	if state.segmentQueue.ReadyCount() != 0 {
+		t.Fatalf("expected segment to be taken off the queue, got %d ready", state.segmentQueue.ReadyCount())
+	}
+	if state.synthesizedCount != 1 {
+		t.Fatalf("expected synthesized count 1, got %d", state.synthesizedCount)
+	}
+
+	shared, err := orchestrator.synthesizeShared(ctx, book.ID, segment.ID, func() error { return nil })
+	if shared || err != nil {
+		t.Fatalf("expected no flight left behind, got shared %v err %v", shared, err)
+	}
+	// A worker that dequeued the segment before it was taken skips it
+	if err := orchestrator.synthesizeSegmentOnce(ctx, state, segment, "voice-a"); err != nil {
+		t.Fatalf("synthesize segment once: %v", err)
+	}
+
+	state.ttsWorkers.Add(1)
+	orchestrator.ttsWorker(ctx, state, 0)
+	orchestrator.completePipeline(state)
+
+	if got := ttsProvider.callsFor("listen now"); got != 1 {
+		t.Fatalf("expected segment to be synthesized once, got %d calls", got)
+	}
+	updatedBook, err := repo.GetBook(ctx, book.ID)
+	if err != nil {
+		t.Fatalf("get book: %v", err)
+	}
+	if updatedBook.Status != "synthesized" {
+		t.Fatalf("expected book synthesized, got %q", updatedBook.Status)
+	}
+}
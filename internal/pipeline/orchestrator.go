@@ -8,7 +8,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/unalkalkan/TwelveReader/internal/audio"
 	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/internal/events"
 	"github.com/unalkalkan/TwelveReader/internal/provider"
 	"github.com/unalkalkan/TwelveReader/internal/segmentation"
 	"github.com/unalkalkan/TwelveReader/internal/storage"
@@ -72,6 +74,8 @@ type Orchestrator struct {
 	// Pipeline state
 	mu        sync.RWMutex
 	pipelines map[string]*pipelineState
+
+	events *events.Bus // Receives pipeline events, if set
 }
 
 // pipelineState tracks state for a single book's pipeline
@@ -83,6 +87,7 @@ type pipelineState struct {
 	ttsQueue         chan *types.Segment
 	voiceMap         *types.VoiceMap
 	progressCallback ProgressCallback
+	stages           stagePublisher
 	cancelFunc       context.CancelFunc
 	wg               sync.WaitGroup
 }
@@ -419,6 +424,12 @@ func (o *Orchestrator) synthesizeSegmentWithProgress(
 
 	// Update segment with audio path and timestamps
 	segment.VoiceID = voiceID
+	segment.AudioDuration = 0
+	if duration, err := audio.Duration(resp.AudioData, resp.Format); err == nil {
+		segment.AudioDuration = duration.Seconds()
+	} else {
+		log.Printf("Could not measure audio of segment %s: %v", segment.ID, err)
+	}
 	if len(resp.Timestamps) > 0 {
 		segment.Timestamps = &types.TimestampData{
 			Precision: "word",
@@ -496,10 +507,16 @@ func (o *Orchestrator) runTTSStage(ctx context.Context, state *pipelineState) {
 		err := o.synthesizeSegmentWithProgress(ctx, state.bookID, segment, voice, ttsProgressCallback)
 		if err != nil {
 			log.Printf("Failed to synthesize segment %s: %v", segment.ID, err)
+			failure := segmentEvent(segment)
+			failure.Error = err.Error()
+			failure.Attempt = 1
+			failure.Permanent = true
+			o.events.Publish(state.bookID, EventSegmentFailed, failure)
 			continue
 		}
 
 		processedCount++
+		o.events.Publish(state.bookID, EventSegmentReady, segmentEvent(segment))
 
 		// Update ready stage as segments become available
 		o.updateStageProgress(state, "ready", func(stage *StageProgress) {
@@ -570,16 +587,22 @@ func (o *Orchestrator) updateStageProgress(state *pipelineState, stageName strin
 	state.status.UpdatedAt = time.Now()
 }
 
-// notifyProgress sends progress update to callback
+// notifyProgress sends progress update to callback and publishes changed
+// stages to the event bus
 func (o *Orchestrator) notifyProgress(state *pipelineState) {
-	if state.progressCallback != nil {
-		// Create a copy to avoid race conditions
-		state.segmentsMu.RLock()
-		statusCopy := *state.status
-		statusCopy.Stages = make([]StageProgress, len(state.status.Stages))
-		copy(statusCopy.Stages, state.status.Stages)
-		state.segmentsMu.RUnlock()
+	if state.progressCallback == nil && o.events == nil {
+		return
+	}
 
+	// Create a copy to avoid race conditions
+	state.segmentsMu.RLock()
+	statusCopy := *state.status
+	statusCopy.Stages = make([]StageProgress, len(state.status.Stages))
+	copy(statusCopy.Stages, state.status.Stages)
+	state.segmentsMu.RUnlock()
+
+	state.stages.publish(o.events, &statusCopy)
+	if state.progressCallback != nil {
 		state.progressCallback(&statusCopy)
 	}
 }
@@ -9,10 +9,13 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/unalkalkan/TwelveReader/internal/audio"
 	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/internal/events"
 	"github.com/unalkalkan/TwelveReader/internal/provider"
 	"github.com/unalkalkan/TwelveReader/internal/segmentation"
 	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/internal/util"
 	"github.com/unalkalkan/TwelveReader/pkg/types"
 )
 
@@ -30,6 +33,12 @@ type HybridOrchestrator struct {
 	// Pipeline state
 	mu        sync.RWMutex
 	pipelines map[string]*hybridPipelineState
+
+	// Segment syntheses in progress, shared by TTS workers and on-demand requests
+	flightMu sync.Mutex
+	flights  map[string]*segmentFlight
+
+	events *events.Bus // Receives pipeline events, if set
 }
 
 // hybridPipelineState tracks state for a single book's hybrid pipeline
@@ -38,6 +47,7 @@ type hybridPipelineState struct {
 	status           *PipelineStatus
 	chapters         []*types.Chapter
 	progressCallback ProgressCallback
+	stages           stagePublisher
 	cancelFunc       context.CancelFunc
 	wg               sync.WaitGroup
 
@@ -45,9 +55,15 @@ type hybridPipelineState struct {
 	segmentsMu           sync.RWMutex
 	allSegments          []*types.Segment
 	segmentCounter       int
+	committedCounter     int // segmentCounter as of the last finished batch; checkpoints save this
 	totalParagraphs      int
 	processedParagraphs  int
 	segmentationComplete bool // Signals when all segments have been processed and queued
+	nextChapter          int  // Index of the chapter segmentation resumes from
+	nextParagraph        int  // Index of the paragraph within nextChapter to segment next
+
+	// Chapters synthesized first or exclusively, nil for the whole book; guarded by segmentsMu
+	scope *SynthesisScope
 
 	// Persona tracking
 	personaMu          sync.RWMutex
@@ -72,6 +88,20 @@ type hybridPipelineState struct {
 	ttsWorkers             sync.WaitGroup
 	maxRetries             int
 	activeSynthesis        int32
+	priority               priorityTracker // Play-now requests and their latency; guarded by ttsMu
+
+	// Serializes checkpoint writes so an older snapshot never overwrites a newer one
+	checkpointMu sync.Mutex
+}
+
+// initialMappingApplied reports whether the initial voice mapping signal has been sent
+func (state *hybridPipelineState) initialMappingApplied() bool {
+	select {
+	case <-state.initialMappingReceived:
+		return true
+	default:
+		return false
+	}
 }
 
 func (state *hybridPipelineState) staleProcessingAllowed() bool {
@@ -126,6 +156,7 @@ func NewHybridOrchestrator(
 		llmProvider: llmProvider,
 		providerReg: providerReg,
 		pipelines:   make(map[string]*hybridPipelineState),
+		flights:     make(map[string]*segmentFlight),
 	}
 }
 
@@ -142,7 +173,107 @@ func (o *HybridOrchestrator) StartPipeline(
 		return fmt.Errorf("pipeline already running for book %s", bookID)
 	}
 
-	pipelineCtx, cancel := context.WithCancel(ctx)
+	state := newHybridPipelineState(bookID, chapters, progressCallback)
+	o.launchPipeline(ctx, state)
+	o.mu.Unlock()
+
+	o.saveCheckpoint(ctx, state)
+	return nil
+}
+
+// ResumePipeline restarts the hybrid pipeline for a book from its last checkpoint.
+// Paragraphs that were already segmented are not sent to the LLM again and
+// segments that already have audio are not resynthesized. ErrNoCheckpoint is
+// returned when the book has no saved checkpoint.
+func (o *HybridOrchestrator) ResumePipeline(
+	ctx context.Context,
+	bookID string,
+	chapters []*types.Chapter,
+	progressCallback ProgressCallback,
+) error {
+	checkpoint, err := o.loadCheckpoint(ctx, bookID)
+	if err != nil {
+		return err
+	}
+
+	o.mu.RLock()
+	_, exists := o.pipelines[bookID]
+	o.mu.RUnlock()
+	if exists {
+		return fmt.Errorf("pipeline already running for book %s", bookID)
+	}
+
+	state := newHybridPipelineState(bookID, chapters, progressCallback)
+	if err := o.restoreCheckpoint(ctx, state, checkpoint); err != nil {
+		return fmt.Errorf("failed to restore checkpoint: %w", err)
+	}
+	state.status.Stages[0].Current = state.processedParagraphs
+	if state.totalParagraphs > 0 {
+		state.status.Stages[0].Percentage = float64(state.processedParagraphs) / float64(state.totalParagraphs) * 100
+	}
+
+	log.Printf("[ResumePipeline] Resuming book %s at chapter %d paragraph %d (%d segments, %d synthesized)",
+		bookID, state.nextChapter, state.nextParagraph, len(state.allSegments), state.synthesizedCount)
+
+	o.mu.Lock()
+	if _, exists := o.pipelines[bookID]; exists {
+		o.mu.Unlock()
+		return fmt.Errorf("pipeline already running for book %s", bookID)
+	}
+	o.launchPipeline(ctx, state)
+	o.mu.Unlock()
+
+	return nil
+}
+
+// ResynthesizePipeline restarts the hybrid pipeline for a book at the TTS
+// stage, e.g. after switching TTS providers. Segmentation is not repeated:
+// existing audio is discarded and every saved segment is synthesized again
+// with the book's saved voice mapping.
+func (o *HybridOrchestrator) ResynthesizePipeline(
+	ctx context.Context,
+	bookID string,
+	chapters []*types.Chapter,
+	progressCallback ProgressCallback,
+) error {
+	o.mu.RLock()
+	_, exists := o.pipelines[bookID]
+	o.mu.RUnlock()
+	if exists {
+		return fmt.Errorf("pipeline already running for book %s", bookID)
+	}
+
+	segments, err := o.repo.ListSegments(ctx, bookID)
+	if err != nil {
+		return fmt.Errorf("failed to list segments: %w", err)
+	}
+	if len(segments) == 0 {
+		return fmt.Errorf("book %s has no segments to synthesize", bookID)
+	}
+
+	if err := o.repo.DeleteAudio(ctx, bookID); err != nil {
+		return fmt.Errorf("failed to delete audio: %w", err)
+	}
+	for _, segment := range segments {
+		segment.VoiceID = ""
+		segment.AudioStale = false
+		segment.StaleVoiceID = ""
+		if err := o.repo.SaveSegment(ctx, segment); err != nil {
+			return fmt.Errorf("failed to reset segment %s: %w", segment.ID, err)
+		}
+	}
+
+	voiceMap, _ := o.repo.GetVoiceMap(ctx, bookID) // A missing map leaves every persona unmapped
+	if err := o.storeCheckpoint(ctx, resynthesisCheckpoint(bookID, chapters, segments, voiceMap)); err != nil {
+		return fmt.Errorf("failed to store checkpoint: %w", err)
+	}
+
+	log.Printf("[ResynthesizePipeline] Resynthesizing %d segments for book %s", len(segments), bookID)
+	return o.ResumePipeline(ctx, bookID, chapters, progressCallback)
+}
+
+// newHybridPipelineState creates the initial state for a book's hybrid pipeline
+func newHybridPipelineState(bookID string, chapters []*types.Chapter, progressCallback ProgressCallback) *hybridPipelineState {
 	state := &hybridPipelineState{
 		bookID:                 bookID,
 		chapters:               chapters,
@@ -155,8 +286,8 @@ func (o *HybridOrchestrator) StartPipeline(
 		voiceMappingDone:       make(chan VoiceMappingUpdate, 10),
 		initialMappingReceived: make(chan struct{}),
 		progressCallback:       progressCallback,
-		cancelFunc:             cancel,
 		maxRetries:             defaultSegmentSynthesisMaxRetries,
+		priority:               priorityTracker{requestedAt: make(map[string]time.Time)},
 	}
 
 	// Calculate total paragraphs
@@ -191,8 +322,15 @@ func (o *HybridOrchestrator) StartPipeline(
 		UpdatedAt: time.Now(),
 	}
 
-	o.pipelines[bookID] = state
-	o.mu.Unlock()
+	return state
+}
+
+// launchPipeline registers the state and starts the pipeline goroutines.
+// Callers must hold o.mu.
+func (o *HybridOrchestrator) launchPipeline(ctx context.Context, state *hybridPipelineState) {
+	pipelineCtx, cancel := context.WithCancel(ctx)
+	state.cancelFunc = cancel
+	o.pipelines[state.bookID] = state
 
 	// Start the pipeline stages
 	state.wg.Add(2)
@@ -204,8 +342,6 @@ func (o *HybridOrchestrator) StartPipeline(
 		state.wg.Wait()
 		o.completePipeline(state)
 	}()
-
-	return nil
 }
 
 // runSegmentationStage processes chapters through LLM segmentation
@@ -226,13 +362,24 @@ func (o *HybridOrchestrator) runSegmentationStage(ctx context.Context, state *hy
 
 	segService := segmentation.NewService(o.llmProvider, o.config.SegmentationBatchSize)
 
-	// Process chapters with persona discovery
-	for _, chapter := range state.chapters {
+	state.segmentsMu.RLock()
+	startChapter := state.nextChapter
+	startParagraph := state.nextParagraph
+	state.segmentsMu.RUnlock()
+
+	// Process chapters with persona discovery, skipping anything already checkpointed
+	for chapterIndex := startChapter; chapterIndex < len(state.chapters); chapterIndex++ {
 		if ctx.Err() != nil {
 			return
 		}
 
-		err := o.segmentChapterWithPersonaTracking(ctx, state, segService, chapter)
+		chapter := state.chapters[chapterIndex]
+		firstParagraph := 0
+		if chapterIndex == startChapter {
+			firstParagraph = startParagraph
+		}
+
+		err := o.segmentChapterWithPersonaTracking(ctx, state, segService, chapter, chapterIndex, firstParagraph)
 		if err != nil {
 			log.Printf("Failed to segment chapter %s: %v", chapter.ID, err)
 			now := time.Now()
@@ -258,6 +405,12 @@ func (o *HybridOrchestrator) runSegmentationStage(ctx context.Context, state *hy
 	})
 	o.notifyProgress(state)
 
+	state.segmentsMu.Lock()
+	state.nextChapter = len(state.chapters)
+	state.nextParagraph = 0
+	state.segmentsMu.Unlock()
+	o.saveCheckpoint(ctx, state)
+
 	o.ensureInitialMappingRequested(ctx, state)
 
 	// Update book metadata
@@ -323,6 +476,7 @@ func (o *HybridOrchestrator) ensureInitialMappingRequested(ctx context.Context,
 		for _, segment := range segments {
 			state.segmentQueue.Enqueue(segment, true)
 		}
+		o.saveCheckpoint(ctx, state)
 
 		o.updateBookAfterDefaultVoiceMapping(ctx, state)
 		return
@@ -339,11 +493,7 @@ func (o *HybridOrchestrator) ensureInitialMappingRequested(ctx context.Context,
 	state.initialMappingDone = true
 	state.personaMu.Unlock()
 
-	select {
-	case state.voiceMappingNeeded <- PersonaDiscoveryEvent{Personas: personas, IsInitial: true}:
-	default:
-		log.Printf("[ensureInitialMappingRequested] Warning: voiceMappingNeeded channel full")
-	}
+	o.requestVoiceMapping(state, PersonaDiscoveryEvent{Personas: personas, IsInitial: true})
 
 	book, err := o.repo.GetBook(ctx, state.bookID)
 	if err == nil && book != nil {
@@ -370,11 +520,13 @@ func (o *HybridOrchestrator) segmentChapterWithPersonaTracking(
 	state *hybridPipelineState,
 	segService *segmentation.Service,
 	chapter *types.Chapter,
+	chapterIndex int,
+	firstParagraph int,
 ) error {
 	paragraphs := chapter.Paragraphs
 
 	// Process paragraphs in batches
-	for i := 0; i < len(paragraphs); {
+	for i := firstParagraph; i < len(paragraphs); {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
@@ -397,6 +549,7 @@ func (o *HybridOrchestrator) segmentChapterWithPersonaTracking(
 			if err != nil {
 				return err
 			}
+			o.recordParagraphsProcessed(ctx, state, chapterIndex, batchEnd, batchEnd-i, len(paragraphs))
 			i = batchEnd
 			continue
 		}
@@ -423,22 +576,43 @@ func (o *HybridOrchestrator) segmentChapterWithPersonaTracking(
 			}
 		}
 
-		// Update progress
-		state.processedParagraphs += (batchEnd - i)
-		o.updateStageProgress(state, "segmenting", func(stage *StageProgress) {
-			stage.Current = state.processedParagraphs
-			if state.totalParagraphs > 0 {
-				stage.Percentage = float64(state.processedParagraphs) / float64(state.totalParagraphs) * 100
-			}
-		})
-		o.notifyProgress(state)
-
+		o.recordParagraphsProcessed(ctx, state, chapterIndex, batchEnd, batchEnd-i, len(paragraphs))
 		i = batchEnd
 	}
 
 	return nil
 }
 
+// recordParagraphsProcessed advances the segmentation position past a finished
+// batch, publishes progress and checkpoints the pipeline
+func (o *HybridOrchestrator) recordParagraphsProcessed(
+	ctx context.Context,
+	state *hybridPipelineState,
+	chapterIndex, nextParagraph, count, chapterParagraphs int,
+) {
+	state.segmentsMu.Lock()
+	state.processedParagraphs += count
+	state.committedCounter = state.segmentCounter
+	if nextParagraph >= chapterParagraphs {
+		state.nextChapter = chapterIndex + 1
+		state.nextParagraph = 0
+	} else {
+		state.nextChapter = chapterIndex
+		state.nextParagraph = nextParagraph
+	}
+	processed := state.processedParagraphs
+	state.segmentsMu.Unlock()
+
+	o.updateStageProgress(state, "segmenting", func(stage *StageProgress) {
+		stage.Current = processed
+		if state.totalParagraphs > 0 {
+			stage.Percentage = float64(processed) / float64(state.totalParagraphs) * 100
+		}
+	})
+	o.notifyProgress(state)
+	o.saveCheckpoint(ctx, state)
+}
+
 // buildBatchRequest creates a batch segmentation request
 func (o *HybridOrchestrator) buildBatchRequest(
 	state *hybridPipelineState,
@@ -564,13 +738,16 @@ func (o *HybridOrchestrator) createSegment(
 	llmSeg *provider.Segment,
 	paragraphIndex int,
 ) *types.Segment {
+	state.segmentsMu.Lock()
 	state.segmentCounter++
+	segmentNumber := state.segmentCounter
+	state.segmentsMu.Unlock()
 
 	// Normalize persona name
 	persona := o.normalizePersona(llmSeg.Person)
 
 	return &types.Segment{
-		ID:               fmt.Sprintf("seg_%05d", state.segmentCounter),
+		ID:               fmt.Sprintf("seg_%05d", segmentNumber),
 		BookID:           state.bookID,
 		Chapter:          chapter.ID,
 		TOCPath:          chapter.TOCPath,
@@ -596,10 +773,13 @@ func (o *HybridOrchestrator) createFallbackSegment(
 	text string,
 	paragraphIndex int,
 ) *types.Segment {
+	state.segmentsMu.Lock()
 	state.segmentCounter++
+	segmentNumber := state.segmentCounter
+	state.segmentsMu.Unlock()
 
 	return &types.Segment{
-		ID:               fmt.Sprintf("seg_%05d", state.segmentCounter),
+		ID:               fmt.Sprintf("seg_%05d", segmentNumber),
 		BookID:           state.bookID,
 		Chapter:          chapter.ID,
 		TOCPath:          chapter.TOCPath,
@@ -683,14 +863,10 @@ func (o *HybridOrchestrator) handlePersonaDiscovery(
 	// Handle initial voice mapping (outside of lock)
 	if needsInitialMapping {
 		// Send event for initial voice mapping (non-blocking, buffered channel)
-		select {
-		case state.voiceMappingNeeded <- PersonaDiscoveryEvent{
+		o.requestVoiceMapping(state, PersonaDiscoveryEvent{
 			Personas:  personas,
 			IsInitial: true,
-		}:
-		default:
-			log.Printf("[handlePersonaDiscovery] Warning: voiceMappingNeeded channel full")
-		}
+		})
 
 		// Update book status asynchronously
 		go func() {
@@ -727,15 +903,11 @@ func (o *HybridOrchestrator) handlePersonaDiscovery(
 			state.personaMu.Unlock()
 
 			// Send event for new persona mapping (non-blocking)
-			select {
-			case state.voiceMappingNeeded <- PersonaDiscoveryEvent{
+			o.requestVoiceMapping(state, PersonaDiscoveryEvent{
 				Personas:        []string{persona},
 				IsInitial:       false,
 				BlockingSegment: segment,
-			}:
-			default:
-				log.Printf("[handlePersonaDiscovery] Warning: voiceMappingNeeded channel full")
-			}
+			})
 
 			// Update book status asynchronously
 			go func() {
@@ -921,9 +1093,10 @@ func (o *HybridOrchestrator) ttsWorker(ctx context.Context, state *hybridPipelin
 
 			mappedCount := state.segmentQueue.ReadyCount()
 			unmappedCount := state.segmentQueue.UnmappedCount()
+			deferredCount := state.segmentQueue.DeferredCount()
 			activeCount := atomic.LoadInt32(&state.activeSynthesis)
 
-			if segmentationDone && mappedCount == 0 && unmappedCount == 0 && activeCount == 0 {
+			if segmentationDone && mappedCount == 0 && unmappedCount == 0 && deferredCount == 0 && activeCount == 0 {
 				state.ttsMu.RLock()
 				synthesizedCount := state.synthesizedCount
 				permanentlyFailed := state.permanentlyFailedCount
@@ -954,78 +1127,109 @@ func (o *HybridOrchestrator) ttsWorker(ctx context.Context, state *hybridPipelin
 		wasStale := segment.AudioStale
 		log.Printf("[ttsWorker-%d] Synthesizing segment %s (persona: %s, voice: %s)",
 			workerID, segment.ID, segment.Person, voiceID)
-		err := o.synthesizeSegment(ctx, state, segment, voiceID)
+		err := o.synthesizeSegmentOnce(ctx, state, segment, voiceID)
 		if err != nil {
 			retryCount := state.segmentQueue.RecordFailure(segment.ID)
+			permanent := false
 			if retryCount <= state.maxRetries {
 				if wasStale {
 					state.segmentQueue.EnqueueStale(segment)
 				} else {
 					state.segmentQueue.Enqueue(segment, true)
 				}
+				state.ttsMu.RLock()
+				_, prioritized := state.priority.requestedAt[segment.ID]
+				state.ttsMu.RUnlock()
+				if prioritized {
+					state.segmentQueue.Prioritize([]string{segment.ID})
+				}
 				log.Printf("[ttsWorker-%d] Requeued segment %s for retry (attempt %d/%d): %v",
 					workerID, segment.ID, retryCount, state.maxRetries, err)
 			} else {
+				permanent = true
 				state.segmentQueue.MarkPermanentlyFailed(segment.ID)
 				state.ttsMu.Lock()
 				state.permanentlyFailedCount++
+				delete(state.priority.requestedAt, segment.ID)
 				state.ttsMu.Unlock()
 				log.Printf("[ttsWorker-%d] Segment %s permanently failed after %d retries: %v",
 					workerID, segment.ID, state.maxRetries, err)
 			}
+			failure := segmentEvent(segment)
+			failure.VoiceID = voiceID
+			failure.Error = err.Error()
+			failure.Attempt = retryCount
+			failure.Permanent = permanent
+			o.events.Publish(state.bookID, EventSegmentFailed, failure)
+			o.saveCheckpoint(ctx, state)
 			atomic.AddInt32(&state.activeSynthesis, -1)
 			continue
 		}
 
 		state.segmentQueue.ClearRetryTracker(segment.ID)
-
-		state.segmentsMu.RLock()
-		totalSegments := len(state.allSegments)
-		state.segmentsMu.RUnlock()
-
-		state.ttsMu.Lock()
-		if !wasStale || state.synthesizedCount < totalSegments {
-			state.synthesizedCount++
-		}
-		currentCount := state.synthesizedCount
-		state.ttsMu.Unlock()
-
+		currentCount, totalSegments := state.recordSynthesized(segment.ID, wasStale)
 		atomic.AddInt32(&state.activeSynthesis, -1)
 
 		log.Printf("[ttsWorker-%d] Completed segment %s (%d/%d)", workerID, segment.ID, currentCount, totalSegments)
+		o.publishSynthesisProgress(ctx, state, currentCount, totalSegments)
+	}
+}
 
-		o.updateStageProgress(state, "synthesizing", func(stage *StageProgress) {
-			stage.Current = currentCount
-			stage.Total = totalSegments
-			if totalSegments > 0 {
-				stage.Percentage = float64(currentCount) / float64(totalSegments) * 100
-			}
-			stage.Message = fmt.Sprintf("Synthesizing segment %d of %d", currentCount, totalSegments)
-		})
+// recordSynthesized counts a segment whose audio was stored and returns the
+// synthesized and total segment counts
+func (state *hybridPipelineState) recordSynthesized(segmentID string, wasStale bool) (int, int) {
+	state.segmentsMu.RLock()
+	totalSegments := len(state.allSegments)
+	state.segmentsMu.RUnlock()
 
-		o.updateStageProgress(state, "ready", func(stage *StageProgress) {
-			if stage.Status == "pending" {
-				now := time.Now()
-				stage.Status = "in_progress"
-				stage.Message = "Audio available for playback"
-				stage.StartedAt = &now
-			}
-			stage.Current = currentCount
-			stage.Total = totalSegments
-			if totalSegments > 0 {
-				stage.Percentage = float64(currentCount) / float64(totalSegments) * 100
-			}
-		})
-		o.notifyProgress(state)
+	state.ttsMu.Lock()
+	if !wasStale || state.synthesizedCount < totalSegments {
+		state.synthesizedCount++
+	}
+	currentCount := state.synthesizedCount
+	priorityLatency, prioritized := state.recordPriorityServed(segmentID)
+	state.ttsMu.Unlock()
 
-		go func(count int) {
-			book, err := o.repo.GetBook(ctx, state.bookID)
-			if err == nil && book != nil {
-				book.SynthesizedSegments = count
-				o.repo.UpdateBook(ctx, book)
-			}
-		}(currentCount)
+	if prioritized {
+		log.Printf("[recordSynthesized] Prioritized segment %s ready %v after play request", segmentID, priorityLatency)
 	}
+	return currentCount, totalSegments
+}
+
+// publishSynthesisProgress reports synthesis progress to the pipeline status,
+// the progress callback and the book metadata
+func (o *HybridOrchestrator) publishSynthesisProgress(ctx context.Context, state *hybridPipelineState, currentCount, totalSegments int) {
+	o.updateStageProgress(state, "synthesizing", func(stage *StageProgress) {
+		stage.Current = currentCount
+		stage.Total = totalSegments
+		if totalSegments > 0 {
+			stage.Percentage = float64(currentCount) / float64(totalSegments) * 100
+		}
+		stage.Message = fmt.Sprintf("Synthesizing segment %d of %d", currentCount, totalSegments)
+	})
+
+	o.updateStageProgress(state, "ready", func(stage *StageProgress) {
+		if stage.Status == "pending" {
+			now := time.Now()
+			stage.Status = "in_progress"
+			stage.Message = "Audio available for playback"
+			stage.StartedAt = &now
+		}
+		stage.Current = currentCount
+		stage.Total = totalSegments
+		if totalSegments > 0 {
+			stage.Percentage = float64(currentCount) / float64(totalSegments) * 100
+		}
+	})
+	o.notifyProgress(state)
+
+	go func(count int) {
+		book, err := o.repo.GetBook(ctx, state.bookID)
+		if err == nil && book != nil {
+			book.SynthesizedSegments = count
+			o.repo.UpdateBook(ctx, book)
+		}
+	}(currentCount)
 }
 
 // monitorVoiceMappings listens for voice mapping updates from the voiceMappingDone channel
@@ -1052,6 +1256,36 @@ func (o *HybridOrchestrator) synthesizeSegment(
 	segment *types.Segment,
 	voiceID string,
 ) error {
+	if err := o.storeSegmentAudio(ctx, state.bookID, segment, voiceID); err != nil {
+		return err
+	}
+
+	if currentVoice := state.currentVoiceForPersona(segment.Person); currentVoice != "" && currentVoice != voiceID {
+		segment.AudioStale = true
+		segment.StaleVoiceID = voiceID
+		if err := o.repo.SaveSegment(ctx, segment); err != nil {
+			log.Printf("[synthesizeSegment] Failed to mark remapped in-flight segment %s stale: %v", segment.ID, err)
+		}
+		state.segmentQueue.EnqueueStale(segment)
+		o.events.Publish(state.bookID, EventSegmentReady, segmentEvent(segment))
+		return nil
+	}
+
+	segment.AudioStale = false
+	segment.StaleVoiceID = ""
+
+	// Save updated segment
+	if err := o.repo.SaveSegment(ctx, segment); err != nil {
+		return fmt.Errorf("failed to update segment: %w", err)
+	}
+
+	o.events.Publish(state.bookID, EventSegmentReady, segmentEvent(segment))
+	return nil
+}
+
+// storeSegmentAudio synthesizes a segment with a voice, stores the audio and
+// records the voice, timestamps and provider on the segment without saving it
+func (o *HybridOrchestrator) storeSegmentAudio(ctx context.Context, bookID string, segment *types.Segment, voiceID string) error {
 	// Get TTS provider
 	ttsProviders := o.providerReg.ListTTS()
 	if len(ttsProviders) == 0 {
@@ -1078,13 +1312,19 @@ func (o *HybridOrchestrator) synthesizeSegment(
 	}
 
 	// Store audio file
-	audioPath := fmt.Sprintf("books/%s/audio/%s.%s", state.bookID, segment.ID, resp.Format)
+	audioPath := util.GetAudioPath(bookID, segment.ID, resp.Format)
 	if err := o.storage.Put(ctx, audioPath, bytes.NewReader(resp.AudioData)); err != nil {
 		return fmt.Errorf("failed to store audio: %w", err)
 	}
 
 	// Update segment with audio info
 	segment.VoiceID = voiceID
+	segment.AudioDuration = 0
+	if duration, err := audio.Duration(resp.AudioData, resp.Format); err == nil {
+		segment.AudioDuration = duration.Seconds()
+	} else {
+		log.Printf("[storeSegmentAudio] Could not measure audio of segment %s: %v", segment.ID, err)
+	}
 	if len(resp.Timestamps) > 0 {
 		segment.Timestamps = &types.TimestampData{
 			Precision: "word",
@@ -1105,47 +1345,30 @@ func (o *HybridOrchestrator) synthesizeSegment(
 	}
 	segment.Processing.TTSProvider = ttsProvider.Name()
 	segment.Processing.GeneratedAt = time.Now()
-	if currentVoice := state.currentVoiceForPersona(segment.Person); currentVoice != "" && currentVoice != voiceID {
-		segment.AudioStale = true
-		segment.StaleVoiceID = voiceID
-		if err := o.repo.SaveSegment(ctx, segment); err != nil {
-			log.Printf("[synthesizeSegment] Failed to mark remapped in-flight segment %s stale: %v", segment.ID, err)
-		}
-		state.segmentQueue.EnqueueStale(segment)
-		return nil
-	}
-
-	segment.AudioStale = false
-	segment.StaleVoiceID = ""
-
-	// Save updated segment
-	if err := o.repo.SaveSegment(ctx, segment); err != nil {
-		return fmt.Errorf("failed to update segment: %w", err)
-	}
-
 	return nil
 }
 
-// ApplyVoiceMapping updates the pipeline with new voice mappings
+// ApplyVoiceMapping updates the pipeline with new voice mappings and returns
+// how many segments waiting for a voice were promoted to the TTS queue.
 // This is called from the API handler when the user submits voice mappings
 func (o *HybridOrchestrator) ApplyVoiceMapping(
 	ctx context.Context,
 	bookID string,
 	voiceMap *types.VoiceMap,
 	isInitial bool,
-) error {
+) (int, error) {
 	o.mu.RLock()
 	state, exists := o.pipelines[bookID]
 	o.mu.RUnlock()
 
 	if !exists {
-		return fmt.Errorf("no active pipeline for book %s", bookID)
+		return 0, fmt.Errorf("no active pipeline for book %s", bookID)
 	}
 
 	log.Printf("[ApplyVoiceMapping] Applying voice mapping for book %s, isInitial=%v", bookID, isInitial)
 
 	// Apply the mapping directly (synchronously)
-	o.applyVoiceMapping(ctx, state, VoiceMappingUpdate{
+	promoted := o.applyVoiceMapping(ctx, state, VoiceMappingUpdate{
 		VoiceMap:  voiceMap,
 		IsInitial: isInitial,
 	})
@@ -1157,17 +1380,19 @@ func (o *HybridOrchestrator) ApplyVoiceMapping(
 			close(state.initialMappingReceived)
 			log.Printf("[ApplyVoiceMapping] Initial mapping signal sent")
 		})
+		o.saveCheckpoint(ctx, state)
 	}
 
-	return nil
+	return promoted, nil
 }
 
-// applyVoiceMapping applies a voice mapping update to the pipeline
+// applyVoiceMapping applies a voice mapping update to the pipeline and
+// returns the number of pending segments it promoted
 func (o *HybridOrchestrator) applyVoiceMapping(
 	ctx context.Context,
 	state *hybridPipelineState,
 	mappingUpdate VoiceMappingUpdate,
-) {
+) int {
 	log.Printf("[applyVoiceMapping] Starting for book %s, isInitial=%v", state.bookID, mappingUpdate.IsInitial)
 
 	state.personaMu.Lock()
@@ -1228,11 +1453,13 @@ func (o *HybridOrchestrator) applyVoiceMapping(
 	}
 
 	// Promote pending segments with newly mapped personas
+	totalPromoted := 0
 	for _, persona := range newlyMapped {
 		promoted := state.segmentQueue.PromotePendingSegments(persona)
 		if promoted > 0 {
 			log.Printf("[applyVoiceMapping] Promoted %d segments for persona %s", promoted, persona)
 		}
+		totalPromoted += promoted
 	}
 
 	if !mappingUpdate.IsInitial {
@@ -1245,6 +1472,7 @@ func (o *HybridOrchestrator) applyVoiceMapping(
 	if err := o.repo.SaveVoiceMap(ctx, persistedVoiceMap); err != nil {
 		log.Printf("[applyVoiceMapping] Failed to persist voice map: %v", err)
 	}
+	o.saveCheckpoint(ctx, state)
 
 	// Update book status
 	book, err := o.repo.GetBook(ctx, state.bookID)
@@ -1266,6 +1494,7 @@ func (o *HybridOrchestrator) applyVoiceMapping(
 	} else {
 		log.Printf("[applyVoiceMapping] Failed to update book: %v", err)
 	}
+	return totalPromoted
 }
 
 func (o *HybridOrchestrator) markRemappedAudioStale(ctx context.Context, state *hybridPipelineState, voiceMap *types.VoiceMap, previousMappings map[string]string) {
@@ -1454,6 +1683,8 @@ func (o *HybridOrchestrator) completePipeline(state *hybridPipelineState) {
 		}
 	}
 
+	o.deleteCheckpoint(ctx, state.bookID)
+
 	o.mu.Lock()
 	delete(o.pipelines, state.bookID)
 	o.mu.Unlock()
@@ -1473,16 +1704,22 @@ func (o *HybridOrchestrator) updateStageProgress(state *hybridPipelineState, sta
 	state.status.UpdatedAt = time.Now()
 }
 
-// notifyProgress sends progress update to callback
+// notifyProgress sends progress update to callback and publishes changed
+// stages to the event bus
 func (o *HybridOrchestrator) notifyProgress(state *hybridPipelineState) {
-	if state.progressCallback != nil {
-		// Create a copy to avoid race conditions
-		state.segmentsMu.RLock()
-		statusCopy := *state.status
-		statusCopy.Stages = make([]StageProgress, len(state.status.Stages))
-		copy(statusCopy.Stages, state.status.Stages)
-		state.segmentsMu.RUnlock()
+	if state.progressCallback == nil && o.events == nil {
+		return
+	}
 
+	// Create a copy to avoid race conditions
+	state.segmentsMu.RLock()
+	statusCopy := *state.status
+	statusCopy.Stages = make([]StageProgress, len(state.status.Stages))
+	copy(statusCopy.Stages, state.status.Stages)
+	state.segmentsMu.RUnlock()
+
+	state.stages.publish(o.events, &statusCopy)
+	if state.progressCallback != nil {
 		state.progressCallback(&statusCopy)
 	}
 }
@@ -204,6 +204,7 @@ func TestShortBookRequestsInitialMappingAfterSegmentationCompletesWithoutDefault
 	state.unmappedPersonas = nil
 
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	done := make(chan struct{})
 	go func() {
 		orchestrator.ensureInitialMappingRequested(ctx, state)
@@ -540,6 +541,35 @@ func TestRemapMarksOldAudioStaleAndRegeneratesItAfterFreshSegments(t *testing.T)
 	}
 }
 
+func TestApplyVoiceMappingReportsPromotedSegments(t *testing.T) {
+	repo := newPipelineTestRepository()
+	orchestrator := NewHybridOrchestrator(PipelineConfig{TTSConcurrency: 1}, repo, newPipelineTestStorage(), &pipelineTestLLMProvider{}, provider.NewRegistry())
+
+	villain := &types.Segment{ID: "seg_00002", BookID: "book_promote", Text: "Mwahaha", Person: "villain"}
+	state := newWorkerTestState("book_promote", villain)
+	state.initialMappingDone = true
+	state.unmappedPersonas = []string{"villain"}
+	state.segmentQueue.Enqueue(villain, false)
+	state.segmentQueue.Enqueue(&types.Segment{ID: "seg_00003", BookID: "book_promote", Person: "villain"}, false)
+	state.segmentQueue.Enqueue(&types.Segment{ID: "seg_00004", BookID: "book_promote", Person: "hero"}, false)
+	orchestrator.pipelines["book_promote"] = state
+
+	promoted, err := orchestrator.ApplyVoiceMapping(context.Background(), "book_promote", &types.VoiceMap{
+		BookID:  "book_promote",
+		Persons: []types.PersonVoice{{ID: "villain", ProviderVoice: "voice-b"}},
+	}, false)
+	if err != nil {
+		t.Fatalf("apply voice mapping: %v", err)
+	}
+	if promoted != 2 || state.segmentQueue.UnmappedCount() != 1 {
+		t.Fatalf("expected both villain segments promoted and the hero left pending, got %d promoted and %d pending", promoted, state.segmentQueue.UnmappedCount())
+	}
+
+	if _, err := orchestrator.ApplyVoiceMapping(context.Background(), "book_missing", &types.VoiceMap{}, false); err == nil {
+		t.Fatal("expected an error without a running pipeline")
+	}
+}
+
 func TestInFlightOldVoiceSynthesisIsMarkedStaleAfterRemap(t *testing.T) {
 	repo := newPipelineTestRepository()
 	store := newPipelineTestStorage()
@@ -613,6 +643,7 @@ type pipelineTestTTSProvider struct {
 	callRecords           []string
 	failuresBeforeSuccess int
 	alwaysFail            bool
+	audio                 []byte // Returned instead of placeholder audio when set
 }
 
 func (p *pipelineTestTTSProvider) Name() string { return "pipeline-test-tts" }
@@ -631,8 +662,12 @@ func (p *pipelineTestTTSProvider) Synthesize(ctx context.Context, req provider.T
 		return nil, fmt.Errorf("intentional tts failure for %s", req.Text)
 	}
 
+	audioData := []byte("audio:" + req.Text)
+	if p.audio != nil {
+		audioData = p.audio
+	}
 	return &provider.TTSResponse{
-		AudioData: []byte("audio:" + req.Text),
+		AudioData: audioData,
 		Format:    "wav",
 	}, nil
 }
@@ -754,6 +789,32 @@ func (r *pipelineTestRepository) ListSegments(ctx context.Context, bookID string
 	return segments, nil
 }
 
+func (r *pipelineTestRepository) DeleteSegment(ctx context.Context, bookID, segmentID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.segments, segmentID)
+	return nil
+}
+
+func (r *pipelineTestRepository) DeleteChapters(ctx context.Context, bookID string) error {
+	return nil
+}
+
+func (r *pipelineTestRepository) DeleteSegments(ctx context.Context, bookID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, segment := range r.segments {
+		if segment.BookID == bookID {
+			delete(r.segments, id)
+		}
+	}
+	return nil
+}
+
+func (r *pipelineTestRepository) DeleteAudio(ctx context.Context, bookID string) error {
+	return nil
+}
+
 func (r *pipelineTestRepository) SaveVoiceMap(ctx context.Context, voiceMap *types.VoiceMap) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -805,11 +866,47 @@ func (r *pipelineTestRepository) GetPersonaProfiles(ctx context.Context, bookID
 func (r *pipelineTestRepository) UpdatePersonaProfilesFromSegments(ctx context.Context, bookID string, segments []*types.Segment) error {
 	return nil
 }
-func (r *pipelineTestRepository) SaveRawFile(ctx context.Context, bookID string, data []byte, format string) error {
+func (r *pipelineTestRepository) SaveRawFile(ctx context.Context, bookID string, data io.Reader, format string) (*book.RawFileInfo, error) {
+	return &book.RawFileInfo{}, nil
+}
+func (r *pipelineTestRepository) FindBooksByContentHash(ctx context.Context, hash string) ([]*types.Book, error) {
+	return nil, nil
+}
+func (r *pipelineTestRepository) CloneBook(ctx context.Context, srcBookID, dstBookID string) error {
+	return nil
+}
+func (r *pipelineTestRepository) OpenRawFile(ctx context.Context, bookID string) (*book.RawFile, error) {
+	return nil, fmt.Errorf("raw file not found")
+}
+func (r *pipelineTestRepository) SaveCover(ctx context.Context, bookID string, data []byte, ext string) error {
+	return nil
+}
+func (r *pipelineTestRepository) GetCover(ctx context.Context, bookID string) ([]byte, string, error) {
+	return nil, "", fmt.Errorf("cover not found")
+}
+func (r *pipelineTestRepository) SaveListeningProgress(ctx context.Context, progress *types.ListeningProgress) error {
+	return nil
+}
+func (r *pipelineTestRepository) GetListeningProgress(ctx context.Context, bookID string) (*types.ListeningProgress, error) {
+	return nil, nil
+}
+func (r *pipelineTestRepository) SaveBookmark(ctx context.Context, bookmark *types.Bookmark) error {
+	return nil
+}
+func (r *pipelineTestRepository) ListBookmarks(ctx context.Context, bookID string) ([]*types.Bookmark, error) {
+	return []*types.Bookmark{}, nil
+}
+func (r *pipelineTestRepository) DeleteBookmark(ctx context.Context, bookID, bookmarkID string) error {
 	return nil
 }
-func (r *pipelineTestRepository) GetRawFile(ctx context.Context, bookID string) ([]byte, string, error) {
-	return nil, "", fmt.Errorf("raw file not found")
+func (r *pipelineTestRepository) SaveHighlight(ctx context.Context, highlight *types.Highlight) error {
+	return nil
+}
+func (r *pipelineTestRepository) ListHighlights(ctx context.Context, bookID string) ([]*types.Highlight, error) {
+	return []*types.Highlight{}, nil
+}
+func (r *pipelineTestRepository) DeleteHighlight(ctx context.Context, bookID, highlightID string) error {
+	return nil
 }
 func (r *pipelineTestRepository) DeleteBook(ctx context.Context, bookID string) error {
 	r.mu.Lock()
@@ -851,6 +948,26 @@ func (s *pipelineTestStorage) Get(ctx context.Context, path string) (io.ReadClos
 	}
 	return io.NopCloser(strings.NewReader(string(data))), nil
 }
+func (s *pipelineTestStorage) GetRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	reader, err := s.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	io.CopyN(io.Discard, reader, offset)
+	if length < 0 {
+		return reader, nil
+	}
+	return io.NopCloser(io.LimitReader(reader, length)), nil
+}
+func (s *pipelineTestStorage) Stat(ctx context.Context, path string) (*storage.Metadata, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.data[path]
+	if !ok {
+		return nil, fmt.Errorf("not found: %s", path)
+	}
+	return &storage.Metadata{Path: path, Size: int64(len(data))}, nil
+}
 func (s *pipelineTestStorage) Delete(ctx context.Context, path string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,121 @@
+package pipeline
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// PriorityLatency summarizes how long prioritized segments waited between
+// the play request and their audio being stored
+type PriorityLatency struct {
+	Requested int   `json:"requested"`  // Segments moved to the priority lane
+	Served    int   `json:"served"`     // Prioritized segments synthesized so far
+	Pending   int   `json:"pending"`    // Prioritized segments still waiting for audio
+	LastMS    int64 `json:"last_ms"`    // Latency of the most recently served segment
+	AverageMS int64 `json:"average_ms"` // Mean latency of served segments
+	MaxMS     int64 `json:"max_ms"`     // Worst latency of served segments
+}
+
+// priorityTracker records when segments were prioritized and how long they
+// took to be synthesized
+type priorityTracker struct {
+	requestedAt map[string]time.Time
+	requested   int
+	served      int
+	total       time.Duration
+	last        time.Duration
+	max         time.Duration
+}
+
+// PrioritizeSegment moves a segment and up to following segments after it to
+// the front of the TTS queue, e.g. when a listener starts playback
+// somewhere the pipeline has not reached yet. Segments that already have audio,
+// are being synthesized or still wait for a voice mapping are left alone. It
+// returns the IDs of the segments moved.
+func (o *HybridOrchestrator) PrioritizeSegment(bookID, segmentID string, following int) ([]string, error) {
+	o.mu.RLock()
+	state, exists := o.pipelines[bookID]
+	o.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("no active pipeline for book %s", bookID)
+	}
+
+	state.segmentsMu.RLock()
+	start := -1
+	for i, segment := range state.allSegments {
+		if segment.ID == segmentID {
+			start = i
+			break
+		}
+	}
+	var segmentIDs []string
+	if start >= 0 {
+		for i := start; i < len(state.allSegments) && i <= start+following; i++ {
+			segmentIDs = append(segmentIDs, state.allSegments[i].ID)
+		}
+	}
+	state.segmentsMu.RUnlock()
+	if start < 0 {
+		return nil, fmt.Errorf("segment %s not found in pipeline", segmentID)
+	}
+
+	moved := state.segmentQueue.Prioritize(segmentIDs)
+
+	now := time.Now()
+	state.ttsMu.Lock()
+	for _, id := range moved {
+		if _, pending := state.priority.requestedAt[id]; !pending {
+			state.priority.requestedAt[id] = now
+			state.priority.requested++
+		}
+	}
+	state.ttsMu.Unlock()
+
+	log.Printf("[PrioritizeSegment] Book %s: moved %d of %d segments from %s to the priority lane", bookID, len(moved), len(segmentIDs), segmentID)
+	return moved, nil
+}
+
+// GetPriorityLatency returns the play-now latency statistics of a running pipeline
+func (o *HybridOrchestrator) GetPriorityLatency(bookID string) (*PriorityLatency, error) {
+	o.mu.RLock()
+	state, exists := o.pipelines[bookID]
+	o.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("no active pipeline for book %s", bookID)
+	}
+
+	state.ttsMu.RLock()
+	defer state.ttsMu.RUnlock()
+	tracker := &state.priority
+	latency := &PriorityLatency{
+		Requested: tracker.requested,
+		Served:    tracker.served,
+		Pending:   len(tracker.requestedAt),
+		LastMS:    tracker.last.Milliseconds(),
+		MaxMS:     tracker.max.Milliseconds(),
+	}
+	if tracker.served > 0 {
+		latency.AverageMS = (tracker.total / time.Duration(tracker.served)).Milliseconds()
+	}
+	return latency, nil
+}
+
+// recordPriorityServed records the latency of a prioritized segment once its
+// audio is stored. Callers must hold state.ttsMu.
+func (state *hybridPipelineState) recordPriorityServed(segmentID string) (time.Duration, bool) {
+	requestedAt, ok := state.priority.requestedAt[segmentID]
+	if !ok {
+		return 0, false
+	}
+	delete(state.priority.requestedAt, segmentID)
+
+	latency := time.Since(requestedAt)
+	state.priority.served++
+	state.priority.total += latency
+	state.priority.last = latency
+	if latency > state.priority.max {
+		state.priority.max = latency
+	}
+	return latency, true
+}
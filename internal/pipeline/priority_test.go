@@ -0,0 +1,136 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/provider"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestSegmentQueuePrioritize(t *testing.T) {
+	queue := NewSegmentQueue()
+	for _, id := range []string{"seg_00001", "seg_00002", "seg_00003", "seg_00004"} {
+		queue.Enqueue(&types.Segment{ID: id}, true)
+	}
+	queue.EnqueueStale(&types.Segment{ID: "seg_00005", AudioStale: true})
+
+	if moved := queue.Prioritize([]string{"seg_00003", "seg_00004", "seg_09999"}); len(moved) != 2 {
+		t.Fatalf("expected 2 segments moved, got %v", moved)
+	}
+	// A later request goes ahead of earlier ones, even for stale audio
+	if moved := queue.Prioritize([]string{"seg_00005"}); len(moved) != 1 {
+		t.Fatalf("expected stale segment moved, got %v", moved)
+	}
+	if queue.PriorityCount() != 3 || queue.ReadyCount() != 5 {
+		t.Fatalf("expected 3 prioritized of 5 ready segments, got %d and %d", queue.PriorityCount(), queue.ReadyCount())
+	}
+
+	expected := []string{"seg_00005", "seg_00003", "seg_00004", "seg_00001", "seg_00002"}
+	for _, id := range expected {
+		if got := queue.DequeueNext(false); got == nil || got.ID != id {
+			t.Fatalf("expected %s next, got %#v", id, got)
+		}
+	}
+	if got := queue.DequeueNext(true); got != nil {
+		t.Fatalf("expected empty queue, got %s", got.ID)
+	}
+}
+
+func TestPrioritizeSegmentJumpsScopeAndReportsLatency(t *testing.T) {
+	ctx := context.Background()
+	repo := newPipelineTestRepository()
+	ttsProvider := &pipelineTestTTSProvider{}
+	registry := provider.NewRegistry()
+	if err := registry.RegisterTTS(ttsProvider); err != nil {
+		t.Fatalf("register tts provider: %v", err)
+	}
+
+	book := &types.Book{ID: "book_priority", Title: "Priority", Status: "synthesizing"}
+	if err := repo.SaveBook(ctx, book); err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+	chapters := []*types.Chapter{
+		{ID: "ch_001", BookID: book.ID, Number: 1, Paragraphs: []string{"one"}},
+		{ID: "ch_002", BookID: book.ID, Number: 2, Paragraphs: []string{"two", "three"}},
+	}
+	segments := []*types.Segment{
+		{ID: "seg_00001", BookID: book.ID, Chapter: "ch_001", Text: "one", Person: "narrator"},
+		{ID: "seg_00002", BookID: book.ID, Chapter: "ch_002", Text: "two", Person: "narrator"},
+		{ID: "seg_00003", BookID: book.ID, Chapter: "ch_002", Text: "three", Person: "narrator"},
+	}
+	for _, segment := range segments {
+		if err := repo.SaveSegment(ctx, segment); err != nil {
+			t.Fatalf("save segment: %v", err)
+		}
+	}
+	voiceMap := &types.VoiceMap{BookID: book.ID, Persons: []types.PersonVoice{{ID: "narrator", ProviderVoice: "voice-a"}}}
+
+	orchestrator := NewHybridOrchestrator(
+		PipelineConfig{TTSConcurrency: 1, MinSegmentsBeforeTTS: 1, SegmentationBatchSize: 1},
+		repo,
+		newPipelineTestStorage(),
+		&recordingLLMProvider{},
+		registry,
+	)
+	// Hold back chapter 2 so only the play request can start it
+	checkpoint := resynthesisCheckpoint(book.ID, chapters, segments, voiceMap)
+	checkpoint.Scope = &SynthesisScope{Chapters: []string{"ch_001"}, Only: true}
+	if err := orchestrator.storeCheckpoint(ctx, checkpoint); err != nil {
+		t.Fatalf("store checkpoint: %v", err)
+	}
+	if err := orchestrator.ResumePipeline(ctx, book.ID, chapters, nil); err != nil {
+		t.Fatalf("resume pipeline: %v", err)
+	}
+
+	moved, err := orchestrator.PrioritizeSegment(book.ID, "seg_00002", 0)
+	if err != nil {
+		t.Fatalf("prioritize segment: %v", err)
+	}
+	if len(moved) != 1 || moved[0] != "seg_00002" {
+		t.Fatalf("expected seg_00002 to be prioritized, got %v", moved)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		latency, err := orchestrator.GetPriorityLatency(book.ID)
+		if err != nil {
+			t.Fatalf("get priority latency: %v", err)
+		}
+		if latency.Served == 1 {
+			if latency.Requested != 1 || latency.Pending != 0 {
+				t.Fatalf("unexpected latency stats: %+v", latency)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected prioritized segment to be served, got %+v", latency)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if ttsProvider.callsFor("three") != 0 {
+		t.Fatalf("expected only the requested segment of the deferred chapter to be synthesized")
+	}
+	if _, err := orchestrator.PrioritizeSegment(book.ID, "seg_09999", 0); err == nil {
+		t.Fatalf("expected error for unknown segment")
+	}
+
+	if _, err := orchestrator.SetSynthesisScope(ctx, book.ID, nil); err != nil {
+		t.Fatalf("clear scope: %v", err)
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for {
+		updated, err := repo.GetBook(ctx, book.ID)
+		if err == nil && updated.Status == "synthesized" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected book to finish, got %+v", updated)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if ttsProvider.callsFor("two") != 1 {
+		t.Fatalf("expected prioritized segment to be synthesized once, got calls %v", ttsProvider.callOrder())
+	}
+}
@@ -1,6 +1,7 @@
 package pipeline
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/unalkalkan/TwelveReader/pkg/types"
@@ -8,20 +9,26 @@ import (
 
 // SegmentQueue manages segments with priority based on voice mapping status
 type SegmentQueue struct {
+	priorityQueue     []*types.Segment // Segments a listener is waiting for, served before all others
 	mappedQueue       []*types.Segment // Segments with mapped voices, ready for TTS
 	unmappedQueue     []*types.Segment // Segments waiting for voice mapping
 	staleQueue        []*types.Segment // Existing stale audio queued for deferred regeneration
+	deferredQueue     []*types.Segment // Ready segments held back by a chapter scope
 	retryCounts       map[string]int   // segment ID -> failed synthesis attempts
 	permanentlyFailed map[string]bool  // segment IDs that exhausted retry budget
+	chapterRank       map[string]int   // chapter ID -> synthesis priority; nil without a chapter scope
+	scopeOnly         bool             // Whether segments outside chapterRank are deferred
 	mu                sync.RWMutex
 }
 
 // NewSegmentQueue creates a new segment queue
 func NewSegmentQueue() *SegmentQueue {
 	return &SegmentQueue{
+		priorityQueue:     make([]*types.Segment, 0),
 		mappedQueue:       make([]*types.Segment, 0),
 		unmappedQueue:     make([]*types.Segment, 0),
 		staleQueue:        make([]*types.Segment, 0),
+		deferredQueue:     make([]*types.Segment, 0),
 		retryCounts:       make(map[string]int),
 		permanentlyFailed: make(map[string]bool),
 	}
@@ -33,20 +40,30 @@ func (sq *SegmentQueue) Enqueue(segment *types.Segment, isMapped bool) {
 	defer sq.mu.Unlock()
 
 	if isMapped {
-		sq.mappedQueue = append(sq.mappedQueue, segment)
+		if sq.isDeferred(segment) {
+			sq.deferredQueue = append(sq.deferredQueue, segment)
+		} else {
+			sq.mappedQueue = sq.insertByRank(sq.mappedQueue, segment)
+		}
 	} else {
 		sq.unmappedQueue = append(sq.unmappedQueue, segment)
 	}
 }
 
 // DequeueNext returns the next segment ready for TTS, or nil if none available.
-// Fresh mapped segments are always processed before stale regeneration work.
-// Stale segments are only returned when allowStale is true so the orchestrator
-// can defer regeneration until fresh/current synthesis is drained.
+// Prioritized segments come first. Fresh mapped segments are always processed
+// before stale regeneration work. Stale segments are only returned when
+// allowStale is true so the orchestrator can defer regeneration until
+// fresh/current synthesis is drained.
 func (sq *SegmentQueue) DequeueNext(allowStale bool) *types.Segment {
 	sq.mu.Lock()
 	defer sq.mu.Unlock()
 
+	if len(sq.priorityQueue) > 0 {
+		segment := sq.priorityQueue[0]
+		sq.priorityQueue = sq.priorityQueue[1:]
+		return segment
+	}
 	if len(sq.mappedQueue) > 0 {
 		segment := sq.mappedQueue[0]
 		sq.mappedQueue = sq.mappedQueue[1:]
@@ -85,10 +102,87 @@ func (sq *SegmentQueue) PromotePendingSegments(persona string) int {
 
 	// Add promoted segments to the FRONT of mapped queue (priority)
 	sq.mappedQueue = append(toPromote, sq.mappedQueue...)
+	if sq.chapterRank != nil {
+		sq.applyChapterScope()
+	}
 
 	return promoted
 }
 
+// Prioritize moves queued segments to the front of the priority lane, ahead of
+// segments prioritized earlier, keeping the given order. Segments that are not
+// waiting in the mapped, stale or deferred queues are skipped. It returns the
+// IDs of the segments moved.
+func (sq *SegmentQueue) Prioritize(segmentIDs []string) []string {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	wanted := make(map[string]bool, len(segmentIDs))
+	for _, segmentID := range segmentIDs {
+		wanted[segmentID] = true
+	}
+	found := make(map[string]*types.Segment, len(segmentIDs))
+	take := func(queue []*types.Segment) []*types.Segment {
+		remaining := queue[:0]
+		for _, segment := range queue {
+			if wanted[segment.ID] && found[segment.ID] == nil {
+				found[segment.ID] = segment
+				continue
+			}
+			remaining = append(remaining, segment)
+		}
+		return remaining
+	}
+	sq.priorityQueue = take(sq.priorityQueue)
+	sq.mappedQueue = take(sq.mappedQueue)
+	sq.staleQueue = take(sq.staleQueue)
+	sq.deferredQueue = take(sq.deferredQueue)
+
+	moved := make([]string, 0, len(found))
+	lane := make([]*types.Segment, 0, len(found)+len(sq.priorityQueue))
+	for _, segmentID := range segmentIDs {
+		if segment := found[segmentID]; segment != nil {
+			lane = append(lane, segment)
+			moved = append(moved, segmentID)
+			delete(found, segmentID)
+		}
+	}
+	sq.priorityQueue = append(lane, sq.priorityQueue...)
+	return moved
+}
+
+// Remove takes a segment off every queue and reports whether it was queued.
+func (sq *SegmentQueue) Remove(segmentID string) bool {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	removed := false
+	drop := func(queue []*types.Segment) []*types.Segment {
+		remaining := queue[:0]
+		for _, segment := range queue {
+			if segment.ID == segmentID {
+				removed = true
+				continue
+			}
+			remaining = append(remaining, segment)
+		}
+		return remaining
+	}
+	sq.priorityQueue = drop(sq.priorityQueue)
+	sq.mappedQueue = drop(sq.mappedQueue)
+	sq.unmappedQueue = drop(sq.unmappedQueue)
+	sq.staleQueue = drop(sq.staleQueue)
+	sq.deferredQueue = drop(sq.deferredQueue)
+	return removed
+}
+
+// PriorityCount returns the number of segments in the priority lane.
+func (sq *SegmentQueue) PriorityCount() int {
+	sq.mu.RLock()
+	defer sq.mu.RUnlock()
+	return len(sq.priorityQueue)
+}
+
 // UnmappedCount returns the number of segments waiting for voice mapping
 func (sq *SegmentQueue) UnmappedCount() int {
 	sq.mu.RLock()
@@ -107,19 +201,33 @@ func (sq *SegmentQueue) MappedCount() int {
 func (sq *SegmentQueue) ReadyCount() int {
 	sq.mu.RLock()
 	defer sq.mu.RUnlock()
-	return len(sq.mappedQueue) + len(sq.staleQueue)
+	return len(sq.priorityQueue) + len(sq.mappedQueue) + len(sq.staleQueue)
 }
 
 // EnqueueStale adds an existing stale-audio segment to deferred regeneration work.
 func (sq *SegmentQueue) EnqueueStale(segment *types.Segment) {
 	sq.mu.Lock()
 	defer sq.mu.Unlock()
+	for _, queued := range sq.priorityQueue {
+		if queued.ID == segment.ID {
+			return
+		}
+	}
 	for _, queued := range sq.staleQueue {
 		if queued.ID == segment.ID {
 			return
 		}
 	}
-	sq.staleQueue = append(sq.staleQueue, segment)
+	for _, queued := range sq.deferredQueue {
+		if queued.ID == segment.ID {
+			return
+		}
+	}
+	if sq.isDeferred(segment) {
+		sq.deferredQueue = append(sq.deferredQueue, segment)
+		return
+	}
+	sq.staleQueue = sq.insertByRank(sq.staleQueue, segment)
 }
 
 // StaleCount returns the number of stale audio segments waiting for deferred regeneration.
@@ -129,6 +237,102 @@ func (sq *SegmentQueue) StaleCount() int {
 	return len(sq.staleQueue)
 }
 
+// DeferredCount returns the number of ready segments held back by the chapter scope.
+func (sq *SegmentQueue) DeferredCount() int {
+	sq.mu.RLock()
+	defer sq.mu.RUnlock()
+	return len(sq.deferredQueue)
+}
+
+// SetChapterScope orders ready segments by chapter: segments of the given
+// chapters are synthesized first, in the order listed. With only set, segments
+// of other chapters are deferred until the scope changes. An empty chapter
+// list clears the scope and releases all deferred segments.
+func (sq *SegmentQueue) SetChapterScope(chapterIDs []string, only bool) {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	sq.chapterRank = nil
+	sq.scopeOnly = false
+	if len(chapterIDs) > 0 {
+		sq.chapterRank = make(map[string]int, len(chapterIDs))
+		for i, chapterID := range chapterIDs {
+			if _, exists := sq.chapterRank[chapterID]; !exists {
+				sq.chapterRank[chapterID] = i
+			}
+		}
+		sq.scopeOnly = only
+	}
+	sq.applyChapterScope()
+}
+
+// applyChapterScope redistributes queued segments after the chapter scope
+// changed. Callers must hold sq.mu.
+func (sq *SegmentQueue) applyChapterScope() {
+	mapped := make([]*types.Segment, 0, len(sq.mappedQueue))
+	stale := make([]*types.Segment, 0, len(sq.staleQueue))
+	deferred := make([]*types.Segment, 0)
+	place := func(segment *types.Segment, isStale bool) {
+		switch {
+		case sq.isDeferred(segment):
+			deferred = append(deferred, segment)
+		case isStale:
+			stale = append(stale, segment)
+		default:
+			mapped = append(mapped, segment)
+		}
+	}
+	for _, segment := range sq.mappedQueue {
+		place(segment, false)
+	}
+	for _, segment := range sq.staleQueue {
+		place(segment, true)
+	}
+	for _, segment := range sq.deferredQueue {
+		place(segment, segment.AudioStale)
+	}
+
+	sort.SliceStable(mapped, func(i, j int) bool { return sq.rank(mapped[i]) < sq.rank(mapped[j]) })
+	sort.SliceStable(stale, func(i, j int) bool { return sq.rank(stale[i]) < sq.rank(stale[j]) })
+	sq.mappedQueue = mapped
+	sq.staleQueue = stale
+	sq.deferredQueue = deferred
+}
+
+// rank returns the synthesis priority of a segment's chapter; lower ranks go first
+func (sq *SegmentQueue) rank(segment *types.Segment) int {
+	if sq.chapterRank == nil {
+		return 0
+	}
+	if rank, ok := sq.chapterRank[segment.Chapter]; ok {
+		return rank
+	}
+	return len(sq.chapterRank)
+}
+
+// isDeferred reports whether the chapter scope holds a segment back
+func (sq *SegmentQueue) isDeferred(segment *types.Segment) bool {
+	if !sq.scopeOnly {
+		return false
+	}
+	_, inScope := sq.chapterRank[segment.Chapter]
+	return !inScope
+}
+
+// insertByRank inserts a segment after every queued segment of the same or a
+// higher priority
+func (sq *SegmentQueue) insertByRank(queue []*types.Segment, segment *types.Segment) []*types.Segment {
+	rank := sq.rank(segment)
+	i := len(queue)
+	for i > 0 && sq.rank(queue[i-1]) > rank {
+		i--
+	}
+	queue = append(queue, nil)
+	copy(queue[i+1:], queue[i:])
+	queue[i] = segment
+	return queue
+}
+
 // GetUnmappedPersonas returns the unique list of personas in the unmapped queue
 func (sq *SegmentQueue) GetUnmappedPersonas() []string {
 	sq.mu.RLock()
@@ -183,6 +387,67 @@ func (sq *SegmentQueue) ClearRetryTracker(segmentID string) {
 	delete(sq.permanentlyFailed, segmentID)
 }
 
+// QueueSnapshot is a serializable view of a SegmentQueue used for pipeline checkpoints.
+// Queues are recorded as segment IDs in their current order.
+type QueueSnapshot struct {
+	Priority          []string       `json:"priority,omitempty"`
+	Mapped            []string       `json:"mapped"`
+	Unmapped          []string       `json:"unmapped"`
+	Stale             []string       `json:"stale"`
+	Deferred          []string       `json:"deferred,omitempty"`
+	RetryCounts       map[string]int `json:"retry_counts,omitempty"`
+	PermanentlyFailed []string       `json:"permanently_failed,omitempty"`
+}
+
+// Snapshot returns the current queue contents and retry state.
+func (sq *SegmentQueue) Snapshot() QueueSnapshot {
+	sq.mu.RLock()
+	defer sq.mu.RUnlock()
+
+	snapshot := QueueSnapshot{
+		Priority:    segmentIDs(sq.priorityQueue),
+		Mapped:      segmentIDs(sq.mappedQueue),
+		Unmapped:    segmentIDs(sq.unmappedQueue),
+		Stale:       segmentIDs(sq.staleQueue),
+		Deferred:    segmentIDs(sq.deferredQueue),
+		RetryCounts: make(map[string]int, len(sq.retryCounts)),
+	}
+	for segmentID, count := range sq.retryCounts {
+		snapshot.RetryCounts[segmentID] = count
+	}
+	for segmentID := range sq.permanentlyFailed {
+		snapshot.PermanentlyFailed = append(snapshot.PermanentlyFailed, segmentID)
+	}
+	return snapshot
+}
+
+// RestoreRetryState reloads retry counts and permanent failures from a snapshot.
+func (sq *SegmentQueue) RestoreRetryState(snapshot QueueSnapshot) {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	for segmentID, count := range snapshot.RetryCounts {
+		sq.retryCounts[segmentID] = count
+	}
+	for _, segmentID := range snapshot.PermanentlyFailed {
+		sq.permanentlyFailed[segmentID] = true
+	}
+}
+
+// IsPermanentlyFailed reports whether a segment exhausted its retry budget.
+func (sq *SegmentQueue) IsPermanentlyFailed(segmentID string) bool {
+	sq.mu.RLock()
+	defer sq.mu.RUnlock()
+	return sq.permanentlyFailed[segmentID]
+}
+
+func segmentIDs(segments []*types.Segment) []string {
+	ids := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		ids = append(ids, segment.ID)
+	}
+	return ids
+}
+
 // Close signals that no more segments will be added
 func (sq *SegmentQueue) Close() {
 	// For future use if we add channels
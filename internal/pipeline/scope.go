@@ -0,0 +1,118 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// SynthesisScope prioritizes or restricts TTS synthesis to chosen chapters.
+// Either Chapters lists the chapters to synthesize first, or Ahead selects
+// that many chapters starting at the listener's position in FromChapter;
+// ahead mode always restricts synthesis to the selected chapters.
+type SynthesisScope struct {
+	Chapters    []string `json:"chapters,omitempty"`     // Chapter IDs, in synthesis order
+	Only        bool     `json:"only,omitempty"`         // Defer segments of all other chapters
+	FromChapter string   `json:"from_chapter,omitempty"` // Listener position for ahead mode
+	Ahead       int      `json:"ahead,omitempty"`        // Chapters to synthesize from FromChapter
+}
+
+// resolveSynthesisScope validates a scope against a book's chapters and
+// expands ahead mode into an explicit chapter list. A scope without chapters
+// resolves to nil, meaning the whole book is synthesized in reading order.
+func resolveSynthesisScope(scope *SynthesisScope, chapters []*types.Chapter) (*SynthesisScope, error) {
+	if scope == nil {
+		return nil, nil
+	}
+
+	index := make(map[string]int, len(chapters))
+	for i, chapter := range chapters {
+		index[chapter.ID] = i
+	}
+
+	if scope.Ahead < 0 {
+		return nil, fmt.Errorf("ahead must not be negative")
+	}
+	if scope.Ahead > 0 {
+		start := 0
+		if scope.FromChapter != "" {
+			i, ok := index[scope.FromChapter]
+			if !ok {
+				return nil, fmt.Errorf("unknown chapter %q", scope.FromChapter)
+			}
+			start = i
+		}
+		resolved := &SynthesisScope{Only: true, FromChapter: scope.FromChapter, Ahead: scope.Ahead}
+		for i := start; i < len(chapters) && i < start+scope.Ahead; i++ {
+			resolved.Chapters = append(resolved.Chapters, chapters[i].ID)
+		}
+		return resolved, nil
+	}
+
+	if len(scope.Chapters) == 0 {
+		if scope.Only {
+			return nil, fmt.Errorf("chapters are required to restrict synthesis")
+		}
+		return nil, nil
+	}
+	for _, chapterID := range scope.Chapters {
+		if _, ok := index[chapterID]; !ok {
+			return nil, fmt.Errorf("unknown chapter %q", chapterID)
+		}
+	}
+	return &SynthesisScope{Chapters: append([]string(nil), scope.Chapters...), Only: scope.Only}, nil
+}
+
+// SetSynthesisScope changes which chapters a running pipeline synthesizes
+// first, or exclusively. Segments held back by a restricting scope keep the
+// pipeline open until the scope is widened or cleared. It returns the
+// resolved scope.
+func (o *HybridOrchestrator) SetSynthesisScope(ctx context.Context, bookID string, scope *SynthesisScope) (*SynthesisScope, error) {
+	o.mu.RLock()
+	state, exists := o.pipelines[bookID]
+	o.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("no active pipeline for book %s", bookID)
+	}
+
+	resolved, err := resolveSynthesisScope(scope, state.chapters)
+	if err != nil {
+		return nil, err
+	}
+	state.applySynthesisScope(resolved)
+	o.saveCheckpoint(ctx, state)
+
+	log.Printf("[SetSynthesisScope] Book %s scope %+v (%d segments deferred)", bookID, resolved, state.segmentQueue.DeferredCount())
+	return resolved, nil
+}
+
+// GetSynthesisScope returns the synthesis scope of a running pipeline and
+// the number of segments it holds back. A nil scope means the whole book.
+func (o *HybridOrchestrator) GetSynthesisScope(bookID string) (*SynthesisScope, int, error) {
+	o.mu.RLock()
+	state, exists := o.pipelines[bookID]
+	o.mu.RUnlock()
+	if !exists {
+		return nil, 0, fmt.Errorf("no active pipeline for book %s", bookID)
+	}
+
+	state.segmentsMu.RLock()
+	scope := state.scope
+	state.segmentsMu.RUnlock()
+	return scope, state.segmentQueue.DeferredCount(), nil
+}
+
+// applySynthesisScope records a resolved scope and reorders the segment queue
+func (state *hybridPipelineState) applySynthesisScope(scope *SynthesisScope) {
+	state.segmentsMu.Lock()
+	state.scope = scope
+	state.segmentsMu.Unlock()
+
+	if scope == nil {
+		state.segmentQueue.SetChapterScope(nil, false)
+		return
+	}
+	state.segmentQueue.SetChapterScope(scope.Chapters, scope.Only)
+}
@@ -0,0 +1,161 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/provider"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+func TestSegmentQueueChapterScope(t *testing.T) {
+	queue := NewSegmentQueue()
+	for _, segment := range []*types.Segment{
+		{ID: "seg_00001", Chapter: "ch_001"},
+		{ID: "seg_00002", Chapter: "ch_002"},
+		{ID: "seg_00003", Chapter: "ch_003"},
+		{ID: "seg_00004", Chapter: "ch_001"},
+	} {
+		queue.Enqueue(segment, true)
+	}
+
+	dequeueIDs := func() []string {
+		var ids []string
+		for segment := queue.DequeueNext(true); segment != nil; segment = queue.DequeueNext(true) {
+			ids = append(ids, segment.ID)
+		}
+		return ids
+	}
+	assertOrder := func(got []string, expected ...string) {
+		t.Helper()
+		if len(got) != len(expected) {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Fatalf("expected %v, got %v", expected, got)
+			}
+		}
+	}
+
+	queue.SetChapterScope([]string{"ch_003", "ch_002"}, true)
+	if queue.DeferredCount() != 2 || queue.ReadyCount() != 2 {
+		t.Fatalf("expected 2 ready and 2 deferred segments, got %d and %d", queue.ReadyCount(), queue.DeferredCount())
+	}
+	queue.Enqueue(&types.Segment{ID: "seg_00005", Chapter: "ch_002"}, true)
+	queue.Enqueue(&types.Segment{ID: "seg_00006", Chapter: "ch_004"}, true)
+	queue.EnqueueStale(&types.Segment{ID: "seg_00007", Chapter: "ch_004", AudioStale: true})
+	assertOrder(dequeueIDs(), "seg_00003", "seg_00002", "seg_00005")
+
+	// Prioritizing keeps other chapters queued behind the chosen ones
+	queue.SetChapterScope([]string{"ch_004"}, false)
+	if queue.DeferredCount() != 0 {
+		t.Fatalf("expected no deferred segments, got %d", queue.DeferredCount())
+	}
+	queue.Enqueue(&types.Segment{ID: "seg_00008", Chapter: "ch_004"}, true)
+	assertOrder(dequeueIDs(), "seg_00006", "seg_00008", "seg_00001", "seg_00004", "seg_00007")
+}
+
+func TestResolveSynthesisScope(t *testing.T) {
+	chapters := []*types.Chapter{{ID: "ch_001"}, {ID: "ch_002"}, {ID: "ch_003"}}
+
+	scope, err := resolveSynthesisScope(&SynthesisScope{FromChapter: "ch_002", Ahead: 5}, chapters)
+	if err != nil {
+		t.Fatalf("resolve ahead scope: %v", err)
+	}
+	if !scope.Only || len(scope.Chapters) != 2 || scope.Chapters[0] != "ch_002" || scope.Chapters[1] != "ch_003" {
+		t.Fatalf("unexpected ahead scope: %+v", scope)
+	}
+
+	if scope, err := resolveSynthesisScope(&SynthesisScope{}, chapters); err != nil || scope != nil {
+		t.Fatalf("expected empty scope to clear, got %+v (%v)", scope, err)
+	}
+	for _, invalid := range []*SynthesisScope{
+		{Chapters: []string{"ch_009"}},
+		{FromChapter: "ch_009", Ahead: 1},
+		{Only: true},
+		{Ahead: -1},
+	} {
+		if _, err := resolveSynthesisScope(invalid, chapters); err == nil {
+			t.Fatalf("expected error for scope %+v", invalid)
+		}
+	}
+}
+
+func TestSynthesisScopeDefersOtherChaptersUntilCleared(t *testing.T) {
+	ctx := context.Background()
+	repo := newPipelineTestRepository()
+	ttsProvider := &pipelineTestTTSProvider{}
+	registry := provider.NewRegistry()
+	if err := registry.RegisterTTS(ttsProvider); err != nil {
+		t.Fatalf("register tts provider: %v", err)
+	}
+
+	book := &types.Book{ID: "book_scope", Title: "Scope", Status: "synthesizing"}
+	if err := repo.SaveBook(ctx, book); err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+	chapters := []*types.Chapter{
+		{ID: "ch_001", BookID: book.ID, Number: 1, Paragraphs: []string{"one"}},
+		{ID: "ch_002", BookID: book.ID, Number: 2, Paragraphs: []string{"two"}},
+	}
+	segments := []*types.Segment{
+		{ID: "seg_00001", BookID: book.ID, Chapter: "ch_001", Text: "one", Person: "narrator"},
+		{ID: "seg_00002", BookID: book.ID, Chapter: "ch_002", Text: "two", Person: "narrator"},
+	}
+	for _, segment := range segments {
+		if err := repo.SaveSegment(ctx, segment); err != nil {
+			t.Fatalf("save segment: %v", err)
+		}
+	}
+	voiceMap := &types.VoiceMap{BookID: book.ID, Persons: []types.PersonVoice{{ID: "narrator", ProviderVoice: "voice-a"}}}
+
+	orchestrator := NewHybridOrchestrator(
+		PipelineConfig{TTSConcurrency: 1, MinSegmentsBeforeTTS: 1, SegmentationBatchSize: 1},
+		repo,
+		newPipelineTestStorage(),
+		&recordingLLMProvider{},
+		registry,
+	)
+	checkpoint := resynthesisCheckpoint(book.ID, chapters, segments, voiceMap)
+	checkpoint.Scope = &SynthesisScope{Chapters: []string{"ch_002"}, Only: true}
+	if err := orchestrator.storeCheckpoint(ctx, checkpoint); err != nil {
+		t.Fatalf("store checkpoint: %v", err)
+	}
+	if err := orchestrator.ResumePipeline(ctx, book.ID, chapters, nil); err != nil {
+		t.Fatalf("resume pipeline: %v", err)
+	}
+
+	waitFor := func(description string, done func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !done() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", description)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	waitFor("in-scope chapter", func() bool { return ttsProvider.callsFor("two") == 1 })
+	time.Sleep(150 * time.Millisecond)
+	if ttsProvider.callsFor("one") != 0 {
+		t.Fatalf("expected chapter outside the scope to be deferred")
+	}
+	scope, deferred, err := orchestrator.GetSynthesisScope(book.ID)
+	if err != nil || scope == nil || deferred != 1 {
+		t.Fatalf("expected open pipeline with one deferred segment, got %+v, %d, %v", scope, deferred, err)
+	}
+
+	if _, err := orchestrator.SetSynthesisScope(ctx, book.ID, nil); err != nil {
+		t.Fatalf("clear scope: %v", err)
+	}
+	waitFor("book to finish", func() bool {
+		updated, err := repo.GetBook(ctx, book.ID)
+		return err == nil && updated.Status == "synthesized"
+	})
+	if ttsProvider.callsFor("one") != 1 {
+		t.Fatalf("expected deferred segment to be synthesized once, got calls %v", ttsProvider.callOrder())
+	}
+}
@@ -243,7 +243,7 @@ func (o *OpenAILLMProvider) callChatCompletion(ctx context.Context, messages []m
 		if err != nil {
 			log.Printf("[LLM-%s] Request attempt %d/%d failed after %v: %v", o.name, attempt+1, o.maxRetries+1, duration, err)
 			if attempt < o.maxRetries {
-				if waitErr := sleepBeforeRetry(ctx, computeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
+				if waitErr := sleepBeforeRetry(ctx, ComputeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
 					return "", fmt.Errorf("failed to execute request: %w", err)
 				}
 				continue
@@ -266,7 +266,7 @@ func (o *OpenAILLMProvider) callChatCompletion(ctx context.Context, messages []m
 
 		if isRetryableStatusCode(resp.StatusCode) && attempt < o.maxRetries {
 			log.Printf("[LLM-%s] Retryable API status %d; retrying after backoff", o.name, resp.StatusCode)
-			if waitErr := sleepBeforeRetry(ctx, computeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
+			if waitErr := sleepBeforeRetry(ctx, ComputeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
 				return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
 			}
 			continue
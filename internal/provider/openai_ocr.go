@@ -212,7 +212,7 @@ func (o *OpenAIOCRProvider) callVisionCompletion(ctx context.Context, messages [
 		if err != nil {
 			log.Printf("[OCR-%s] Request attempt %d/%d failed after %v: %v", o.name, attempt+1, o.maxRetries+1, duration, err)
 			if attempt < o.maxRetries {
-				if waitErr := sleepBeforeRetry(ctx, computeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
+				if waitErr := sleepBeforeRetry(ctx, ComputeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
 					return "", fmt.Errorf("failed to execute request: %w", err)
 				}
 				continue
@@ -238,7 +238,7 @@ func (o *OpenAIOCRProvider) callVisionCompletion(ctx context.Context, messages [
 
 		if isRetryableStatusCode(resp.StatusCode) && attempt < o.maxRetries {
 			log.Printf("[OCR-%s] Retryable API status %d; retrying after backoff", o.name, resp.StatusCode)
-			if waitErr := sleepBeforeRetry(ctx, computeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
+			if waitErr := sleepBeforeRetry(ctx, ComputeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
 				return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
 			}
 			continue
@@ -1,9 +1,7 @@
 package provider
 
 import (
-	"bytes"
 	"context"
-	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,6 +12,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/unalkalkan/TwelveReader/internal/audio"
 	"github.com/unalkalkan/TwelveReader/pkg/types"
 )
 
@@ -120,7 +119,7 @@ func (o *OpenAITTSProvider) Synthesize(ctx context.Context, req TTSRequest) (*TT
 		var err error
 		switch format {
 		case "wav":
-			audioData, err = concatWAV(audioChunks)
+			audioData, _, err = audio.ConcatWAV(audioChunks, 0)
 		default:
 			return nil, fmt.Errorf("cannot concatenate %d TTS chunks with format %s", len(audioChunks), format)
 		}
@@ -305,7 +304,7 @@ func (o *OpenAITTSProvider) callTTSAPI(ctx context.Context, req ttsAPIRequest) (
 		if err != nil {
 			log.Printf("[TTS-%s] Request attempt %d/%d failed after %v: %v", o.name, attempt+1, o.maxRetries+1, duration, err)
 			if attempt < o.maxRetries {
-				if waitErr := sleepBeforeRetry(ctx, computeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
+				if waitErr := sleepBeforeRetry(ctx, ComputeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
 					return nil, "", fmt.Errorf("failed to execute request: %w", err)
 				}
 				continue
@@ -328,7 +327,7 @@ func (o *OpenAITTSProvider) callTTSAPI(ctx context.Context, req ttsAPIRequest) (
 
 		if isRetryableStatusCode(resp.StatusCode) && attempt < o.maxRetries {
 			log.Printf("[TTS-%s] Retryable API status %d; retrying after backoff", o.name, resp.StatusCode)
-			if waitErr := sleepBeforeRetry(ctx, computeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
+			if waitErr := sleepBeforeRetry(ctx, ComputeBackoff(attempt, o.retryBackoffMs)); waitErr != nil {
 				return nil, "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
 			}
 			continue
@@ -461,76 +460,6 @@ func normalizeTTSLanguage(language string) string {
 	}
 }
 
-func concatWAV(chunks [][]byte) ([]byte, error) {
-	if len(chunks) == 0 {
-		return nil, fmt.Errorf("no wav chunks")
-	}
-	if len(chunks) == 1 {
-		return chunks[0], nil
-	}
-
-	first, err := parseSimpleWAV(chunks[0])
-	if err != nil {
-		return nil, err
-	}
-	var data bytes.Buffer
-	data.Write(first.data)
-	for i, chunk := range chunks[1:] {
-		parsed, err := parseSimpleWAV(chunk)
-		if err != nil {
-			return nil, fmt.Errorf("chunk %d: %w", i+2, err)
-		}
-		if !bytes.Equal(parsed.fmtChunk, first.fmtChunk) {
-			return nil, fmt.Errorf("chunk %d has different WAV format", i+2)
-		}
-		data.Write(parsed.data)
-	}
-
-	dataBytes := data.Bytes()
-	out := make([]byte, 44+len(dataBytes))
-	copy(out[0:4], "RIFF")
-	binary.LittleEndian.PutUint32(out[4:8], uint32(36+len(dataBytes)))
-	copy(out[8:12], "WAVE")
-	copy(out[12:16], "fmt ")
-	binary.LittleEndian.PutUint32(out[16:20], 16)
-	copy(out[20:36], first.fmtChunk)
-	copy(out[36:40], "data")
-	binary.LittleEndian.PutUint32(out[40:44], uint32(len(dataBytes)))
-	copy(out[44:], dataBytes)
-	return out, nil
-}
-
-type simpleWAV struct {
-	fmtChunk []byte
-	data     []byte
-}
-
-func parseSimpleWAV(body []byte) (*simpleWAV, error) {
-	if len(body) < 44 || string(body[0:4]) != "RIFF" || string(body[8:12]) != "WAVE" {
-		return nil, fmt.Errorf("not a RIFF/WAVE file")
-	}
-	if string(body[12:16]) != "fmt " {
-		return nil, fmt.Errorf("unsupported WAV layout: missing fmt chunk")
-	}
-	fmtLen := int(binary.LittleEndian.Uint32(body[16:20]))
-	if fmtLen < 16 || len(body) < 20+fmtLen+8 {
-		return nil, fmt.Errorf("invalid WAV fmt chunk")
-	}
-	dataHeader := 20 + fmtLen
-	if string(body[dataHeader:dataHeader+4]) != "data" {
-		return nil, fmt.Errorf("unsupported WAV layout: missing data chunk")
-	}
-	dataLen := int(binary.LittleEndian.Uint32(body[dataHeader+4 : dataHeader+8]))
-	dataStart := dataHeader + 8
-	if len(body) < dataStart+dataLen {
-		return nil, fmt.Errorf("truncated WAV data")
-	}
-	return &simpleWAV{
-		fmtChunk: append([]byte(nil), body[20:20+fmtLen]...),
-		data:     append([]byte(nil), body[dataStart:dataStart+dataLen]...),
-	}, nil
-}
-
 func audioFormatFromBytes(body []byte) string {
 	if len(body) >= 12 && string(body[0:4]) == "RIFF" && string(body[8:12]) == "WAVE" {
 		return "wav"
@@ -19,7 +19,9 @@ func isRetryableStatusCode(statusCode int) bool {
 	return statusCode == http.StatusTooManyRequests || statusCode >= 500
 }
 
-func computeBackoff(attempt int, baseBackoffMs int) time.Duration {
+// ComputeBackoff returns the exponential backoff before retry attempt+1,
+// capped at 30 seconds
+func ComputeBackoff(attempt int, baseBackoffMs int) time.Duration {
 	ms := float64(baseBackoffMs) * math.Pow(2, float64(attempt))
 	if ms > maxBackoffMs {
 		ms = maxBackoffMs
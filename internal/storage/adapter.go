@@ -13,6 +13,14 @@ type Adapter interface {
 	// Get retrieves data from the given path
 	Get(ctx context.Context, path string) (io.ReadCloser, error)
 
+	// GetRange retrieves length bytes starting at offset from the given path.
+	// A negative length reads to the end of the data.
+	GetRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+
+	// Stat returns the size, modification time and entity tag of the data at
+	// the given path
+	Stat(ctx context.Context, path string) (*Metadata, error)
+
 	// Delete removes data at the given path
 	Delete(ctx context.Context, path string) error
 
@@ -33,6 +41,7 @@ type Adapter interface {
 type Metadata struct {
 	Path         string
 	Size         int64
-	LastModified int64
+	LastModified int64 // Unix time in seconds
 	ContentType  string
+	ETag         string // Quoted entity tag that changes whenever the data does
 }
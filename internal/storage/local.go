@@ -65,6 +65,47 @@ func (l *LocalAdapter) Get(ctx context.Context, path string) (io.ReadCloser, err
 	return file, nil
 }
 
+// GetRange retrieves length bytes starting at offset from the given path
+func (l *LocalAdapter) GetRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	reader, err := l.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	file := reader.(*os.File)
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek file: %w", err)
+	}
+	if length < 0 {
+		return file, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(file, length), file}, nil
+}
+
+// Stat returns the size, modification time and entity tag of the file at the given path
+func (l *LocalAdapter) Stat(ctx context.Context, path string) (*Metadata, error) {
+	info, err := os.Stat(l.fullPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("file not found: %s", path)
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+
+	return &Metadata{
+		Path:         path,
+		Size:         info.Size(),
+		LastModified: info.ModTime().Unix(),
+		ETag:         fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()),
+	}, nil
+}
+
 // Delete removes data at the given path
 func (l *LocalAdapter) Delete(ctx context.Context, path string) error {
 	fullPath := l.fullPath(path)
@@ -97,6 +138,11 @@ func (l *LocalAdapter) Exists(ctx context.Context, path string) (bool, error) {
 // List returns paths matching the given prefix
 func (l *LocalAdapter) List(ctx context.Context, prefix string) ([]string, error) {
 	fullPrefix := l.fullPath(prefix)
+	// filepath.Join drops the trailing separator, which would let a
+	// directory prefix match sibling files sharing its name
+	if strings.HasSuffix(prefix, "/") || strings.HasSuffix(prefix, string(filepath.Separator)) {
+		fullPrefix += string(filepath.Separator)
+	}
 	var paths []string
 
 	// Walk the directory tree
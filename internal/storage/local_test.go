@@ -70,6 +70,72 @@ func TestLocalAdapter(t *testing.T) {
 		if len(paths) < 1 {
 			t.Errorf("Expected at least 1 file, got %d", len(paths))
 		}
+
+		adapter.Put(ctx, "test_sibling.txt", bytes.NewReader([]byte("sibling")))
+		paths, err = adapter.List(ctx, "test/")
+		if err != nil {
+			t.Fatalf("Failed to list files: %v", err)
+		}
+		for _, path := range paths {
+			if path == "test_sibling.txt" {
+				t.Errorf("Expected a directory prefix to exclude sibling files, got %v", paths)
+			}
+		}
+	})
+
+	// Test GetRange
+	t.Run("GetRange", func(t *testing.T) {
+		tests := []struct {
+			offset, length int64
+			expected       string
+		}{
+			{7, 5, "World"},
+			{7, -1, "World!"},
+			{0, 0, ""},
+			{12, 10, "!"},
+		}
+		for _, tt := range tests {
+			reader, err := adapter.GetRange(ctx, testPath, tt.offset, tt.length)
+			if err != nil {
+				t.Fatalf("Failed to get range %d+%d: %v", tt.offset, tt.length, err)
+			}
+			data, err := io.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				t.Fatalf("Failed to read range: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected range %d+%d to be %q, got %q", tt.offset, tt.length, tt.expected, data)
+			}
+		}
+	})
+
+	// Test Stat
+	t.Run("Stat", func(t *testing.T) {
+		metadata, err := adapter.Stat(ctx, testPath)
+		if err != nil {
+			t.Fatalf("Failed to stat data: %v", err)
+		}
+		if metadata.Size != int64(len(testData)) || metadata.LastModified == 0 || metadata.ETag == "" {
+			t.Errorf("Unexpected metadata: %+v", metadata)
+		}
+
+		adapter.Put(ctx, testPath, bytes.NewReader([]byte("Hello, Reader!!")))
+		updated, err := adapter.Stat(ctx, testPath)
+		if err != nil {
+			t.Fatalf("Failed to stat data: %v", err)
+		}
+		if updated.ETag == metadata.ETag {
+			t.Error("Expected entity tag to change with the data")
+		}
+		adapter.Put(ctx, testPath, bytes.NewReader(testData))
+
+		if _, err := adapter.Stat(ctx, "non-existent.txt"); err == nil {
+			t.Error("Expected error for non-existent file")
+		}
+		if _, err := adapter.Stat(ctx, "test"); err == nil {
+			t.Error("Expected error for a directory")
+		}
 	})
 
 	// Test Delete
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -76,25 +77,89 @@ func NewS3Adapter(opts S3Options) (*S3Adapter, error) {
 	}, nil
 }
 
-// Put stores data at the given path
+// s3PartSize is the chunk size of multipart uploads, which bounds the memory
+// Put needs for large files
+const s3PartSize = 8 << 20
+
+// s3BufferPool recycles the buffers Put reads data into. A buffer only grows
+// as large as the data, so small objects such as segment JSON stay small.
+var s3BufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// Put stores data at the given path. Data larger than one part is uploaded
+// with a multipart upload so it is never held in memory as a whole.
 func (s *S3Adapter) Put(ctx context.Context, path string, data io.Reader) error {
-	// Read all data into memory (for small files this is acceptable)
-	// For large files, we'd want to use multipart uploads
-	buf, err := io.ReadAll(data)
-	if err != nil {
+	buf := s3BufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer s3BufferPool.Put(buf)
+
+	n, err := io.CopyN(buf, data, s3PartSize)
+	if err != nil && err != io.EOF {
 		return fmt.Errorf("failed to read data: %w", err)
 	}
+	if n < s3PartSize {
+		_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(path),
+			Body:   bytes.NewReader(buf.Bytes()),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to put object: %w", err)
+		}
+		return nil
+	}
 
-	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+	upload, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(path),
-		Body:   bytes.NewReader(buf),
 	})
-
 	if err != nil {
-		return fmt.Errorf("failed to put object: %w", err)
+		return fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+
+	if err := s.uploadParts(ctx, path, upload.UploadId, data, buf); err != nil {
+		// Use a fresh context so cancelled uploads are still cleaned up
+		s.client.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      aws.String(path),
+			UploadId: upload.UploadId,
+		})
+		return err
 	}
+	return nil
+}
+
+// uploadParts uploads the first part already read into buf followed by the
+// rest of data, then completes the multipart upload
+func (s *S3Adapter) uploadParts(ctx context.Context, path string, uploadID *string, data io.Reader, buf *bytes.Buffer) error {
+	var parts []types.CompletedPart
+	for partNumber := int32(1); buf.Len() > 0; partNumber++ {
+		result, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String(s.bucket),
+			Key:        aws.String(path),
+			UploadId:   uploadID,
+			PartNumber: aws.Int32(partNumber),
+			Body:       bytes.NewReader(buf.Bytes()),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+		}
+		parts = append(parts, types.CompletedPart{ETag: result.ETag, PartNumber: aws.Int32(partNumber)})
 
+		buf.Reset()
+		if _, err := io.CopyN(buf, data, s3PartSize); err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read data: %w", err)
+		}
+	}
+
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(path),
+		UploadId:        uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
 	return nil
 }
 
@@ -112,6 +177,51 @@ func (s *S3Adapter) Get(ctx context.Context, path string) (io.ReadCloser, error)
 	return result.Body, nil
 }
 
+// GetRange retrieves length bytes starting at offset from the given path
+// with a ranged GET
+func (s *S3Adapter) GetRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		byteRange = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(path),
+		Range:  aws.String(byteRange),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object range: %w", err)
+	}
+
+	return result.Body, nil
+}
+
+// Stat returns the size, modification time and entity tag of the object at the given path
+func (s *S3Adapter) Stat(ctx context.Context, path string) (*Metadata, error) {
+	result, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	metadata := &Metadata{
+		Path:        path,
+		Size:        aws.ToInt64(result.ContentLength),
+		ContentType: aws.ToString(result.ContentType),
+		ETag:        aws.ToString(result.ETag),
+	}
+	if result.LastModified != nil {
+		metadata.LastModified = result.LastModified.Unix()
+	}
+	return metadata, nil
+}
+
 // Delete removes data at the given path
 func (s *S3Adapter) Delete(ctx context.Context, path string) error {
 	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"time"
 )
 
 // SampleStore stores reusable voice preview audio samples.
@@ -13,6 +14,7 @@ type SampleStore interface {
 	Put(ctx context.Context, path string, data []byte) error
 	Get(ctx context.Context, path string) ([]byte, error)
 	Exists(ctx context.Context, path string) (bool, error)
+	Stat(ctx context.Context, path string) (*Metadata, error)
 }
 
 // AdapterSampleStore persists samples through the configured storage adapter.
@@ -51,15 +53,23 @@ func (s *AdapterSampleStore) Exists(ctx context.Context, path string) (bool, err
 	return s.adapter.Exists(ctx, path)
 }
 
+func (s *AdapterSampleStore) Stat(ctx context.Context, path string) (*Metadata, error) {
+	if s == nil || s.adapter == nil {
+		return nil, fmt.Errorf("sample storage adapter is nil")
+	}
+	return s.adapter.Stat(ctx, path)
+}
+
 // MemorySampleStore is an in-memory implementation for tests.
 type MemorySampleStore struct {
-	mu   sync.RWMutex
-	data map[string][]byte
+	mu       sync.RWMutex
+	data     map[string][]byte
+	modTimes map[string]time.Time
 }
 
 // NewMemorySampleStore creates an in-memory sample store.
 func NewMemorySampleStore() *MemorySampleStore {
-	return &MemorySampleStore{data: make(map[string][]byte)}
+	return &MemorySampleStore{data: make(map[string][]byte), modTimes: make(map[string]time.Time)}
 }
 
 func (s *MemorySampleStore) Put(ctx context.Context, path string, data []byte) error {
@@ -70,6 +80,7 @@ func (s *MemorySampleStore) Put(ctx context.Context, path string, data []byte) e
 	defer s.mu.Unlock()
 	copyData := append([]byte(nil), data...)
 	s.data[path] = copyData
+	s.modTimes[path] = time.Now()
 	return nil
 }
 
@@ -95,3 +106,16 @@ func (s *MemorySampleStore) Exists(ctx context.Context, path string) (bool, erro
 	_, ok := s.data[path]
 	return ok, nil
 }
+
+func (s *MemorySampleStore) Stat(ctx context.Context, path string) (*Metadata, error) {
+	if s == nil {
+		return nil, fmt.Errorf("memory sample store is nil")
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.data[path]
+	if !ok {
+		return nil, fmt.Errorf("sample not found: %s", path)
+	}
+	return &Metadata{Path: path, Size: int64(len(data)), LastModified: s.modTimes[path].Unix()}, nil
+}
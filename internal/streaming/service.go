@@ -4,27 +4,43 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/internal/util"
 	"github.com/unalkalkan/TwelveReader/pkg/types"
 )
 
+// DefaultPollInterval is how often a followed stream checks for new segments
+const DefaultPollInterval = time.Second
+
 // Service handles streaming of book segments
 type Service struct {
-	bookRepo book.Repository
+	bookRepo     book.Repository
+	storage      storage.Adapter
+	pollInterval time.Duration
 }
 
 // NewService creates a new streaming service
-func NewService(bookRepo book.Repository) *Service {
+func NewService(bookRepo book.Repository, storage storage.Adapter) *Service {
 	return &Service{
-		bookRepo: bookRepo,
+		bookRepo:     bookRepo,
+		storage:      storage,
+		pollInterval: DefaultPollInterval,
 	}
 }
 
+// SetPollInterval sets how often followed streams check for new segments
+func (s *Service) SetPollInterval(interval time.Duration) {
+	s.pollInterval = interval
+}
+
 // StreamItem represents a single item in the NDJSON stream
 type StreamItem struct {
 	*types.Segment
-	AudioURL string `json:"audio_url"`
+	AudioURL   string `json:"audio_url"`
+	AudioReady bool   `json:"audio_ready"` // Whether the audio at AudioURL has been synthesized
 }
 
 // StreamSegments returns segments as NDJSON for streaming playback
@@ -57,8 +73,9 @@ func (s *Service) StreamSegments(ctx context.Context, bookID string, afterSegmen
 		audioURL := s.getAudioURL(bookID, seg.ID)
 
 		item := StreamItem{
-			Segment:  seg,
-			AudioURL: audioURL,
+			Segment:    seg,
+			AudioURL:   audioURL,
+			AudioReady: s.audioReady(ctx, bookID, seg),
 		}
 		items = append(items, item)
 	}
@@ -66,6 +83,78 @@ func (s *Service) StreamSegments(ctx context.Context, bookID string, afterSegmen
 	return items, nil
 }
 
+// FollowSegments passes segments to emit as the pipeline produces them,
+// starting after afterSegmentID when given. A segment is emitted when it
+// first appears and again once its audio is ready. It returns nil after the
+// book is synthesized or fails and every segment has been emitted, or the
+// first error from emit or ctx.
+func (s *Service) FollowSegments(ctx context.Context, bookID string, afterSegmentID string, emit func(StreamItem) error) error {
+	emitted := make(map[string]bool) // Segment ID to whether its audio was ready
+	for {
+		// Read the status before the segments so none are missed when the
+		// pipeline finishes in between
+		book, err := s.bookRepo.GetBook(ctx, bookID)
+		if err != nil {
+			return fmt.Errorf("failed to get book: %w", err)
+		}
+		segments, err := s.bookRepo.ListSegments(ctx, bookID)
+		if err != nil {
+			return fmt.Errorf("failed to list segments: %w", err)
+		}
+
+		started := afterSegmentID == ""
+		for _, seg := range segments {
+			if !started {
+				started = seg.ID == afterSegmentID
+				continue
+			}
+			wasReady, seen := emitted[seg.ID]
+			if wasReady {
+				continue
+			}
+			ready := s.audioReady(ctx, bookID, seg)
+			if seen && !ready {
+				continue
+			}
+			item := StreamItem{
+				Segment:    seg,
+				AudioURL:   s.getAudioURL(bookID, seg.ID),
+				AudioReady: ready,
+			}
+			if err := emit(item); err != nil {
+				return err
+			}
+			emitted[seg.ID] = ready
+		}
+
+		switch book.Status {
+		case "synthesized", "error", "synthesis_error":
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.pollInterval):
+		}
+	}
+}
+
+// audioReady reports whether synthesized audio of a segment is stored.
+// Segments get their voice ID once their audio is stored, so others are
+// not looked up.
+func (s *Service) audioReady(ctx context.Context, bookID string, seg *types.Segment) bool {
+	if seg.VoiceID == "" || s.storage == nil {
+		return false
+	}
+	for _, format := range util.AudioFormats() {
+		if exists, err := s.storage.Exists(ctx, util.GetAudioPath(bookID, seg.ID, format)); err == nil && exists {
+			return true
+		}
+	}
+	return false
+}
+
 // getAudioURL generates the audio URL for a segment
 func (s *Service) getAudioURL(bookID, segmentID string) string {
 	// In production, this would be a signed URL or CDN URL
@@ -78,7 +78,7 @@ func TestService_StreamSegments(t *testing.T) {
 	}
 
 	// Create streaming service
-	service := NewService(repo)
+	service := NewService(repo, storageAdapter)
 
 	t.Run("StreamAll", func(t *testing.T) {
 		items, err := service.StreamSegments(ctx, "book_stream_001", "")
@@ -184,3 +184,91 @@ func TestGetAudioURL(t *testing.T) {
 		t.Errorf("Expected URL to contain '%s', got '%s'", expectedPrefix, url)
 	}
 }
+
+func TestService_FollowSegments(t *testing.T) {
+	ctx := context.Background()
+
+	storageAdapter, err := storage.NewLocalAdapter(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage adapter: %v", err)
+	}
+	defer storageAdapter.Close()
+
+	repo := book.NewRepository(storageAdapter)
+	service := NewService(repo, storageAdapter)
+	service.SetPollInterval(10 * time.Millisecond)
+
+	repo.SaveBook(ctx, &types.Book{ID: "book_follow", Status: "segmenting"})
+	repo.SaveSegment(ctx, &types.Segment{ID: "seg_001", BookID: "book_follow", Text: "First."})
+
+	items := make(chan StreamItem)
+	done := make(chan error, 1)
+	go func() {
+		done <- service.FollowSegments(ctx, "book_follow", "", func(item StreamItem) error {
+			items <- item
+			return nil
+		})
+	}()
+	next := func() StreamItem {
+		t.Helper()
+		select {
+		case item := <-items:
+			return item
+		case err := <-done:
+			t.Fatalf("Stream ended early: %v", err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for a stream item")
+		}
+		return StreamItem{}
+	}
+
+	if item := next(); item.ID != "seg_001" || item.AudioReady {
+		t.Fatalf("Expected seg_001 without audio, got %s (ready %v)", item.ID, item.AudioReady)
+	}
+
+	// Synthesizing the first segment and adding a second emits both
+	storageAdapter.Put(ctx, "books/book_follow/audio/seg_001.mp3", strings.NewReader("audio"))
+	repo.SaveSegment(ctx, &types.Segment{ID: "seg_001", BookID: "book_follow", Text: "First.", VoiceID: "voice-a"})
+	repo.SaveSegment(ctx, &types.Segment{ID: "seg_002", BookID: "book_follow", Text: "Second."})
+	if item := next(); item.ID != "seg_001" || !item.AudioReady {
+		t.Fatalf("Expected seg_001 again with audio, got %s (ready %v)", item.ID, item.AudioReady)
+	}
+	if item := next(); item.ID != "seg_002" || item.AudioReady {
+		t.Fatalf("Expected seg_002 without audio, got %s (ready %v)", item.ID, item.AudioReady)
+	}
+
+	repo.UpdateBook(ctx, &types.Book{ID: "book_follow", Status: "synthesized"})
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected the stream to end cleanly, got %v", err)
+		}
+	case item := <-items:
+		t.Fatalf("Unexpected item %s", item.ID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the stream to end once the book was synthesized")
+	}
+
+	t.Run("Resumes after a segment", func(t *testing.T) {
+		var ids []string
+		err := service.FollowSegments(ctx, "book_follow", "seg_001", func(item StreamItem) error {
+			ids = append(ids, item.ID)
+			return nil
+		})
+		if err != nil || len(ids) != 1 || ids[0] != "seg_002" {
+			t.Errorf("Expected only seg_002, got %v (%v)", ids, err)
+		}
+	})
+
+	t.Run("Stops when cancelled", func(t *testing.T) {
+		repo.UpdateBook(ctx, &types.Book{ID: "book_follow", Status: "synthesizing"})
+		cancelCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		err := service.FollowSegments(cancelCtx, "book_follow", "seg_002", func(item StreamItem) error {
+			return nil
+		})
+		if err != context.DeadlineExceeded {
+			t.Errorf("Expected deadline exceeded, got %v", err)
+		}
+	})
+}
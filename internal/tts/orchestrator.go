@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/unalkalkan/TwelveReader/internal/audio"
 	"github.com/unalkalkan/TwelveReader/internal/book"
 	"github.com/unalkalkan/TwelveReader/internal/provider"
 	"github.com/unalkalkan/TwelveReader/internal/storage"
@@ -183,6 +184,12 @@ func (o *Orchestrator) synthesizeSegment(ctx context.Context, segment *types.Seg
 
 	// Update segment with audio path and timestamps
 	segment.VoiceID = voiceID
+	segment.AudioDuration = 0
+	if duration, err := audio.Duration(resp.AudioData, resp.Format); err == nil {
+		segment.AudioDuration = duration.Seconds()
+	} else {
+		log.Printf("Could not measure audio of segment %s: %v", segment.ID, err)
+	}
 	if len(resp.Timestamps) > 0 {
 		segment.Timestamps = &types.TimestampData{
 			Precision: "word",
@@ -14,3 +14,19 @@ func GetAudioPath(bookID, segmentID, format string) string {
 func AudioFormats() []string {
 	return []string{"wav", "mp3", "ogg", "flac"}
 }
+
+// AudioMimeType returns the content type of an audio format
+func AudioMimeType(format string) string {
+	switch format {
+	case "mp3":
+		return "audio/mpeg"
+	case "wav":
+		return "audio/wav"
+	case "ogg":
+		return "audio/ogg"
+	case "flac":
+		return "audio/flac"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,405 @@
+// Package webhook notifies subscribed URLs when books reach lifecycle
+// statuses. Payloads are signed with HMAC-SHA256, failed deliveries are
+// retried with exponential backoff, and every attempt is recorded in the
+// debug store.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/debugstate"
+	"github.com/unalkalkan/TwelveReader/internal/netguard"
+	"github.com/unalkalkan/TwelveReader/internal/provider"
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// Lifecycle events subscribers can receive
+const (
+	EventVoiceMapping = "voice_mapping"
+	EventSynthesized  = "synthesized"
+	EventError        = "error"
+)
+
+// Events lists every lifecycle event
+var Events = []string{EventVoiceMapping, EventSynthesized, EventError}
+
+// Request headers of a delivery
+const (
+	SignatureHeader = "X-TwelveReader-Signature" // "sha256=" and the hex HMAC of the body
+	EventHeader     = "X-TwelveReader-Event"
+	DeliveryHeader  = "X-TwelveReader-Delivery"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBackoffMs = 500
+
+	// deliveryTimeout bounds a single delivery attempt
+	deliveryTimeout = 10 * time.Second
+
+	// subscriptionDir holds the subscriptions created through the API
+	subscriptionDir = "webhooks"
+)
+
+var (
+	// ErrNotFound is returned for unknown subscription IDs
+	ErrNotFound = errors.New("webhook subscription not found")
+
+	// ErrConfigured is returned when deleting a subscription that comes
+	// from the configuration file
+	ErrConfigured = errors.New("webhook subscription is configured in the configuration file")
+)
+
+// Dispatcher delivers lifecycle events to webhook subscriptions
+type Dispatcher struct {
+	storage storage.Adapter
+	store   *debugstate.Store
+
+	// Configured subscriptions may point anywhere the operator chooses;
+	// ones created through the API only reach public addresses
+	client       *http.Client
+	publicClient *http.Client
+	configured   []*types.WebhookSubscription
+
+	maxRetries     int
+	retryBackoffMs int
+
+	deliveries sync.WaitGroup
+
+	// stopping is closed by Shutdown so deliveries stop waiting to retry
+	stopping chan struct{}
+	stopOnce sync.Once
+}
+
+// NewDispatcher creates a dispatcher for the configured subscriptions and
+// the ones created through the API, which are kept in storage
+func NewDispatcher(adapter storage.Adapter, configs []types.WebhookConfig) *Dispatcher {
+	configured := make([]*types.WebhookSubscription, 0, len(configs))
+	for i, cfg := range configs {
+		configured = append(configured, &types.WebhookSubscription{
+			ID:     fmt.Sprintf("config_%d", i+1),
+			URL:    cfg.URL,
+			Secret: cfg.Secret,
+			Events: cfg.Events,
+			Source: "config",
+		})
+	}
+	return &Dispatcher{
+		storage:        adapter,
+		store:          debugstate.NewStore(adapter),
+		client:         &http.Client{Timeout: deliveryTimeout},
+		publicClient:   &http.Client{Timeout: deliveryTimeout, Transport: netguard.NewTransport()},
+		configured:     configured,
+		maxRetries:     defaultMaxRetries,
+		retryBackoffMs: defaultRetryBackoffMs,
+		stopping:       make(chan struct{}),
+	}
+}
+
+// SetRetryPolicy sets how often a failed delivery is retried and the base
+// of its exponential backoff
+func (d *Dispatcher) SetRetryPolicy(maxRetries, retryBackoffMs int) {
+	if maxRetries >= 0 {
+		d.maxRetries = maxRetries
+	}
+	if retryBackoffMs > 0 {
+		d.retryBackoffMs = retryBackoffMs
+	}
+}
+
+// ValidateSubscription checks a subscription's URL and events
+func ValidateSubscription(rawURL string, events []string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid webhook url: %q", rawURL)
+	}
+	for _, event := range events {
+		if event == "" || EventForStatus(event) != event {
+			return fmt.Errorf("invalid webhook event: %q (must be one of %s)", event, strings.Join(Events, ", "))
+		}
+	}
+	return nil
+}
+
+// EventForStatus returns the lifecycle event of a book status, or "" for
+// statuses without one
+func EventForStatus(status string) string {
+	switch status {
+	case "voice_mapping":
+		return EventVoiceMapping
+	case "synthesized":
+		return EventSynthesized
+	case "error", "synthesis_error":
+		return EventError
+	default:
+		return ""
+	}
+}
+
+// ListSubscriptions returns the configured subscriptions followed by the
+// ones created through the API, oldest first
+func (d *Dispatcher) ListSubscriptions(ctx context.Context) ([]*types.WebhookSubscription, error) {
+	paths, err := d.storage.List(ctx, subscriptionDir+string(filepath.Separator))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	created := make([]*types.WebhookSubscription, 0, len(paths))
+	for _, path := range paths {
+		subscription, err := d.readSubscription(ctx, path)
+		if err != nil {
+			log.Printf("[ListSubscriptions] Skipping unreadable webhook subscription %s: %v", path, err)
+			continue
+		}
+		created = append(created, subscription)
+	}
+	sort.Slice(created, func(i, j int) bool { return created[i].CreatedAt.Before(created[j].CreatedAt) })
+	return append(append([]*types.WebhookSubscription{}, d.configured...), created...), nil
+}
+
+// CreateSubscription validates and stores a subscription. A secret is
+// generated when none is given. The URL must not point at a loopback,
+// private or link-local address.
+func (d *Dispatcher) CreateSubscription(ctx context.Context, subscription *types.WebhookSubscription) error {
+	if err := ValidateSubscription(subscription.URL, subscription.Events); err != nil {
+		return err
+	}
+	parsed, _ := url.Parse(subscription.URL)
+	if err := netguard.CheckURL(parsed); err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if subscription.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+	subscription.ID = debugstate.NewID("webhook")
+	subscription.Source = "api"
+	subscription.CreatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(subscription)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook subscription: %w", err)
+	}
+	if err := d.storage.Put(ctx, subscriptionPath(subscription.ID), bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to save webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteSubscription removes a subscription created through the API
+func (d *Dispatcher) DeleteSubscription(ctx context.Context, id string) error {
+	for _, subscription := range d.configured {
+		if subscription.ID == id {
+			return ErrConfigured
+		}
+	}
+	path := subscriptionPath(debugstate.SafeID(id))
+	exists, err := d.storage.Exists(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to check webhook subscription: %w", err)
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	if err := d.storage.Delete(ctx, path); err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// Notify delivers an event about a book to every subscription of that event
+// in the background
+func (d *Dispatcher) Notify(ctx context.Context, event string, book *types.Book) {
+	deliveryID := debugstate.NewID("delivery")
+	body, err := json.Marshal(types.WebhookPayload{
+		DeliveryID: deliveryID,
+		Event:      event,
+		BookID:     book.ID,
+		Book:       book,
+		Time:       time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("[Notify] Failed to marshal %s webhook of book %s: %v", event, book.ID, err)
+		return
+	}
+
+	// Deliveries outlive the request that changed the book
+	ctx = context.WithoutCancel(ctx)
+	d.deliveries.Add(1)
+	go func() {
+		defer d.deliveries.Done()
+		subscriptions, err := d.ListSubscriptions(ctx)
+		if err != nil {
+			log.Printf("[Notify] %v", err)
+			return
+		}
+		for _, subscription := range subscriptions {
+			if !subscribed(subscription, event) {
+				continue
+			}
+			d.deliveries.Add(1)
+			go func() {
+				defer d.deliveries.Done()
+				d.deliver(ctx, subscription, deliveryID, event, book.ID, body)
+			}()
+		}
+	}()
+}
+
+// Wait blocks until all pending deliveries finished, including retries
+func (d *Dispatcher) Wait() {
+	d.deliveries.Wait()
+}
+
+// Shutdown cancels pending retries and waits for deliveries in flight,
+// giving up when ctx is done
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.stopOnce.Do(func() { close(d.stopping) })
+	done := make(chan struct{})
+	go func() {
+		d.deliveries.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deliver posts a payload to a subscription, retrying server errors and
+// network failures, and records every attempt
+func (d *Dispatcher) deliver(ctx context.Context, subscription *types.WebhookSubscription, deliveryID, event, bookID string, body []byte) {
+	for attempt := 0; attempt <= d.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(provider.ComputeBackoff(attempt-1, d.retryBackoffMs)):
+			case <-ctx.Done():
+				return
+			case <-d.stopping:
+				log.Printf("[deliver] Dropping retries of %s webhook for book %s to %s on shutdown", event, bookID, subscription.URL)
+				return
+			}
+		}
+
+		started := time.Now()
+		statusCode, err := d.post(ctx, subscription, deliveryID, event, body)
+		record := &types.WebhookDelivery{
+			ID:             fmt.Sprintf("%s_%s_%d", deliveryID, debugstate.SafeID(subscription.ID), attempt+1),
+			DeliveryID:     deliveryID,
+			SubscriptionID: subscription.ID,
+			BookID:         bookID,
+			Event:          event,
+			URL:            subscription.URL,
+			Attempt:        attempt + 1,
+			StatusCode:     statusCode,
+			Success:        err == nil,
+			DurationMS:     time.Since(started).Milliseconds(),
+			CreatedAt:      started.UTC(),
+		}
+		if err != nil {
+			record.Error = err.Error()
+		}
+		if saveErr := d.store.SaveWebhookDelivery(ctx, record); saveErr != nil {
+			log.Printf("[deliver] Failed to record webhook delivery %s: %v", record.ID, saveErr)
+		}
+
+		if err == nil {
+			return
+		}
+		log.Printf("[deliver] Attempt %d of %s webhook for book %s to %s failed: %v", attempt+1, event, bookID, subscription.URL, err)
+		if statusCode != 0 && !retryableStatus(statusCode) {
+			break
+		}
+	}
+
+	_ = d.store.SaveEvent(ctx, &types.DebugEvent{BookID: bookID, Scope: "system", Severity: "danger", Title: fmt.Sprintf("Webhook %s not delivered", event), Detail: subscription.URL, Source: "webhook"})
+}
+
+// post sends one delivery attempt and returns the response status
+func (d *Dispatcher) post(ctx context.Context, subscription *types.WebhookSubscription, deliveryID, event string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(subscription.Secret, body))
+
+	client := d.client
+	if subscription.Source != "config" {
+		client = d.publicClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of a payload
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryableStatus reports whether a response status may succeed on retry
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// subscribed reports whether a subscription receives an event
+func subscribed(subscription *types.WebhookSubscription, event string) bool {
+	if len(subscription.Events) == 0 {
+		return true
+	}
+	for _, subscribedEvent := range subscription.Events {
+		if subscribedEvent == event {
+			return true
+		}
+	}
+	return false
+}
+
+func subscriptionPath(id string) string {
+	return filepath.Join(subscriptionDir, id+".json")
+}
+
+func (d *Dispatcher) readSubscription(ctx context.Context, path string) (*types.WebhookSubscription, error) {
+	reader, err := d.storage.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	var subscription types.WebhookSubscription
+	if err := json.NewDecoder(reader).Decode(&subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
@@ -0,0 +1,273 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/internal/debugstate"
+	"github.com/unalkalkan/TwelveReader/internal/netguard"
+	"github.com/unalkalkan/TwelveReader/internal/storage"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// webhookReceiver is a test server recording the deliveries it receives
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int // Responses to the first requests; later ones get 200
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	t.Helper()
+	receiver := &webhookReceiver{statuses: statuses}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receiver.mu.Lock()
+		receiver.requests = append(receiver.requests, r)
+		receiver.bodies = append(receiver.bodies, body)
+		status := http.StatusOK
+		if len(receiver.requests) <= len(receiver.statuses) {
+			status = receiver.statuses[len(receiver.requests)-1]
+		}
+		receiver.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (r *webhookReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func newTestDispatcher(t *testing.T, configs ...types.WebhookConfig) (*Dispatcher, storage.Adapter) {
+	t.Helper()
+	adapter, err := storage.NewLocalAdapter(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage adapter: %v", err)
+	}
+	t.Cleanup(func() { adapter.Close() })
+	dispatcher := NewDispatcher(adapter, configs)
+	dispatcher.SetRetryPolicy(3, 1)
+	return dispatcher, adapter
+}
+
+func TestDispatcher_Notify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Signs payloads", func(t *testing.T) {
+		receiver := newWebhookReceiver(t)
+		dispatcher, _ := newTestDispatcher(t, types.WebhookConfig{URL: receiver.URL, Secret: "s3cret"})
+
+		dispatcher.Notify(ctx, EventSynthesized, &types.Book{ID: "book_1", Title: "Dune", Status: "synthesized"})
+		dispatcher.Wait()
+
+		if receiver.count() != 1 {
+			t.Fatalf("Expected 1 delivery, got %d", receiver.count())
+		}
+		req, body := receiver.requests[0], receiver.bodies[0]
+		if signature := req.Header.Get(SignatureHeader); signature != "sha256="+Sign("s3cret", body) {
+			t.Errorf("Unexpected signature %q", signature)
+		}
+		var payload types.WebhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("Invalid payload: %v", err)
+		}
+		if payload.Event != EventSynthesized || payload.BookID != "book_1" || payload.Book.Title != "Dune" {
+			t.Errorf("Unexpected payload %+v", payload)
+		}
+		if req.Header.Get(EventHeader) != EventSynthesized || req.Header.Get(DeliveryHeader) != payload.DeliveryID {
+			t.Errorf("Unexpected headers %v", req.Header)
+		}
+	})
+
+	t.Run("Retries server errors", func(t *testing.T) {
+		receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+		dispatcher, adapter := newTestDispatcher(t, types.WebhookConfig{URL: receiver.URL, Secret: "s3cret"})
+
+		dispatcher.Notify(ctx, EventError, &types.Book{ID: "book_1", Status: "error"})
+		dispatcher.Wait()
+
+		deliveries, err := debugstate.NewStore(adapter).ListWebhookDeliveries(ctx, "book_1", 0)
+		if err != nil || len(deliveries) != 3 {
+			t.Fatalf("Expected 3 recorded attempts, got %d (%v)", len(deliveries), err)
+		}
+		succeeded := 0
+		for _, delivery := range deliveries {
+			if delivery.Success {
+				succeeded++
+				if delivery.Attempt != 3 {
+					t.Errorf("Expected the third attempt to succeed, got attempt %d", delivery.Attempt)
+				}
+			} else if delivery.StatusCode < 500 || delivery.Error == "" {
+				t.Errorf("Unexpected failed attempt %+v", delivery)
+			}
+		}
+		if succeeded != 1 {
+			t.Errorf("Expected 1 successful attempt, got %d", succeeded)
+		}
+	})
+
+	t.Run("Gives up on client errors", func(t *testing.T) {
+		receiver := newWebhookReceiver(t, http.StatusGone)
+		dispatcher, adapter := newTestDispatcher(t, types.WebhookConfig{URL: receiver.URL, Secret: "s3cret"})
+
+		dispatcher.Notify(ctx, EventError, &types.Book{ID: "book_1", Status: "error"})
+		dispatcher.Wait()
+
+		if receiver.count() != 1 {
+			t.Errorf("Expected no retries, got %d requests", receiver.count())
+		}
+		events, _ := debugstate.NewStore(adapter).ListEvents(ctx, "book_1", 0)
+		if len(events) != 1 || events[0].Source != "webhook" {
+			t.Errorf("Expected a debug event for the failed delivery, got %+v", events)
+		}
+	})
+
+	t.Run("Filters events", func(t *testing.T) {
+		receiver := newWebhookReceiver(t)
+		dispatcher, _ := newTestDispatcher(t, types.WebhookConfig{URL: receiver.URL, Secret: "s3cret", Events: []string{EventSynthesized}})
+
+		dispatcher.Notify(ctx, EventVoiceMapping, &types.Book{ID: "book_1", Status: "voice_mapping"})
+		dispatcher.Wait()
+
+		if receiver.count() != 0 {
+			t.Errorf("Expected no delivery of an unsubscribed event, got %d", receiver.count())
+		}
+	})
+}
+
+func TestDispatcher_RefusesPrivateAddressesOfAPISubscriptions(t *testing.T) {
+	ctx := context.Background()
+	receiver := newWebhookReceiver(t)
+	dispatcher, adapter := newTestDispatcher(t)
+	dispatcher.SetRetryPolicy(0, 1)
+
+	// Stored directly, as from a host name that later resolves to loopback
+	data, _ := json.Marshal(&types.WebhookSubscription{ID: "webhook_1", URL: receiver.URL, Secret: "s3cret", Source: "api"})
+	adapter.Put(ctx, subscriptionPath("webhook_1"), bytes.NewReader(data))
+
+	dispatcher.Notify(ctx, EventSynthesized, &types.Book{ID: "book_1", Status: "synthesized"})
+	dispatcher.Wait()
+
+	if receiver.count() != 0 {
+		t.Errorf("Expected no delivery to a loopback address, got %d", receiver.count())
+	}
+	deliveries, _ := debugstate.NewStore(adapter).ListWebhookDeliveries(ctx, "book_1", 0)
+	if len(deliveries) != 1 || deliveries[0].Success || !strings.Contains(deliveries[0].Error, netguard.ErrNotPublic.Error()) {
+		t.Errorf("Expected a refused delivery to be recorded, got %+v", deliveries)
+	}
+}
+
+func TestDispatcher_Shutdown(t *testing.T) {
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release }))
+	defer receiver.Close()
+	defer close(release)
+	dispatcher, _ := newTestDispatcher(t, types.WebhookConfig{URL: receiver.URL, Secret: "s3cret"})
+	t.Cleanup(dispatcher.Wait)
+
+	dispatcher.Notify(context.Background(), EventSynthesized, &types.Book{ID: "book_1", Status: "synthesized"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := dispatcher.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Shutdown to give up on a pending delivery, got %v", err)
+	}
+}
+
+func TestDispatcher_ShutdownCancelsRetries(t *testing.T) {
+	attempts := make(chan struct{}, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts <- struct{}{}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+	dispatcher, _ := newTestDispatcher(t, types.WebhookConfig{URL: receiver.URL, Secret: "s3cret"})
+	dispatcher.SetRetryPolicy(3, 60000)
+
+	dispatcher.Notify(context.Background(), EventSynthesized, &types.Book{ID: "book_1", Status: "synthesized"})
+	<-attempts
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := dispatcher.Shutdown(ctx); err != nil {
+		t.Fatalf("Expected Shutdown to drain without waiting out the backoff, got %v", err)
+	}
+	if len(attempts) != 0 {
+		t.Errorf("Expected no retries after Shutdown, got %d", len(attempts))
+	}
+}
+
+func TestDispatcher_Subscriptions(t *testing.T) {
+	ctx := context.Background()
+	dispatcher, _ := newTestDispatcher(t, types.WebhookConfig{URL: "https://cms.example.com/hooks", Secret: "s3cret"})
+
+	subscription := &types.WebhookSubscription{URL: "https://ops.example.com/hooks", Events: []string{EventError}}
+	if err := dispatcher.CreateSubscription(ctx, subscription); err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+	if subscription.ID == "" || subscription.Secret == "" || subscription.Source != "api" {
+		t.Errorf("Expected an ID and a generated secret, got %+v", subscription)
+	}
+	for _, rawURL := range []string{"ftp://example.com", "http://127.0.0.1:8080/hooks", "http://169.254.169.254/latest", "http://localhost/hooks"} {
+		if err := dispatcher.CreateSubscription(ctx, &types.WebhookSubscription{URL: rawURL}); err == nil {
+			t.Errorf("Expected %s to be rejected", rawURL)
+		}
+	}
+
+	subscriptions, err := dispatcher.ListSubscriptions(ctx)
+	if err != nil || len(subscriptions) != 2 || subscriptions[0].ID != "config_1" || subscriptions[1].ID != subscription.ID {
+		t.Fatalf("Unexpected subscriptions %+v (%v)", subscriptions, err)
+	}
+
+	if err := dispatcher.DeleteSubscription(ctx, "config_1"); !errors.Is(err, ErrConfigured) {
+		t.Errorf("Expected ErrConfigured, got %v", err)
+	}
+	if err := dispatcher.DeleteSubscription(ctx, subscription.ID); err != nil {
+		t.Errorf("Failed to delete subscription: %v", err)
+	}
+	if err := dispatcher.DeleteSubscription(ctx, subscription.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWatchRepository(t *testing.T) {
+	ctx := context.Background()
+	receiver := newWebhookReceiver(t)
+	dispatcher, adapter := newTestDispatcher(t, types.WebhookConfig{URL: receiver.URL, Secret: "s3cret"})
+	repo := WatchRepository(book.NewRepository(adapter), dispatcher)
+
+	b := &types.Book{ID: "book_1", Status: "parsing"}
+	repo.SaveBook(ctx, b)
+	for _, status := range []string{"segmenting", "voice_mapping", "voice_mapping", "synthesizing", "error", "synthesis_error", "synthesized"} {
+		b.Status = status
+		if err := repo.UpdateBook(ctx, b); err != nil {
+			t.Fatalf("Failed to update book: %v", err)
+		}
+	}
+	dispatcher.Wait()
+
+	// Deliveries run concurrently, so only the set of events is fixed
+	var events []string
+	for _, req := range receiver.requests {
+		events = append(events, req.Header.Get(EventHeader))
+	}
+	sort.Strings(events)
+	if strings.Join(events, ",") != "error,synthesized,voice_mapping" {
+		t.Errorf("Expected one event per lifecycle transition, got %v", events)
+	}
+}
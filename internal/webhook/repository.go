@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+
+	"github.com/unalkalkan/TwelveReader/internal/book"
+	"github.com/unalkalkan/TwelveReader/pkg/types"
+)
+
+// watchedRepository is a book repository that notifies webhook subscribers
+// when a saved book enters a lifecycle status. Book statuses are set in many
+// places, so watching the repository catches every transition.
+type watchedRepository struct {
+	book.Repository
+	dispatcher *Dispatcher
+
+	mu       sync.Mutex
+	statuses map[string]string // Last saved status per book
+}
+
+// WatchRepository wraps a book repository so that status transitions to
+// "voice_mapping", "synthesized" and "error" are delivered to webhooks
+func WatchRepository(repo book.Repository, dispatcher *Dispatcher) book.Repository {
+	return &watchedRepository{
+		Repository: repo,
+		dispatcher: dispatcher,
+		statuses:   make(map[string]string),
+	}
+}
+
+// SaveBook stores book metadata and notifies subscribers of its status
+func (r *watchedRepository) SaveBook(ctx context.Context, b *types.Book) error {
+	if err := r.Repository.SaveBook(ctx, b); err != nil {
+		return err
+	}
+	r.observe(ctx, b, "")
+	return nil
+}
+
+// UpdateBook updates book metadata and notifies subscribers when its status
+// changed
+func (r *watchedRepository) UpdateBook(ctx context.Context, b *types.Book) error {
+	r.mu.Lock()
+	previous, known := r.statuses[b.ID]
+	r.mu.Unlock()
+	if !known {
+		// Not seen since the server started
+		if stored, err := r.Repository.GetBook(ctx, b.ID); err == nil && stored != nil {
+			previous = stored.Status
+		}
+	}
+
+	if err := r.Repository.UpdateBook(ctx, b); err != nil {
+		return err
+	}
+	r.observe(ctx, b, previous)
+	return nil
+}
+
+// DeleteBook removes a book and forgets its status
+func (r *watchedRepository) DeleteBook(ctx context.Context, bookID string) error {
+	r.mu.Lock()
+	delete(r.statuses, bookID)
+	r.mu.Unlock()
+	return r.Repository.DeleteBook(ctx, bookID)
+}
+
+// observe records a saved book's status and notifies subscribers when it
+// entered a lifecycle status
+func (r *watchedRepository) observe(ctx context.Context, b *types.Book, previous string) {
+	r.mu.Lock()
+	if last, ok := r.statuses[b.ID]; ok {
+		previous = last
+	}
+	r.statuses[b.ID] = b.Status
+	r.mu.Unlock()
+
+	if event := EventForStatus(b.Status); event != "" && event != EventForStatus(previous) {
+		r.dispatcher.Notify(ctx, event, b)
+	}
+}
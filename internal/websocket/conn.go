@@ -0,0 +1,315 @@
+// Package websocket implements the server side of the WebSocket protocol
+// (RFC 6455) for the JSON messages of the API's control channels. It
+// supports unfragmented and fragmented text and binary messages, answers
+// pings and performs the closing handshake; extensions and subprotocols are
+// not negotiated.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxMessageSize is the size of the largest message a connection
+// accepts
+const DefaultMaxMessageSize = 1 << 20
+
+// acceptGUID is appended to the client's key to compute Sec-WebSocket-Accept
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Frame opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close status codes
+const (
+	CloseNormal        = 1000
+	CloseGoingAway     = 1001
+	CloseProtocolError = 1002
+	CloseInvalidData   = 1007
+	CloseMessageTooBig = 1009
+	CloseInternalError = 1011
+	CloseTryAgainLater = 1013
+	closeNoStatus      = 1005
+)
+
+// maxControlPayloadLen is the size of the largest ping, pong or close payload
+const maxControlPayloadLen = 125
+
+// writeTimeout bounds writing one frame to a client that stopped reading
+const writeTimeout = 10 * time.Second
+
+// ErrClosed is returned by ReadMessage once the peer closed the connection
+var ErrClosed = errors.New("websocket: connection closed")
+
+// Conn is a server side WebSocket connection. Reads must come from one
+// goroutine; writes may come from any.
+type Conn struct {
+	conn           net.Conn
+	reader         *bufio.Reader
+	writer         *bufio.Writer
+	maxMessageSize int64
+
+	writeMu sync.Mutex
+	closed  bool // A close frame was sent; guarded by writeMu
+}
+
+// Upgrade completes the opening handshake of a WebSocket request and takes
+// over its connection. On failure it has already responded to the request.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("websocket: method %s not allowed", r.Method)
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "WebSocket upgrade required", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: not an upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		http.Error(w, "Invalid Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket: invalid key")
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket unsupported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: failed to hijack connection: %w", err)
+	}
+	// The server's read and write timeouts do not apply to the connection
+	conn.SetDeadline(time.Time{})
+
+	fmt.Fprintf(rw.Writer, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", acceptKey(key))
+	if err := rw.Writer.Flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: failed to write handshake: %w", err)
+	}
+	return &Conn{conn: conn, reader: rw.Reader, writer: rw.Writer, maxMessageSize: DefaultMaxMessageSize}, nil
+}
+
+// acceptKey computes Sec-WebSocket-Accept for a Sec-WebSocket-Key
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerHasToken reports whether a comma separated header contains token,
+// ignoring case
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetMaxMessageSize sets the size of the largest message ReadMessage accepts
+func (c *Conn) SetMaxMessageSize(size int64) {
+	c.maxMessageSize = size
+}
+
+// ReadMessage returns the payload of the next text or binary message. Pings
+// are answered while waiting. When the peer closes the connection the close
+// is answered and ErrClosed returned; protocol violations close the
+// connection with an error status.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var message []byte
+	started := false
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			code := closeNoStatus
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			c.WriteClose(code, "")
+			return nil, ErrClosed
+		case opText, opBinary:
+			if started {
+				return nil, c.fail(CloseProtocolError, "new message inside a fragmented message")
+			}
+			started = true
+			message = payload
+		case opContinuation:
+			if !started {
+				return nil, c.fail(CloseProtocolError, "continuation without a message")
+			}
+			if int64(len(message)+len(payload)) > c.maxMessageSize {
+				return nil, c.fail(CloseMessageTooBig, "message too big")
+			}
+			message = append(message, payload...)
+		default:
+			return nil, c.fail(CloseProtocolError, fmt.Sprintf("unknown opcode %d", opcode))
+		}
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+// readFrame reads one frame from the client and unmasks its payload
+func (c *Conn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "client frames must be masked")
+	}
+
+	length := int64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(extended[:]) & (1<<63 - 1))
+	}
+	if opcode >= opClose && (length > maxControlPayloadLen || !fin) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+	if length > c.maxMessageSize {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// fail closes the connection with a status after a protocol violation
+func (c *Conn) fail(code int, reason string) error {
+	c.WriteClose(code, reason)
+	return fmt.Errorf("websocket: %s", reason)
+}
+
+// WriteMessage sends a text message
+func (c *Conn) WriteMessage(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+// WriteJSON sends v encoded as JSON in a text message
+func (c *Conn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	return c.WriteMessage(data)
+}
+
+// Ping sends a ping, which clients answer to keep the connection alive
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// WriteClose starts or answers the closing handshake with a status code.
+// Nothing is sent after it.
+func (c *Conn) WriteClose(code int, reason string) error {
+	var payload []byte
+	if code != closeNoStatus {
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+		if len(reason) > maxControlPayloadLen-2 {
+			reason = reason[:maxControlPayloadLen-2]
+		}
+		payload = append(payload, reason...)
+	}
+	err := c.writeFrame(opClose, payload)
+
+	c.writeMu.Lock()
+	c.closed = true
+	c.writeMu.Unlock()
+	return err
+}
+
+// writeFrame sends one unmasked, unfragmented frame
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+
+	header := []byte{0x80 | opcode, 0}
+	switch length := len(payload); {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	defer c.conn.SetWriteDeadline(time.Time{})
+	if _, err := c.writer.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.writer.Write(payload); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+// Close closes the underlying connection without a closing handshake
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,159 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testClient is a WebSocket client speaking raw frames to a test server
+type testClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialTestServer(t *testing.T, server *httptest.Server) (*testClient, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	io.WriteString(conn, "GET /socket HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("Failed to read handshake: %v", err)
+	}
+	return &testClient{conn: conn, reader: reader}, resp
+}
+
+// writeFrame sends a masked frame
+func (c *testClient) writeFrame(t *testing.T, fin bool, opcode byte, payload []byte) {
+	t.Helper()
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, 0x80|byte(len(payload)))
+	default:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+}
+
+// readFrame reads an unmasked server frame
+func (c *testClient) readFrame(t *testing.T) (byte, []byte) {
+	t.Helper()
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		t.Fatalf("Failed to read frame: %v", err)
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var extended [2]byte
+		io.ReadFull(c.reader, extended[:])
+		length = int(binary.BigEndian.Uint16(extended[:]))
+	}
+	payload := make([]byte, length)
+	io.ReadFull(c.reader, payload)
+	return header[0] & 0x0F, payload
+}
+
+func TestConn(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetMaxMessageSize(300)
+		for {
+			message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteJSON(map[string]string{"echo": string(message)})
+		}
+	}))
+	defer server.Close()
+
+	t.Run("Handshake", func(t *testing.T) {
+		_, resp := dialTestServer(t, server)
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("Expected 101, got %d", resp.StatusCode)
+		}
+		// The example key and accept value of RFC 6455 section 1.3
+		if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+			t.Errorf("Unexpected Sec-WebSocket-Accept %q", accept)
+		}
+	})
+
+	t.Run("Rejects plain requests", func(t *testing.T) {
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUpgradeRequired {
+			t.Errorf("Expected 426, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Messages", func(t *testing.T) {
+		client, _ := dialTestServer(t, server)
+		client.writeFrame(t, true, opText, []byte("hello"))
+		if opcode, payload := client.readFrame(t); opcode != opText || string(payload) != `{"echo":"hello"}` {
+			t.Errorf("Unexpected reply %d %q", opcode, payload)
+		}
+
+		// Pings between fragments are answered first
+		client.writeFrame(t, false, opText, []byte("frag"))
+		client.writeFrame(t, true, opPing, []byte("p"))
+		client.writeFrame(t, true, opContinuation, []byte("mented"))
+		if opcode, payload := client.readFrame(t); opcode != opPong || string(payload) != "p" {
+			t.Errorf("Expected pong, got %d %q", opcode, payload)
+		}
+		if _, payload := client.readFrame(t); string(payload) != `{"echo":"fragmented"}` {
+			t.Errorf("Unexpected reply %q", payload)
+		}
+
+		client.writeFrame(t, true, opClose, binary.BigEndian.AppendUint16(nil, CloseNormal))
+		if opcode, payload := client.readFrame(t); opcode != opClose || binary.BigEndian.Uint16(payload) != CloseNormal {
+			t.Errorf("Expected the close to be answered, got %d %v", opcode, payload)
+		}
+	})
+
+	t.Run("Rejects oversized messages", func(t *testing.T) {
+		client, _ := dialTestServer(t, server)
+		client.writeFrame(t, true, opText, make([]byte, 301))
+		if opcode, payload := client.readFrame(t); opcode != opClose || binary.BigEndian.Uint16(payload) != CloseMessageTooBig {
+			t.Errorf("Expected close 1009, got %d %v", opcode, payload)
+		}
+	})
+
+	t.Run("Rejects unmasked frames", func(t *testing.T) {
+		client, _ := dialTestServer(t, server)
+		client.conn.Write([]byte{0x80 | opText, 2, 'h', 'i'})
+		if opcode, payload := client.readFrame(t); opcode != opClose || binary.BigEndian.Uint16(payload) != CloseProtocolError {
+			t.Errorf("Expected close 1002, got %d %v", opcode, payload)
+		}
+	})
+}
@@ -20,10 +20,19 @@ type Book struct {
 	Language      string    `json:"language"` // ISO-639-1 code
 	UploadedAt    time.Time `json:"uploaded_at"`
 	Status        string    `json:"status"`      // "uploaded", "parsing", "segmenting", "voice_mapping", "ready", "synthesizing", "synthesized", "error"
-	OrigFormat    string    `json:"orig_format"` // "pdf", "epub", "txt"
+	OrigFormat    string    `json:"orig_format"` // "pdf", "epub", "txt", "docx", "md", "html", "fb2"
 	Error         string    `json:"error,omitempty"`
 	TotalChapters int       `json:"total_chapters"`
 	TotalSegments int       `json:"total_segments"`
+	HasCover      bool      `json:"has_cover,omitempty"`    // Cover image extracted from the document
+	SourceURL     string    `json:"source_url,omitempty"`   // URL the book was imported from
+	FileSize      int64     `json:"file_size,omitempty"`    // Size of the raw file in bytes
+	ContentHash   string    `json:"content_hash,omitempty"` // SHA-256 of the raw file, hex encoded
+	ClonedFrom    string    `json:"cloned_from,omitempty"`  // Book whose processed data this book was copied from
+
+	// SkipFrontMatter drops chapters detected as front matter (cover, copyright,
+	// table of contents, index) before segmentation
+	SkipFrontMatter bool `json:"skip_front_matter,omitempty"`
 
 	// Progress tracking fields
 	TotalParagraphs     int `json:"total_paragraphs"`     // Total paragraphs to segment
@@ -45,6 +54,21 @@ type Chapter struct {
 	Title      string   `json:"title"`
 	TOCPath    []string `json:"toc_path"` // Hierarchical breadcrumbs
 	Paragraphs []string `json:"paragraphs"`
+
+	// FrontMatter is the kind of non-narrative section ("cover", "copyright",
+	// "toc", "index") detected by the parser, empty for body text
+	FrontMatter string `json:"front_matter,omitempty"`
+
+	// OCR results, set when some pages had no text layer and were recognized from images
+	OCRConfidence float64   `json:"ocr_confidence,omitempty"` // Average confidence across OCR pages (0-1)
+	OCRPages      []OCRPage `json:"ocr_pages,omitempty"`
+}
+
+// OCRPage records the OCR quality of a single source page
+type OCRPage struct {
+	Page          int     `json:"page"` // 1-based page number
+	Confidence    float64 `json:"confidence"`
+	LowConfidence bool    `json:"low_confidence,omitempty"`
 }
 
 // Segment represents a processed text segment with metadata
@@ -61,6 +85,7 @@ type Segment struct {
 	AudioStale       bool            `json:"audio_stale,omitempty"`    // Existing audio was generated with an older persona voice
 	StaleVoiceID     string          `json:"stale_voice_id,omitempty"` // Voice ID used by stale audio before regeneration
 	Timestamps       *TimestampData  `json:"timestamps,omitempty"`
+	AudioDuration    float64         `json:"audio_duration_seconds,omitempty"` // Length of the audio, measured when it was synthesized
 	SourceContext    *SourceContext  `json:"source_context,omitempty"`
 	Processing       *ProcessingInfo `json:"processing"`
 }
@@ -148,3 +173,38 @@ type PersonaDiscovery struct {
 	Unmapped        []string          `json:"unmapped"`         // Personas needing mapping
 	PendingSegments int               `json:"pending_segments"` // Segments waiting for voice mapping
 }
+
+// ListeningProgress is where the listener is in a book, saved so listening
+// resumes on any device
+type ListeningProgress struct {
+	BookID        string    `json:"book_id"`
+	SegmentID     string    `json:"segment_id"`
+	OffsetSeconds float64   `json:"offset_seconds"`   // Position within the segment's audio
+	Device        string    `json:"device,omitempty"` // Device that saved the progress
+	UpdatedAt     time.Time `json:"updated_at"`
+	Version       int64     `json:"version"` // Assigned by the server, incremented on every save
+}
+
+// Bookmark is a named position in a book
+type Bookmark struct {
+	ID            string    `json:"id"`
+	BookID        string    `json:"book_id"`
+	SegmentID     string    `json:"segment_id"`
+	OffsetSeconds float64   `json:"offset_seconds"`
+	Name          string    `json:"name"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Highlight marks text of a segment, optionally with a note
+type Highlight struct {
+	ID        string    `json:"id"`
+	BookID    string    `json:"book_id"`
+	SegmentID string    `json:"segment_id"`
+	StartChar int       `json:"start_char"` // Character range within the segment text; both 0 for the whole segment
+	EndChar   int       `json:"end_char"`
+	Text      string    `json:"text"` // The highlighted text
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
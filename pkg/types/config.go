@@ -6,6 +6,7 @@ type Config struct {
 	Storage   StorageConfig   `yaml:"storage" json:"storage"`
 	Providers ProvidersConfig `yaml:"providers" json:"providers"`
 	Pipeline  PipelineConfig  `yaml:"pipeline" json:"pipeline"`
+	Webhooks  []WebhookConfig `yaml:"webhooks" json:"webhooks"`
 }
 
 // ServerConfig holds HTTP server settings
@@ -14,6 +15,7 @@ type ServerConfig struct {
 	Port         int    `yaml:"port" json:"port"`
 	ReadTimeout  int    `yaml:"read_timeout" json:"read_timeout"`   // seconds
 	WriteTimeout int    `yaml:"write_timeout" json:"write_timeout"` // seconds
+	MaxUploadMB  int    `yaml:"max_upload_mb" json:"max_upload_mb"` // largest accepted book file, in MB
 }
 
 // StorageConfig defines storage adapter settings
@@ -84,8 +86,16 @@ type OCRProviderConfig struct {
 
 // PipelineConfig holds pipeline-level settings
 type PipelineConfig struct {
-	WorkerPoolSize int    `yaml:"worker_pool_size" json:"worker_pool_size"`
-	MaxRetries     int    `yaml:"max_retries" json:"max_retries"`
-	RetryBackoffMs int    `yaml:"retry_backoff_ms" json:"retry_backoff_ms"`
-	TempDir        string `yaml:"temp_dir" json:"temp_dir"`
+	WorkerPoolSize        int    `yaml:"worker_pool_size" json:"worker_pool_size"`
+	MaxRetries            int    `yaml:"max_retries" json:"max_retries"`
+	RetryBackoffMs        int    `yaml:"retry_backoff_ms" json:"retry_backoff_ms"`
+	TempDir               string `yaml:"temp_dir" json:"temp_dir"`
+	ChapterAudioSilenceMs int    `yaml:"chapter_audio_silence_ms" json:"chapter_audio_silence_ms"` // silence between segments in chapter audio
+}
+
+// WebhookConfig subscribes a URL to book lifecycle events
+type WebhookConfig struct {
+	URL    string   `yaml:"url" json:"url"`
+	Secret string   `yaml:"secret" json:"secret"` // HMAC-SHA256 key for the signature header
+	Events []string `yaml:"events" json:"events"` // "voice_mapping", "synthesized", "error"; empty for all
 }
@@ -18,6 +18,7 @@ type SynthJob struct {
 	OutputPath       string     `json:"output_path,omitempty"`
 	OutputFormat     string     `json:"output_format,omitempty"`
 	OutputBytes      int64      `json:"output_bytes,omitempty"`
+	AudioDurationSec float64    `json:"audio_duration_sec,omitempty"` // Length of the synthesized audio
 	RetryCount       int        `json:"retry_count"`
 	Error            string     `json:"error,omitempty"`
 	Worker           string     `json:"worker,omitempty"`
@@ -42,15 +43,16 @@ type PlaybackEvent struct {
 
 // AudioArtifactValidation reports whether a segment audio artifact exists and looks usable.
 type AudioArtifactValidation struct {
-	BookID      string    `json:"book_id"`
-	SegmentID   string    `json:"segment_id"`
-	Status      string    `json:"status"` // attached, missing, stale, invalid
-	Format      string    `json:"format,omitempty"`
-	Path        string    `json:"path,omitempty"`
-	Bytes       int64     `json:"bytes,omitempty"`
-	ContentType string    `json:"content_type,omitempty"`
-	Error       string    `json:"error,omitempty"`
-	CheckedAt   time.Time `json:"checked_at"`
+	BookID           string    `json:"book_id"`
+	SegmentID        string    `json:"segment_id"`
+	Status           string    `json:"status"` // attached, missing, stale, invalid
+	Format           string    `json:"format,omitempty"`
+	Path             string    `json:"path,omitempty"`
+	Bytes            int64     `json:"bytes,omitempty"`
+	AudioDurationSec float64   `json:"audio_duration_sec,omitempty"`
+	ContentType      string    `json:"content_type,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	CheckedAt        time.Time `json:"checked_at"`
 }
 
 // UserProgress summarizes the current single-user journey through a book.
@@ -70,6 +72,22 @@ type UserProgress struct {
 	UpdatedAt               time.Time `json:"updated_at"`
 }
 
+// WebhookDelivery records one attempt to deliver a webhook event.
+type WebhookDelivery struct {
+	ID             string    `json:"id"`
+	DeliveryID     string    `json:"delivery_id"` // Shared by all attempts of one event
+	SubscriptionID string    `json:"subscription_id"`
+	BookID         string    `json:"book_id"`
+	Event          string    `json:"event"`
+	URL            string    `json:"url"`
+	Attempt        int       `json:"attempt"`
+	StatusCode     int       `json:"status_code,omitempty"`
+	Success        bool      `json:"success"`
+	Error          string    `json:"error,omitempty"`
+	DurationMS     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
 // DebugEvent is the unified operational event shape used by polling and SSE endpoints.
 type DebugEvent struct {
 	ID        string    `json:"id"`
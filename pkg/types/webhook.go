@@ -0,0 +1,24 @@
+package types
+
+import "time"
+
+// WebhookSubscription is a URL notified of book lifecycle events. Source is
+// "config" for subscriptions from the configuration file and "api" for ones
+// created through the API.
+type WebhookSubscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"` // Empty for all events
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookPayload is the JSON body posted to webhook subscribers
+type WebhookPayload struct {
+	DeliveryID string    `json:"delivery_id"`
+	Event      string    `json:"event"` // "voice_mapping", "synthesized" or "error"
+	BookID     string    `json:"book_id"`
+	Book       *Book     `json:"book"`
+	Time       time.Time `json:"time"`
+}